@@ -1,9 +1,13 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"net/http"
+	"os"
+	"strings"
 
+	"github.com/djordjev/auth/internal/domain"
 	"github.com/djordjev/auth/internal/utils"
 	"github.com/djordjev/auth/packages/server"
 )
@@ -21,8 +25,86 @@ func main() {
 
 	defer server.Close()
 
+	// Manual key rotation: `auth rotate-keys`
+	if len(os.Args) > 1 && os.Args[1] == "rotate-keys" {
+		kid, err := server.RotateSigningKey()
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Printf("Rotated signing key, new key id %s\n", kid)
+		return
+	}
+
+	// Client registration: `auth clients create -name <name> [-redirect-uris ...] [-scopes ...] [-trusted] [-public]`
+	if len(os.Args) > 2 && os.Args[1] == "clients" && os.Args[2] == "create" {
+		client, public := parseClientFlags(os.Args[3:])
+
+		created, secret, err := server.CreateOAuthClient(client, public)
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Printf("Created client %s\n", created.Name)
+		fmt.Printf("Client id: %s\n", created.ID)
+		if secret != "" {
+			fmt.Printf("Client secret: %s\n", secret)
+			fmt.Println("Secret is shown only once, store it now")
+		}
+
+		return
+	}
+
 	server.Mount("/")
 
+	// Authenticating reverse proxy in front of configured upstreams
+	if config.HasProxy() {
+		err = server.MountProxy()
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	fmt.Printf("Running server on port %s\n", config.Port)
 	http.ListenAndServe(fmt.Sprintf(":%s", config.Port), r)
 }
+
+// parseClientFlags reads client to register from arguments of `auth clients create`
+func parseClientFlags(args []string) (client domain.OAuthClient, public bool) {
+	flags := flag.NewFlagSet("clients create", flag.ExitOnError)
+
+	name := flags.String("name", "", "name of the client shown on consent screen")
+	redirectURIs := flags.String("redirect-uris", "", "comma separated redirect URIs, none for clients using only client credentials or device flow")
+	scopes := flags.String("scopes", "", "comma separated scopes client can get with client credentials")
+	trusted := flags.Bool("trusted", false, "skip consent screen for this client")
+	flags.BoolVar(&public, "public", false, "client without secret that has to use PKCE")
+
+	flags.Parse(args)
+
+	if *name == "" {
+		fmt.Fprintln(os.Stderr, "-name is required")
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	client = domain.OAuthClient{
+		Name:         *name,
+		RedirectURIs: splitList(*redirectURIs),
+		Scopes:       splitList(*scopes),
+		Trusted:      *trusted,
+	}
+
+	return
+}
+
+func splitList(value string) []string {
+	items := []string{}
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
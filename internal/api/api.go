@@ -13,6 +13,7 @@ import (
 
 type Api interface {
 	Mount(point string)
+	MountProxy() error
 }
 
 type jsonApi struct {
@@ -39,6 +40,7 @@ func (a *jsonApi) setupMiddleware() {
 	r := a.subrouter
 
 	r.Use(middleware.Logger(a.logger))
+	r.Use(middleware.Client())
 	r.Use(chimiddleware.RequestID)
 	r.Use(chimiddleware.Recoverer)
 }
@@ -48,12 +50,53 @@ func (a *jsonApi) setupRoutes() {
 
 	r.Post("/signup", a.postSignup)
 	r.Post("/login", a.postLogin)
+	r.Post("/login/identify", a.postIdentify)
 	r.Delete("/delete", a.deleteAccount)
 	r.Post("/verify", a.postVerifyAccount)
 	r.Post("/forget", a.postForgetPassword)
 	r.Post("/passwordreset", a.postVerifyPasswordReset)
 	r.Get("/session", a.getSession)
 	r.Post("/logout", a.postLogout)
+	r.Post("/refresh", a.postRefresh)
+	r.Post("/refresh/revoke", a.postRevokeRefresh)
+	r.Post("/magic", a.postMagicLink)
+	r.Post("/login/magic", a.postLogInMagicLink)
+	r.Post("/code", a.postOneTimeCode)
+	r.Post("/login/code", a.postLogInOneTimeCode)
+	r.Post("/login/totp", a.postLogInTOTP)
+	r.Post("/mfa/totp/enroll", a.postEnrollTOTP)
+	r.Post("/mfa/totp/confirm", a.postConfirmTOTP)
+	r.Post("/login/recovery", a.postLogInRecoveryCode)
+	r.Get("/mfa/recovery", a.getRecoveryCodesCount)
+	r.Post("/mfa/recovery/regenerate", a.postRegenerateRecoveryCodes)
+	r.Post("/login/passkey/begin", a.postBeginPasskeyLogIn)
+	r.Post("/login/passkey/finish", a.postFinishPasskeyLogIn)
+	r.Post("/passkey/register/begin", a.postBeginPasskeyRegistration)
+	r.Post("/passkey/register/finish", a.postFinishPasskeyRegistration)
+	r.Get("/oidc/{provider}", a.getOIDCLogIn)
+	r.Get("/oidc/{provider}/callback", a.getOIDCCallback)
+	r.Get("/saml/{provider}", a.getSAMLLogIn)
+	r.Get("/saml/{provider}/metadata", a.getSAMLMetadata)
+	r.Post("/saml/{provider}/acs", a.postSAMLACS)
+	r.Get("/sessions", a.getSessions)
+	r.Delete("/sessions", a.deleteOtherSessions)
+	r.Delete("/sessions/{id}", a.deleteSession)
+	r.Get("/identities", a.getIdentities)
+	r.Delete("/identities/{provider}", a.deleteIdentity)
+	r.Post("/apikeys", a.postCreateAPIKey)
+	r.Get("/apikeys", a.getAPIKeys)
+	r.Delete("/apikeys/{id}", a.deleteAPIKey)
+	r.Get("/.well-known/openid-configuration", a.getDiscovery)
+	r.Get("/authorize", a.getAuthorize)
+	r.Post("/authorize/consent", a.postConsent)
+	r.Post("/token", a.postToken)
+	r.Get("/userinfo", a.getUserInfo)
+	r.Post("/introspect", a.postIntrospect)
+	r.HandleFunc("/forward-auth", a.handleForwardAuth)
+	r.Get("/.well-known/jwks.json", a.getJWKS)
+	r.Post("/device/code", a.postDeviceCode)
+	r.Get("/device/verify", a.getDeviceVerification)
+	r.Post("/device/verify", a.postDeviceVerification)
 }
 
 func (a *jsonApi) Mount(point string) {
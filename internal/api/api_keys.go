@@ -0,0 +1,132 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+type CreateAPIKeyRequest struct {
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type APIKeyResponse struct {
+	ID        uint64     `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// postCreateAPIKey responds with the key itself only once, it can't be retrieved later
+func (a *jsonApi) postCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req CreateAPIKeyRequest
+	logger := utils.MustGetLogger(r)
+
+	token := a.sessionToken(r)
+	if token == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	err := parseRequest(r, &req)
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	err = validateCreateAPIKey(req)
+	if err != nil {
+		respondWithError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	created, secret, err := a.domain.CreateAPIKey(setup, token, createAPIKeyRequestToAPIKey(req))
+	if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	mustWriteJSONResponse(w, CreateAPIKeyResponse{APIKeyResponse: apiKeyToResponse(created), Key: secret})
+}
+
+type APIKeysResponse struct {
+	APIKeys []APIKeyResponse `json:"api_keys"`
+}
+
+func (a *jsonApi) getAPIKeys(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+
+	token := a.sessionToken(r)
+	if token == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	keys, err := a.domain.APIKeys(setup, token)
+	if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, apiKeysToResponse(keys))
+}
+
+type RevokeAPIKeyResponse struct {
+	Revoked bool `json:"revoked"`
+}
+
+func (a *jsonApi) deleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	token := a.sessionToken(r)
+	if token == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	err = a.domain.RevokeAPIKey(setup, token, id)
+	if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err == domain.ErrAPIKeyNotFound {
+		respondWithError(w, "api key not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, RevokeAPIKeyResponse{Revoked: true})
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func withAPIKeyID(r *http.Request, id string) *http.Request {
+	routeContext := chi.NewRouteContext()
+	routeContext.URLParams.Add("id", id)
+
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeContext))
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	requestBuilder := utils.RequestBuilder("POST", "/apikeys")
+	createdAt := time.Date(2023, 11, 22, 10, 0, 0, 0, time.UTC)
+	expiresAt := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		request    string
+		token      string
+		statusCode int
+		response   string
+		key        domain.APIKey
+		returnKey  domain.APIKey
+		returnErr  error
+		callDomain bool
+	}{
+		{
+			name:       "success",
+			request:    `{"name": "ci", "scopes": ["/reports"], "expires_at": "2099-01-01T00:00:00Z"}`,
+			token:      "session",
+			statusCode: http.StatusOK,
+			response:   `{"id": 7, "name": "ci", "prefix": "ak_abcdefg", "scopes": ["/reports"], "expires_at": "2099-01-01T00:00:00Z", "created_at": "2023-11-22T10:00:00Z", "key": "ak_abcdefghijk"}`,
+			key:        domain.APIKey{Name: "ci", Scopes: []string{"/reports"}, ExpiresAt: expiresAt},
+			returnKey:  domain.APIKey{ID: 7, Name: "ci", Prefix: "ak_abcdefg", Scopes: []string{"/reports"}, ExpiresAt: expiresAt, CreatedAt: createdAt},
+			callDomain: true,
+		},
+		{
+			name:       "not logged in",
+			request:    `{"name": "ci"}`,
+			token:      "ak_abcdefghijk",
+			statusCode: http.StatusUnauthorized,
+			response:   utils.ErrorJSON("unauthorized"),
+			key:        domain.APIKey{Name: "ci"},
+			returnErr:  domain.ErrNoSession,
+			callDomain: true,
+		},
+		{
+			name:       "missing name",
+			request:    `{"scopes": ["/reports"]}`,
+			token:      "session",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("missing name"),
+		},
+		{
+			name:       "expired",
+			request:    `{"name": "ci", "expires_at": "2020-01-01T00:00:00Z"}`,
+			token:      "session",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("expiration has to be in the future"),
+		},
+		{
+			name:       "scope that is not a path",
+			request:    `{"name": "ci", "scopes": ["read"]}`,
+			token:      "session",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("scopes have to be paths starting with /"),
+		},
+		{
+			name:       "missing session",
+			request:    `{"name": "ci"}`,
+			statusCode: http.StatusUnauthorized,
+			response:   utils.ErrorJSON("unauthorized"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.callDomain {
+				secret := ""
+				if tc.returnErr == nil {
+					secret = "ak_abcdefghijk"
+				}
+
+				baseMock.EXPECT().CreateAPIKey(mock.Anything, tc.token, tc.key).Return(tc.returnKey, secret, tc.returnErr)
+			}
+
+			req := requestBuilder(tc.request)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+
+			api := NewApi(utils.Config{SessionCookie: "_tkn"}, mux, baseMock, sl)
+			api.postCreateAPIKey(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
+
+func TestAPIKeys(t *testing.T) {
+	t.Parallel()
+
+	rr := httptest.NewRecorder()
+	baseMock := domain.NewMockDomain(t)
+	createdAt := time.Date(2023, 11, 22, 10, 0, 0, 0, time.UTC)
+
+	baseMock.EXPECT().APIKeys(mock.Anything, "session").Return([]domain.APIKey{
+		{ID: 7, Name: "ci", Prefix: "ak_abcdefg", Scopes: []string{}, CreatedAt: createdAt},
+	}, nil)
+
+	req := utils.RequestBuilder("GET", "/apikeys")("")
+	req.AddCookie(&http.Cookie{Name: "_tkn", Value: "session"})
+
+	api := NewApi(utils.Config{SessionCookie: "_tkn"}, mux, baseMock, sl)
+	api.getAPIKeys(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"api_keys": [{"id": 7, "name": "ci", "prefix": "ak_abcdefg", "scopes": [], "created_at": "2023-11-22T10:00:00Z"}]}`, rr.Body.String())
+}
+
+func TestDeleteAPIKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		id         string
+		statusCode int
+		response   string
+		returnErr  error
+		callDomain bool
+	}{
+		{
+			name:       "success",
+			id:         "7",
+			statusCode: http.StatusOK,
+			response:   `{"revoked": true}`,
+			callDomain: true,
+		},
+		{
+			name:       "not found",
+			id:         "7",
+			statusCode: http.StatusNotFound,
+			response:   utils.ErrorJSON("api key not found"),
+			returnErr:  domain.ErrAPIKeyNotFound,
+			callDomain: true,
+		},
+		{
+			name:       "invalid id",
+			id:         "ci",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid request"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.callDomain {
+				baseMock.EXPECT().RevokeAPIKey(mock.Anything, "session", uint64(7)).Return(tc.returnErr)
+			}
+
+			req := utils.RequestBuilder("DELETE", "/apikeys/"+tc.id)("")
+			req.AddCookie(&http.Cookie{Name: "_tkn", Value: "session"})
+
+			api := NewApi(utils.Config{SessionCookie: "_tkn"}, mux, baseMock, sl)
+			api.deleteAPIKey(rr, withAPIKeyID(req, tc.id))
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
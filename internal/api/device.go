@@ -0,0 +1,157 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+)
+
+type DeviceCodeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// postDeviceCode starts device authorization grant. Like the token endpoint
+// it accepts form encoded request with optional client authentication.
+func (a *jsonApi) postDeviceCode(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+
+	err := r.ParseForm()
+	if err != nil {
+		respondWithError(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	clientId := r.PostForm.Get("client_id")
+	clientSecret := r.PostForm.Get("client_secret")
+	if id, secret, ok := r.BasicAuth(); ok {
+		clientId, _ = url.QueryUnescape(id)
+		clientSecret, _ = url.QueryUnescape(secret)
+	}
+
+	if clientId == "" {
+		respondWithError(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	device, err := a.domain.AuthorizeDevice(setup, clientId, clientSecret, r.PostForm.Get("scope"))
+	if err == domain.ErrDeviceFlowNotConfigured {
+		respondWithError(w, "device authorization is not configured", http.StatusNotFound)
+		return
+	} else if err == domain.ErrInvalidClient {
+		respondWithError(w, "invalid_client", http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	mustWriteJSONResponse(w, DeviceCodeResponse{
+		DeviceCode:              device.DeviceCode,
+		UserCode:                device.UserCode,
+		VerificationURI:         a.cfg.OIDCDeviceURL,
+		VerificationURIComplete: withQuery(a.cfg.OIDCDeviceURL, url.Values{"user_code": {device.UserCode}}),
+		ExpiresIn:               device.ExpiresIn,
+		Interval:                device.Interval,
+	})
+}
+
+type DeviceVerificationRequest struct {
+	UserCode string `json:"user_code"`
+	Approve  bool   `json:"approve"`
+}
+
+type DeviceVerificationResponse struct {
+	UserCode string `json:"user_code"`
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope"`
+	Approved bool   `json:"approved"`
+}
+
+// getDeviceVerification shows which client waits for approval on the device page
+func (a *jsonApi) getDeviceVerification(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+
+	token := a.sessionToken(r)
+	if token == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	userCode := r.URL.Query().Get("user_code")
+	if userCode == "" {
+		respondWithError(w, "missing user code", http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	device, err := a.domain.DeviceVerification(setup, token, userCode)
+	if err == domain.ErrDeviceFlowNotConfigured {
+		respondWithError(w, "device authorization is not configured", http.StatusNotFound)
+		return
+	} else if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err == domain.ErrInvalidUserCode {
+		respondWithError(w, "invalid or expired user code", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, deviceToVerificationResponse(device))
+}
+
+func (a *jsonApi) postDeviceVerification(w http.ResponseWriter, r *http.Request) {
+	var req DeviceVerificationRequest
+	logger := utils.MustGetLogger(r)
+
+	token := a.sessionToken(r)
+	if token == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	err := parseRequest(r, &req)
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	err = validateDeviceVerification(req)
+	if err != nil {
+		respondWithError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	device, err := a.domain.VerifyDevice(setup, token, req.UserCode, req.Approve)
+	if err == domain.ErrDeviceFlowNotConfigured {
+		respondWithError(w, "device authorization is not configured", http.StatusNotFound)
+		return
+	} else if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err == domain.ErrInvalidUserCode {
+		respondWithError(w, "invalid or expired user code", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, deviceToVerificationResponse(device))
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var deviceConfig = utils.Config{
+	SessionCookie: "_tkn",
+	OIDCIssuer:    "https://auth.example.com",
+	OIDCDeviceURL: "https://example.com/device",
+}
+
+func TestDeviceCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		body         string
+		statusCode   int
+		response     string
+		returnDevice domain.DeviceAuthorization
+		returnErr    error
+		callDomain   bool
+	}{
+		{
+			name:         "success",
+			body:         "client_id=cli&scope=openid",
+			statusCode:   http.StatusOK,
+			response:     `{"device_code": "device", "user_code": "BCDF-GHJK", "verification_uri": "https://example.com/device", "verification_uri_complete": "https://example.com/device?user_code=BCDF-GHJK", "expires_in": 600, "interval": 5}`,
+			returnDevice: domain.DeviceAuthorization{DeviceCode: "device", UserCode: "BCDF-GHJK", Interval: 5, ExpiresIn: 600},
+			callDomain:   true,
+		},
+		{
+			name:       "invalid client",
+			body:       "client_id=cli&scope=openid",
+			statusCode: http.StatusUnauthorized,
+			response:   utils.ErrorJSON("invalid_client"),
+			returnErr:  domain.ErrInvalidClient,
+			callDomain: true,
+		},
+		{
+			name:       "missing client",
+			body:       "scope=openid",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid_request"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.callDomain {
+				baseMock.EXPECT().AuthorizeDevice(mock.Anything, "cli", "", "openid").Return(tc.returnDevice, tc.returnErr)
+			}
+
+			req := utils.RequestBuilder("POST", "/device/code")(tc.body)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			api := NewApi(deviceConfig, mux, baseMock, sl)
+			api.postDeviceCode(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
+
+func TestDeviceVerification(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		request      string
+		token        string
+		statusCode   int
+		response     string
+		returnDevice domain.DeviceAuthorization
+		returnErr    error
+		callDomain   bool
+	}{
+		{
+			name:         "approve",
+			request:      `{"user_code": "bcdf-ghjk", "approve": true}`,
+			token:        "session",
+			statusCode:   http.StatusOK,
+			response:     `{"user_code": "BCDF-GHJK", "client_id": "cli", "scope": "openid", "approved": true}`,
+			returnDevice: domain.DeviceAuthorization{UserCode: "BCDF-GHJK", ClientID: "cli", Scope: "openid", Status: "approved"},
+			callDomain:   true,
+		},
+		{
+			name:       "invalid code",
+			request:    `{"user_code": "bcdf-ghjk", "approve": true}`,
+			token:      "session",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid or expired user code"),
+			returnErr:  domain.ErrInvalidUserCode,
+			callDomain: true,
+		},
+		{
+			name:       "missing code",
+			request:    `{"approve": true}`,
+			token:      "session",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("missing user code"),
+		},
+		{
+			name:       "not logged in",
+			request:    `{"user_code": "bcdf-ghjk", "approve": true}`,
+			statusCode: http.StatusUnauthorized,
+			response:   utils.ErrorJSON("unauthorized"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.callDomain {
+				baseMock.EXPECT().VerifyDevice(mock.Anything, tc.token, "bcdf-ghjk", true).Return(tc.returnDevice, tc.returnErr)
+			}
+
+			req := utils.RequestBuilder("POST", "/device/verify")(tc.request)
+			if tc.token != "" {
+				req.AddCookie(&http.Cookie{Name: "_tkn", Value: tc.token})
+			}
+
+			api := NewApi(deviceConfig, mux, baseMock, sl)
+			api.postDeviceVerification(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
+
+func TestDeviceTokenPolling(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		returnErr error
+		response  string
+	}{
+		{name: "pending", returnErr: domain.ErrAuthorizationPending, response: utils.ErrorJSON("authorization_pending")},
+		{name: "slow down", returnErr: domain.ErrSlowDown, response: utils.ErrorJSON("slow_down")},
+		{name: "denied", returnErr: domain.ErrAccessDenied, response: utils.ErrorJSON("access_denied")},
+		{name: "expired", returnErr: domain.ErrExpiredToken, response: utils.ErrorJSON("expired_token")},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			request := domain.TokenRequest{GrantType: deviceCodeGrantType, ClientID: "cli", DeviceCode: "device"}
+			baseMock.EXPECT().Token(mock.Anything, request).Return(domain.Tokens{}, tc.returnErr)
+
+			req := utils.RequestBuilder("POST", "/token")("grant_type=urn%3Aietf%3Aparams%3Aoauth%3Agrant-type%3Adevice_code&client_id=cli&device_code=device")
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			api := NewApi(deviceConfig, mux, baseMock, sl)
+			api.postToken(rr, req)
+
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
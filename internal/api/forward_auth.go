@@ -0,0 +1,81 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+)
+
+// handleForwardAuth answers subrequests of reverse proxies (nginx auth_request, Traefik ForwardAuth,
+// Caddy forward_auth) which keep method of the original request so any method is accepted. Request
+// that proxy is deciding about is described by forwarded headers.
+func (a *jsonApi) handleForwardAuth(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+
+	original := forwardedURL(r)
+
+	setup := domain.NewSetup(r.Context(), logger)
+	user, err := a.domain.ForwardAuth(setup, a.sessionToken(r), original.Path)
+	if err == domain.ErrNoSession {
+		a.respondWithLogIn(w, r, original)
+		return
+	} else if err == domain.ErrForbidden {
+		respondWithError(w, "forbidden", http.StatusForbidden)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	w.Header().Set("X-Auth-User-Id", strconv.FormatUint(user.ID, 10))
+	w.Header().Set("X-Auth-Email", user.Email)
+	w.Header().Set("X-Auth-Role", user.Role)
+	w.WriteHeader(http.StatusOK)
+}
+
+// respondWithLogIn sends browsers to the login page which returns them to the requested URL
+func (a *jsonApi) respondWithLogIn(w http.ResponseWriter, r *http.Request, returnTo *url.URL) {
+	if a.cfg.ForwardAuth.LoginURL != "" && strings.Contains(r.Header.Get("Accept"), "text/html") {
+		params := url.Values{"return_to": {returnTo.String()}}
+		http.Redirect(w, r, withQuery(a.cfg.ForwardAuth.LoginURL, params), http.StatusFound)
+		return
+	}
+
+	respondWithUnauthorized(w)
+}
+
+// forwardedURL rebuilds URL of the original request from X-Forwarded-* headers sent by
+// Traefik and Caddy or X-Original-URL and X-Original-URI commonly set for nginx
+func forwardedURL(r *http.Request) *url.URL {
+	if original, err := url.Parse(r.Header.Get("X-Original-URL")); err == nil && original.Host != "" {
+		return original
+	}
+
+	uri := r.Header.Get("X-Forwarded-Uri")
+	if uri == "" {
+		uri = r.Header.Get("X-Original-URI")
+	}
+
+	original, err := url.ParseRequestURI(uri)
+	if err != nil {
+		original = &url.URL{Path: "/"}
+	}
+
+	// without host login page gets only the path
+	original.Host = r.Header.Get("X-Forwarded-Host")
+	if original.Host == "" {
+		return original
+	}
+
+	original.Scheme = r.Header.Get("X-Forwarded-Proto")
+	if original.Scheme == "" {
+		original.Scheme = "https"
+	}
+
+	return original
+}
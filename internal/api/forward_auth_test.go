@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardAuth(t *testing.T) {
+	t.Parallel()
+
+	user := domain.User{ID: 452, Email: "djvukovic@gmail.com", Role: "admin"}
+
+	tests := []struct {
+		name       string
+		loginURL   string
+		headers    map[string]string
+		path       string
+		statusCode int
+		location   string
+		returnUser domain.User
+		returnErr  error
+		userHeader bool
+	}{
+		{
+			name:       "traefik",
+			headers:    map[string]string{"X-Forwarded-Host": "tools.example.com", "X-Forwarded-Uri": "/admin/users?page=2"},
+			path:       "/admin/users",
+			statusCode: http.StatusOK,
+			returnUser: user,
+			userHeader: true,
+		},
+		{
+			name:       "nginx",
+			headers:    map[string]string{"X-Original-URL": "https://tools.example.com/admin/users"},
+			path:       "/admin/users",
+			statusCode: http.StatusOK,
+			returnUser: user,
+			userHeader: true,
+		},
+		{
+			name:       "role not allowed",
+			headers:    map[string]string{"X-Original-URI": "/admin"},
+			path:       "/admin",
+			statusCode: http.StatusForbidden,
+			returnErr:  domain.ErrForbidden,
+		},
+		{
+			name:       "no session",
+			headers:    map[string]string{"X-Forwarded-Host": "tools.example.com", "X-Forwarded-Uri": "/admin", "Accept": "text/html"},
+			path:       "/admin",
+			statusCode: http.StatusUnauthorized,
+			returnErr:  domain.ErrNoSession,
+		},
+		{
+			name:       "browser redirected to login",
+			loginURL:   "https://example.com/login",
+			headers:    map[string]string{"X-Forwarded-Proto": "http", "X-Forwarded-Host": "tools.example.com", "X-Forwarded-Uri": "/admin", "Accept": "text/html"},
+			path:       "/admin",
+			statusCode: http.StatusFound,
+			location:   "https://example.com/login?return_to=http%3A%2F%2Ftools.example.com%2Fadmin",
+			returnErr:  domain.ErrNoSession,
+		},
+		{
+			name:       "api client not redirected",
+			loginURL:   "https://example.com/login",
+			headers:    map[string]string{"X-Forwarded-Uri": "/admin", "Accept": "application/json"},
+			path:       "/admin",
+			statusCode: http.StatusUnauthorized,
+			returnErr:  domain.ErrNoSession,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			baseMock.EXPECT().ForwardAuth(mock.Anything, "session", tc.path).Return(tc.returnUser, tc.returnErr)
+
+			req := utils.RequestBuilder("GET", "/forward-auth")("")
+			req.AddCookie(&http.Cookie{Name: "_tkn", Value: "session"})
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+
+			config := utils.Config{SessionCookie: "_tkn", ForwardAuth: utils.ForwardAuth{LoginURL: tc.loginURL}}
+
+			api := NewApi(config, mux, baseMock, sl)
+			api.handleForwardAuth(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.Equal(t, tc.location, rr.Header().Get("Location"))
+
+			if tc.userHeader {
+				require.Equal(t, "452", rr.Header().Get("X-Auth-User-Id"))
+				require.Equal(t, user.Email, rr.Header().Get("X-Auth-Email"))
+				require.Equal(t, user.Role, rr.Header().Get("X-Auth-Role"))
+			} else {
+				require.Empty(t, rr.Header().Get("X-Auth-User-Id"))
+			}
+		})
+	}
+}
@@ -61,6 +61,7 @@ type LogInRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	Email    string `json:"email"`
+	Tokens   bool   `json:"tokens"`
 }
 
 type LogInResponse struct {
@@ -71,6 +72,16 @@ type LogInResponse struct {
 	Verified bool   `json:"verified"`
 }
 
+type LogInTokensResponse struct {
+	LogInResponse
+	TokensResponse
+}
+
+type LogInChallengeResponse struct {
+	SecondFactorRequired bool   `json:"mfa_required"`
+	Challenge            string `json:"challenge"`
+}
+
 func (a *jsonApi) postLogin(w http.ResponseWriter, r *http.Request) {
 	var req LogInRequest
 	logger := utils.MustGetLogger(r)
@@ -89,27 +100,24 @@ func (a *jsonApi) postLogin(w http.ResponseWriter, r *http.Request) {
 
 	setup := domain.NewSetup(r.Context(), logger)
 	user, session, err := a.domain.LogIn(setup, logInRequestToUser(req))
-	if err == domain.ErrInvalidCredentials {
+	if err == domain.ErrSecondFactorRequired {
+		mustWriteJSONResponse(w, LogInChallengeResponse{SecondFactorRequired: true, Challenge: session})
+		return
+	} else if err == domain.ErrInvalidCredentials {
 		respondWithError(w, "invalid credentials", http.StatusBadRequest)
 		return
+	} else if err == domain.ErrIdentityLinkRequired {
+		respondWithError(w, "account with this email already exists", http.StatusConflict)
+		return
+	} else if err == domain.ErrSSORequired {
+		respondWithError(w, "log in with identity provider of your organization", http.StatusForbidden)
+		return
 	} else if err != nil {
 		respondWithError(w, "failed login attempt", http.StatusBadRequest)
 		return
 	}
 
-	cookie := &http.Cookie{
-		Name:     a.cfg.SessionCookie,
-		Value:    session,
-		Path:     "/",
-		MaxAge:   int(utils.SESSION_TTL.Milliseconds()),
-		HttpOnly: true,
-		Secure:   true,
-		SameSite: http.SameSiteLaxMode,
-	}
-
-	http.SetCookie(w, cookie)
-
-	mustWriteJSONResponse(w, userToLogInResponse(user))
+	a.respondWithSession(w, r, user, session, req.Tokens)
 }
 
 type DeleteAccountRequest struct {
@@ -198,24 +206,18 @@ func (a *jsonApi) postForgetPassword(w http.ResponseWriter, r *http.Request) {
 }
 
 type SessionResponse struct {
-	ID       uint64 `json:"id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
-	Email    string `json:"email"`
-	Verified bool   `json:"verified"`
+	ID       uint64   `json:"id"`
+	Username string   `json:"username"`
+	Role     string   `json:"role"`
+	Email    string   `json:"email"`
+	Verified bool     `json:"verified"`
+	Scopes   []string `json:"scopes,omitempty"`
 }
 
 func (a *jsonApi) getSession(w http.ResponseWriter, r *http.Request) {
-	var token string
 	logger := utils.MustGetLogger(r)
 
-	tokenCookie, err := r.Cookie(a.cfg.SessionCookie)
-	if err == nil {
-		token = tokenCookie.Value
-	} else {
-		token = r.URL.Query().Get("token")
-	}
-
+	token := a.sessionToken(r)
 	if token == "" {
 		respondWithBadRequest(w)
 		return
@@ -233,29 +235,23 @@ func (a *jsonApi) getSession(w http.ResponseWriter, r *http.Request) {
 		Role:     user.Role,
 		Email:    user.Email,
 		Verified: user.Verified,
+		Scopes:   user.Scopes,
 	}
 
 	mustWriteJSONResponse(w, response)
 }
 
 func (a *jsonApi) postLogout(w http.ResponseWriter, r *http.Request) {
-	var token string
 	logger := utils.MustGetLogger(r)
 
-	tokenCookie, err := r.Cookie(a.cfg.SessionCookie)
-	if err == nil {
-		token = tokenCookie.Value
-	} else {
-		token = r.URL.Query().Get("token")
-	}
-
+	token := a.sessionToken(r)
 	if token == "" {
 		respondWithBadRequest(w)
 		return
 	}
 
 	setup := domain.NewSetup(r.Context(), logger)
-	err = a.domain.Logout(setup, token)
+	err := a.domain.Logout(setup, token)
 	if err == domain.ErrNoSession {
 		respondWithBadRequest(w)
 		return
@@ -75,6 +75,32 @@ func TestLogIn(t *testing.T) {
 			responseCode: http.StatusOK,
 			responseBody: `{"id": 884, "username": "djvukovic", "email": "djvukovic@gmail.com", "role": "admin", "verified": true }`,
 		},
+		{
+			name:    "token mode",
+			request: requestBuilder(`{"email": "djvukovic@gmail.com", "password": "testee", "tokens": true}`),
+			setupDomain: func(d *domain.MockDomain, tc *testCase) {
+				d.EXPECT().LogIn(mock.Anything, userMatcher).Return(successUser, "session", nil)
+				d.EXPECT().ExchangeSession(mock.Anything, "session").Return(domain.SessionTokens{
+					AccessToken:  "access",
+					RefreshToken: "refresh",
+					ExpiresIn:    900,
+				}, nil)
+			},
+			responseCode: http.StatusOK,
+			responseBody: `{
+				"id": 884, "username": "djvukovic", "email": "djvukovic@gmail.com", "role": "admin", "verified": true,
+				"access_token": "access", "refresh_token": "refresh", "token_type": "Bearer", "expires_in": 900
+			}`,
+		},
+		{
+			name:    "second factor required",
+			request: requestBuilder(logInRequest),
+			setupDomain: func(d *domain.MockDomain, tc *testCase) {
+				d.EXPECT().LogIn(mock.Anything, userMatcher).Return(domain.User{}, "challenge-key", domain.ErrSecondFactorRequired)
+			},
+			responseCode: http.StatusOK,
+			responseBody: `{"mfa_required": true, "challenge": "challenge-key"}`,
+		},
 		{
 			name:         "validation fail",
 			request:      requestBuilder(`{}`),
@@ -96,6 +122,24 @@ func TestLogIn(t *testing.T) {
 			responseCode: http.StatusBadRequest,
 			responseBody: utils.ErrorJSON("invalid credentials"),
 		},
+		{
+			name:    "directory account email taken",
+			request: requestBuilder(logInRequest),
+			setupDomain: func(d *domain.MockDomain, tc *testCase) {
+				d.EXPECT().LogIn(mock.Anything, userMatcher).Return(domain.User{}, "", domain.ErrIdentityLinkRequired)
+			},
+			responseCode: http.StatusConflict,
+			responseBody: utils.ErrorJSON("account with this email already exists"),
+		},
+		{
+			name:    "single sign-on required",
+			request: requestBuilder(logInRequest),
+			setupDomain: func(d *domain.MockDomain, tc *testCase) {
+				d.EXPECT().LogIn(mock.Anything, userMatcher).Return(domain.User{}, "", domain.ErrSSORequired)
+			},
+			responseCode: http.StatusForbidden,
+			responseBody: utils.ErrorJSON("log in with identity provider of your organization"),
+		},
 		{
 			name:    "random error",
 			request: requestBuilder(logInRequest),
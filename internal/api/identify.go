@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+)
+
+type IdentifyRequest struct {
+	Email    string `json:"email"`
+	Username string `json:"username"`
+}
+
+type ConnectionResponse struct {
+	Provider string `json:"provider"`
+	Protocol string `json:"protocol"`
+}
+
+type IdentifyResponse struct {
+	Methods    []string            `json:"methods"`
+	Connection *ConnectionResponse `json:"connection,omitempty"`
+}
+
+func (a *jsonApi) postIdentify(w http.ResponseWriter, r *http.Request) {
+	var req IdentifyRequest
+	logger := utils.MustGetLogger(r)
+
+	err := parseRequest(r, &req)
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	err = validateIdentify(req)
+	if err != nil {
+		respondWithError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	methods, err := a.domain.DiscoverLogIn(setup, identifyRequestToUser(req))
+	if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, logInMethodsToResponse(methods))
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentify(t *testing.T) {
+	t.Parallel()
+
+	requestBuilder := utils.RequestBuilder("POST", "/login/identify")
+
+	tests := []struct {
+		name          string
+		request       string
+		user          domain.User
+		returnMethods domain.LogInMethods
+		returnErr     error
+		statusCode    int
+		response      string
+	}{
+		{
+			name:          "password and passkey",
+			request:       `{ "email": "djvukovic@gmail.com" }`,
+			user:          domain.User{Email: "djvukovic@gmail.com"},
+			returnMethods: domain.LogInMethods{Methods: []string{"password", "passkey"}},
+			statusCode:    http.StatusOK,
+			response:      `{ "methods": ["password", "passkey"] }`,
+		},
+		{
+			name:          "enterprise connection",
+			request:       `{ "username": "someone" }`,
+			user:          domain.User{Username: "someone"},
+			returnMethods: domain.LogInMethods{Methods: []string{"sso"}, Connection: domain.Connection{Provider: "okta", Protocol: "saml"}},
+			statusCode:    http.StatusOK,
+			response:      `{ "methods": ["sso"], "connection": { "provider": "okta", "protocol": "saml" } }`,
+		},
+		{
+			name:       "missing email and username",
+			request:    `{}`,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("missing email or username"),
+		},
+		{
+			name:       "internal error",
+			request:    `{ "email": "djvukovic@gmail.com" }`,
+			user:       domain.User{Email: "djvukovic@gmail.com"},
+			returnErr:  errors.New("random error"),
+			statusCode: http.StatusInternalServerError,
+			response:   utils.ErrorJSON("internal server error"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.user.Email != "" || tc.user.Username != "" {
+				baseMock.EXPECT().DiscoverLogIn(mock.Anything, tc.user).Return(tc.returnMethods, tc.returnErr)
+			}
+
+			api := NewApi(utils.Config{}, mux, baseMock, sl)
+			api.postIdentify(rr, requestBuilder(tc.request))
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
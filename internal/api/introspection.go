@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+)
+
+// IntrospectionResponse follows RFC 7662. Inactive tokens are described only by active field.
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	TokenType string `json:"token_type,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Role      string `json:"role,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+}
+
+// postIntrospect lets resource servers check session keys, api keys and access tokens.
+// Like the token endpoint it accepts form encoded request and client has to authenticate.
+// Token type hint is not needed since type of the token is recognized from the token itself.
+func (a *jsonApi) postIntrospect(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+
+	err := r.ParseForm()
+	if err != nil {
+		respondWithError(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	clientId := r.PostForm.Get("client_id")
+	clientSecret := r.PostForm.Get("client_secret")
+	if id, secret, ok := r.BasicAuth(); ok {
+		clientId, _ = url.QueryUnescape(id)
+		clientSecret, _ = url.QueryUnescape(secret)
+	}
+
+	token := r.PostForm.Get("token")
+	if clientId == "" || token == "" {
+		respondWithError(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	setup := domain.NewSetup(r.Context(), logger)
+	introspection, err := a.domain.Introspect(setup, clientId, clientSecret, token)
+	if err == domain.ErrInvalidClient {
+		respondWithError(w, "invalid_client", http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, introspectionToResponse(introspection, a.cfg.OIDCIssuer))
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntrospect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                string
+		body                string
+		basicAuth           bool
+		statusCode          int
+		response            string
+		returnIntrospection domain.Introspection
+		returnErr           error
+		callDomain          bool
+	}{
+		{
+			name:       "active token",
+			body:       "token=session&token_type_hint=access_token",
+			basicAuth:  true,
+			statusCode: http.StatusOK,
+			response:   `{"active": true, "token_type": "api_key", "sub": "452", "username": "djvukovic", "role": "admin", "scope": "read write", "iat": 1700000000, "exp": 1700003600, "iss": "https://auth.example.com"}`,
+			returnIntrospection: domain.Introspection{
+				Active:    true,
+				TokenType: "api_key",
+				Subject:   "452",
+				Username:  "djvukovic",
+				Role:      "admin",
+				Scopes:    []string{"read", "write"},
+				IssuedAt:  time.Unix(1700000000, 0),
+				ExpiresAt: time.Unix(1700003600, 0),
+			},
+			callDomain: true,
+		},
+		{
+			name:       "credentials in form",
+			body:       "token=session&client_id=api&client_secret=secret",
+			statusCode: http.StatusOK,
+			response:   `{"active": false}`,
+			callDomain: true,
+		},
+		{
+			name:       "invalid client",
+			body:       "token=session",
+			basicAuth:  true,
+			statusCode: http.StatusUnauthorized,
+			response:   utils.ErrorJSON("invalid_client"),
+			returnErr:  domain.ErrInvalidClient,
+			callDomain: true,
+		},
+		{
+			name:       "missing token",
+			body:       "",
+			basicAuth:  true,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid_request"),
+		},
+		{
+			name:       "missing client",
+			body:       "token=session",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid_request"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.callDomain {
+				baseMock.EXPECT().Introspect(mock.Anything, "api", "secret", "session").Return(tc.returnIntrospection, tc.returnErr)
+			}
+
+			req := utils.RequestBuilder("POST", "/introspect")(tc.body)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tc.basicAuth {
+				req.SetBasicAuth("api", "secret")
+			}
+
+			api := NewApi(providerConfig, mux, baseMock, sl)
+			api.postIntrospect(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+)
+
+type MagicLinkRequest struct {
+	Email    string `json:"email"`
+	Username string `json:"username"`
+}
+
+type MagicLinkResponse struct {
+	Sent  bool   `json:"sent"`
+	Email string `json:"email"`
+}
+
+func (a *jsonApi) postMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req MagicLinkRequest
+	logger := utils.MustGetLogger(r)
+
+	err := parseRequest(r, &req)
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	err = validateMagicLink(req)
+	if err != nil {
+		respondWithError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	user, err := a.domain.MagicLinkRequest(setup, magicLinkRequestToUser(req))
+	if err == domain.ErrUserNotExist {
+		respondWithError(w, "user does not exist", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrMagicLinkNotConfigured {
+		respondWithError(w, "magic link login is not enabled", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, MagicLinkResponse{Sent: true, Email: user.Email})
+}
+
+type LogInMagicLinkRequest struct {
+	Token  string `json:"token"`
+	Tokens bool   `json:"tokens"`
+}
+
+func (a *jsonApi) postLogInMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req LogInMagicLinkRequest
+	logger := utils.MustGetLogger(r)
+
+	err := parseRequest(r, &req)
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	if req.Token == "" {
+		respondWithError(w, "invalid token", http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	user, session, err := a.domain.LogInMagicLink(setup, req.Token)
+	if err == domain.ErrSecondFactorRequired {
+		mustWriteJSONResponse(w, LogInChallengeResponse{SecondFactorRequired: true, Challenge: session})
+		return
+	} else if err == domain.ErrInvalidToken {
+		respondWithError(w, "invalid or expired login link", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	a.respondWithSession(w, r, user, session, req.Tokens)
+}
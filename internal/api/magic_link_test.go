@@ -0,0 +1,157 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMagicLink(t *testing.T) {
+	t.Parallel()
+
+	requestBuilder := utils.RequestBuilder("POST", "/magic")
+	user := domain.User{ID: 884, Email: "djvukovic@gmail.com", Username: "djvukovic"}
+
+	tests := []struct {
+		name       string
+		request    string
+		statusCode int
+		response   string
+		returnErr  error
+	}{
+		{
+			name:       "success",
+			request:    `{ "email": "djvukovic@gmail.com" }`,
+			statusCode: http.StatusOK,
+			response:   `{ "sent": true, "email": "djvukovic@gmail.com" }`,
+		},
+		{
+			name:       "missing email and username",
+			request:    `{}`,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("missing email or username"),
+		},
+		{
+			name:       "user does not exist",
+			request:    `{ "email": "djvukovic@gmail.com" }`,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("user does not exist"),
+			returnErr:  domain.ErrUserNotExist,
+		},
+		{
+			name:       "not configured",
+			request:    `{ "email": "djvukovic@gmail.com" }`,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("magic link login is not enabled"),
+			returnErr:  domain.ErrMagicLinkNotConfigured,
+		},
+		{
+			name:       "internal error",
+			request:    `{ "email": "djvukovic@gmail.com" }`,
+			statusCode: http.StatusInternalServerError,
+			response:   utils.ErrorJSON("internal server error"),
+			returnErr:  errors.New("random error"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.statusCode == http.StatusOK {
+				baseMock.EXPECT().MagicLinkRequest(mock.Anything, domain.User{Email: user.Email}).Return(user, nil)
+			} else if tc.returnErr != nil {
+				baseMock.EXPECT().MagicLinkRequest(mock.Anything, domain.User{Email: user.Email}).Return(domain.User{}, tc.returnErr)
+			}
+
+			api := NewApi(utils.Config{}, mux, baseMock, sl)
+			api.postMagicLink(rr, requestBuilder(tc.request))
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
+
+func TestLogInMagicLink(t *testing.T) {
+	t.Parallel()
+
+	requestBuilder := utils.RequestBuilder("POST", "/login/magic")
+	user := domain.User{ID: 884, Email: "djvukovic@gmail.com", Username: "djvukovic", Role: "admin", Verified: true}
+
+	tests := []struct {
+		name       string
+		request    string
+		statusCode int
+		response   string
+		returnUser domain.User
+		returnKey  string
+		returnErr  error
+		cookie     string
+	}{
+		{
+			name:       "success",
+			request:    `{ "token": "token" }`,
+			statusCode: http.StatusOK,
+			response:   `{"id": 884, "username": "djvukovic", "email": "djvukovic@gmail.com", "role": "admin", "verified": true }`,
+			returnUser: user,
+			returnKey:  "session",
+			cookie:     "session",
+		},
+		{
+			name:       "second factor required",
+			request:    `{ "token": "token" }`,
+			statusCode: http.StatusOK,
+			response:   `{ "mfa_required": true, "challenge": "challenge" }`,
+			returnKey:  "challenge",
+			returnErr:  domain.ErrSecondFactorRequired,
+		},
+		{
+			name:       "missing token",
+			request:    `{}`,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid token"),
+		},
+		{
+			name:       "invalid token",
+			request:    `{ "token": "token" }`,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid or expired login link"),
+			returnErr:  domain.ErrInvalidToken,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.returnKey != "" || tc.returnErr != nil {
+				baseMock.EXPECT().LogInMagicLink(mock.Anything, "token").Return(tc.returnUser, tc.returnKey, tc.returnErr)
+			}
+
+			api := NewApi(utils.Config{SessionCookie: "_tkn", SessionLifetime: 24 * time.Hour}, mux, baseMock, sl)
+			api.postLogInMagicLink(rr, requestBuilder(tc.request))
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+
+			cookies := rr.Result().Cookies()
+			if tc.cookie != "" {
+				require.Len(t, cookies, 1)
+				require.Equal(t, tc.cookie, cookies[0].Value)
+				require.Equal(t, 86400, cookies[0].MaxAge)
+			} else {
+				require.Empty(t, cookies)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"strings"
+
 	"github.com/djordjev/auth/internal/domain"
 )
 
@@ -28,6 +30,23 @@ func logInRequestToUser(req LogInRequest) domain.User {
 	return domain.User{Email: req.Email, Username: req.Username, Password: req.Password}
 }
 
+func identifyRequestToUser(req IdentifyRequest) domain.User {
+	return domain.User{Email: req.Email, Username: req.Username}
+}
+
+func logInMethodsToResponse(methods domain.LogInMethods) IdentifyResponse {
+	response := IdentifyResponse{Methods: methods.Methods}
+
+	if methods.Connection.Provider != "" {
+		response.Connection = &ConnectionResponse{
+			Provider: methods.Connection.Provider,
+			Protocol: methods.Connection.Protocol,
+		}
+	}
+
+	return response
+}
+
 func userToLogInResponse(user domain.User) LogInResponse {
 	return LogInResponse{
 		ID:       user.ID,
@@ -45,3 +64,115 @@ func deleteRequestToUser(req DeleteAccountRequest) domain.User {
 func forgetPasswordToUser(req ForgetPasswordRequest) domain.User {
 	return domain.User{Email: req.Email, Username: req.Username}
 }
+
+func magicLinkRequestToUser(req MagicLinkRequest) domain.User {
+	return domain.User{Email: req.Email, Username: req.Username}
+}
+
+func identitiesToResponse(identities []domain.Identity) IdentitiesResponse {
+	response := IdentitiesResponse{Identities: make([]IdentityResponse, 0, len(identities))}
+
+	for _, identity := range identities {
+		response.Identities = append(response.Identities, IdentityResponse{
+			Provider: identity.Provider,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+		})
+	}
+
+	return response
+}
+
+func deviceToVerificationResponse(device domain.DeviceAuthorization) DeviceVerificationResponse {
+	return DeviceVerificationResponse{
+		UserCode: device.UserCode,
+		ClientID: device.ClientID,
+		Scope:    device.Scope,
+		Approved: device.Status == "approved",
+	}
+}
+
+func sessionTokensToResponse(tokens domain.SessionTokens) TokensResponse {
+	return TokensResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    tokens.ExpiresIn,
+	}
+}
+
+func createAPIKeyRequestToAPIKey(req CreateAPIKeyRequest) domain.APIKey {
+	return domain.APIKey{Name: req.Name, Scopes: req.Scopes, ExpiresAt: req.ExpiresAt}
+}
+
+func apiKeyToResponse(key domain.APIKey) APIKeyResponse {
+	response := APIKeyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scopes:    key.Scopes,
+		CreatedAt: key.CreatedAt,
+	}
+
+	if !key.ExpiresAt.IsZero() {
+		expiresAt := key.ExpiresAt
+		response.ExpiresAt = &expiresAt
+	}
+
+	return response
+}
+
+func apiKeysToResponse(keys []domain.APIKey) APIKeysResponse {
+	response := APIKeysResponse{APIKeys: make([]APIKeyResponse, 0, len(keys))}
+
+	for _, key := range keys {
+		response.APIKeys = append(response.APIKeys, apiKeyToResponse(key))
+	}
+
+	return response
+}
+
+func sessionsToResponse(sessions []domain.Session) SessionsResponse {
+	response := SessionsResponse{Sessions: make([]ActiveSessionResponse, 0, len(sessions))}
+
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, ActiveSessionResponse{
+			ID:        session.ID,
+			CreatedAt: session.CreatedAt,
+			LastSeen:  session.LastSeen,
+			ExpiresAt: session.ExpiresAt,
+			IP:        session.IP,
+			UserAgent: session.UserAgent,
+			Current:   session.Current,
+		})
+	}
+
+	return response
+}
+
+func introspectionToResponse(introspection domain.Introspection, issuer string) IntrospectionResponse {
+	if !introspection.Active {
+		return IntrospectionResponse{}
+	}
+
+	response := IntrospectionResponse{
+		Active:    true,
+		TokenType: introspection.TokenType,
+		Subject:   introspection.Subject,
+		Username:  introspection.Username,
+		Role:      introspection.Role,
+		ClientID:  introspection.ClientID,
+		Scope:     strings.Join(introspection.Scopes, " "),
+		Issuer:    issuer,
+	}
+
+	if !introspection.IssuedAt.IsZero() {
+		response.IssuedAt = introspection.IssuedAt.Unix()
+	}
+
+	if !introspection.ExpiresAt.IsZero() {
+		response.ExpiresAt = introspection.ExpiresAt.Unix()
+	}
+
+	return response
+}
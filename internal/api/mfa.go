@@ -0,0 +1,225 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+)
+
+type EnrollTOTPResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+func (a *jsonApi) postEnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+
+	token := a.sessionToken(r)
+	if token == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	secret, uri, err := a.domain.EnrollTOTP(setup, token)
+	if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err == domain.ErrAlreadyEnrolled {
+		respondWithError(w, "totp already enabled", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, EnrollTOTPResponse{Secret: secret, URI: uri})
+}
+
+type ConfirmTOTPRequest struct {
+	Code string `json:"code"`
+}
+
+type ConfirmTOTPResponse struct {
+	Enabled       bool     `json:"enabled"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (a *jsonApi) postConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	var req ConfirmTOTPRequest
+	logger := utils.MustGetLogger(r)
+
+	token := a.sessionToken(r)
+	if token == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	err := parseRequest(r, &req)
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	err = validateConfirmTOTP(req)
+	if err != nil {
+		respondWithError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	codes, err := a.domain.ConfirmTOTP(setup, token, req.Code)
+	if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err == domain.ErrInvalidCode {
+		respondWithError(w, "invalid code", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrAlreadyEnrolled {
+		respondWithError(w, "totp already enabled", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, ConfirmTOTPResponse{Enabled: true, RecoveryCodes: codes})
+}
+
+type LogInTOTPRequest struct {
+	Challenge string `json:"challenge"`
+	Code      string `json:"code"`
+	Tokens    bool   `json:"tokens"`
+}
+
+func (a *jsonApi) postLogInTOTP(w http.ResponseWriter, r *http.Request) {
+	var req LogInTOTPRequest
+	logger := utils.MustGetLogger(r)
+
+	err := parseRequest(r, &req)
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	err = validateLogInTOTP(req)
+	if err != nil {
+		respondWithError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	user, session, err := a.domain.LogInTOTP(setup, req.Challenge, req.Code)
+	if err == domain.ErrInvalidChallenge {
+		respondWithError(w, "invalid or expired challenge", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrInvalidCode {
+		respondWithError(w, "invalid code", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithError(w, "failed login attempt", http.StatusBadRequest)
+		return
+	}
+
+	a.respondWithSession(w, r, user, session, req.Tokens)
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (a *jsonApi) postRegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+
+	token := a.sessionToken(r)
+	if token == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	codes, err := a.domain.RegenerateRecoveryCodes(setup, token)
+	if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err == domain.ErrNotEnrolled {
+		respondWithError(w, "second factor is not enabled", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+type RecoveryCodesCountResponse struct {
+	Remaining int `json:"remaining"`
+}
+
+func (a *jsonApi) getRecoveryCodesCount(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+
+	token := a.sessionToken(r)
+	if token == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	remaining, err := a.domain.RecoveryCodesCount(setup, token)
+	if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, RecoveryCodesCountResponse{Remaining: remaining})
+}
+
+type LogInRecoveryCodeRequest struct {
+	Challenge string `json:"challenge"`
+	Code      string `json:"code"`
+	Tokens    bool   `json:"tokens"`
+}
+
+func (a *jsonApi) postLogInRecoveryCode(w http.ResponseWriter, r *http.Request) {
+	var req LogInRecoveryCodeRequest
+	logger := utils.MustGetLogger(r)
+
+	err := parseRequest(r, &req)
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	err = validateLogInRecoveryCode(req)
+	if err != nil {
+		respondWithError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	user, session, err := a.domain.LogInRecoveryCode(setup, req.Challenge, req.Code)
+	if err == domain.ErrInvalidChallenge {
+		respondWithError(w, "invalid or expired challenge", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrInvalidCode {
+		respondWithError(w, "invalid code", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithError(w, "failed login attempt", http.StatusBadRequest)
+		return
+	}
+
+	a.respondWithSession(w, r, user, session, req.Tokens)
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnrollTOTP(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		token      string
+		statusCode int
+		response   string
+		returnErr  error
+	}{
+		{
+			name:       "success",
+			token:      "session",
+			statusCode: http.StatusOK,
+			response:   `{ "secret": "SECRET", "uri": "otpauth://totp/auth:djvukovic@gmail.com?secret=SECRET" }`,
+		},
+		{
+			name:       "missing session",
+			statusCode: http.StatusUnauthorized,
+			response:   utils.ErrorJSON("unauthorized"),
+		},
+		{
+			name:       "already enrolled",
+			token:      "session",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("totp already enabled"),
+			returnErr:  domain.ErrAlreadyEnrolled,
+		},
+		{
+			name:       "internal error",
+			token:      "session",
+			statusCode: http.StatusInternalServerError,
+			response:   utils.ErrorJSON("internal server error"),
+			returnErr:  errors.New("random error"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := utils.RequestBuilder("POST", "/mfa/totp/enroll?token="+tc.token)(``)
+			rr := httptest.NewRecorder()
+
+			baseMock := domain.NewMockDomain(t)
+			if tc.token != "" {
+				secret, uri := "SECRET", "otpauth://totp/auth:djvukovic@gmail.com?secret=SECRET"
+				if tc.returnErr != nil {
+					secret, uri = "", ""
+				}
+
+				baseMock.EXPECT().EnrollTOTP(mock.Anything, tc.token).Return(secret, uri, tc.returnErr)
+			}
+
+			api := NewApi(utils.Config{}, http.NewServeMux(), baseMock, logger)
+			api.postEnrollTOTP(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
+
+func TestLogInTOTP(t *testing.T) {
+	t.Parallel()
+
+	requestBuilder := utils.RequestBuilder("POST", "/login/totp")
+
+	user := domain.User{
+		ID:       884,
+		Email:    "djvukovic@gmail.com",
+		Username: "djvukovic",
+		Role:     "admin",
+		Verified: true,
+	}
+
+	type testCase struct {
+		name         string
+		request      *http.Request
+		setupDomain  func(*domain.MockDomain, *testCase)
+		responseCode int
+		responseBody string
+		cookie       string
+	}
+
+	tests := []testCase{
+		{
+			name:    "success",
+			request: requestBuilder(`{ "challenge": "abc", "code": "123456" }`),
+			setupDomain: func(d *domain.MockDomain, tc *testCase) {
+				d.EXPECT().LogInTOTP(mock.Anything, "abc", "123456").Return(user, "session", nil)
+			},
+			responseCode: http.StatusOK,
+			responseBody: `{"id": 884, "username": "djvukovic", "email": "djvukovic@gmail.com", "role": "admin", "verified": true }`,
+			cookie:       "session",
+		},
+		{
+			name:    "token mode",
+			request: requestBuilder(`{ "challenge": "abc", "code": "123456", "tokens": true }`),
+			setupDomain: func(d *domain.MockDomain, tc *testCase) {
+				d.EXPECT().LogInTOTP(mock.Anything, "abc", "123456").Return(user, "session", nil)
+				d.EXPECT().ExchangeSession(mock.Anything, "session").Return(domain.SessionTokens{
+					AccessToken:  "access",
+					RefreshToken: "refresh",
+					ExpiresIn:    900,
+				}, nil)
+			},
+			responseCode: http.StatusOK,
+			responseBody: `{
+				"id": 884, "username": "djvukovic", "email": "djvukovic@gmail.com", "role": "admin", "verified": true,
+				"access_token": "access", "refresh_token": "refresh", "token_type": "Bearer", "expires_in": 900
+			}`,
+		},
+		{
+			name:         "validation fail",
+			request:      requestBuilder(`{ "challenge": "abc", "code": "12" }`),
+			responseCode: http.StatusBadRequest,
+			responseBody: utils.ErrorJSON("code must have 6 digits"),
+		},
+		{
+			name:    "invalid code",
+			request: requestBuilder(`{ "challenge": "abc", "code": "123456" }`),
+			setupDomain: func(d *domain.MockDomain, tc *testCase) {
+				d.EXPECT().LogInTOTP(mock.Anything, "abc", "123456").Return(domain.User{}, "", domain.ErrInvalidCode)
+			},
+			responseCode: http.StatusBadRequest,
+			responseBody: utils.ErrorJSON("invalid code"),
+		},
+		{
+			name:    "invalid challenge",
+			request: requestBuilder(`{ "challenge": "abc", "code": "123456" }`),
+			setupDomain: func(d *domain.MockDomain, tc *testCase) {
+				d.EXPECT().LogInTOTP(mock.Anything, "abc", "123456").Return(domain.User{}, "", domain.ErrInvalidChallenge)
+			},
+			responseCode: http.StatusBadRequest,
+			responseBody: utils.ErrorJSON("invalid or expired challenge"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			// Setup mocks
+			if tc.setupDomain != nil {
+				tc.setupDomain(baseMock, &tc)
+			}
+
+			// Run
+			api := NewApi(utils.Config{SessionCookie: "_tkn"}, mux, baseMock, sl)
+			api.postLogInTOTP(rr, tc.request)
+
+			// Assertions
+			require.Equal(t, tc.responseCode, rr.Code)
+			require.JSONEq(t, tc.responseBody, rr.Body.String())
+
+			cookies := rr.Result().Cookies()
+			if tc.cookie != "" {
+				require.Len(t, cookies, 1)
+				require.Equal(t, tc.cookie, cookies[0].Value)
+			} else {
+				require.Empty(t, cookies)
+			}
+		})
+	}
+}
+
+func TestLogInRecoveryCode(t *testing.T) {
+	t.Parallel()
+
+	requestBuilder := utils.RequestBuilder("POST", "/login/recovery")
+
+	user := domain.User{ID: 884, Email: "djvukovic@gmail.com", Username: "djvukovic", Role: "admin", Verified: true}
+
+	tests := []struct {
+		name       string
+		request    string
+		statusCode int
+		response   string
+		returnErr  error
+	}{
+		{
+			name:       "success",
+			request:    `{ "challenge": "abc", "code": "abcde-fghij" }`,
+			statusCode: http.StatusOK,
+			response:   `{"id": 884, "username": "djvukovic", "email": "djvukovic@gmail.com", "role": "admin", "verified": true }`,
+		},
+		{
+			name:       "invalid code",
+			request:    `{ "challenge": "abc", "code": "abcde-fghij" }`,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid code"),
+			returnErr:  domain.ErrInvalidCode,
+		},
+		{
+			name:       "missing code",
+			request:    `{ "challenge": "abc" }`,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("missing recovery code"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.statusCode == http.StatusOK {
+				baseMock.EXPECT().LogInRecoveryCode(mock.Anything, "abc", "abcde-fghij").Return(user, "session", nil)
+			} else if tc.returnErr != nil {
+				baseMock.EXPECT().LogInRecoveryCode(mock.Anything, "abc", "abcde-fghij").Return(domain.User{}, "", tc.returnErr)
+			}
+
+			api := NewApi(utils.Config{SessionCookie: "_tkn"}, mux, baseMock, sl)
+			api.postLogInRecoveryCode(rr, requestBuilder(tc.request))
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/djordjev/auth/internal/utils"
+)
+
+// Client records address and user agent of the request for sessions started by it
+func Client() Wrapper {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, utils.InjectClientIntoContext(r))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+// getOIDCLogIn redirects to external provider. With `link=true` query param
+// provider is linked to the account of signed in user instead. With `tokens=true`
+// callback responds with tokens instead of session cookie.
+func (a *jsonApi) getOIDCLogIn(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+	provider := chi.URLParam(r, "provider")
+
+	token := ""
+	if r.URL.Query().Get("link") == "true" {
+		token = a.sessionToken(r)
+		if token == "" {
+			respondWithUnauthorized(w)
+			return
+		}
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	authURL, state, err := a.domain.BeginOIDCLogIn(setup, provider, token)
+	if err == domain.ErrUnknownProvider {
+		respondWithError(w, "unknown identity provider", http.StatusNotFound)
+		return
+	} else if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	setStateCookie(w, oidcStateCookie, state, http.SameSiteLaxMode, r.URL.Query().Get("tokens") == "true")
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+func (a *jsonApi) getOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+	provider := chi.URLParam(r, "provider")
+	query := r.URL.Query()
+
+	if query.Get("error") != "" {
+		respondWithError(w, query.Get("error"), http.StatusBadRequest)
+		return
+	}
+
+	if query.Get("state") == "" || query.Get("code") == "" {
+		respondWithBadRequest(w)
+		return
+	}
+
+	// state has to come back to the browser that started the log in, otherwise attacker could
+	// make the victim finish log in or linking started by the attacker
+	bound, tokens := takeStateCookie(w, r, oidcStateCookie, query.Get("state"))
+	if !bound {
+		respondWithError(w, "invalid or expired state", http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	user, session, err := a.domain.FinishOIDCLogIn(setup, provider, query.Get("state"), query.Get("code"))
+	if err == domain.ErrSecondFactorRequired {
+		mustWriteJSONResponse(w, LogInChallengeResponse{SecondFactorRequired: true, Challenge: session})
+		return
+	} else if err == domain.ErrUnknownProvider {
+		respondWithError(w, "unknown identity provider", http.StatusNotFound)
+		return
+	} else if err == domain.ErrInvalidCeremony {
+		respondWithError(w, "invalid or expired state", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrIdentityLinkRequired {
+		respondWithError(w, "account with this email already exists, log in to link the provider", http.StatusConflict)
+		return
+	} else if err == domain.ErrIdentityInUse {
+		respondWithError(w, "identity is linked to another account", http.StatusConflict)
+		return
+	} else if errors.Is(err, domain.ErrInvalidIdentity) {
+		utils.LogError(logger, err)
+		respondWithError(w, "invalid identity", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	a.respondWithSession(w, r, user, session, tokens)
+}
+
+type IdentityResponse struct {
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"`
+	Email    string `json:"email"`
+}
+
+type IdentitiesResponse struct {
+	Identities []IdentityResponse `json:"identities"`
+}
+
+func (a *jsonApi) getIdentities(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+
+	token := a.sessionToken(r)
+	if token == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	identities, err := a.domain.Identities(setup, token)
+	if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, identitiesToResponse(identities))
+}
+
+type UnlinkIdentityResponse struct {
+	Unlinked bool `json:"unlinked"`
+}
+
+func (a *jsonApi) deleteIdentity(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+	provider := chi.URLParam(r, "provider")
+
+	token := a.sessionToken(r)
+	if token == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	err := a.domain.UnlinkIdentity(setup, token, provider)
+	if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err == domain.ErrIdentityNotFound {
+		respondWithError(w, "identity not found", http.StatusNotFound)
+		return
+	} else if err == domain.ErrIdentityManaged {
+		respondWithError(w, "directory identity can't be unlinked", http.StatusForbidden)
+		return
+	} else if err == domain.ErrLastLogInMethod {
+		respondWithError(w, "set password or add passkey before unlinking the last identity", http.StatusConflict)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, UnlinkIdentityResponse{Unlinked: true})
+}
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+)
+
+type DiscoveryResponse struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	DeviceAuthorizationEndpoint       string   `json:"device_authorization_endpoint,omitempty"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+func (a *jsonApi) getDiscovery(w http.ResponseWriter, r *http.Request) {
+	if !a.cfg.IsOIDCProvider() {
+		respondWithError(w, "openid provider is not configured", http.StatusNotFound)
+		return
+	}
+
+	issuer := a.cfg.OIDCIssuer
+
+	grantTypes := []string{"authorization_code", "client_credentials"}
+	deviceEndpoint := ""
+	if a.cfg.HasDeviceFlow() {
+		grantTypes = append(grantTypes, deviceCodeGrantType)
+		deviceEndpoint = issuer + "/device/code"
+	}
+
+	mustWriteJSONResponse(w, DiscoveryResponse{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + "/authorize",
+		TokenEndpoint:                     issuer + "/token",
+		UserInfoEndpoint:                  issuer + "/userinfo",
+		JWKSURI:                           issuer + "/.well-known/jwks.json",
+		IntrospectionEndpoint:             issuer + "/introspect",
+		DeviceAuthorizationEndpoint:       deviceEndpoint,
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               grantTypes,
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{a.cfg.SigningKeys.Algorithm},
+		ScopesSupported:                   []string{"openid", "email", "profile"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported: []string{
+			"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce",
+			"email", "email_verified", "preferred_username", "role", "payload",
+		},
+	})
+}
+
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+type ConsentRequiredResponse struct {
+	ConsentRequired bool   `json:"consent_required"`
+	Consent         string `json:"consent"`
+	ClientID        string `json:"client_id"`
+	Scope           string `json:"scope"`
+}
+
+// getAuthorize uses session cookie set on log in as single sign on session. Users without
+// session are sent to the login page and those who didn't grant access to the consent page.
+func (a *jsonApi) getAuthorize(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+	query := r.URL.Query()
+
+	request := domain.AuthorizationRequest{
+		ResponseType:        query.Get("response_type"),
+		ClientID:            query.Get("client_id"),
+		RedirectURI:         query.Get("redirect_uri"),
+		Scope:               query.Get("scope"),
+		State:               query.Get("state"),
+		Nonce:               query.Get("nonce"),
+		CodeChallenge:       query.Get("code_challenge"),
+		CodeChallengeMethod: query.Get("code_challenge_method"),
+		Prompt:              query.Get("prompt"),
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	redirect, consent, err := a.domain.Authorize(setup, a.sessionToken(r), request)
+	if err == domain.ErrProviderNotConfigured {
+		respondWithError(w, "openid provider is not configured", http.StatusNotFound)
+		return
+	} else if err == domain.ErrInvalidClient {
+		respondWithError(w, "invalid client or redirect uri", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrLoginRequired {
+		if a.cfg.OIDCLoginURL == "" {
+			respondWithUnauthorized(w)
+			return
+		}
+
+		returnTo := a.cfg.OIDCIssuer + "/authorize?" + r.URL.RawQuery
+		http.Redirect(w, r, withQuery(a.cfg.OIDCLoginURL, url.Values{"return_to": {returnTo}}), http.StatusFound)
+		return
+	} else if err == domain.ErrConsentRequired {
+		if a.cfg.OIDCConsentURL == "" {
+			mustWriteJSONResponse(w, ConsentRequiredResponse{
+				ConsentRequired: true,
+				Consent:         consent,
+				ClientID:        request.ClientID,
+				Scope:           request.Scope,
+			})
+			return
+		}
+
+		params := url.Values{"consent": {consent}, "client_id": {request.ClientID}, "scope": {request.Scope}}
+		http.Redirect(w, r, withQuery(a.cfg.OIDCConsentURL, params), http.StatusFound)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+type ConsentRequest struct {
+	Consent string `json:"consent"`
+	Approve bool   `json:"approve"`
+}
+
+type ConsentResponse struct {
+	Redirect string `json:"redirect"`
+}
+
+func (a *jsonApi) postConsent(w http.ResponseWriter, r *http.Request) {
+	var req ConsentRequest
+	logger := utils.MustGetLogger(r)
+
+	token := a.sessionToken(r)
+	if token == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	err := parseRequest(r, &req)
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	err = validateConsent(req)
+	if err != nil {
+		respondWithError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	redirect, err := a.domain.Consent(setup, token, req.Consent, req.Approve)
+	if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err == domain.ErrInvalidCeremony {
+		respondWithError(w, "invalid or expired consent request", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, ConsentResponse{Redirect: redirect})
+}
+
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	IDToken     string `json:"id_token,omitempty"`
+	Scope       string `json:"scope"`
+}
+
+// postToken accepts form encoded request as defined by OAuth 2.0. Client
+// credentials can be sent using basic authentication or in the form. Tokens
+// issued for client_credentials grant have no id token.
+func (a *jsonApi) postToken(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+
+	err := r.ParseForm()
+	if err != nil {
+		respondWithError(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	request := domain.TokenRequest{
+		GrantType:    r.PostForm.Get("grant_type"),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		ClientID:     r.PostForm.Get("client_id"),
+		ClientSecret: r.PostForm.Get("client_secret"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+		Scope:        r.PostForm.Get("scope"),
+		DeviceCode:   r.PostForm.Get("device_code"),
+	}
+
+	if id, secret, ok := r.BasicAuth(); ok {
+		request.ClientID, _ = url.QueryUnescape(id)
+		request.ClientSecret, _ = url.QueryUnescape(secret)
+	}
+
+	err = validateToken(request)
+	if err != nil {
+		respondWithError(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	setup := domain.NewSetup(r.Context(), logger)
+	tokens, err := a.domain.Token(setup, request)
+	if err == domain.ErrProviderNotConfigured {
+		respondWithError(w, "openid provider is not configured", http.StatusNotFound)
+		return
+	} else if err == domain.ErrUnsupportedGrantType {
+		respondWithError(w, "unsupported_grant_type", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrInvalidClient {
+		respondWithError(w, "invalid_client", http.StatusUnauthorized)
+		return
+	} else if err == domain.ErrInvalidGrant {
+		respondWithError(w, "invalid_grant", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrInvalidScope {
+		respondWithError(w, "invalid_scope", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrAuthorizationPending {
+		respondWithError(w, "authorization_pending", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrSlowDown {
+		respondWithError(w, "slow_down", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrAccessDenied {
+		respondWithError(w, "access_denied", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrExpiredToken {
+		respondWithError(w, "expired_token", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, TokenResponse{
+		AccessToken: tokens.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   tokens.ExpiresIn,
+		IDToken:     tokens.IDToken,
+		Scope:       tokens.Scope,
+	})
+}
+
+func (a *jsonApi) getUserInfo(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+
+	accessToken, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || accessToken == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	claims, err := a.domain.UserInfo(setup, accessToken)
+	if err == domain.ErrProviderNotConfigured {
+		respondWithError(w, "openid provider is not configured", http.StatusNotFound)
+		return
+	} else if err == domain.ErrInvalidAccessToken {
+		respondWithUnauthorized(w)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, claims)
+}
+
+func (a *jsonApi) getJWKS(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+
+	setup := domain.NewSetup(r.Context(), logger)
+	keys, err := a.domain.JWKS(setup)
+	if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, json.RawMessage(keys))
+}
+
+func withQuery(base string, params url.Values) string {
+	separator := "?"
+	if strings.Contains(base, "?") {
+		separator = "&"
+	}
+
+	return base + separator + params.Encode()
+}
@@ -0,0 +1,309 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var providerConfig = utils.Config{
+	SessionCookie:  "_tkn",
+	OIDCIssuer:     "https://auth.example.com",
+	OIDCLoginURL:   "https://example.com/login",
+	OIDCConsentURL: "https://example.com/consent",
+}
+
+func TestAuthorize(t *testing.T) {
+	t.Parallel()
+
+	query := "response_type=code&client_id=app&redirect_uri=https%3A%2F%2Fapp.example.com%2Fcallback&scope=openid"
+
+	tests := []struct {
+		name          string
+		config        utils.Config
+		token         string
+		statusCode    int
+		location      string
+		response      string
+		returnURL     string
+		returnConsent string
+		returnErr     error
+	}{
+		{
+			name:       "issues code",
+			config:     providerConfig,
+			token:      "session",
+			statusCode: http.StatusFound,
+			location:   "https://app.example.com/callback?code=abc",
+			returnURL:  "https://app.example.com/callback?code=abc",
+		},
+		{
+			name:       "redirects to login",
+			config:     providerConfig,
+			statusCode: http.StatusFound,
+			location:   "https://example.com/login?return_to=" + url.QueryEscape("https://auth.example.com/authorize?"+query),
+			returnErr:  domain.ErrLoginRequired,
+		},
+		{
+			name:       "login required without login page",
+			config:     utils.Config{SessionCookie: "_tkn", OIDCIssuer: "https://auth.example.com"},
+			statusCode: http.StatusUnauthorized,
+			response:   utils.ErrorJSON("unauthorized"),
+			returnErr:  domain.ErrLoginRequired,
+		},
+		{
+			name:          "redirects to consent",
+			config:        providerConfig,
+			token:         "session",
+			statusCode:    http.StatusFound,
+			location:      "https://example.com/consent?client_id=app&consent=key&scope=openid",
+			returnConsent: "key",
+			returnErr:     domain.ErrConsentRequired,
+		},
+		{
+			name:          "consent required without consent page",
+			config:        utils.Config{SessionCookie: "_tkn", OIDCIssuer: "https://auth.example.com"},
+			token:         "session",
+			statusCode:    http.StatusOK,
+			response:      `{"consent_required": true, "consent": "key", "client_id": "app", "scope": "openid"}`,
+			returnConsent: "key",
+			returnErr:     domain.ErrConsentRequired,
+		},
+		{
+			name:       "invalid client",
+			config:     providerConfig,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid client or redirect uri"),
+			returnErr:  domain.ErrInvalidClient,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			request := domain.AuthorizationRequest{
+				ResponseType: "code",
+				ClientID:     "app",
+				RedirectURI:  "https://app.example.com/callback",
+				Scope:        "openid",
+			}
+			baseMock.EXPECT().Authorize(mock.Anything, tc.token, request).Return(tc.returnURL, tc.returnConsent, tc.returnErr)
+
+			req := utils.RequestBuilder("GET", "/authorize?"+query)("")
+			if tc.token != "" {
+				req.AddCookie(&http.Cookie{Name: "_tkn", Value: tc.token})
+			}
+
+			api := NewApi(tc.config, mux, baseMock, sl)
+			api.getAuthorize(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.Equal(t, tc.location, rr.Header().Get("Location"))
+			if tc.response != "" {
+				require.JSONEq(t, tc.response, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestToken(t *testing.T) {
+	t.Parallel()
+
+	body := "grant_type=authorization_code&code=abc&redirect_uri=https%3A%2F%2Fapp.example.com%2Fcallback&code_verifier=verifier"
+	request := domain.TokenRequest{
+		GrantType:    "authorization_code",
+		Code:         "abc",
+		RedirectURI:  "https://app.example.com/callback",
+		ClientID:     "app",
+		ClientSecret: "secret",
+		CodeVerifier: "verifier",
+	}
+
+	tests := []struct {
+		name         string
+		body         string
+		basicAuth    bool
+		statusCode   int
+		response     string
+		returnTokens domain.Tokens
+		returnErr    error
+		callDomain   bool
+	}{
+		{
+			name:         "basic authentication",
+			body:         body,
+			basicAuth:    true,
+			statusCode:   http.StatusOK,
+			response:     `{"access_token": "at", "token_type": "Bearer", "expires_in": 3600, "id_token": "it", "scope": "openid"}`,
+			returnTokens: domain.Tokens{AccessToken: "at", IDToken: "it", Scope: "openid", ExpiresIn: 3600},
+			callDomain:   true,
+		},
+		{
+			name:         "credentials in form",
+			body:         body + "&client_id=app&client_secret=secret",
+			statusCode:   http.StatusOK,
+			response:     `{"access_token": "at", "token_type": "Bearer", "expires_in": 3600, "id_token": "it", "scope": "openid"}`,
+			returnTokens: domain.Tokens{AccessToken: "at", IDToken: "it", Scope: "openid", ExpiresIn: 3600},
+			callDomain:   true,
+		},
+		{
+			name:       "invalid grant",
+			body:       body,
+			basicAuth:  true,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid_grant"),
+			returnErr:  domain.ErrInvalidGrant,
+			callDomain: true,
+		},
+		{
+			name:       "invalid client",
+			body:       body,
+			basicAuth:  true,
+			statusCode: http.StatusUnauthorized,
+			response:   utils.ErrorJSON("invalid_client"),
+			returnErr:  domain.ErrInvalidClient,
+			callDomain: true,
+		},
+		{
+			name:       "invalid scope",
+			body:       body,
+			basicAuth:  true,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid_scope"),
+			returnErr:  domain.ErrInvalidScope,
+			callDomain: true,
+		},
+		{
+			name:       "missing client",
+			body:       body,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid_request"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.callDomain {
+				baseMock.EXPECT().Token(mock.Anything, request).Return(tc.returnTokens, tc.returnErr)
+			}
+
+			req := utils.RequestBuilder("POST", "/token")(tc.body)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tc.basicAuth {
+				req.SetBasicAuth("app", "secret")
+			}
+
+			api := NewApi(providerConfig, mux, baseMock, sl)
+			api.postToken(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
+
+func TestClientCredentialsToken(t *testing.T) {
+	t.Parallel()
+
+	rr := httptest.NewRecorder()
+	baseMock := domain.NewMockDomain(t)
+
+	request := domain.TokenRequest{
+		GrantType:    "client_credentials",
+		ClientID:     "billing",
+		ClientSecret: "secret",
+		Scope:        "reports:read",
+	}
+	baseMock.EXPECT().Token(mock.Anything, request).Return(domain.Tokens{AccessToken: "at", Scope: "reports:read", ExpiresIn: 3600}, nil)
+
+	req := utils.RequestBuilder("POST", "/token")("grant_type=client_credentials&scope=reports%3Aread")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("billing", "secret")
+
+	api := NewApi(providerConfig, mux, baseMock, sl)
+	api.postToken(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"access_token": "at", "token_type": "Bearer", "expires_in": 3600, "scope": "reports:read"}`, rr.Body.String())
+}
+
+func TestUserInfo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		authorization string
+		statusCode    int
+		response      string
+		returnClaims  map[string]any
+		returnErr     error
+		callDomain    bool
+	}{
+		{
+			name:          "success",
+			authorization: "Bearer at",
+			statusCode:    http.StatusOK,
+			response:      `{"sub": "452", "email": "djvukovic@gmail.com"}`,
+			returnClaims:  map[string]any{"sub": "452", "email": "djvukovic@gmail.com"},
+			callDomain:    true,
+		},
+		{
+			name:          "invalid token",
+			authorization: "Bearer at",
+			statusCode:    http.StatusUnauthorized,
+			response:      utils.ErrorJSON("unauthorized"),
+			returnErr:     domain.ErrInvalidAccessToken,
+			callDomain:    true,
+		},
+		{
+			name:       "missing token",
+			statusCode: http.StatusUnauthorized,
+			response:   utils.ErrorJSON("unauthorized"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.callDomain {
+				baseMock.EXPECT().UserInfo(mock.Anything, "at").Return(tc.returnClaims, tc.returnErr)
+			}
+
+			req := utils.RequestBuilder("GET", "/userinfo")("")
+			req.Header.Set("Authorization", tc.authorization)
+
+			api := NewApi(providerConfig, mux, baseMock, sl)
+			api.getUserInfo(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
+
+func TestDiscovery(t *testing.T) {
+	t.Parallel()
+
+	rr := httptest.NewRecorder()
+
+	api := NewApi(providerConfig, mux, domain.NewMockDomain(t), sl)
+	api.getDiscovery(rr, utils.RequestBuilder("GET", "/.well-known/openid-configuration")(""))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `"issuer":"https://auth.example.com"`)
+	require.Contains(t, rr.Body.String(), `"jwks_uri":"https://auth.example.com/.well-known/jwks.json"`)
+	require.Contains(t, rr.Body.String(), `"introspection_endpoint":"https://auth.example.com/introspect"`)
+}
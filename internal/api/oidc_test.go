@@ -0,0 +1,238 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func withProvider(r *http.Request, provider string) *http.Request {
+	routeContext := chi.NewRouteContext()
+	routeContext.URLParams.Add("provider", provider)
+
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeContext))
+}
+
+// withStateCookie adds cookie binding the state to the browser, cookie value can carry the mode
+func withStateCookie(r *http.Request, name string, state string) *http.Request {
+	state, mode, _ := strings.Cut(state, ".")
+	if state != "" {
+		hash := sha256.Sum256([]byte(state))
+
+		value := hex.EncodeToString(hash[:])
+		if mode != "" {
+			value += "." + mode
+		}
+
+		r.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+
+	return r
+}
+
+// stateCookie finds cookie with the name set by the response
+func stateCookie(rr *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range rr.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+
+	return nil
+}
+
+func TestOIDCLogIn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		path       string
+		token      string
+		statusCode int
+		location   string
+		returnErr  error
+		tokens     bool
+	}{
+		{
+			name:       "redirects to provider",
+			path:       "/oidc/google",
+			statusCode: http.StatusFound,
+			location:   "https://accounts.google.com/auth?state=abc",
+		},
+		{
+			name:       "links provider",
+			path:       "/oidc/google?link=true&token=session",
+			token:      "session",
+			statusCode: http.StatusFound,
+			location:   "https://accounts.google.com/auth?state=abc",
+		},
+		{
+			name:       "token mode",
+			path:       "/oidc/google?tokens=true",
+			statusCode: http.StatusFound,
+			location:   "https://accounts.google.com/auth?state=abc",
+			tokens:     true,
+		},
+		{
+			name:       "unknown provider",
+			path:       "/oidc/google",
+			statusCode: http.StatusNotFound,
+			returnErr:  domain.ErrUnknownProvider,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			state := ""
+			if tc.returnErr == nil {
+				state = "abc"
+			}
+
+			baseMock.EXPECT().BeginOIDCLogIn(mock.Anything, "google", tc.token).Return(tc.location, state, tc.returnErr)
+
+			api := NewApi(utils.Config{SessionCookie: "_tkn"}, mux, baseMock, sl)
+			api.getOIDCLogIn(rr, withProvider(utils.RequestBuilder("GET", tc.path)(""), "google"))
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.Equal(t, tc.location, rr.Header().Get("Location"))
+
+			cookie := stateCookie(rr, "oidc_state")
+			if tc.returnErr != nil {
+				require.Nil(t, cookie)
+				return
+			}
+
+			hash := sha256.Sum256([]byte(state))
+			value := hex.EncodeToString(hash[:])
+			if tc.tokens {
+				value += ".tokens"
+			}
+
+			require.NotNil(t, cookie)
+			require.Equal(t, value, cookie.Value)
+			require.True(t, cookie.HttpOnly)
+			require.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+		})
+	}
+}
+
+func TestOIDCCallback(t *testing.T) {
+	t.Parallel()
+
+	user := domain.User{ID: 884, Email: "djvukovic@gmail.com", Username: "djvukovic", Role: "admin", Verified: true}
+
+	tests := []struct {
+		name       string
+		path       string
+		cookie     string
+		statusCode int
+		response   string
+		returnUser domain.User
+		returnKey  string
+		returnErr  error
+		callDomain bool
+		tokens     bool
+	}{
+		{
+			name:       "success",
+			path:       "/oidc/google/callback?state=abc&code=xyz",
+			cookie:     "abc",
+			statusCode: http.StatusOK,
+			response:   `{"id": 884, "username": "djvukovic", "email": "djvukovic@gmail.com", "role": "admin", "verified": true }`,
+			returnUser: user,
+			returnKey:  "session",
+			callDomain: true,
+		},
+		{
+			name:       "token mode",
+			path:       "/oidc/google/callback?state=abc&code=xyz",
+			cookie:     "abc.tokens",
+			statusCode: http.StatusOK,
+			response: `{
+				"id": 884, "username": "djvukovic", "email": "djvukovic@gmail.com", "role": "admin", "verified": true,
+				"access_token": "access", "refresh_token": "refresh", "token_type": "Bearer", "expires_in": 900
+			}`,
+			returnUser: user,
+			returnKey:  "session",
+			callDomain: true,
+			tokens:     true,
+		},
+		{
+			name:       "provider error",
+			path:       "/oidc/google/callback?error=access_denied&state=abc",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("access_denied"),
+		},
+		{
+			name:       "missing state cookie",
+			path:       "/oidc/google/callback?state=abc&code=xyz",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid or expired state"),
+		},
+		{
+			name:       "state cookie of another log in",
+			path:       "/oidc/google/callback?state=abc&code=xyz",
+			cookie:     "def",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid or expired state"),
+		},
+		{
+			name:       "link required",
+			path:       "/oidc/google/callback?state=abc&code=xyz",
+			cookie:     "abc",
+			statusCode: http.StatusConflict,
+			response:   utils.ErrorJSON("account with this email already exists, log in to link the provider"),
+			returnErr:  domain.ErrIdentityLinkRequired,
+			callDomain: true,
+		},
+		{
+			name:       "invalid identity",
+			path:       "/oidc/google/callback?state=abc&code=xyz",
+			cookie:     "abc",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid identity"),
+			returnErr:  errors.Join(domain.ErrInvalidIdentity, errors.New("token expired")),
+			callDomain: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.callDomain {
+				baseMock.EXPECT().FinishOIDCLogIn(mock.Anything, "google", "abc", "xyz").Return(tc.returnUser, tc.returnKey, tc.returnErr)
+			}
+
+			if tc.tokens {
+				baseMock.EXPECT().ExchangeSession(mock.Anything, tc.returnKey).Return(domain.SessionTokens{
+					AccessToken:  "access",
+					RefreshToken: "refresh",
+					ExpiresIn:    900,
+				}, nil)
+			}
+
+			api := NewApi(utils.Config{SessionCookie: "_tkn"}, mux, baseMock, sl)
+			r := withStateCookie(utils.RequestBuilder("GET", tc.path)(""), "oidc_state", tc.cookie)
+			api.getOIDCCallback(rr, withProvider(r, "google"))
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
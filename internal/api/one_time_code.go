@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+)
+
+type OneTimeCodeRequest struct {
+	Email    string `json:"email"`
+	Username string `json:"username"`
+}
+
+type OneTimeCodeResponse struct {
+	Sent  bool   `json:"sent"`
+	Email string `json:"email"`
+}
+
+func (a *jsonApi) postOneTimeCode(w http.ResponseWriter, r *http.Request) {
+	var req OneTimeCodeRequest
+	logger := utils.MustGetLogger(r)
+
+	err := parseRequest(r, &req)
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	err = validateOneTimeCode(req)
+	if err != nil {
+		respondWithError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	user, err := a.domain.OneTimeCodeRequest(setup, domain.User{Email: req.Email, Username: req.Username})
+	if err == domain.ErrUserNotExist {
+		respondWithError(w, "user does not exist", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, OneTimeCodeResponse{Sent: true, Email: user.Email})
+}
+
+type LogInOneTimeCodeRequest struct {
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	Code     string `json:"code"`
+	Tokens   bool   `json:"tokens"`
+}
+
+func (a *jsonApi) postLogInOneTimeCode(w http.ResponseWriter, r *http.Request) {
+	var req LogInOneTimeCodeRequest
+	logger := utils.MustGetLogger(r)
+
+	err := parseRequest(r, &req)
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	err = validateLogInOneTimeCode(req)
+	if err != nil {
+		respondWithError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	user, session, err := a.domain.LogInOneTimeCode(setup, domain.User{Email: req.Email, Username: req.Username}, req.Code)
+	if err == domain.ErrSecondFactorRequired {
+		mustWriteJSONResponse(w, LogInChallengeResponse{SecondFactorRequired: true, Challenge: session})
+		return
+	} else if err == domain.ErrInvalidCode {
+		respondWithError(w, "invalid code", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	a.respondWithSession(w, r, user, session, req.Tokens)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogInOneTimeCode(t *testing.T) {
+	t.Parallel()
+
+	requestBuilder := utils.RequestBuilder("POST", "/login/code")
+	user := domain.User{ID: 884, Email: "djvukovic@gmail.com", Username: "djvukovic", Role: "admin", Verified: true}
+
+	tests := []struct {
+		name       string
+		request    string
+		statusCode int
+		response   string
+		returnUser domain.User
+		returnKey  string
+		returnErr  error
+		callDomain bool
+	}{
+		{
+			name:       "success",
+			request:    `{ "email": "djvukovic@gmail.com", "code": "123456" }`,
+			statusCode: http.StatusOK,
+			response:   `{"id": 884, "username": "djvukovic", "email": "djvukovic@gmail.com", "role": "admin", "verified": true }`,
+			returnUser: user,
+			returnKey:  "session",
+			callDomain: true,
+		},
+		{
+			name:       "second factor required",
+			request:    `{ "email": "djvukovic@gmail.com", "code": "123456" }`,
+			statusCode: http.StatusOK,
+			response:   `{ "mfa_required": true, "challenge": "challenge" }`,
+			returnKey:  "challenge",
+			returnErr:  domain.ErrSecondFactorRequired,
+			callDomain: true,
+		},
+		{
+			name:       "invalid code",
+			request:    `{ "email": "djvukovic@gmail.com", "code": "123456" }`,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid code"),
+			returnErr:  domain.ErrInvalidCode,
+			callDomain: true,
+		},
+		{
+			name:       "malformed code",
+			request:    `{ "email": "djvukovic@gmail.com", "code": "12345" }`,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("code must have 6 digits"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.callDomain {
+				baseMock.EXPECT().
+					LogInOneTimeCode(mock.Anything, domain.User{Email: user.Email}, "123456").
+					Return(tc.returnUser, tc.returnKey, tc.returnErr)
+			}
+
+			api := NewApi(utils.Config{SessionCookie: "_tkn"}, mux, baseMock, sl)
+			api.postLogInOneTimeCode(rr, requestBuilder(tc.request))
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
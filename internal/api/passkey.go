@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+)
+
+type PasskeyOptionsResponse struct {
+	Ceremony string          `json:"ceremony"`
+	Options  json.RawMessage `json:"options"`
+}
+
+func (a *jsonApi) postBeginPasskeyRegistration(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+
+	token := a.sessionToken(r)
+	if token == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	options, ceremony, err := a.domain.BeginPasskeyRegistration(setup, token)
+	if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err == domain.ErrPasskeysNotConfigured {
+		respondWithError(w, "passkeys are not enabled", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, PasskeyOptionsResponse{Ceremony: ceremony, Options: options})
+}
+
+type FinishPasskeyRegistrationRequest struct {
+	Ceremony   string          `json:"ceremony"`
+	Credential json.RawMessage `json:"credential"`
+}
+
+type FinishPasskeyRegistrationResponse struct {
+	Registered    bool     `json:"registered"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (a *jsonApi) postFinishPasskeyRegistration(w http.ResponseWriter, r *http.Request) {
+	var req FinishPasskeyRegistrationRequest
+	logger := utils.MustGetLogger(r)
+
+	token := a.sessionToken(r)
+	if token == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	err := parseRequest(r, &req)
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	err = validateFinishPasskeyRegistration(req)
+	if err != nil {
+		respondWithError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	codes, err := a.domain.FinishPasskeyRegistration(setup, token, req.Ceremony, req.Credential)
+	if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err == domain.ErrPasskeysNotConfigured {
+		respondWithError(w, "passkeys are not enabled", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrInvalidCeremony {
+		respondWithError(w, "invalid or expired ceremony", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrInvalidCredentials {
+		respondWithError(w, "invalid credential", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, FinishPasskeyRegistrationResponse{Registered: true, RecoveryCodes: codes})
+}
+
+type BeginPasskeyLogInRequest struct {
+	Challenge string `json:"challenge"`
+}
+
+func (a *jsonApi) postBeginPasskeyLogIn(w http.ResponseWriter, r *http.Request) {
+	var req BeginPasskeyLogInRequest
+	logger := utils.MustGetLogger(r)
+
+	err := parseRequest(r, &req)
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	options, ceremony, err := a.domain.BeginPasskeyLogIn(setup, req.Challenge)
+	if err == domain.ErrPasskeysNotConfigured {
+		respondWithError(w, "passkeys are not enabled", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrInvalidChallenge {
+		respondWithError(w, "invalid or expired challenge", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrNotEnrolled {
+		respondWithError(w, "user has no passkeys", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, PasskeyOptionsResponse{Ceremony: ceremony, Options: options})
+}
+
+type FinishPasskeyLogInRequest struct {
+	Ceremony   string          `json:"ceremony"`
+	Credential json.RawMessage `json:"credential"`
+	Tokens     bool            `json:"tokens"`
+}
+
+func (a *jsonApi) postFinishPasskeyLogIn(w http.ResponseWriter, r *http.Request) {
+	var req FinishPasskeyLogInRequest
+	logger := utils.MustGetLogger(r)
+
+	err := parseRequest(r, &req)
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	err = validateFinishPasskeyLogIn(req)
+	if err != nil {
+		respondWithError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	user, session, err := a.domain.FinishPasskeyLogIn(setup, req.Ceremony, req.Credential)
+	if err == domain.ErrPasskeysNotConfigured {
+		respondWithError(w, "passkeys are not enabled", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrInvalidCeremony {
+		respondWithError(w, "invalid or expired ceremony", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrInvalidChallenge {
+		respondWithError(w, "invalid or expired challenge", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrInvalidCredentials {
+		respondWithError(w, "invalid credentials", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithError(w, "failed login attempt", http.StatusBadRequest)
+		return
+	}
+
+	a.respondWithSession(w, r, user, session, req.Tokens)
+}
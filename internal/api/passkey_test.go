@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBeginPasskeyLogIn(t *testing.T) {
+	t.Parallel()
+
+	requestBuilder := utils.RequestBuilder("POST", "/login/passkey/begin")
+
+	tests := []struct {
+		name       string
+		request    string
+		challenge  string
+		statusCode int
+		response   string
+		returnErr  error
+	}{
+		{
+			name:       "passwordless",
+			request:    `{}`,
+			statusCode: http.StatusOK,
+			response:   `{ "ceremony": "ceremony", "options": { "publicKey": {} } }`,
+		},
+		{
+			name:       "second factor",
+			request:    `{ "challenge": "abc" }`,
+			challenge:  "abc",
+			statusCode: http.StatusOK,
+			response:   `{ "ceremony": "ceremony", "options": { "publicKey": {} } }`,
+		},
+		{
+			name:       "not configured",
+			request:    `{}`,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("passkeys are not enabled"),
+			returnErr:  domain.ErrPasskeysNotConfigured,
+		},
+		{
+			name:       "internal error",
+			request:    `{}`,
+			statusCode: http.StatusInternalServerError,
+			response:   utils.ErrorJSON("internal server error"),
+			returnErr:  errors.New("random error"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			var options json.RawMessage
+			if tc.returnErr == nil {
+				options = json.RawMessage(`{ "publicKey": {} }`)
+			}
+
+			baseMock.EXPECT().BeginPasskeyLogIn(mock.Anything, tc.challenge).Return(options, "ceremony", tc.returnErr)
+
+			api := NewApi(utils.Config{}, mux, baseMock, sl)
+			api.postBeginPasskeyLogIn(rr, requestBuilder(tc.request))
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
+
+func TestFinishPasskeyLogIn(t *testing.T) {
+	t.Parallel()
+
+	requestBuilder := utils.RequestBuilder("POST", "/login/passkey/finish")
+
+	user := domain.User{ID: 884, Email: "djvukovic@gmail.com", Username: "djvukovic", Role: "admin", Verified: true}
+	credentialMatcher := mock.MatchedBy(func(credential []byte) bool {
+		return string(credential) == `{"id":"abc"}`
+	})
+
+	tests := []struct {
+		name       string
+		request    string
+		statusCode int
+		response   string
+		returnErr  error
+	}{
+		{
+			name:       "success",
+			request:    `{ "ceremony": "ceremony", "credential": {"id":"abc"} }`,
+			statusCode: http.StatusOK,
+			response:   `{"id": 884, "username": "djvukovic", "email": "djvukovic@gmail.com", "role": "admin", "verified": true }`,
+		},
+		{
+			name:       "missing credential",
+			request:    `{ "ceremony": "ceremony" }`,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("missing credential"),
+		},
+		{
+			name:       "rejected assertion",
+			request:    `{ "ceremony": "ceremony", "credential": {"id":"abc"} }`,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid credentials"),
+			returnErr:  domain.ErrInvalidCredentials,
+		},
+		{
+			name:       "expired ceremony",
+			request:    `{ "ceremony": "ceremony", "credential": {"id":"abc"} }`,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid or expired ceremony"),
+			returnErr:  domain.ErrInvalidCeremony,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.statusCode == http.StatusOK {
+				baseMock.EXPECT().FinishPasskeyLogIn(mock.Anything, "ceremony", credentialMatcher).Return(user, "session", nil)
+			} else if tc.returnErr != nil {
+				baseMock.EXPECT().FinishPasskeyLogIn(mock.Anything, "ceremony", credentialMatcher).Return(domain.User{}, "", tc.returnErr)
+			}
+
+			api := NewApi(utils.Config{SessionCookie: "_tkn"}, mux, baseMock, sl)
+			api.postFinishPasskeyLogIn(rr, requestBuilder(tc.request))
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
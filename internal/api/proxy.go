@@ -0,0 +1,170 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/djordjev/auth/internal/api/middleware"
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+// identityHeaderPrefix is prefix of headers upstreams get identity of the user from.
+// Clients can't set them since all headers with the prefix are dropped.
+const identityHeaderPrefix = "X-Auth-"
+
+// MountProxy forwards configured routes to their upstreams once request is authenticated.
+// Routes are more specific than the app so they would take precedence over its routes,
+// routes shadowing them are rejected and the app has to be mounted first to check that.
+func (a *jsonApi) MountProxy() error {
+	if len(a.subrouter.Routes()) == 0 {
+		return errors.New("app has to be mounted before the proxy")
+	}
+
+	for _, route := range a.cfg.Proxy.Routes {
+		if shadowed, found := a.shadowedRoute(route.Path); found {
+			return fmt.Errorf("proxy route %s shadows %s route of the app", route.Path, shadowed)
+		}
+	}
+
+	for _, route := range a.cfg.Proxy.Routes {
+		handler := middleware.Logger(a.logger)(middleware.Client()(a.proxy(route)))
+		a.mux.Handle(route.Path, handler)
+	}
+
+	return nil
+}
+
+// shadowedRoute finds route of the app under the proxy path, url params match any segment
+func (a *jsonApi) shadowedRoute(path string) (shadowed string, found bool) {
+	prefix := strings.Split(strings.Trim(path, "/"), "/")
+
+	chi.Walk(a.subrouter, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		segments := strings.Split(strings.Trim(route, "/"), "/")
+		if found || len(segments) < len(prefix) {
+			return nil
+		}
+
+		for i, segment := range prefix {
+			if segments[i] != segment && !strings.HasPrefix(segments[i], "{") {
+				return nil
+			}
+		}
+
+		shadowed, found = route, true
+		return nil
+	})
+
+	return
+}
+
+// proxy checks session and role rules of the route the same way forward-auth does
+func (a *jsonApi) proxy(route utils.ProxyRoute) http.Handler {
+	upstream := http.StripPrefix(strings.TrimSuffix(route.Path, "/"), &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(route.Upstream)
+			pr.SetXForwarded()
+		},
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := utils.MustGetLogger(r)
+		token := a.sessionToken(r)
+
+		setup := domain.NewSetup(r.Context(), logger)
+		user, err := a.domain.ForwardAuth(setup, token, r.URL.Path)
+		if err == domain.ErrNoSession {
+			a.respondWithLogIn(w, r, requestURL(r))
+			return
+		} else if err == domain.ErrForbidden {
+			respondWithError(w, "forbidden", http.StatusForbidden)
+			return
+		} else if err != nil {
+			utils.LogError(logger, err)
+			respondWithInternalError(w)
+			return
+		}
+
+		outgoing := r.Clone(r.Context())
+		a.dropCredentials(outgoing, token)
+		setIdentityHeaders(outgoing.Header, user, a.cfg.Proxy.Secret, time.Now())
+
+		upstream.ServeHTTP(w, outgoing)
+	})
+}
+
+// dropCredentials removes session token and identity claimed by the client so upstream never sees them
+func (a *jsonApi) dropCredentials(r *http.Request, token string) {
+	for key := range r.Header {
+		if isIdentityHeader(key) {
+			delete(r.Header, key)
+		}
+	}
+
+	cookies := r.Cookies()
+	r.Header.Del("Cookie")
+	for _, cookie := range cookies {
+		if cookie.Name != a.cfg.SessionCookie {
+			r.AddCookie(cookie)
+		}
+	}
+
+	if r.Header.Get("Authorization") == "Bearer "+token {
+		r.Header.Del("Authorization")
+	}
+
+	if query := r.URL.Query(); query.Get("token") == token {
+		query.Del("token")
+		r.URL.RawQuery = query.Encode()
+	}
+}
+
+// isIdentityHeader matches identity headers however client spells them. Headers with
+// underscores aren't canonicalized and some servers treat them same as with dashes.
+func isIdentityHeader(key string) bool {
+	normalized := strings.ReplaceAll(key, "_", "-")
+
+	return len(normalized) >= len(identityHeaderPrefix) &&
+		strings.EqualFold(normalized[:len(identityHeaderPrefix)], identityHeaderPrefix)
+}
+
+// setIdentityHeaders adds identity of the user signed with HMAC-SHA256 of
+// "<user id>\n<email>\n<role>\n<unix timestamp>" so upstream can check it came
+// from the proxy. Header values can't contain new lines so fields can't be mixed up.
+func setIdentityHeaders(header http.Header, user domain.User, secret string, now time.Time) {
+	userId := strconv.FormatUint(user.ID, 10)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{userId, user.Email, user.Role, timestamp}, "\n")))
+
+	header.Set(identityHeaderPrefix+"User-Id", userId)
+	header.Set(identityHeaderPrefix+"Email", user.Email)
+	header.Set(identityHeaderPrefix+"Role", user.Role)
+	header.Set(identityHeaderPrefix+"Timestamp", timestamp)
+	header.Set(identityHeaderPrefix+"Signature", hex.EncodeToString(mac.Sum(nil)))
+}
+
+func requestURL(r *http.Request) *url.URL {
+	requested := *r.URL
+	requested.Host = r.Host
+
+	requested.Scheme = r.Header.Get("X-Forwarded-Proto")
+	if requested.Scheme == "" && r.TLS != nil {
+		requested.Scheme = "https"
+	} else if requested.Scheme == "" {
+		requested.Scheme = "http"
+	}
+
+	return &requested
+}
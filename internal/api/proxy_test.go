@@ -0,0 +1,175 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxy(t *testing.T) {
+	t.Parallel()
+
+	user := domain.User{ID: 452, Email: "djvukovic@gmail.com", Role: "admin"}
+
+	var received *http.Request
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer upstream.Close()
+
+	upstreamURL, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+
+	config := utils.Config{
+		SessionCookie: "_tkn",
+		ForwardAuth:   utils.ForwardAuth{LoginURL: "https://example.com/login"},
+		Proxy: utils.Proxy{
+			Routes: []utils.ProxyRoute{{Path: "/wiki/", Upstream: upstreamURL}},
+			Secret: "secret",
+		},
+	}
+
+	tests := []struct {
+		name       string
+		accept     string
+		statusCode int
+		location   string
+		returnUser domain.User
+		returnErr  error
+		forwarded  bool
+	}{
+		{
+			name:       "forwarded with identity",
+			statusCode: http.StatusTeapot,
+			returnUser: user,
+			forwarded:  true,
+		},
+		{
+			name:       "role not allowed",
+			statusCode: http.StatusForbidden,
+			returnErr:  domain.ErrForbidden,
+		},
+		{
+			name:       "no session",
+			statusCode: http.StatusUnauthorized,
+			returnErr:  domain.ErrNoSession,
+		},
+		{
+			name:       "browser redirected to login",
+			accept:     "text/html",
+			statusCode: http.StatusFound,
+			location:   "https://example.com/login?return_to=http%3A%2F%2Ftools.example.com%2Fwiki%2Fpage%3Fv%3D1",
+			returnErr:  domain.ErrNoSession,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			received = nil
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			baseMock.EXPECT().ForwardAuth(mock.Anything, "session", "/wiki/page").Return(tc.returnUser, tc.returnErr)
+
+			req := httptest.NewRequest("GET", "http://tools.example.com/wiki/page?v=1", nil)
+			req.AddCookie(&http.Cookie{Name: "_tkn", Value: "session"})
+			req.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+			req.Header.Set("X-Auth-Role", "superuser")
+			req.Header.Set("x-auth-impersonate", "1")
+			req.Header["X_Auth_User_Id"] = []string{"1"}
+			req.Header["x_auth_email"] = []string{"admin@example.com"}
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+
+			proxyMux := http.NewServeMux()
+			api := NewApi(config, proxyMux, baseMock, sl)
+			api.Mount("/")
+			require.NoError(t, api.MountProxy())
+			proxyMux.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.Equal(t, tc.location, rr.Header().Get("Location"))
+
+			if !tc.forwarded {
+				require.Nil(t, received)
+				return
+			}
+
+			require.NotNil(t, received)
+			require.Equal(t, "/page", received.URL.Path)
+			require.Equal(t, "v=1", received.URL.RawQuery)
+
+			cookies := received.Cookies()
+			require.Len(t, cookies, 1)
+			require.Equal(t, "theme", cookies[0].Name)
+
+			require.Empty(t, received.Header.Get("X-Auth-Impersonate"))
+			for key := range received.Header {
+				if isIdentityHeader(key) {
+					require.Contains(t, []string{"X-Auth-User-Id", "X-Auth-Email", "X-Auth-Role", "X-Auth-Timestamp", "X-Auth-Signature"}, key)
+				}
+			}
+			require.Equal(t, "452", received.Header.Get("X-Auth-User-Id"))
+			require.Equal(t, user.Email, received.Header.Get("X-Auth-Email"))
+			require.Equal(t, "admin", received.Header.Get("X-Auth-Role"))
+
+			mac := hmac.New(sha256.New, []byte("secret"))
+			mac.Write([]byte("452\ndjvukovic@gmail.com\nadmin\n" + received.Header.Get("X-Auth-Timestamp")))
+			require.Equal(t, hex.EncodeToString(mac.Sum(nil)), received.Header.Get("X-Auth-Signature"))
+		})
+	}
+}
+
+func TestMountProxyShadowedRoutes(t *testing.T) {
+	t.Parallel()
+
+	upstreamURL, err := url.Parse("http://upstream:8080")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		path     string
+		mount    bool
+		errorMsg string
+	}{
+		{name: "own path", path: "/wiki/", mount: true},
+		{name: "deeper than app route", path: "/apikeys/7/usage/", mount: true},
+		{name: "shadows routes under it", path: "/device/", mount: true, errorMsg: "proxy route /device/ shadows /device/code route of the app"},
+		{name: "shadows route with url param", path: "/oidc/google/", mount: true, errorMsg: "proxy route /oidc/google/ shadows /oidc/{provider} route of the app"},
+		{name: "shadows route with the same path", path: "/login/", mount: true, errorMsg: "proxy route /login/ shadows /login route of the app"},
+		{name: "app not mounted", path: "/wiki/", errorMsg: "app has to be mounted before the proxy"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := utils.Config{Proxy: utils.Proxy{
+				Routes: []utils.ProxyRoute{{Path: tc.path, Upstream: upstreamURL}},
+				Secret: "secret",
+			}}
+
+			api := NewApi(config, http.NewServeMux(), domain.NewMockDomain(t), sl)
+			if tc.mount {
+				api.Mount("/")
+			}
+
+			err := api.MountProxy()
+
+			if tc.errorMsg != "" {
+				require.EqualError(t, err, tc.errorMsg)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
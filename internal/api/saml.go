@@ -0,0 +1,122 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+func (a *jsonApi) getSAMLMetadata(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+	provider := chi.URLParam(r, "provider")
+
+	setup := domain.NewSetup(r.Context(), logger)
+	metadata, err := a.domain.SAMLMetadata(setup, provider)
+	if err == domain.ErrUnknownProvider {
+		respondWithError(w, "unknown identity provider", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/samlmetadata+xml")
+	w.Write(metadata)
+}
+
+// getSAMLLogIn redirects to identity provider. With `link=true` query param
+// provider is linked to the account of signed in user instead. With `tokens=true`
+// ACS responds with tokens instead of session cookie.
+func (a *jsonApi) getSAMLLogIn(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+	provider := chi.URLParam(r, "provider")
+
+	token := ""
+	if r.URL.Query().Get("link") == "true" {
+		token = a.sessionToken(r)
+		if token == "" {
+			respondWithUnauthorized(w)
+			return
+		}
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	redirect, relayState, err := a.domain.BeginSAMLLogIn(setup, provider, token)
+	if err == domain.ErrUnknownProvider {
+		respondWithError(w, "unknown identity provider", http.StatusNotFound)
+		return
+	} else if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	// identity provider posts the response cross site so lax cookie wouldn't come back
+	setStateCookie(w, samlStateCookie, relayState, http.SameSiteNoneMode, r.URL.Query().Get("tokens") == "true")
+
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+// postSAMLACS receives response identity provider posts with HTTP-POST binding
+func (a *jsonApi) postSAMLACS(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+	provider := chi.URLParam(r, "provider")
+
+	err := r.ParseForm()
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	relayState := r.PostForm.Get("RelayState")
+	response := r.PostForm.Get("SAMLResponse")
+
+	if relayState == "" || response == "" {
+		respondWithBadRequest(w)
+		return
+	}
+
+	// response has to come back to the browser that started the log in, otherwise attacker
+	// could make the victim finish log in or linking started by the attacker
+	bound, tokens := takeStateCookie(w, r, samlStateCookie, relayState)
+	if !bound {
+		respondWithError(w, "invalid or expired relay state", http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	user, session, err := a.domain.FinishSAMLLogIn(setup, provider, relayState, response)
+	if err == domain.ErrSecondFactorRequired {
+		mustWriteJSONResponse(w, LogInChallengeResponse{SecondFactorRequired: true, Challenge: session})
+		return
+	} else if err == domain.ErrUnknownProvider {
+		respondWithError(w, "unknown identity provider", http.StatusNotFound)
+		return
+	} else if err == domain.ErrInvalidCeremony {
+		respondWithError(w, "invalid or expired relay state", http.StatusBadRequest)
+		return
+	} else if err == domain.ErrIdentityLinkRequired {
+		respondWithError(w, "account with this email already exists, log in to link the provider", http.StatusConflict)
+		return
+	} else if err == domain.ErrIdentityInUse {
+		respondWithError(w, "identity is linked to another account", http.StatusConflict)
+		return
+	} else if errors.Is(err, domain.ErrInvalidIdentity) {
+		utils.LogError(logger, err)
+		respondWithError(w, "invalid identity", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	a.respondWithSession(w, r, user, session, tokens)
+}
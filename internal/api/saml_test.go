@@ -0,0 +1,215 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSAMLMetadata(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		statusCode  int
+		contentType string
+		response    string
+		metadata    []byte
+		returnErr   error
+	}{
+		{
+			name:        "success",
+			statusCode:  http.StatusOK,
+			contentType: "application/samlmetadata+xml",
+			response:    "<EntityDescriptor></EntityDescriptor>",
+			metadata:    []byte("<EntityDescriptor></EntityDescriptor>"),
+		},
+		{
+			name:       "unknown provider",
+			statusCode: http.StatusNotFound,
+			response:   utils.ErrorJSON("unknown identity provider"),
+			returnErr:  domain.ErrUnknownProvider,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			baseMock.EXPECT().SAMLMetadata(mock.Anything, "okta").Return(tc.metadata, tc.returnErr)
+
+			api := NewApi(utils.Config{SessionCookie: "_tkn"}, mux, baseMock, sl)
+			api.getSAMLMetadata(rr, withProvider(utils.RequestBuilder("GET", "/saml/okta/metadata")(""), "okta"))
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			if tc.returnErr != nil {
+				require.JSONEq(t, tc.response, rr.Body.String())
+				return
+			}
+
+			require.Equal(t, tc.contentType, rr.Header().Get("Content-Type"))
+			require.Equal(t, tc.response, rr.Body.String())
+		})
+	}
+}
+
+func TestSAMLLogIn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		path       string
+		token      string
+		statusCode int
+		location   string
+		returnErr  error
+	}{
+		{
+			name:       "redirects to identity provider",
+			path:       "/saml/okta",
+			statusCode: http.StatusFound,
+			location:   "https://example.okta.com/sso?SAMLRequest=abc&RelayState=xyz",
+		},
+		{
+			name:       "links provider",
+			path:       "/saml/okta?link=true&token=session",
+			token:      "session",
+			statusCode: http.StatusFound,
+			location:   "https://example.okta.com/sso?SAMLRequest=abc&RelayState=xyz",
+		},
+		{
+			name:       "unknown provider",
+			path:       "/saml/okta",
+			statusCode: http.StatusNotFound,
+			returnErr:  domain.ErrUnknownProvider,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			relayState := ""
+			if tc.returnErr == nil {
+				relayState = "xyz"
+			}
+
+			baseMock.EXPECT().BeginSAMLLogIn(mock.Anything, "okta", tc.token).Return(tc.location, relayState, tc.returnErr)
+
+			api := NewApi(utils.Config{SessionCookie: "_tkn"}, mux, baseMock, sl)
+			api.getSAMLLogIn(rr, withProvider(utils.RequestBuilder("GET", tc.path)(""), "okta"))
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.Equal(t, tc.location, rr.Header().Get("Location"))
+
+			cookie := stateCookie(rr, "saml_relay_state")
+			if tc.returnErr != nil {
+				require.Nil(t, cookie)
+				return
+			}
+
+			hash := sha256.Sum256([]byte(relayState))
+			require.NotNil(t, cookie)
+			require.Equal(t, hex.EncodeToString(hash[:]), cookie.Value)
+			require.True(t, cookie.HttpOnly)
+			require.Equal(t, http.SameSiteNoneMode, cookie.SameSite)
+		})
+	}
+}
+
+func TestSAMLACS(t *testing.T) {
+	t.Parallel()
+
+	user := domain.User{ID: 884, Email: "djvukovic@gmail.com", Username: "djvukovic", Role: "admin", Verified: true}
+
+	tests := []struct {
+		name       string
+		body       string
+		cookie     string
+		statusCode int
+		response   string
+		returnUser domain.User
+		returnKey  string
+		returnErr  error
+		callDomain bool
+	}{
+		{
+			name:       "success",
+			body:       "SAMLResponse=cmVzcG9uc2U%3D&RelayState=abc",
+			cookie:     "abc",
+			statusCode: http.StatusOK,
+			response:   `{"id": 884, "username": "djvukovic", "email": "djvukovic@gmail.com", "role": "admin", "verified": true }`,
+			returnUser: user,
+			returnKey:  "session",
+			callDomain: true,
+		},
+		{
+			name:       "missing response",
+			body:       "RelayState=abc",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid request"),
+		},
+		{
+			name:       "missing relay state cookie",
+			body:       "SAMLResponse=cmVzcG9uc2U%3D&RelayState=abc",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid or expired relay state"),
+		},
+		{
+			name:       "relay state cookie of another log in",
+			body:       "SAMLResponse=cmVzcG9uc2U%3D&RelayState=abc",
+			cookie:     "def",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid or expired relay state"),
+		},
+		{
+			name:       "expired relay state",
+			body:       "SAMLResponse=cmVzcG9uc2U%3D&RelayState=abc",
+			cookie:     "abc",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid or expired relay state"),
+			returnErr:  domain.ErrInvalidCeremony,
+			callDomain: true,
+		},
+		{
+			name:       "invalid signature",
+			body:       "SAMLResponse=cmVzcG9uc2U%3D&RelayState=abc",
+			cookie:     "abc",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid identity"),
+			returnErr:  errors.Join(domain.ErrInvalidIdentity, errors.New("signature verification failed")),
+			callDomain: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.callDomain {
+				baseMock.EXPECT().FinishSAMLLogIn(mock.Anything, "okta", "abc", "cmVzcG9uc2U=").Return(tc.returnUser, tc.returnKey, tc.returnErr)
+			}
+
+			req := utils.RequestBuilder("POST", "/saml/okta/acs")(tc.body)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req = withStateCookie(req, "saml_relay_state", tc.cookie)
+
+			api := NewApi(utils.Config{SessionCookie: "_tkn"}, mux, baseMock, sl)
+			api.postSAMLACS(rr, withProvider(req, "okta"))
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
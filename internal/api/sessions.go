@@ -0,0 +1,106 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+type ActiveSessionResponse struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	LastSeen  time.Time `json:"last_seen"`
+	ExpiresAt time.Time `json:"expires_at"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Current   bool      `json:"current"`
+}
+
+type SessionsResponse struct {
+	Sessions []ActiveSessionResponse `json:"sessions"`
+}
+
+func (a *jsonApi) getSessions(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+
+	token := a.sessionToken(r)
+	if token == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	sessions, err := a.domain.Sessions(setup, token)
+	if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, sessionsToResponse(sessions))
+}
+
+type RevokeSessionResponse struct {
+	Revoked bool `json:"revoked"`
+}
+
+func (a *jsonApi) deleteSession(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+	id := chi.URLParam(r, "id")
+
+	token := a.sessionToken(r)
+	if token == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	err := a.domain.RevokeSession(setup, token, id)
+	if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err == domain.ErrSessionNotFound {
+		respondWithError(w, "session not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, RevokeSessionResponse{Revoked: true})
+}
+
+type RevokeOtherSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+// deleteOtherSessions signs user out everywhere except the device making the request
+func (a *jsonApi) deleteOtherSessions(w http.ResponseWriter, r *http.Request) {
+	logger := utils.MustGetLogger(r)
+
+	token := a.sessionToken(r)
+	if token == "" {
+		respondWithUnauthorized(w)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	revoked, err := a.domain.RevokeOtherSessions(setup, token)
+	if err == domain.ErrNoSession {
+		respondWithUnauthorized(w)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, RevokeOtherSessionsResponse{Revoked: revoked})
+}
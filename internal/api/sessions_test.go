@@ -0,0 +1,133 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessions(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2023, 11, 22, 10, 0, 0, 0, time.UTC)
+	lastSeen := time.Date(2023, 11, 23, 8, 30, 0, 0, time.UTC)
+	expiresAt := time.Date(2023, 12, 22, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		token      string
+		sessions   []domain.Session
+		returnErr  error
+		statusCode int
+		response   string
+	}{
+		{
+			name:  "success",
+			token: "session",
+			sessions: []domain.Session{
+				{ID: "4f1b", CreatedAt: createdAt, LastSeen: lastSeen, ExpiresAt: expiresAt, IP: "10.0.0.1", UserAgent: "curl/8.0", Current: true},
+			},
+			statusCode: http.StatusOK,
+			response:   `{"sessions": [{"id": "4f1b", "created_at": "2023-11-22T10:00:00Z", "last_seen": "2023-11-23T08:30:00Z", "expires_at": "2023-12-22T10:00:00Z", "ip": "10.0.0.1", "user_agent": "curl/8.0", "current": true}]}`,
+		},
+		{
+			name:       "not signed in",
+			statusCode: http.StatusUnauthorized,
+			response:   utils.ErrorJSON("unauthorized"),
+		},
+		{
+			name:       "expired session",
+			token:      "session",
+			returnErr:  domain.ErrNoSession,
+			statusCode: http.StatusUnauthorized,
+			response:   utils.ErrorJSON("unauthorized"),
+		},
+		{
+			name:       "internal error",
+			token:      "session",
+			returnErr:  errors.New("redis down"),
+			statusCode: http.StatusInternalServerError,
+			response:   utils.ErrorJSON("internal server error"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.token != "" {
+				baseMock.EXPECT().Sessions(mock.Anything, tc.token).Return(tc.sessions, tc.returnErr)
+			}
+
+			req := utils.RequestBuilder("GET", "/sessions")("")
+			if tc.token != "" {
+				req.AddCookie(&http.Cookie{Name: "_tkn", Value: tc.token})
+			}
+
+			api := NewApi(utils.Config{SessionCookie: "_tkn"}, mux, baseMock, sl)
+			api.getSessions(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
+
+func TestDeleteSession(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		returnErr  error
+		statusCode int
+		response   string
+	}{
+		{name: "success", statusCode: http.StatusOK, response: `{"revoked": true}`},
+		{name: "not found", returnErr: domain.ErrSessionNotFound, statusCode: http.StatusNotFound, response: utils.ErrorJSON("session not found")},
+		{name: "no session", returnErr: domain.ErrNoSession, statusCode: http.StatusUnauthorized, response: utils.ErrorJSON("unauthorized")},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			baseMock.EXPECT().RevokeSession(mock.Anything, "session", "4f1b").Return(tc.returnErr)
+
+			req := utils.RequestBuilder("DELETE", "/sessions/4f1b")("")
+			req.AddCookie(&http.Cookie{Name: "_tkn", Value: "session"})
+
+			api := NewApi(utils.Config{SessionCookie: "_tkn"}, mux, baseMock, sl)
+			api.deleteSession(rr, withAPIKeyID(req, "4f1b"))
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
+
+func TestDeleteOtherSessions(t *testing.T) {
+	t.Parallel()
+
+	rr := httptest.NewRecorder()
+	baseMock := domain.NewMockDomain(t)
+
+	baseMock.EXPECT().RevokeOtherSessions(mock.Anything, "session").Return(3, nil)
+
+	req := utils.RequestBuilder("DELETE", "/sessions")("")
+	req.AddCookie(&http.Cookie{Name: "_tkn", Value: "session"})
+
+	api := NewApi(utils.Config{SessionCookie: "_tkn"}, mux, baseMock, sl)
+	api.deleteOtherSessions(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"revoked": 3}`, rr.Body.String())
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+)
+
+type TokensResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// postRefresh rotates refresh token of clients that logged in with `tokens: true`
+func (a *jsonApi) postRefresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	logger := utils.MustGetLogger(r)
+
+	err := parseRequest(r, &req)
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	err = validateRefresh(req)
+	if err != nil {
+		respondWithError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	_, tokens, err := a.domain.RefreshTokens(setup, req.RefreshToken)
+	if err == domain.ErrInvalidRefreshToken || err == domain.ErrRefreshTokenReused {
+		respondWithError(w, "invalid or expired refresh token", http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, sessionTokensToResponse(tokens))
+}
+
+type RevokeRefreshResponse struct {
+	Success bool `json:"success"`
+}
+
+func (a *jsonApi) postRevokeRefresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	logger := utils.MustGetLogger(r)
+
+	err := parseRequest(r, &req)
+	if err != nil {
+		respondWithBadRequest(w)
+		return
+	}
+
+	err = validateRefresh(req)
+	if err != nil {
+		respondWithError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setup := domain.NewSetup(r.Context(), logger)
+	err = a.domain.RevokeRefreshToken(setup, req.RefreshToken)
+	if err == domain.ErrInvalidRefreshToken {
+		respondWithError(w, "invalid refresh token", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, RevokeRefreshResponse{Success: true})
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefresh(t *testing.T) {
+	t.Parallel()
+
+	requestBuilder := utils.RequestBuilder("POST", "/refresh")
+
+	tests := []struct {
+		name         string
+		request      string
+		statusCode   int
+		response     string
+		returnTokens domain.SessionTokens
+		returnErr    error
+		callDomain   bool
+	}{
+		{
+			name:         "success",
+			request:      `{"refresh_token": "refresh"}`,
+			statusCode:   http.StatusOK,
+			response:     `{"access_token": "access", "refresh_token": "rotated", "token_type": "Bearer", "expires_in": 900}`,
+			returnTokens: domain.SessionTokens{AccessToken: "access", RefreshToken: "rotated", ExpiresIn: 900},
+			callDomain:   true,
+		},
+		{
+			name:       "reused token",
+			request:    `{"refresh_token": "refresh"}`,
+			statusCode: http.StatusUnauthorized,
+			response:   utils.ErrorJSON("invalid or expired refresh token"),
+			returnErr:  domain.ErrRefreshTokenReused,
+			callDomain: true,
+		},
+		{
+			name:       "missing token",
+			request:    `{}`,
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("missing refresh token"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.callDomain {
+				baseMock.EXPECT().RefreshTokens(mock.Anything, "refresh").Return(domain.User{}, tc.returnTokens, tc.returnErr)
+			}
+
+			api := NewApi(utils.Config{}, mux, baseMock, sl)
+			api.postRefresh(rr, requestBuilder(tc.request))
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
+
+func TestRevokeRefresh(t *testing.T) {
+	t.Parallel()
+
+	requestBuilder := utils.RequestBuilder("POST", "/refresh/revoke")
+
+	tests := []struct {
+		name       string
+		statusCode int
+		response   string
+		returnErr  error
+	}{
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			response:   `{"success": true}`,
+		},
+		{
+			name:       "unknown token",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid refresh token"),
+			returnErr:  domain.ErrInvalidRefreshToken,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			baseMock.EXPECT().RevokeRefreshToken(mock.Anything, "refresh").Return(tc.returnErr)
+
+			api := NewApi(utils.Config{}, mux, baseMock, sl)
+			api.postRevokeRefresh(rr, requestBuilder(`{"refresh_token": "refresh"}`))
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
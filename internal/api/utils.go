@@ -1,9 +1,16 @@
 package api
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
 )
 
 func parseRequest(req *http.Request, target any) error {
@@ -50,3 +57,102 @@ func respondWithBadRequest(w http.ResponseWriter) {
 
 	http.Error(w, string(responseData), http.StatusBadRequest)
 }
+
+// sessionToken reads session key from the session cookie falling back to bearer
+// authorization used by api keys and then to token query param
+func (a *jsonApi) sessionToken(r *http.Request) string {
+	return utils.SessionToken(r, a.cfg.SessionCookie)
+}
+
+func (a *jsonApi) setSessionCookie(w http.ResponseWriter, session string) {
+	cookie := &http.Cookie{
+		Name:     a.cfg.SessionCookie,
+		Value:    session,
+		Path:     "/",
+		MaxAge:   int(a.cfg.SessionLifetime.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	}
+
+	http.SetCookie(w, cookie)
+}
+
+// respondWithSession finishes every log in either with session cookie or, when client asked
+// for tokens, with access and refresh tokens the session is exchanged for
+func (a *jsonApi) respondWithSession(w http.ResponseWriter, r *http.Request, user domain.User, session string, tokens bool) {
+	if !tokens {
+		a.setSessionCookie(w, session)
+		mustWriteJSONResponse(w, userToLogInResponse(user))
+		return
+	}
+
+	logger := utils.MustGetLogger(r)
+
+	exchanged, err := a.domain.ExchangeSession(domain.NewSetup(r.Context(), logger), session)
+	if err != nil {
+		utils.LogError(logger, err)
+		respondWithInternalError(w)
+		return
+	}
+
+	mustWriteJSONResponse(w, LogInTokensResponse{
+		LogInResponse:  userToLogInResponse(user),
+		TokensResponse: sessionTokensToResponse(exchanged),
+	})
+}
+
+// cookies binding state of OIDC and SAML log in to the browser that started it
+const (
+	oidcStateCookie = "oidc_state"
+	samlStateCookie = "saml_relay_state"
+)
+
+// tokensMode marks state cookie of log in that finishes with tokens instead of session cookie
+const tokensMode = ".tokens"
+
+// setStateCookie binds state of a redirect flow to the browser that started it so the
+// callback can't be replayed in another browser. Only hash of the state is kept, together
+// with the mode log in finishes in.
+func setStateCookie(w http.ResponseWriter, name string, state string, sameSite http.SameSite, tokens bool) {
+	hash := sha256.Sum256([]byte(state))
+
+	value := hex.EncodeToString(hash[:])
+	if tokens {
+		value += tokensMode
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(utils.WEBAUTHN_CEREMONY_TTL.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: sameSite,
+	})
+}
+
+// takeStateCookie clears the state cookie and reports whether it was set for the state
+// and whether log in was started in tokens mode
+func takeStateCookie(w http.ResponseWriter, r *http.Request, name string, state string) (bound bool, tokens bool) {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: name, Path: "/", MaxAge: -1, HttpOnly: true, Secure: true})
+
+	value, tokens := strings.CutSuffix(cookie.Value, tokensMode)
+
+	hash := sha256.Sum256([]byte(state))
+	bound = subtle.ConstantTimeCompare([]byte(value), []byte(hex.EncodeToString(hash[:]))) == 1
+
+	return
+}
+
+func respondWithUnauthorized(w http.ResponseWriter) {
+	responseData, _ := json.Marshal(ErrorResponse{Error: "unauthorized"})
+
+	http.Error(w, string(responseData), http.StatusUnauthorized)
+}
@@ -3,9 +3,15 @@ package api
 import (
 	"fmt"
 	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
 )
 
 const minPasswordLength = 5
+const totpCodeLength = 6
+const oneTimeCodeLength = 6
 
 func validateSignup(request SignUpRequest) error {
 	if request.Email == "" {
@@ -39,6 +45,14 @@ func validateLogin(request LogInRequest) error {
 	return nil
 }
 
+func validateIdentify(request IdentifyRequest) error {
+	if request.Username == "" && request.Email == "" {
+		return fmt.Errorf("missing email or username")
+	}
+
+	return nil
+}
+
 func validateDeleteAccount(request DeleteAccountRequest) error {
 	if request.Username == "" && request.Email == "" {
 		return fmt.Errorf("missing email or username")
@@ -59,6 +73,46 @@ func validateForgetPassword(request ForgetPasswordRequest) error {
 	return nil
 }
 
+func validateMagicLink(request MagicLinkRequest) error {
+	if request.Email == "" && request.Username == "" {
+		return fmt.Errorf("missing email or username")
+	}
+
+	return nil
+}
+
+func validateOneTimeCode(request OneTimeCodeRequest) error {
+	if request.Email == "" && request.Username == "" {
+		return fmt.Errorf("missing email or username")
+	}
+
+	return nil
+}
+
+func validateLogInOneTimeCode(request LogInOneTimeCodeRequest) error {
+	if request.Email == "" && request.Username == "" {
+		return fmt.Errorf("missing email or username")
+	}
+
+	return validateDigits(request.Code, oneTimeCodeLength)
+}
+
+func validateVerifyAccount(request VerifyAccountRequest) error {
+	if request.Code == "" {
+		if request.Token == "" {
+			return fmt.Errorf("missing token or code")
+		}
+
+		return nil
+	}
+
+	if request.Email == "" && request.Username == "" {
+		return fmt.Errorf("missing email or username")
+	}
+
+	return validateDigits(request.Code, oneTimeCodeLength)
+}
+
 func validateVerifyPasswordResetRequest(request VerifyPasswordResetRequest) error {
 	if request.Token == "" {
 		return fmt.Errorf("invalid token")
@@ -70,3 +124,123 @@ func validateVerifyPasswordResetRequest(request VerifyPasswordResetRequest) erro
 
 	return nil
 }
+
+func validateDigits(code string, length int) error {
+	if len(code) != length {
+		return fmt.Errorf("code must have %d digits", length)
+	}
+
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("code must have %d digits", length)
+		}
+	}
+
+	return nil
+}
+
+func validateTOTPCode(code string) error {
+	return validateDigits(code, totpCodeLength)
+}
+
+func validateConfirmTOTP(request ConfirmTOTPRequest) error {
+	return validateTOTPCode(request.Code)
+}
+
+func validateLogInTOTP(request LogInTOTPRequest) error {
+	if request.Challenge == "" {
+		return fmt.Errorf("missing challenge")
+	}
+
+	return validateTOTPCode(request.Code)
+}
+
+func validateLogInRecoveryCode(request LogInRecoveryCodeRequest) error {
+	if request.Challenge == "" {
+		return fmt.Errorf("missing challenge")
+	}
+
+	if request.Code == "" {
+		return fmt.Errorf("missing recovery code")
+	}
+
+	return nil
+}
+
+func validateFinishPasskeyRegistration(request FinishPasskeyRegistrationRequest) error {
+	if request.Ceremony == "" {
+		return fmt.Errorf("missing ceremony")
+	}
+
+	if len(request.Credential) == 0 {
+		return fmt.Errorf("missing credential")
+	}
+
+	return nil
+}
+
+func validateFinishPasskeyLogIn(request FinishPasskeyLogInRequest) error {
+	if request.Ceremony == "" {
+		return fmt.Errorf("missing ceremony")
+	}
+
+	if len(request.Credential) == 0 {
+		return fmt.Errorf("missing credential")
+	}
+
+	return nil
+}
+
+func validateConsent(request ConsentRequest) error {
+	if request.Consent == "" {
+		return fmt.Errorf("missing consent")
+	}
+
+	return nil
+}
+
+func validateToken(request domain.TokenRequest) error {
+	if request.GrantType == "" {
+		return fmt.Errorf("missing grant type")
+	}
+
+	if request.ClientID == "" {
+		return fmt.Errorf("missing client id")
+	}
+
+	return nil
+}
+
+func validateRefresh(request RefreshRequest) error {
+	if request.RefreshToken == "" {
+		return fmt.Errorf("missing refresh token")
+	}
+
+	return nil
+}
+
+func validateCreateAPIKey(request CreateAPIKeyRequest) error {
+	if request.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+
+	if !request.ExpiresAt.IsZero() && request.ExpiresAt.Before(time.Now()) {
+		return fmt.Errorf("expiration has to be in the future")
+	}
+
+	for _, scope := range request.Scopes {
+		if !strings.HasPrefix(scope, "/") {
+			return fmt.Errorf("scopes have to be paths starting with /")
+		}
+	}
+
+	return nil
+}
+
+func validateDeviceVerification(request DeviceVerificationRequest) error {
+	if request.UserCode == "" {
+		return fmt.Errorf("missing user code")
+	}
+
+	return nil
+}
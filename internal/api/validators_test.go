@@ -206,3 +206,91 @@ func TestValidateVerifyPasswordReset(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateLogInTOTP(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		request LogInTOTPRequest
+		err     string
+	}{
+		{
+			name:    "success",
+			request: LogInTOTPRequest{Challenge: "challenge", Code: "012345"},
+		},
+		{
+			name:    "missing challenge",
+			request: LogInTOTPRequest{Code: "012345"},
+			err:     "missing challenge",
+		},
+		{
+			name:    "short code",
+			request: LogInTOTPRequest{Challenge: "challenge", Code: "12345"},
+			err:     "code must have 6 digits",
+		},
+		{
+			name:    "non numeric code",
+			request: LogInTOTPRequest{Challenge: "challenge", Code: "12a456"},
+			err:     "code must have 6 digits",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateLogInTOTP(tc.request)
+
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateVerifyAccount(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		request VerifyAccountRequest
+		err     string
+	}{
+		{
+			name:    "token",
+			request: VerifyAccountRequest{Token: "abc"},
+		},
+		{
+			name:    "code",
+			request: VerifyAccountRequest{Email: "djvukovic@gmail.com", Code: "012345"},
+		},
+		{
+			name:    "missing token and code",
+			request: VerifyAccountRequest{},
+			err:     "missing token or code",
+		},
+		{
+			name:    "code without email",
+			request: VerifyAccountRequest{Code: "012345"},
+			err:     "missing email or username",
+		},
+		{
+			name:    "invalid code",
+			request: VerifyAccountRequest{Username: "djvukovic", Code: "1234"},
+			err:     "code must have 6 digits",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateVerifyAccount(tc.request)
+
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
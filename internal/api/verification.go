@@ -8,7 +8,10 @@ import (
 )
 
 type VerifyAccountRequest struct {
-	Token string `json:"token"`
+	Token    string `json:"token"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	Code     string `json:"code"`
 }
 
 type VerifyAccountResponse struct {
@@ -25,12 +28,28 @@ func (a *jsonApi) postVerifyAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = validateVerifyAccount(req)
+	if err != nil {
+		respondWithError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	setup := domain.NewSetup(r.Context(), logger)
 
-	verified, err := a.domain.VerifyAccount(setup, req.Token)
+	// mobile clients send the emailed code instead of the token from the link
+	var verified bool
+	if req.Code != "" {
+		verified, err = a.domain.VerifyAccountCode(setup, domain.User{Email: req.Email, Username: req.Username}, req.Code)
+	} else {
+		verified, err = a.domain.VerifyAccount(setup, req.Token)
+	}
+
 	if err == domain.ErrInvalidToken {
 		respondWithError(w, "invalid verification token", http.StatusBadRequest)
 		return
+	} else if err == domain.ErrInvalidCode {
+		respondWithError(w, "invalid verification code", http.StatusBadRequest)
+		return
 	} else if err != nil {
 		respondWithInternalError(w)
 		return
@@ -42,6 +61,7 @@ func (a *jsonApi) postVerifyAccount(w http.ResponseWriter, r *http.Request) {
 type VerifyPasswordResetRequest struct {
 	Token       string `json:"token"`
 	NewPassword string `json:"new_password"`
+	KeepSession bool   `json:"keep_session"`
 }
 
 type VerifyPasswordResetResponse struct {
@@ -64,8 +84,14 @@ func (a *jsonApi) postVerifyPasswordReset(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// other sessions are revoked, the one resetting the password can stay signed in
+	keepSession := ""
+	if req.KeepSession {
+		keepSession = a.sessionToken(r)
+	}
+
 	setup := domain.NewSetup(r.Context(), logger)
-	user, err := a.domain.VerifyPasswordReset(setup, req.Token, req.NewPassword)
+	user, err := a.domain.VerifyPasswordReset(setup, req.Token, req.NewPassword, keepSession)
 	if err == domain.ErrInvalidToken {
 		respondWithError(w, "invalid token", http.StatusBadRequest)
 		return
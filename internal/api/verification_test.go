@@ -88,6 +88,7 @@ func TestVerifyPasswordReset(t *testing.T) {
 		returnVal  domain.User
 		returnErr  error
 		skipMock   bool
+		keep       string
 	}{
 		{
 			name:       "success",
@@ -97,6 +98,14 @@ func TestVerifyPasswordReset(t *testing.T) {
 			returnVal:  domain.User{Password: "abc"},
 			returnErr:  nil,
 		},
+		{
+			name:       "keeps current session",
+			request:    `{ "token": "abc", "new_password": "testee", "keep_session": true }`,
+			statusCode: http.StatusOK,
+			response:   `{ "success": true }`,
+			returnVal:  domain.User{Password: "abc"},
+			keep:       "session",
+		},
 		{
 			name:       "invalid token",
 			request:    verifyPasswordReset,
@@ -133,6 +142,7 @@ func TestVerifyPasswordReset(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 
 			req := utils.RequestBuilder("POST", "/passwordreset")(tc.request)
+			req.Header.Set("Authorization", "Bearer session")
 			rr := httptest.NewRecorder()
 
 			baseExpector := domain.NewMockDomain(t)
@@ -147,7 +157,7 @@ func TestVerifyPasswordReset(t *testing.T) {
 			})
 
 			if !tc.skipMock {
-				domain.VerifyPasswordReset(mock.Anything, tokenMatcher, passwordMatcher).
+				domain.VerifyPasswordReset(mock.Anything, tokenMatcher, passwordMatcher, tc.keep).
 					Return(tc.returnVal, tc.returnErr)
 			}
 
@@ -160,3 +170,50 @@ func TestVerifyPasswordReset(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyAccountCode(t *testing.T) {
+	var verifyAccountBody = `
+		{ "email": "djvukovic@gmail.com", "code": "123456" }
+	`
+
+	tests := []struct {
+		name       string
+		statusCode int
+		response   string
+		returnVal  bool
+		returnErr  error
+	}{
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			response:   `{ "verified": true }`,
+			returnVal:  true,
+		},
+		{
+			name:       "wrong code",
+			statusCode: http.StatusBadRequest,
+			response:   utils.ErrorJSON("invalid verification code"),
+			returnErr:  domain.ErrInvalidCode,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := utils.RequestBuilder("POST", "/verify")(verifyAccountBody)
+
+			rr := httptest.NewRecorder()
+
+			baseExpector := domain.NewMockDomain(t)
+			baseExpector.EXPECT().
+				VerifyAccountCode(mock.Anything, domain.User{Email: "djvukovic@gmail.com"}, "123456").
+				Return(tc.returnVal, tc.returnErr)
+
+			api := NewApi(utils.Config{}, http.NewServeMux(), baseExpector, logger)
+
+			api.postVerifyAccount(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			require.JSONEq(t, tc.response, rr.Body.String())
+		})
+	}
+}
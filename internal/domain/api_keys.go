@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+)
+
+const apiKeyPrefix = "ak_"
+const apiKeyBytes = 32
+
+// apiKeyDisplayLength is number of leading characters of the key stored in
+// plain text so users can tell their keys apart
+const apiKeyDisplayLength = 10
+
+// CreateAPIKey issues long lived key for the user. Key is returned only
+// once, afterwards only its hash is known.
+func (d *domain) CreateAPIKey(setup Setup, token string, key APIKey) (created APIKey, secret string, err error) {
+	user, err := d.loginSession(setup, token)
+	if err != nil {
+		return
+	}
+
+	random := make([]byte, apiKeyBytes)
+	if _, err = rand.Read(random); err != nil {
+		err = fmt.Errorf("domain CreateAPIKey -> unable to generate key %w", err)
+		return
+	}
+
+	secret = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	key.UserID = user.ID
+	key.Prefix = secret[:apiKeyDisplayLength]
+	key.KeyHash = hashToken(secret)
+
+	created, err = d.db.APIKey(setup.ctx).Create(key)
+	if err != nil {
+		secret = ""
+		err = fmt.Errorf("domain CreateAPIKey -> %w", err)
+	}
+
+	return
+}
+
+func (d *domain) APIKeys(setup Setup, token string) (keys []APIKey, err error) {
+	user, err := d.loginSession(setup, token)
+	if err != nil {
+		return
+	}
+
+	keys, err = d.db.APIKey(setup.ctx).GetByUser(user.ID)
+	if err != nil {
+		err = fmt.Errorf("domain APIKeys -> failed to get api keys of user %d %w", user.ID, err)
+	}
+
+	return
+}
+
+func (d *domain) RevokeAPIKey(setup Setup, token string, id uint64) (err error) {
+	user, err := d.loginSession(setup, token)
+	if err != nil {
+		return
+	}
+
+	err = d.db.APIKey(setup.ctx).Delete(user.ID, id)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrAPIKeyNotFound
+	} else if err != nil {
+		err = fmt.Errorf("domain RevokeAPIKey -> %w", err)
+	}
+
+	return
+}
+
+// loginSession resolves only sessions started by logging in so leaked
+// api key can't be used to create new keys or revoke the existing ones
+func (d *domain) loginSession(setup Setup, token string) (user User, err error) {
+	if isAPIKey(token) {
+		err = ErrNoSession
+		return
+	}
+
+	return d.Session(setup, token)
+}
+
+// apiKeyUser resolves owner of the api key. Unknown and expired keys are
+// reported the same way as missing sessions.
+func (d *domain) apiKeyUser(setup Setup, secret string) (user User, err error) {
+	key, err := d.db.APIKey(setup.ctx).GetByHash(hashToken(secret))
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrNoSession
+		return
+	} else if err != nil {
+		err = fmt.Errorf("unable to get api key %w", err)
+		return
+	}
+
+	if !key.ExpiresAt.IsZero() && time.Now().After(key.ExpiresAt) {
+		err = ErrNoSession
+		return
+	}
+
+	user, err = d.db.User(setup.ctx).GetByID(key.UserID)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrNoSession
+		return
+	} else if err != nil {
+		err = fmt.Errorf("unable to get owner of api key %d %w", key.ID, err)
+		return
+	}
+
+	// same as session stores, the password hash and payload never leave
+	// the repository row
+	user.Password = ""
+	user.Payload = nil
+
+	if len(key.Scopes) > 0 {
+		user.Scopes = key.Scopes
+	}
+
+	return
+}
+
+// InScope tells if path is within scopes of the api key user authenticated with. Scopes
+// are path prefixes, users of sessions and of keys without scopes can access any path.
+func (user User) InScope(requestPath string) bool {
+	if len(user.Scopes) == 0 {
+		return true
+	}
+
+	requestPath = path.Clean("/" + requestPath)
+
+	for _, scope := range user.Scopes {
+		prefix := strings.TrimSuffix(scope, "/")
+		if requestPath == prefix || strings.HasPrefix(requestPath, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isAPIKey(token string) bool {
+	return strings.HasPrefix(token, apiKeyPrefix)
+}
@@ -0,0 +1,279 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com"}
+
+	// Create mocks
+	repository := NewMockRepository(t)
+	sessionRepository := NewMockRepositorySession(t)
+	apiKeyRepository := NewMockRepositoryAPIKey(t)
+
+	// Setup mocks
+	var stored APIKey
+	repository.EXPECT().Session(context.TODO()).Return(sessionRepository)
+	repository.EXPECT().APIKey(context.TODO()).Return(apiKeyRepository)
+	sessionRepository.EXPECT().Get("session").Return(user, nil)
+	apiKeyRepository.EXPECT().Create(mock.Anything).RunAndReturn(func(key APIKey) (APIKey, error) {
+		stored = key
+		key.ID = 7
+		return key, nil
+	})
+
+	// Run
+	domain := NewDomain(repository, utils.Config{}, NewMockNotifier(t))
+	created, secret, err := domain.CreateAPIKey(setup, "session", APIKey{Name: "ci", Scopes: []string{"/reports"}})
+
+	// Assertions
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), created.ID)
+	require.True(t, strings.HasPrefix(secret, apiKeyPrefix))
+	require.True(t, strings.HasPrefix(secret, stored.Prefix))
+	require.Equal(t, user.ID, stored.UserID)
+	require.Equal(t, "ci", stored.Name)
+	require.Equal(t, []string{"/reports"}, stored.Scopes)
+	require.Equal(t, hashToken(secret), stored.KeyHash)
+	require.NotContains(t, stored.KeyHash, secret)
+}
+
+func TestManageAPIKeysWithAPIKey(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	domain := NewDomain(NewMockRepository(t), utils.Config{}, NewMockNotifier(t))
+
+	_, _, err := domain.CreateAPIKey(setup, "ak_secret", APIKey{Name: "ci"})
+	require.ErrorIs(t, err, ErrNoSession)
+
+	_, err = domain.APIKeys(setup, "ak_secret")
+	require.ErrorIs(t, err, ErrNoSession)
+
+	err = domain.RevokeAPIKey(setup, "ak_secret", 7)
+	require.ErrorIs(t, err, ErrNoSession)
+}
+
+func TestCredentialManagementWithAPIKey(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	d := NewDomain(NewMockRepository(t), passkeyConfig, NewMockNotifier(t))
+
+	calls := map[string]func() error{
+		"identities": func() error {
+			_, err := d.Identities(setup, "ak_secret")
+			return err
+		},
+		"unlink identity": func() error {
+			return d.UnlinkIdentity(setup, "ak_secret", "google")
+		},
+		"consent": func() error {
+			_, err := d.Consent(setup, "ak_secret", "consent", true)
+			return err
+		},
+		"authorization user": func() error {
+			_, err := d.(*domain).authorizationUser(setup, "ak_secret")
+			return err
+		},
+		"begin passkey registration": func() error {
+			_, _, err := d.BeginPasskeyRegistration(setup, "ak_secret")
+			return err
+		},
+		"finish passkey registration": func() error {
+			_, err := d.FinishPasskeyRegistration(setup, "ak_secret", "ceremony", []byte("{}"))
+			return err
+		},
+		"regenerate recovery codes": func() error {
+			_, err := d.RegenerateRecoveryCodes(setup, "ak_secret")
+			return err
+		},
+		"recovery codes count": func() error {
+			_, err := d.RecoveryCodesCount(setup, "ak_secret")
+			return err
+		},
+		"enroll totp": func() error {
+			_, _, err := d.EnrollTOTP(setup, "ak_secret")
+			return err
+		},
+		"confirm totp": func() error {
+			_, err := d.ConfirmTOTP(setup, "ak_secret", "123456")
+			return err
+		},
+		"exchange session": func() error {
+			_, err := d.ExchangeSession(setup, "ak_secret")
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		require.ErrorIs(t, call(), ErrNoSession, name)
+	}
+}
+
+func TestAPIKeySession(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com"}
+
+	tests := []struct {
+		name        string
+		key         APIKey
+		keyError    error
+		userError   error
+		fetchUser   bool
+		returnError error
+	}{
+		{
+			name:      "success",
+			key:       APIKey{ID: 7, UserID: user.ID},
+			fetchUser: true,
+		},
+		{
+			name:      "not expired",
+			key:       APIKey{ID: 7, UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)},
+			fetchUser: true,
+		},
+		{
+			name:      "scoped key",
+			key:       APIKey{ID: 7, UserID: user.ID, Scopes: []string{"/reports"}},
+			fetchUser: true,
+		},
+		{
+			name:        "expired",
+			key:         APIKey{ID: 7, UserID: user.ID, ExpiresAt: time.Now().Add(-time.Hour)},
+			returnError: ErrNoSession,
+		},
+		{
+			name:        "unknown key",
+			keyError:    modelErrors.ErrNotFound,
+			returnError: ErrNoSession,
+		},
+		{
+			name:        "deleted user",
+			key:         APIKey{ID: 7, UserID: user.ID},
+			userError:   modelErrors.ErrNotFound,
+			fetchUser:   true,
+			returnError: ErrNoSession,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			apiKeyRepository := NewMockRepositoryAPIKey(t)
+			userRepository := NewMockRepositoryUser(t)
+
+			// Setup mocks
+			repository.EXPECT().APIKey(context.TODO()).Return(apiKeyRepository)
+			apiKeyRepository.EXPECT().GetByHash(hashToken("ak_secret")).Return(tc.key, tc.keyError)
+
+			if tc.fetchUser {
+				repository.EXPECT().User(context.TODO()).Return(userRepository)
+				row := user
+				row.Password = "$2a$14$hash"
+				row.Payload = map[string]any{"secret": "value"}
+				userRepository.EXPECT().GetByID(user.ID).Return(row, tc.userError)
+			}
+
+			// Run
+			domain := NewDomain(repository, utils.Config{}, NewMockNotifier(t))
+			found, err := domain.Session(setup, "ak_secret")
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorIs(t, err, tc.returnError)
+			} else {
+				expected := user
+				if len(tc.key.Scopes) > 0 {
+					expected.Scopes = tc.key.Scopes
+				}
+
+				require.NoError(t, err)
+				require.Equal(t, expected, found)
+			}
+		})
+	}
+}
+
+func TestUserInScope(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		scopes  []string
+		path    string
+		inScope bool
+	}{
+		{name: "session", path: "/admin", inScope: true},
+		{name: "scope itself", scopes: []string{"/reports"}, path: "/reports", inScope: true},
+		{name: "below scope", scopes: []string{"/wiki", "/reports/"}, path: "/reports/daily", inScope: true},
+		{name: "root scope", scopes: []string{"/"}, path: "/admin", inScope: true},
+		{name: "outside scope", scopes: []string{"/reports"}, path: "/admin", inScope: false},
+		{name: "scope prefix of other path", scopes: []string{"/reports"}, path: "/reports-admin", inScope: false},
+		{name: "dot segments", scopes: []string{"/reports"}, path: "/reports/../admin", inScope: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			user := User{ID: 452, Scopes: tc.scopes}
+			require.Equal(t, tc.inScope, user.InScope(tc.path))
+		})
+	}
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com"}
+
+	tests := []struct {
+		name        string
+		deleteError error
+		returnError error
+	}{
+		{name: "success"},
+		{name: "not found", deleteError: modelErrors.ErrNotFound, returnError: ErrAPIKeyNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			sessionRepository := NewMockRepositorySession(t)
+			apiKeyRepository := NewMockRepositoryAPIKey(t)
+
+			// Setup mocks
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository)
+			repository.EXPECT().APIKey(context.TODO()).Return(apiKeyRepository)
+			sessionRepository.EXPECT().Get("session").Return(user, nil)
+			apiKeyRepository.EXPECT().Delete(user.ID, uint64(7)).Return(tc.deleteError)
+
+			// Run
+			domain := NewDomain(repository, utils.Config{}, NewMockNotifier(t))
+			err := domain.RevokeAPIKey(setup, "session", 7)
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorIs(t, err, tc.returnError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
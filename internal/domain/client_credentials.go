@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/google/uuid"
+)
+
+// clientCredentialsToken issues access token to the client itself. Client is
+// the subject of the token so it can only get scopes it was registered with.
+func (d *domain) clientCredentialsToken(setup Setup, client OAuthClient, scope string) (tokens Tokens, err error) {
+	if client.SecretHash == "" {
+		err = ErrInvalidClient
+		return
+	}
+
+	scopes, err := clientScopes(client, scope)
+	if err != nil {
+		return
+	}
+
+	signer, err := d.tokenSigner(setup, accessTokenType)
+	if err != nil {
+		err = fmt.Errorf("domain Token -> %w", err)
+		return
+	}
+
+	now := time.Now()
+	claims := accessTokenClaims{
+		Claims: jwt.Claims{
+			Issuer:   d.config.OIDCIssuer,
+			Subject:  client.ID,
+			Audience: jwt.Audience{d.config.OIDCIssuer},
+			ID:       uuid.NewString(),
+			IssuedAt: jwt.NewNumericDate(now),
+			Expiry:   jwt.NewNumericDate(now.Add(utils.OIDC_TOKEN_TTL)),
+		},
+		ClientID: client.ID,
+		Scope:    strings.Join(scopes, " "),
+	}
+
+	tokens.AccessToken, err = jwt.Signed(signer).Claims(claims).Serialize()
+	if err != nil {
+		err = fmt.Errorf("domain Token -> unable to sign access token %w", err)
+		return
+	}
+
+	tokens.Scope = claims.Scope
+	tokens.ExpiresIn = int64(utils.OIDC_TOKEN_TTL.Seconds())
+
+	return
+}
+
+// clientScopes defaults to all scopes client is allowed to request. OpenID
+// scope is never granted since there is no user behind the token.
+func clientScopes(client OAuthClient, scope string) (scopes []string, err error) {
+	scopes = strings.Fields(scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+
+	if slices.Contains(scopes, "openid") || !coversScopes(client.Scopes, scopes) {
+		scopes = nil
+		err = ErrInvalidScope
+	}
+
+	return
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCredentials(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	secretHash := sha256.Sum256([]byte("secret"))
+	service := OAuthClient{
+		ID:         "billing",
+		SecretHash: hex.EncodeToString(secretHash[:]),
+		Scopes:     []string{"reports:read", "reports:write"},
+	}
+	request := TokenRequest{GrantType: "client_credentials", ClientID: "billing", ClientSecret: "secret"}
+
+	tests := []struct {
+		name        string
+		client      OAuthClient
+		request     func(*TokenRequest)
+		scope       string
+		returnError error
+	}{
+		{
+			name:   "all allowed scopes",
+			client: service,
+			scope:  "reports:read reports:write",
+		},
+		{
+			name:    "requested scope",
+			client:  service,
+			request: func(r *TokenRequest) { r.Scope = "reports:read" },
+			scope:   "reports:read",
+		},
+		{
+			name:        "scope not allowed",
+			client:      service,
+			request:     func(r *TokenRequest) { r.Scope = "reports:read users:write" },
+			returnError: ErrInvalidScope,
+		},
+		{
+			name:        "openid scope",
+			client:      OAuthClient{ID: "billing", SecretHash: service.SecretHash, Scopes: []string{"openid"}},
+			returnError: ErrInvalidScope,
+		},
+		{
+			name:        "invalid secret",
+			client:      service,
+			request:     func(r *TokenRequest) { r.ClientSecret = "guess" },
+			returnError: ErrInvalidClient,
+		},
+		{
+			name:        "public client",
+			client:      OAuthClient{ID: "billing", Scopes: service.Scopes},
+			returnError: ErrInvalidClient,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			clientRepository := NewMockRepositoryOAuthClient(t)
+
+			// Setup mocks
+			repository.EXPECT().OAuthClient(context.TODO()).Return(clientRepository)
+			clientRepository.EXPECT().Get("billing").Return(tc.client, nil)
+
+			tokenRequest := request
+			if tc.request != nil {
+				tc.request(&tokenRequest)
+			}
+
+			// Run
+			domain := NewDomain(repository, providerConfig, NewMockNotifier(t))
+			tokens, err := domain.Token(setup, tokenRequest)
+
+			// Assertions
+			require.Equal(t, tc.returnError, err)
+			if tc.returnError != nil {
+				require.Empty(t, tokens)
+				return
+			}
+
+			require.Equal(t, tc.scope, tokens.Scope)
+			require.Empty(t, tokens.IDToken)
+
+			keys, err := domain.JWKS(setup)
+			require.NoError(t, err)
+
+			var set jose.JSONWebKeySet
+			require.NoError(t, json.Unmarshal(keys, &set))
+
+			parsed, err := jwt.ParseSigned(tokens.AccessToken, signingAlgorithms)
+			require.NoError(t, err)
+			require.Equal(t, accessTokenType, parsed.Headers[0].ExtraHeaders[jose.HeaderType])
+
+			var claims accessTokenClaims
+			require.NoError(t, parsed.Claims(set, &claims))
+			require.NoError(t, claims.Validate(jwt.Expected{Issuer: providerConfig.OIDCIssuer}))
+			require.Equal(t, "billing", claims.Subject)
+			require.Equal(t, "billing", claims.ClientID)
+			require.Equal(t, tc.scope, claims.Scope)
+
+			// there is no user behind the token
+			_, err = domain.UserInfo(setup, tokens.AccessToken)
+			require.Equal(t, ErrInvalidAccessToken, err)
+		})
+	}
+}
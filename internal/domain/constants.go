@@ -7,3 +7,40 @@ var ErrInvalidCredentials = errors.New("invalid credentials")
 var ErrUserNotExist = errors.New("user not exists")
 var ErrInvalidToken = errors.New("invalid token")
 var ErrNoSession = errors.New("no session")
+var ErrSecondFactorRequired = errors.New("second factor required")
+var ErrInvalidChallenge = errors.New("invalid challenge")
+var ErrInvalidCode = errors.New("invalid code")
+var ErrAlreadyEnrolled = errors.New("second factor already enrolled")
+var ErrNotEnrolled = errors.New("second factor not enrolled")
+var ErrPasskeysNotConfigured = errors.New("passkeys are not configured")
+var ErrInvalidCeremony = errors.New("invalid ceremony")
+var ErrMagicLinkNotConfigured = errors.New("magic link is not configured")
+var ErrUnknownProvider = errors.New("unknown identity provider")
+var ErrInvalidIdentity = errors.New("invalid identity")
+var ErrIdentityLinkRequired = errors.New("identity has to be linked from existing account")
+var ErrIdentityInUse = errors.New("identity is linked to another user")
+var ErrIdentityNotFound = errors.New("identity not found")
+var ErrIdentityManaged = errors.New("identity is managed by the directory")
+var ErrLastLogInMethod = errors.New("identity is the only log in method")
+var ErrSSORequired = errors.New("single sign-on required")
+var ErrProviderNotConfigured = errors.New("openid provider is not configured")
+var ErrInvalidClient = errors.New("invalid client")
+var ErrInvalidGrant = errors.New("invalid grant")
+var ErrUnsupportedGrantType = errors.New("unsupported grant type")
+var ErrLoginRequired = errors.New("login required")
+var ErrConsentRequired = errors.New("consent required")
+var ErrInvalidAccessToken = errors.New("invalid access token")
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+var ErrRefreshTokenReused = errors.New("refresh token reused")
+var ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+var ErrKeyRotationUnavailable = errors.New("signing key is not managed by the app and can't be rotated")
+var ErrAPIKeyNotFound = errors.New("api key not found")
+var ErrSessionNotFound = errors.New("session not found")
+var ErrInvalidScope = errors.New("invalid scope")
+var ErrDeviceFlowNotConfigured = errors.New("device authorization is not configured")
+var ErrAuthorizationPending = errors.New("authorization pending")
+var ErrSlowDown = errors.New("slow down")
+var ErrAccessDenied = errors.New("access denied")
+var ErrExpiredToken = errors.New("expired token")
+var ErrInvalidUserCode = errors.New("invalid user code")
+var ErrForbidden = errors.New("forbidden")
@@ -0,0 +1,255 @@
+package domain
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+)
+
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+const (
+	deviceStatusPending  = "pending"
+	deviceStatusApproved = "approved"
+	deviceStatusDenied   = "denied"
+)
+
+// userCodeAlphabet has no vowels to avoid forming words and no digits
+// that can be mistaken for letters
+const userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+const userCodeLength = 8
+
+// slowDownSeconds is added to polling interval of clients polling too often
+const slowDownSeconds = 5
+
+// AuthorizeDevice starts device authorization of a client running on a device
+// without browser. User approves it by entering returned user code on the device page.
+func (d *domain) AuthorizeDevice(setup Setup, clientId string, clientSecret string, scope string) (device DeviceAuthorization, err error) {
+	if !d.config.HasDeviceFlow() {
+		err = ErrDeviceFlowNotConfigured
+		return
+	}
+
+	client, err := d.authenticateClient(setup, clientId, clientSecret)
+	if err != nil {
+		return
+	}
+
+	if scope == "" {
+		scope = "openid"
+	}
+
+	userCode, err := generateUserCode()
+	if err != nil {
+		err = fmt.Errorf("domain AuthorizeDevice -> failed to generate user code %w", err)
+		return
+	}
+
+	device, err = d.db.DeviceAuthorization(setup.ctx).Create(DeviceAuthorization{
+		UserCode: userCode,
+		ClientID: client.ID,
+		Scope:    scope,
+		Status:   deviceStatusPending,
+		Interval: int64(utils.DEVICE_POLL_INTERVAL.Seconds()),
+	})
+	if err != nil {
+		err = fmt.Errorf("domain AuthorizeDevice -> %w", err)
+		return
+	}
+
+	device.UserCode = formatUserCode(device.UserCode)
+	device.ExpiresIn = int64(utils.DEVICE_CODE_TTL.Seconds())
+
+	return
+}
+
+// DeviceVerification returns pending device authorization so the user can see
+// which client asks for access before approving it
+func (d *domain) DeviceVerification(setup Setup, token string, userCode string) (device DeviceAuthorization, err error) {
+	if !d.config.HasDeviceFlow() {
+		err = ErrDeviceFlowNotConfigured
+		return
+	}
+
+	_, err = d.loginSession(setup, token)
+	if err != nil {
+		return
+	}
+
+	return d.pendingDevice(setup, userCode)
+}
+
+// VerifyDevice records user's decision, client gets tokens on the next poll
+func (d *domain) VerifyDevice(setup Setup, token string, userCode string, approve bool) (device DeviceAuthorization, err error) {
+	if !d.config.HasDeviceFlow() {
+		err = ErrDeviceFlowNotConfigured
+		return
+	}
+
+	user, err := d.loginSession(setup, token)
+	if err != nil {
+		return
+	}
+
+	device, err = d.pendingDevice(setup, userCode)
+	if err != nil {
+		return
+	}
+
+	status := deviceStatusDenied
+	if approve {
+		status = deviceStatusApproved
+	}
+
+	err = d.db.DeviceAuthorization(setup.ctx).Decide(device.DeviceCode, status, user.ID)
+	if err != nil {
+		device = DeviceAuthorization{}
+		err = fmt.Errorf("domain VerifyDevice -> %w", err)
+		return
+	}
+
+	device.Status = status
+	device.UserID = user.ID
+
+	return
+}
+
+func (d *domain) pendingDevice(setup Setup, userCode string) (device DeviceAuthorization, err error) {
+	device, err = d.db.DeviceAuthorization(setup.ctx).GetByUserCode(normalizeUserCode(userCode))
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrInvalidUserCode
+		return
+	} else if err != nil {
+		err = fmt.Errorf("unable to get device authorization %w", err)
+		return
+	}
+
+	if device.Status != deviceStatusPending {
+		device = DeviceAuthorization{}
+		err = ErrInvalidUserCode
+		return
+	}
+
+	device.UserCode = formatUserCode(device.UserCode)
+
+	return
+}
+
+// deviceToken answers polling client. Approved device code is redeemed only once.
+func (d *domain) deviceToken(setup Setup, client OAuthClient, deviceCode string) (tokens Tokens, err error) {
+	repo := d.db.DeviceAuthorization(setup.ctx)
+
+	device, err := repo.Get(deviceCode)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrExpiredToken
+		return
+	} else if err != nil {
+		err = fmt.Errorf("domain Token -> unable to get device authorization %w", err)
+		return
+	}
+
+	if device.ClientID != client.ID {
+		err = ErrInvalidGrant
+		return
+	}
+
+	now := time.Now()
+	previous, err := repo.Poll(deviceCode, now)
+	if err != nil {
+		err = fmt.Errorf("domain Token -> %w", err)
+		return
+	}
+
+	if !previous.IsZero() && now.Sub(previous) < time.Duration(device.Interval)*time.Second {
+		_, err = repo.SlowDown(deviceCode, slowDownSeconds)
+		if err != nil {
+			err = fmt.Errorf("domain Token -> %w", err)
+			return
+		}
+
+		err = ErrSlowDown
+		return
+	}
+
+	if device.Status == deviceStatusPending {
+		err = ErrAuthorizationPending
+		return
+	}
+
+	err = repo.Delete(deviceCode)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrInvalidGrant
+		return
+	} else if err != nil {
+		err = fmt.Errorf("domain Token -> %w", err)
+		return
+	}
+
+	if device.Status != deviceStatusApproved {
+		err = ErrAccessDenied
+		return
+	}
+
+	user, err := d.db.User(setup.ctx).GetByID(device.UserID)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrInvalidGrant
+		return
+	} else if err != nil {
+		err = fmt.Errorf("domain Token -> unable to fetch user %d %w", device.UserID, err)
+		return
+	}
+
+	state := authorization{
+		Request:  AuthorizationRequest{ClientID: client.ID, Scope: device.Scope},
+		UserID:   user.ID,
+		AuthTime: device.DecidedAt.Unix(),
+	}
+
+	tokens, err = d.issueTokens(setup, client, user, state)
+	if err != nil {
+		err = fmt.Errorf("domain Token -> %w", err)
+	}
+
+	return
+}
+
+func generateUserCode() (string, error) {
+	var code strings.Builder
+	max := big.NewInt(int64(len(userCodeAlphabet)))
+
+	for i := 0; i < userCodeLength; i++ {
+		index, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+
+		code.WriteByte(userCodeAlphabet[index.Int64()])
+	}
+
+	return code.String(), nil
+}
+
+// normalizeUserCode accepts code the way users type it, in any case and with or without separator
+func normalizeUserCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+
+		return r
+	}, strings.ToUpper(code))
+}
+
+func formatUserCode(code string) string {
+	if len(code) != userCodeLength {
+		return code
+	}
+
+	return code[:userCodeLength/2] + "-" + code[userCodeLength/2:]
+}
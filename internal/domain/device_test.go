@@ -0,0 +1,272 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var deviceConfig = utils.Config{OIDCIssuer: "https://auth.example.com", OIDCDeviceURL: "https://example.com/device"}
+
+func TestUserCode(t *testing.T) {
+	t.Parallel()
+
+	code, err := generateUserCode()
+	require.NoError(t, err)
+	require.Len(t, code, userCodeLength)
+
+	for _, c := range code {
+		require.True(t, strings.ContainsRune(userCodeAlphabet, c))
+	}
+
+	require.Equal(t, "BCDF-GHJK", formatUserCode("BCDFGHJK"))
+	require.Equal(t, "BCDFGHJK", normalizeUserCode("bcdf-ghjk"))
+	require.Equal(t, "BCDFGHJK", normalizeUserCode(" BCDF GHJK"))
+}
+
+func TestAuthorizeDevice(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+
+	// Create mocks
+	repository := NewMockRepository(t)
+	clientRepository := NewMockRepositoryOAuthClient(t)
+	deviceRepository := NewMockRepositoryDeviceAuthorization(t)
+
+	// Setup mocks
+	repository.EXPECT().OAuthClient(context.TODO()).Return(clientRepository)
+	repository.EXPECT().DeviceAuthorization(context.TODO()).Return(deviceRepository)
+	clientRepository.EXPECT().Get("cli").Return(OAuthClient{ID: "cli"}, nil)
+	deviceRepository.EXPECT().Create(mock.Anything).RunAndReturn(func(device DeviceAuthorization) (DeviceAuthorization, error) {
+		require.Len(t, device.UserCode, userCodeLength)
+		require.Equal(t, "cli", device.ClientID)
+		require.Equal(t, "openid", device.Scope)
+		require.Equal(t, deviceStatusPending, device.Status)
+
+		device.DeviceCode = "device"
+		return device, nil
+	})
+
+	// Run
+	domain := NewDomain(repository, deviceConfig, NewMockNotifier(t))
+	device, err := domain.AuthorizeDevice(setup, "cli", "", "")
+
+	// Assertions
+	require.NoError(t, err)
+	require.Equal(t, "device", device.DeviceCode)
+	require.Len(t, device.UserCode, userCodeLength+1)
+	require.Equal(t, int64(utils.DEVICE_POLL_INTERVAL.Seconds()), device.Interval)
+	require.Equal(t, int64(utils.DEVICE_CODE_TTL.Seconds()), device.ExpiresIn)
+
+	// device flow has to be enabled
+	domain = NewDomain(repository, providerConfig, NewMockNotifier(t))
+	_, err = domain.AuthorizeDevice(setup, "cli", "", "")
+	require.Equal(t, ErrDeviceFlowNotConfigured, err)
+}
+
+func TestVerifyDevice(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com"}
+	pending := DeviceAuthorization{DeviceCode: "device", UserCode: "BCDFGHJK", ClientID: "cli", Scope: "openid", Status: deviceStatusPending}
+
+	tests := []struct {
+		name        string
+		approve     bool
+		device      DeviceAuthorization
+		deviceError error
+		status      string
+		returnError error
+	}{
+		{name: "approve", approve: true, device: pending, status: deviceStatusApproved},
+		{name: "deny", device: pending, status: deviceStatusDenied},
+		{
+			name:        "already approved",
+			approve:     true,
+			device:      DeviceAuthorization{DeviceCode: "device", Status: deviceStatusApproved},
+			returnError: ErrInvalidUserCode,
+		},
+		{
+			name:        "unknown code",
+			approve:     true,
+			deviceError: modelErrors.ErrNotFound,
+			returnError: ErrInvalidUserCode,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			sessionRepository := NewMockRepositorySession(t)
+			deviceRepository := NewMockRepositoryDeviceAuthorization(t)
+
+			// Setup mocks
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository)
+			repository.EXPECT().DeviceAuthorization(context.TODO()).Return(deviceRepository)
+			sessionRepository.EXPECT().Get("session").Return(user, nil)
+			deviceRepository.EXPECT().GetByUserCode("BCDFGHJK").Return(tc.device, tc.deviceError)
+
+			if tc.status != "" {
+				deviceRepository.EXPECT().Decide("device", tc.status, user.ID).Return(nil)
+			}
+
+			// Run
+			domain := NewDomain(repository, deviceConfig, NewMockNotifier(t))
+			device, err := domain.VerifyDevice(setup, "session", "bcdf-ghjk", tc.approve)
+
+			// Assertions
+			require.Equal(t, tc.returnError, err)
+			if tc.returnError == nil {
+				require.Equal(t, tc.status, device.Status)
+				require.Equal(t, "BCDF-GHJK", device.UserCode)
+				require.Equal(t, "cli", device.ClientID)
+			}
+		})
+	}
+}
+
+func TestDeviceToken(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com", Verified: true}
+	request := TokenRequest{GrantType: deviceCodeGrantType, ClientID: "cli", DeviceCode: "device"}
+	approved := DeviceAuthorization{
+		DeviceCode: "device",
+		ClientID:   "cli",
+		Scope:      "openid email",
+		Status:     deviceStatusApproved,
+		UserID:     user.ID,
+		Interval:   5,
+		DecidedAt:  time.Now(),
+	}
+
+	type testCase struct {
+		name        string
+		setupModels func(*MockRepositoryDeviceAuthorization, *MockRepositoryUser)
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name: "approved",
+			setupModels: func(rd *MockRepositoryDeviceAuthorization, ru *MockRepositoryUser) {
+				rd.EXPECT().Get("device").Return(approved, nil)
+				rd.EXPECT().Poll("device", mock.Anything).Return(time.Now().Add(-time.Minute), nil)
+				rd.EXPECT().Delete("device").Return(nil)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+			},
+		},
+		{
+			name: "pending",
+			setupModels: func(rd *MockRepositoryDeviceAuthorization, ru *MockRepositoryUser) {
+				pending := approved
+				pending.Status = deviceStatusPending
+				rd.EXPECT().Get("device").Return(pending, nil)
+				rd.EXPECT().Poll("device", mock.Anything).Return(time.Time{}, nil)
+			},
+			returnError: ErrAuthorizationPending,
+		},
+		{
+			name: "polling too fast",
+			setupModels: func(rd *MockRepositoryDeviceAuthorization, ru *MockRepositoryUser) {
+				rd.EXPECT().Get("device").Return(approved, nil)
+				rd.EXPECT().Poll("device", mock.Anything).Return(time.Now().Add(-time.Second), nil)
+				rd.EXPECT().SlowDown("device", int64(slowDownSeconds)).Return(10, nil)
+			},
+			returnError: ErrSlowDown,
+		},
+		{
+			name: "denied",
+			setupModels: func(rd *MockRepositoryDeviceAuthorization, ru *MockRepositoryUser) {
+				denied := approved
+				denied.Status = deviceStatusDenied
+				rd.EXPECT().Get("device").Return(denied, nil)
+				rd.EXPECT().Poll("device", mock.Anything).Return(time.Time{}, nil)
+				rd.EXPECT().Delete("device").Return(nil)
+			},
+			returnError: ErrAccessDenied,
+		},
+		{
+			name: "already redeemed",
+			setupModels: func(rd *MockRepositoryDeviceAuthorization, ru *MockRepositoryUser) {
+				rd.EXPECT().Get("device").Return(approved, nil)
+				rd.EXPECT().Poll("device", mock.Anything).Return(time.Time{}, nil)
+				rd.EXPECT().Delete("device").Return(modelErrors.ErrNotFound)
+			},
+			returnError: ErrInvalidGrant,
+		},
+		{
+			name: "expired",
+			setupModels: func(rd *MockRepositoryDeviceAuthorization, ru *MockRepositoryUser) {
+				rd.EXPECT().Get("device").Return(DeviceAuthorization{}, modelErrors.ErrNotFound)
+			},
+			returnError: ErrExpiredToken,
+		},
+		{
+			name: "other client",
+			setupModels: func(rd *MockRepositoryDeviceAuthorization, ru *MockRepositoryUser) {
+				other := approved
+				other.ClientID = "app"
+				rd.EXPECT().Get("device").Return(other, nil)
+			},
+			returnError: ErrInvalidGrant,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			clientRepository := NewMockRepositoryOAuthClient(t)
+			deviceRepository := NewMockRepositoryDeviceAuthorization(t)
+			userRepository := NewMockRepositoryUser(t)
+
+			// Setup mocks
+			repository.EXPECT().OAuthClient(context.TODO()).Return(clientRepository)
+			repository.EXPECT().DeviceAuthorization(context.TODO()).Return(deviceRepository)
+			repository.EXPECT().User(context.TODO()).Return(userRepository).Maybe()
+			clientRepository.EXPECT().Get("cli").Return(OAuthClient{ID: "cli"}, nil)
+			tc.setupModels(deviceRepository, userRepository)
+
+			// Run
+			domain := NewDomain(repository, deviceConfig, NewMockNotifier(t))
+			tokens, err := domain.Token(setup, request)
+
+			// Assertions
+			require.Equal(t, tc.returnError, err)
+			if tc.returnError != nil {
+				require.Empty(t, tokens)
+				return
+			}
+
+			require.Equal(t, "openid email", tokens.Scope)
+			require.NotEmpty(t, tokens.AccessToken)
+			require.NotEmpty(t, tokens.IDToken)
+
+			userInfo, err := domain.UserInfo(setup, tokens.AccessToken)
+			require.NoError(t, err)
+			require.Equal(t, userClaims(user, []string{"openid", "email"}), userInfo)
+		})
+	}
+}
+
+func TestDeviceGrantNotConfigured(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	request := TokenRequest{GrantType: deviceCodeGrantType, ClientID: "cli", DeviceCode: "device"}
+
+	domain := NewDomain(NewMockRepository(t), providerConfig, NewMockNotifier(t))
+	_, err := domain.Token(setup, request)
+	require.Equal(t, ErrUnsupportedGrantType, err)
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// log in methods reported by DiscoverLogIn
+const (
+	methodPassword = "password"
+	methodPasskey  = "passkey"
+	methodSSO      = "sso"
+)
+
+const (
+	protocolOIDC = "oidc"
+	protocolSAML = "saml"
+)
+
+// DiscoverLogIn returns methods user identified by email or username can log in with.
+// Users with email domain of enterprise connection are sent to their identity provider and
+// are offered nothing else since every other method would be refused. Users identified by
+// username are looked up for their email, unknown ones get the same answer as any account
+// outside of enterprise domains so accounts can't be enumerated.
+func (d *domain) DiscoverLogIn(setup Setup, user User) (methods LogInMethods, err error) {
+	email := user.Email
+	if email == "" && user.Username != "" {
+		existing, e := d.findUser(setup, user)
+		if e == nil {
+			email = existing.Email
+		} else if e != ErrUserNotExist {
+			err = fmt.Errorf("domain DiscoverLogIn -> %w", e)
+			return
+		}
+	}
+
+	if connection, ok := d.connectionOf(email); ok {
+		methods = LogInMethods{Methods: []string{methodSSO}, Connection: connection}
+		return
+	}
+
+	methods.Methods = []string{methodPassword}
+
+	// passkeys are discoverable so they're offered whether user registered one or not
+	if d.config.HasWebAuthnSetup() {
+		methods.Methods = append(methods.Methods, methodPasskey)
+	}
+
+	return
+}
+
+// emailDomain returns lower cased domain of the email or empty string if it has none
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at == -1 {
+		return ""
+	}
+
+	return strings.ToLower(email[at+1:])
+}
+
+// connectionOf finds external provider configured for domain of the email
+func (d *domain) connectionOf(email string) (connection Connection, ok bool) {
+	domain := emailDomain(email)
+	if domain == "" {
+		return
+	}
+
+	for _, provider := range d.config.OIDCProviders {
+		for _, name := range provider.Domains {
+			if name == domain {
+				return Connection{Provider: provider.Name, Protocol: protocolOIDC}, true
+			}
+		}
+	}
+
+	for _, provider := range d.config.SAMLProviders {
+		for _, name := range provider.Domains {
+			if name == domain {
+				return Connection{Provider: provider.Name, Protocol: protocolSAML}, true
+			}
+		}
+	}
+
+	return
+}
+
+// requireConnection refuses users whose email domain belongs to enterprise connection
+// unless they logged in through that connection's provider. Checked only after the first
+// factor succeeded so the error doesn't tell which accounts exist.
+func (d *domain) requireConnection(user User, provider string) error {
+	if connection, ok := d.connectionOf(user.Email); ok && connection.Provider != provider {
+		return ErrSSORequired
+	}
+
+	return nil
+}
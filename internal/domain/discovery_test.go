@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverLogIn(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+
+	config := utils.Config{
+		WebAuthn:      utils.WebAuthn{RPID: "example.com", RPOrigins: []string{"https://example.com"}},
+		OIDCProviders: []utils.OIDCProvider{{Name: "google"}, {Name: "azure", Domains: []string{"contoso.com"}}},
+		SAMLProviders: []utils.SAMLProvider{{Name: "okta", Domains: []string{"bigcustomer.com"}}},
+	}
+
+	type testCase struct {
+		name          string
+		config        utils.Config
+		user          User
+		found         User
+		findError     error
+		returnMethods LogInMethods
+		returnError   error
+	}
+
+	tests := []testCase{
+		{
+			name:          "password and passkey",
+			config:        config,
+			user:          User{Email: "djvukovic@gmail.com"},
+			returnMethods: LogInMethods{Methods: []string{"password", "passkey"}},
+		},
+		{
+			name:          "username",
+			config:        config,
+			user:          User{Username: "djvukovic"},
+			found:         User{ID: 1, Username: "djvukovic", Email: "djvukovic@gmail.com"},
+			returnMethods: LogInMethods{Methods: []string{"password", "passkey"}},
+		},
+		{
+			name:          "username of enterprise connection user",
+			config:        config,
+			user:          User{Username: "employee"},
+			found:         User{ID: 2, Username: "employee", Email: "employee@bigcustomer.com"},
+			returnMethods: LogInMethods{Methods: []string{"sso"}, Connection: Connection{Provider: "okta", Protocol: "saml"}},
+		},
+		{
+			name:          "unknown username",
+			config:        config,
+			user:          User{Username: "nobody"},
+			findError:     modelErrors.ErrNotFound,
+			returnMethods: LogInMethods{Methods: []string{"password", "passkey"}},
+		},
+		{
+			name:        "username lookup fails",
+			config:      config,
+			user:        User{Username: "djvukovic"},
+			findError:   errors.New("connection refused"),
+			returnError: errors.New("domain DiscoverLogIn -> failed to fetch user connection refused"),
+		},
+		{
+			name:          "passkeys not configured",
+			user:          User{Email: "djvukovic@gmail.com"},
+			returnMethods: LogInMethods{Methods: []string{"password"}},
+		},
+		{
+			name:          "saml connection by email domain",
+			config:        config,
+			user:          User{Email: "new@bigcustomer.com"},
+			returnMethods: LogInMethods{Methods: []string{"sso"}, Connection: Connection{Provider: "okta", Protocol: "saml"}},
+		},
+		{
+			name:          "oidc connection by email domain",
+			config:        config,
+			user:          User{Email: "someone@Contoso.com"},
+			returnMethods: LogInMethods{Methods: []string{"sso"}, Connection: Connection{Provider: "azure", Protocol: "oidc"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			userRepository := NewMockRepositoryUser(t)
+
+			// Setup mocks
+			if tc.user.Email == "" {
+				repository.EXPECT().User(setup.ctx).Return(userRepository)
+				userRepository.EXPECT().GetByUsername(tc.user.Username).Return(tc.found, tc.findError)
+			}
+
+			// Run
+			domain := NewDomain(repository, tc.config, NewMockNotifier(t))
+			methods, err := domain.DiscoverLogIn(setup, tc.user)
+
+			// Assertions
+			if tc.returnError != nil {
+				require.EqualError(t, err, tc.returnError.Error())
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.returnMethods, methods)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -27,25 +28,92 @@ func NewSetup(ctx context.Context, logger *slog.Logger) Setup {
 type Domain interface {
 	SignUp(setup Setup, user User) (newUser User, err error)
 	LogIn(setup Setup, user User) (existing User, sessionKey string, err error)
+	DiscoverLogIn(setup Setup, user User) (methods LogInMethods, err error)
 	Delete(setup Setup, user User) (deleted bool, err error)
 	VerifyAccount(setup Setup, token string) (verified bool, err error)
 	ResetPasswordRequest(setup Setup, user User) (sentTo User, err error)
-	VerifyPasswordReset(setup Setup, token string, password string) (updated User, err error)
+	VerifyPasswordReset(setup Setup, token string, password string, keepSession string) (updated User, err error)
 	Session(setup Setup, token string) (user User, err error)
 	Logout(setup Setup, token string) (err error)
+	MagicLinkRequest(setup Setup, user User) (sentTo User, err error)
+	LogInMagicLink(setup Setup, token string) (existing User, sessionKey string, err error)
+	OneTimeCodeRequest(setup Setup, user User) (sentTo User, err error)
+	LogInOneTimeCode(setup Setup, user User, code string) (existing User, sessionKey string, err error)
+	VerifyAccountCode(setup Setup, user User, code string) (verified bool, err error)
+	EnrollTOTP(setup Setup, token string) (secret string, uri string, err error)
+	ConfirmTOTP(setup Setup, token string, code string) (recoveryCodes []string, err error)
+	LogInTOTP(setup Setup, challenge string, code string) (existing User, sessionKey string, err error)
+	RegenerateRecoveryCodes(setup Setup, token string) (recoveryCodes []string, err error)
+	RecoveryCodesCount(setup Setup, token string) (remaining int, err error)
+	LogInRecoveryCode(setup Setup, challenge string, code string) (existing User, sessionKey string, err error)
+	BeginPasskeyRegistration(setup Setup, token string) (options json.RawMessage, ceremony string, err error)
+	FinishPasskeyRegistration(setup Setup, token string, ceremony string, response []byte) (recoveryCodes []string, err error)
+	BeginPasskeyLogIn(setup Setup, challenge string) (options json.RawMessage, ceremony string, err error)
+	FinishPasskeyLogIn(setup Setup, ceremony string, response []byte) (existing User, sessionKey string, err error)
+	BeginOIDCLogIn(setup Setup, provider string, token string) (authURL string, state string, err error)
+	FinishOIDCLogIn(setup Setup, provider string, state string, code string) (existing User, sessionKey string, err error)
+	Sessions(setup Setup, token string) (sessions []Session, err error)
+	RevokeSession(setup Setup, token string, id string) (err error)
+	RevokeOtherSessions(setup Setup, token string) (revoked int, err error)
+	Identities(setup Setup, token string) (identities []Identity, err error)
+	UnlinkIdentity(setup Setup, token string, provider string) (err error)
+	SAMLMetadata(setup Setup, provider string) (metadata []byte, err error)
+	BeginSAMLLogIn(setup Setup, provider string, token string) (redirect string, relayState string, err error)
+	FinishSAMLLogIn(setup Setup, provider string, relayState string, response string) (existing User, sessionKey string, err error)
+	Authorize(setup Setup, token string, request AuthorizationRequest) (redirect string, consent string, err error)
+	Consent(setup Setup, token string, consent string, approve bool) (redirect string, err error)
+	Token(setup Setup, request TokenRequest) (tokens Tokens, err error)
+	UserInfo(setup Setup, accessToken string) (claims map[string]any, err error)
+	Introspect(setup Setup, clientId string, clientSecret string, token string) (introspection Introspection, err error)
+	ForwardAuth(setup Setup, token string, requestPath string) (user User, err error)
+	JWKS(setup Setup) (keys json.RawMessage, err error)
+	RotateSigningKey(setup Setup) (kid string, err error)
+	CreateOAuthClient(setup Setup, client OAuthClient, public bool) (created OAuthClient, secret string, err error)
+	ExchangeSession(setup Setup, sessionKey string) (tokens SessionTokens, err error)
+	RefreshTokens(setup Setup, refreshToken string) (user User, tokens SessionTokens, err error)
+	RevokeRefreshToken(setup Setup, refreshToken string) (err error)
+	CreateAPIKey(setup Setup, token string, key APIKey) (created APIKey, secret string, err error)
+	APIKeys(setup Setup, token string) (keys []APIKey, err error)
+	RevokeAPIKey(setup Setup, token string, id uint64) (err error)
+	AuthorizeDevice(setup Setup, clientId string, clientSecret string, scope string) (device DeviceAuthorization, err error)
+	DeviceVerification(setup Setup, token string, userCode string) (device DeviceAuthorization, err error)
+	VerifyDevice(setup Setup, token string, userCode string, approve bool) (device DeviceAuthorization, err error)
 }
 
 func NewDomain(repository Repository, config utils.Config, notifier Notifier) Domain {
-	return &domain{db: repository, config: config, notifier: notifier}
+	return &domain{db: repository, config: config, notifier: notifier, signer: &signer{}, oidc: &oidcProviders{}, saml: newSAMLProviders()}
 }
 
 type domain struct {
 	db       Repository
 	config   utils.Config
 	notifier Notifier
+	signer   *signer
+	oidc     *oidcProviders
+	saml     *samlProviders
 }
 
 func (d *domain) LogIn(setup Setup, user User) (existingUser User, sessionKey string, err error) {
+	var directoryErr error
+
+	if d.config.HasLDAPSetup() {
+		existingUser, err = d.logInDirectory(setup, user)
+		if err == nil {
+			return d.completeLogIn(setup, existingUser, "")
+		} else if err == ErrIdentityLinkRequired {
+			existingUser = User{}
+			return
+		} else if err != ErrInvalidCredentials {
+			// directory being down doesn't lock out local accounts, directory accounts
+			// still fail with this error once they are found below
+			setup.logger.Warn("directory login failed, trying local account", "error", err.Error())
+			directoryErr = err
+		}
+
+		// accounts that are not in the directory log in with local password
+		existingUser = User{}
+	}
+
 	userModel := d.db.User(setup.ctx)
 
 	if user.Username != "" {
@@ -59,6 +127,23 @@ func (d *domain) LogIn(setup Setup, user User) (existingUser User, sessionKey st
 		return
 	}
 
+	if d.config.HasLDAPSetup() {
+		linked, e := d.isDirectoryUser(setup, existingUser)
+		if e != nil {
+			existingUser = User{}
+			err = e
+			return
+		} else if linked && directoryErr != nil {
+			existingUser = User{}
+			err = fmt.Errorf("domain LogIn -> %w", directoryErr)
+			return
+		} else if linked {
+			existingUser = User{}
+			err = ErrInvalidCredentials
+			return
+		}
+	}
+
 	err = bcrypt.CompareHashAndPassword([]byte(existingUser.Password), []byte(user.Password))
 	if err != nil {
 		existingUser = User{}
@@ -66,17 +151,75 @@ func (d *domain) LogIn(setup Setup, user User) (existingUser User, sessionKey st
 		return
 	}
 
-	// Create session
-	session, err := d.db.Session(setup.ctx).Create(existingUser)
+	return d.completeLogIn(setup, existingUser, "")
+}
+
+// completeLogIn starts session for user that passed the first factor or issues
+// second factor challenge if user has one enrolled. Provider is the identity provider
+// user logged in with or empty string for local first factors.
+func (d *domain) completeLogIn(setup Setup, user User, provider string) (existingUser User, sessionKey string, err error) {
+	err = d.requireConnection(user, provider)
+	if err != nil {
+		return
+	}
+
+	enrolled, err := d.hasSecondFactor(setup, user)
+	if err != nil {
+		return
+	}
+
+	if enrolled {
+		challenge, e := d.db.Challenge(setup.ctx).Create(user)
+		if e != nil {
+			err = fmt.Errorf("unable to create login challenge for user id %d %w", user.ID, e)
+			return
+		}
+
+		sessionKey = challenge.ID
+		err = ErrSecondFactorRequired
+		return
+	}
+
+	sessionKey, err = d.startSession(setup, user)
+	if err != nil {
+		return
+	}
+
+	existingUser = user
+	return
+}
+
+func (d *domain) startSession(setup Setup, user User) (sessionKey string, err error) {
+	client := utils.GetClient(setup.ctx)
+
+	session, err := d.db.Session(setup.ctx).Create(user, client, d.config.SessionIdleTimeout, d.config.SessionLifetime)
 	if err != nil {
-		err = fmt.Errorf("unable to create session for user id %d %w", existingUser.ID, err)
+		err = fmt.Errorf("unable to create session for user id %d %w", user.ID, err)
 		return
 	}
 
 	sessionKey = session.ID
 
 	return
+}
 
+// findUser looks user up by email falling back to username
+func (d *domain) findUser(setup Setup, user User) (existing User, err error) {
+	userModel := d.db.User(setup.ctx)
+
+	if user.Email != "" {
+		existing, err = userModel.GetByEmail(user.Email)
+	} else {
+		existing, err = userModel.GetByUsername(user.Username)
+	}
+
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrUserNotExist
+	} else if err != nil {
+		err = fmt.Errorf("failed to fetch user %w", err)
+	}
+
+	return
 }
 
 func (d *domain) Delete(setup Setup, user User) (deleted bool, err error) {
@@ -102,7 +245,22 @@ func (d *domain) Delete(setup Setup, user User) (deleted bool, err error) {
 		return
 	}
 
+	// sessions are revoked first since deleting the user cascades to the
+	// records stores need in order to find and revoke them
+	_, err = d.revokeSessions(setup, existingUser.ID, "")
+	if err != nil {
+		err = fmt.Errorf("domain Delete -> %w", err)
+		return
+	}
+
+	err = revokeCredentials(setup, d.db, existingUser.ID)
+	if err != nil {
+		err = fmt.Errorf("domain Delete -> %w", err)
+		return
+	}
+
 	deleted, err = userModel.Delete(existingUser.ID)
+
 	return
 }
 
@@ -187,9 +345,15 @@ func (d *domain) SignUp(setup Setup, user User) (newUser User, err error) {
 			return e
 		}
 
+		// code is an alternative to the link for clients that can't open it
+		code, e := createOneTimeCode(txRepo.OneTimeCode(setup.ctx), oneTimeCodeVerify, newUserCopy.ID)
+		if e != nil {
+			return e
+		}
+
 		verificationLink := fmt.Sprintf("%s?t=%s", d.config.VerificationLink, verificationReq.Token)
-		text := fmt.Sprintf(verifyTextTemplate, user.Email, verificationLink)
-		html := fmt.Sprintf(verifyHtmlTemplate, user.Email, verificationLink)
+		text := fmt.Sprintf(verifyTextTemplate, user.Email, verificationLink, code)
+		html := fmt.Sprintf(verifyHtmlTemplate, user.Email, verificationLink, code)
 
 		e = d.notifier.Send(user.Email, "Verify new account", text, html)
 		if e != nil {
@@ -203,7 +367,12 @@ func (d *domain) SignUp(setup Setup, user User) (newUser User, err error) {
 	return
 }
 
+// Session resolves user from session key or api key
 func (d *domain) Session(setup Setup, token string) (user User, err error) {
+	if isAPIKey(token) {
+		return d.apiKeyUser(setup, token)
+	}
+
 	user, err = d.db.Session(setup.ctx).Get(token)
 
 	if err == modelErrors.ErrNotFound {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	modelErrors "github.com/djordjev/auth/internal/models/errors"
 	"golang.org/x/crypto/bcrypt"
@@ -24,8 +25,9 @@ func TestSignUp(t *testing.T) {
 		config          utils.Config
 		setupUserRepo   func(*MockRepositoryUser, *testCase)
 		setupVerifyRepo func(*MockRepositoryVerifyAccount, *testCase)
+		setupCodeRepo   func(*MockRepositoryOneTimeCode, *testCase)
 		setupNotify     func(*MockNotifier, *testCase)
-		setupRepo       func(*MockRepository, *MockRepositoryUser, *MockRepositoryVerifyAccount, *testCase)
+		setupRepo       func(*MockRepository, *MockRepositoryUser, *MockRepositoryVerifyAccount, *MockRepositoryOneTimeCode, *testCase)
 		returnUser      User
 		returnError     error
 	}
@@ -52,7 +54,7 @@ func TestSignUp(t *testing.T) {
 			},
 			setupVerifyRepo: func(rva *MockRepositoryVerifyAccount, tc *testCase) {},
 			setupNotify:     func(mn *MockNotifier, tc *testCase) {},
-			setupRepo: func(r *MockRepository, u *MockRepositoryUser, v *MockRepositoryVerifyAccount, tc *testCase) {
+			setupRepo: func(r *MockRepository, u *MockRepositoryUser, v *MockRepositoryVerifyAccount, c *MockRepositoryOneTimeCode, tc *testCase) {
 				r.EXPECT().User(setup.ctx).Return(u)
 
 				r.EXPECT().Atomic(mock.Anything).RunAndReturn(func(f func(Repository) error) error {
@@ -70,7 +72,7 @@ func TestSignUp(t *testing.T) {
 			},
 			setupVerifyRepo: func(rva *MockRepositoryVerifyAccount, tc *testCase) {},
 			setupNotify:     func(mn *MockNotifier, tc *testCase) {},
-			setupRepo: func(r *MockRepository, u *MockRepositoryUser, v *MockRepositoryVerifyAccount, tc *testCase) {
+			setupRepo: func(r *MockRepository, u *MockRepositoryUser, v *MockRepositoryVerifyAccount, c *MockRepositoryOneTimeCode, tc *testCase) {
 				r.EXPECT().User(setup.ctx).Return(u)
 			},
 			returnUser:  User{},
@@ -88,16 +90,20 @@ func TestSignUp(t *testing.T) {
 			setupVerifyRepo: func(rva *MockRepositoryVerifyAccount, tc *testCase) {
 				rva.EXPECT().Create(mock.Anything, returnUser.ID).Return(VerifyAccount{Token: "uuid-token"}, nil)
 			},
+			setupCodeRepo: func(rc *MockRepositoryOneTimeCode, tc *testCase) {
+				rc.EXPECT().Create(oneTimeCodeVerify, returnUser.ID, mock.Anything).Return(OneTimeCode{}, nil)
+			},
 			setupNotify: func(mn *MockNotifier, tc *testCase) {
 				matcher := mock.MatchedBy(func(link string) bool {
-					return strings.Contains(link, "uuid-token")
+					return strings.Contains(link, "uuid-token") && strings.Contains(link, "enter code")
 				})
 
 				mn.EXPECT().Send(signUpUser.Email, "Verify new account", matcher, matcher).Return(nil)
 			},
-			setupRepo: func(r *MockRepository, u *MockRepositoryUser, v *MockRepositoryVerifyAccount, tc *testCase) {
+			setupRepo: func(r *MockRepository, u *MockRepositoryUser, v *MockRepositoryVerifyAccount, c *MockRepositoryOneTimeCode, tc *testCase) {
 				r.EXPECT().User(setup.ctx).Return(u)
 				r.EXPECT().VerifyAccount(setup.ctx).Return(v)
+				r.EXPECT().OneTimeCode(setup.ctx).Return(c)
 
 				r.EXPECT().Atomic(mock.Anything).RunAndReturn(func(f func(Repository) error) error {
 					return f(r)
@@ -118,7 +124,7 @@ func TestSignUp(t *testing.T) {
 			setupVerifyRepo: func(rva *MockRepositoryVerifyAccount, tc *testCase) {},
 			setupNotify: func(mn *MockNotifier, tc *testCase) {
 			},
-			setupRepo: func(r *MockRepository, u *MockRepositoryUser, v *MockRepositoryVerifyAccount, tc *testCase) {
+			setupRepo: func(r *MockRepository, u *MockRepositoryUser, v *MockRepositoryVerifyAccount, c *MockRepositoryOneTimeCode, tc *testCase) {
 				r.EXPECT().User(setup.ctx).Return(u)
 
 				r.EXPECT().Atomic(mock.Anything).RunAndReturn(func(f func(Repository) error) error {
@@ -136,12 +142,16 @@ func TestSignUp(t *testing.T) {
 			repository := NewMockRepository(t)
 			userRepository := NewMockRepositoryUser(t)
 			verifyRepository := NewMockRepositoryVerifyAccount(t)
+			codeRepository := NewMockRepositoryOneTimeCode(t)
 			notifier := NewMockNotifier(t)
 
 			// Setup mocks
-			tc.setupRepo(repository, userRepository, verifyRepository, &tc)
+			tc.setupRepo(repository, userRepository, verifyRepository, codeRepository, &tc)
 			tc.setupUserRepo(userRepository, &tc)
 			tc.setupVerifyRepo(verifyRepository, &tc)
+			if tc.setupCodeRepo != nil {
+				tc.setupCodeRepo(codeRepository, &tc)
+			}
 			tc.setupNotify(notifier, &tc)
 
 			// Run
@@ -167,12 +177,14 @@ func TestLogIn(t *testing.T) {
 	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
 
 	type testCase struct {
-		name             string
-		inputUser        User
-		setupUserRepo    func(*MockRepositoryUser, *testCase)
-		setupSessionRepo func(*MockRepositorySession, *testCase)
-		returnUser       User
-		returnError      error
+		name              string
+		inputUser         User
+		setupUserRepo     func(*MockRepositoryUser, *testCase)
+		setupSessionRepo  func(*MockRepositorySession, *testCase)
+		setupSecondFactor func(*MockRepositoryTOTP, *MockRepositoryPasskey, *MockRepositoryChallenge, *testCase)
+		returnUser        User
+		returnKey         string
+		returnError       error
 	}
 
 	hash, _ := bcrypt.GenerateFromPassword([]byte("testee"), 14)
@@ -194,9 +206,61 @@ func TestLogIn(t *testing.T) {
 				ru.EXPECT().GetByEmail(tc.inputUser.Email).Return(existing, nil)
 			},
 			setupSessionRepo: func(mrs *MockRepositorySession, tc *testCase) {
-				mrs.EXPECT().Create(mock.Anything).Return(Session{ID: "abc"}, nil)
+				mrs.EXPECT().Create(existing, utils.Client{}, time.Hour, 24*time.Hour).Return(Session{ID: "abc"}, nil)
+			},
+			setupSecondFactor: func(rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rc *MockRepositoryChallenge, tc *testCase) {
+				rt.EXPECT().Get(existing.ID).Return(TOTP{}, modelErrors.ErrNotFound)
+				rp.EXPECT().GetByUser(existing.ID).Return([]Passkey{}, nil)
 			},
 			returnUser: existing,
+			returnKey:  "abc",
+		},
+		{
+			name:      "second factor required",
+			inputUser: User{Email: "djvukovic@gmail.com", Password: "testee"},
+			setupUserRepo: func(ru *MockRepositoryUser, tc *testCase) {
+				ru.EXPECT().GetByEmail(tc.inputUser.Email).Return(existing, nil)
+			},
+			setupSessionRepo: func(mrs *MockRepositorySession, tc *testCase) {},
+			setupSecondFactor: func(rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rc *MockRepositoryChallenge, tc *testCase) {
+				rt.EXPECT().Get(existing.ID).Return(TOTP{UserID: existing.ID, Confirmed: true}, nil)
+				rc.EXPECT().Create(existing).Return(Challenge{ID: "challenge-key", UserID: existing.ID}, nil)
+			},
+			returnUser:  User{},
+			returnKey:   "challenge-key",
+			returnError: ErrSecondFactorRequired,
+		},
+		{
+			name:      "passkey as second factor",
+			inputUser: User{Email: "djvukovic@gmail.com", Password: "testee"},
+			setupUserRepo: func(ru *MockRepositoryUser, tc *testCase) {
+				ru.EXPECT().GetByEmail(tc.inputUser.Email).Return(existing, nil)
+			},
+			setupSessionRepo: func(mrs *MockRepositorySession, tc *testCase) {},
+			setupSecondFactor: func(rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rc *MockRepositoryChallenge, tc *testCase) {
+				rt.EXPECT().Get(existing.ID).Return(TOTP{}, modelErrors.ErrNotFound)
+				rp.EXPECT().GetByUser(existing.ID).Return([]Passkey{{UserID: existing.ID}}, nil)
+				rc.EXPECT().Create(existing).Return(Challenge{ID: "challenge-key", UserID: existing.ID}, nil)
+			},
+			returnUser:  User{},
+			returnKey:   "challenge-key",
+			returnError: ErrSecondFactorRequired,
+		},
+		{
+			name:      "unconfirmed second factor is ignored",
+			inputUser: User{Email: "djvukovic@gmail.com", Password: "testee"},
+			setupUserRepo: func(ru *MockRepositoryUser, tc *testCase) {
+				ru.EXPECT().GetByEmail(tc.inputUser.Email).Return(existing, nil)
+			},
+			setupSessionRepo: func(mrs *MockRepositorySession, tc *testCase) {
+				mrs.EXPECT().Create(mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(Session{ID: "abc"}, nil)
+			},
+			setupSecondFactor: func(rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rc *MockRepositoryChallenge, tc *testCase) {
+				rt.EXPECT().Get(existing.ID).Return(TOTP{UserID: existing.ID, Confirmed: false}, nil)
+				rp.EXPECT().GetByUser(existing.ID).Return([]Passkey{}, nil)
+			},
+			returnUser: existing,
+			returnKey:  "abc",
 		},
 		{
 			name:      "username does not exist",
@@ -231,6 +295,25 @@ func TestLogIn(t *testing.T) {
 			returnUser:       User{},
 			returnError:      ErrInvalidCredentials,
 		},
+		{
+			name:      "user of enterprise connection",
+			inputUser: User{Email: "someone@bigcustomer.com", Password: "testee"},
+			setupUserRepo: func(ru *MockRepositoryUser, tc *testCase) {
+				customer := existing
+				customer.Email = tc.inputUser.Email
+
+				ru.EXPECT().GetByEmail(tc.inputUser.Email).Return(customer, nil)
+			},
+			setupSessionRepo: func(mrs *MockRepositorySession, tc *testCase) {},
+			returnUser:       User{},
+			returnError:      ErrSSORequired,
+		},
+	}
+
+	config := utils.Config{
+		SessionIdleTimeout: time.Hour,
+		SessionLifetime:    24 * time.Hour,
+		SAMLProviders:      []utils.SAMLProvider{{Name: "okta", Domains: []string{"bigcustomer.com"}}},
 	}
 
 	for _, tc := range tests {
@@ -239,17 +322,26 @@ func TestLogIn(t *testing.T) {
 			repository := NewMockRepository(t)
 			userRepository := NewMockRepositoryUser(t)
 			sessionRepository := NewMockRepositorySession(t)
+			totpRepository := NewMockRepositoryTOTP(t)
+			challengeRepository := NewMockRepositoryChallenge(t)
+			passkeyRepository := NewMockRepositoryPasskey(t)
 			notifier := NewMockNotifier(t)
 
 			// Setup mocks
 			repository.EXPECT().User(context.TODO()).Return(userRepository).Maybe()
 			repository.EXPECT().Session(context.TODO()).Return(sessionRepository).Maybe()
+			repository.EXPECT().TOTP(context.TODO()).Return(totpRepository).Maybe()
+			repository.EXPECT().Challenge(context.TODO()).Return(challengeRepository).Maybe()
+			repository.EXPECT().Passkey(context.TODO()).Return(passkeyRepository).Maybe()
 			tc.setupUserRepo(userRepository, &tc)
 			tc.setupSessionRepo(sessionRepository, &tc)
+			if tc.setupSecondFactor != nil {
+				tc.setupSecondFactor(totpRepository, passkeyRepository, challengeRepository, &tc)
+			}
 
 			// Run
-			domain := NewDomain(repository, utils.Config{}, notifier)
-			user, _, err := domain.LogIn(setup, tc.inputUser)
+			domain := NewDomain(repository, config, notifier)
+			user, key, err := domain.LogIn(setup, tc.inputUser)
 
 			// Assertions
 			if tc.returnError != nil {
@@ -259,6 +351,7 @@ func TestLogIn(t *testing.T) {
 			}
 
 			require.Equal(t, user, tc.returnUser)
+			require.Equal(t, key, tc.returnKey)
 		})
 	}
 }
@@ -282,6 +375,9 @@ func TestDelete(t *testing.T) {
 		name          string
 		inputUser     User
 		setupUserRepo func(*MockRepositoryUser, *testCase)
+		setupSessions func(*MockRepositorySession, *testCase)
+		revoked       bool
+		credentials   bool
 		returnDeleted bool
 		returnError   error
 	}
@@ -292,8 +388,17 @@ func TestDelete(t *testing.T) {
 			inputUser: User{Email: "djvukovic@gmail.com", Password: "testee"},
 			setupUserRepo: func(ru *MockRepositoryUser, tc *testCase) {
 				ru.EXPECT().GetByEmail(tc.inputUser.Email).Return(existing, nil)
-				ru.EXPECT().Delete(existing.ID).Return(true, nil)
+				ru.EXPECT().Delete(existing.ID).RunAndReturn(func(id uint64) (bool, error) {
+					// deleting the user would remove sessions before they are revoked
+					return tc.revoked, nil
+				})
 			},
+			setupSessions: func(rs *MockRepositorySession, tc *testCase) {
+				rs.EXPECT().GetByUser(existing.ID).Return([]Session{{ID: "laptop"}, {ID: "phone"}}, nil)
+				rs.EXPECT().Delete("laptop").Return(nil)
+				rs.EXPECT().Delete("phone").Run(func(key string) { tc.revoked = true }).Return(nil)
+			},
+			credentials:   true,
 			returnDeleted: true,
 		},
 		{
@@ -319,6 +424,21 @@ func TestDelete(t *testing.T) {
 				ru.EXPECT().GetByUsername(tc.inputUser.Username).Return(existing, nil)
 				ru.EXPECT().Delete(existing.ID).Return(false, errModel)
 			},
+			setupSessions: func(rs *MockRepositorySession, tc *testCase) {
+				rs.EXPECT().GetByUser(existing.ID).Return(nil, nil)
+			},
+			credentials: true,
+			returnError: errModel,
+		},
+		{
+			name:      "revoke failed",
+			inputUser: User{Username: "djvukovic", Password: "testee"},
+			setupUserRepo: func(ru *MockRepositoryUser, tc *testCase) {
+				ru.EXPECT().GetByUsername(tc.inputUser.Username).Return(existing, nil)
+			},
+			setupSessions: func(rs *MockRepositorySession, tc *testCase) {
+				rs.EXPECT().GetByUser(existing.ID).Return(nil, errModel)
+			},
 			returnError: errModel,
 		},
 		{
@@ -339,11 +459,24 @@ func TestDelete(t *testing.T) {
 			// Create mocks
 			repository := NewMockRepository(t)
 			userRepository := NewMockRepositoryUser(t)
+			sessionRepository := NewMockRepositorySession(t)
+			refreshTokenRepository := NewMockRepositoryRefreshToken(t)
+			apiKeyRepository := NewMockRepositoryAPIKey(t)
 			notifier := NewMockNotifier(t)
 
 			// Setup mocks
 			repository.EXPECT().User(context.TODO()).Return(userRepository).Maybe()
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository).Maybe()
+			if tc.credentials {
+				repository.EXPECT().RefreshToken(context.TODO()).Return(refreshTokenRepository)
+				repository.EXPECT().APIKey(context.TODO()).Return(apiKeyRepository)
+				refreshTokenRepository.EXPECT().RevokeUser(existing.ID).Return(nil)
+				apiKeyRepository.EXPECT().DeleteByUser(existing.ID).Return(nil)
+			}
 			tc.setupUserRepo(userRepository, &tc)
+			if tc.setupSessions != nil {
+				tc.setupSessions(sessionRepository, &tc)
+			}
 
 			// Run
 			domain := NewDomain(repository, utils.Config{}, notifier)
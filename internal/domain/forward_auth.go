@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/djordjev/auth/internal/utils"
+)
+
+// ForwardAuth decides whether reverse proxy lets request for the path through. Session
+// keys and api keys are accepted, role of the user has to be allowed by the path rule
+// and the path has to be within scopes of the api key.
+func (d *domain) ForwardAuth(setup Setup, token string, requestPath string) (user User, err error) {
+	if token == "" {
+		err = ErrNoSession
+		return
+	}
+
+	user, err = d.Session(setup, token)
+	if err != nil {
+		return
+	}
+
+	rule, found := d.forwardAuthRule(requestPath)
+	if (found && !slices.Contains(rule.Roles, user.Role)) || !user.InScope(requestPath) {
+		user = User{}
+		err = ErrForbidden
+	}
+
+	return
+}
+
+// forwardAuthRule returns rule with the longest path that is the requested path or its parent.
+// Path is cleaned first so dot segments can't be used to step out of a protected path.
+func (d *domain) forwardAuthRule(requestPath string) (rule utils.ForwardAuthRule, found bool) {
+	requestPath = path.Clean("/" + requestPath)
+
+	for _, candidate := range d.config.ForwardAuth.Rules {
+		prefix := strings.TrimSuffix(candidate.Path, "/")
+
+		matches := requestPath == prefix || strings.HasPrefix(requestPath, prefix+"/")
+		if matches && (!found || len(candidate.Path) > len(rule.Path)) {
+			rule = candidate
+			found = true
+		}
+	}
+
+	return
+}
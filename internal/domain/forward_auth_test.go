@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"context"
+	"testing"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardAuth(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	config := utils.Config{ForwardAuth: utils.ForwardAuth{Rules: []utils.ForwardAuthRule{
+		{Path: "/admin", Roles: []string{"admin"}},
+		{Path: "/admin/reports/", Roles: []string{"admin", "analyst"}},
+	}}}
+
+	tests := []struct {
+		name        string
+		noToken     bool
+		path        string
+		user        User
+		sessionErr  error
+		returnError error
+	}{
+		{
+			name: "no rule",
+			path: "/tools",
+			user: User{ID: 1, Role: "user"},
+		},
+		{
+			name: "allowed role",
+			path: "/admin/users",
+			user: User{ID: 1, Role: "admin"},
+		},
+		{
+			name:        "role not allowed",
+			path:        "/admin",
+			user:        User{ID: 1, Role: "analyst"},
+			returnError: ErrForbidden,
+		},
+		{
+			name: "longest rule applies",
+			path: "/admin/reports/daily",
+			user: User{ID: 1, Role: "analyst"},
+		},
+		{
+			name: "similar path",
+			path: "/administration",
+			user: User{ID: 1, Role: "user"},
+		},
+		{
+			name: "within api key scopes",
+			path: "/tools/build",
+			user: User{ID: 1, Role: "user", Scopes: []string{"/tools"}},
+		},
+		{
+			name:        "outside of api key scopes",
+			path:        "/reports",
+			user:        User{ID: 1, Role: "user", Scopes: []string{"/tools"}},
+			returnError: ErrForbidden,
+		},
+		{
+			name:        "dot segments",
+			path:        "/tools/../admin/users",
+			user:        User{ID: 1, Role: "user"},
+			returnError: ErrForbidden,
+		},
+		{
+			name:        "expired session",
+			path:        "/tools",
+			sessionErr:  modelErrors.ErrNotFound,
+			returnError: ErrNoSession,
+		},
+		{
+			name:        "no token",
+			noToken:     true,
+			path:        "/tools",
+			returnError: ErrNoSession,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			sessionRepository := NewMockRepositorySession(t)
+
+			// Setup mocks
+			token := "session"
+			if tc.noToken {
+				token = ""
+			} else {
+				repository.EXPECT().Session(context.TODO()).Return(sessionRepository)
+				sessionRepository.EXPECT().Get(token).Return(tc.user, tc.sessionErr)
+			}
+
+			// Run
+			domain := NewDomain(repository, config, NewMockNotifier(t))
+			user, err := domain.ForwardAuth(setup, token, tc.path)
+
+			// Assertions
+			require.Equal(t, tc.returnError, err)
+			if tc.returnError != nil {
+				require.Empty(t, user)
+				return
+			}
+
+			require.Equal(t, tc.user, user)
+		})
+	}
+}
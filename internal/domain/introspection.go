@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+)
+
+// token types reported by introspection
+const (
+	tokenTypeSession     = "session"
+	tokenTypeAPIKey      = "api_key"
+	tokenTypeAccessToken = "access_token"
+)
+
+// introspectedClaims are claims of both access tokens issued on log in and those issued to clients
+type introspectedClaims struct {
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope"`
+}
+
+// Introspect tells confidential client whether token issued by this app is active and who it
+// belongs to. Reason why token isn't active is not disclosed.
+func (d *domain) Introspect(setup Setup, clientId string, clientSecret string, token string) (introspection Introspection, err error) {
+	client, err := d.authenticateClient(setup, clientId, clientSecret)
+	if err != nil {
+		return
+	}
+
+	// public clients can't keep secret so anyone could ask in their name
+	if client.SecretHash == "" {
+		err = ErrInvalidClient
+		return
+	}
+
+	if token == "" {
+		return
+	}
+
+	if isAPIKey(token) {
+		introspection, err = d.introspectAPIKey(setup, token)
+	} else if strings.Count(token, ".") == 2 {
+		introspection, err = d.introspectAccessToken(setup, token)
+	} else {
+		introspection, err = d.introspectSession(setup, token)
+	}
+
+	if err != nil {
+		err = fmt.Errorf("domain Introspect -> %w", err)
+	}
+
+	return
+}
+
+func (d *domain) introspectSession(setup Setup, key string) (introspection Introspection, err error) {
+	// asking about the session is not using it so it's not extended
+	session, err := d.db.Session(setup.ctx).Peek(key)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		return Introspection{}, nil
+	} else if err != nil {
+		err = fmt.Errorf("unable to get session %w", err)
+		return
+	}
+
+	introspection = Introspection{
+		Active:    true,
+		TokenType: tokenTypeSession,
+		Subject:   strconv.FormatUint(session.User.ID, 10),
+		Username:  session.User.Username,
+		Role:      session.User.Role,
+		IssuedAt:  session.CreatedAt,
+		ExpiresAt: session.ExpiresAt,
+	}
+
+	// session ends earlier if it's not used until it becomes idle
+	if !session.IdleExpiresAt.IsZero() && session.IdleExpiresAt.Before(session.ExpiresAt) {
+		introspection.ExpiresAt = session.IdleExpiresAt
+	}
+
+	return
+}
+
+func (d *domain) introspectAPIKey(setup Setup, secret string) (introspection Introspection, err error) {
+	key, err := d.db.APIKey(setup.ctx).GetByHash(hashToken(secret))
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		return Introspection{}, nil
+	} else if err != nil {
+		err = fmt.Errorf("unable to get api key %w", err)
+		return
+	}
+
+	if !key.ExpiresAt.IsZero() && time.Now().After(key.ExpiresAt) {
+		return
+	}
+
+	user, err := d.db.User(setup.ctx).GetByID(key.UserID)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		return Introspection{}, nil
+	} else if err != nil {
+		err = fmt.Errorf("unable to get owner of api key %d %w", key.ID, err)
+		return
+	}
+
+	introspection = Introspection{
+		Active:    true,
+		TokenType: tokenTypeAPIKey,
+		Subject:   strconv.FormatUint(user.ID, 10),
+		Username:  user.Username,
+		Role:      user.Role,
+		Scopes:    key.Scopes,
+		IssuedAt:  key.CreatedAt,
+		ExpiresAt: key.ExpiresAt,
+	}
+
+	return
+}
+
+// introspectAccessToken reports current role of the user since the one in the token
+// might be outdated. Tokens issued to clients themselves have no user behind them.
+func (d *domain) introspectAccessToken(setup Setup, accessToken string) (introspection Introspection, err error) {
+	var extra introspectedClaims
+	claims, err := d.verifyAccessToken(setup, accessToken, &extra)
+	if err == ErrInvalidAccessToken {
+		return Introspection{}, nil
+	} else if err != nil {
+		return
+	}
+
+	introspection = Introspection{
+		Active:    true,
+		TokenType: tokenTypeAccessToken,
+		Subject:   claims.Subject,
+		ClientID:  extra.ClientID,
+		Scopes:    strings.Fields(extra.Scope),
+	}
+
+	if claims.IssuedAt != nil {
+		introspection.IssuedAt = claims.IssuedAt.Time()
+	}
+
+	if claims.Expiry != nil {
+		introspection.ExpiresAt = claims.Expiry.Time()
+	}
+
+	if extra.ClientID != "" && claims.Subject == extra.ClientID {
+		return
+	}
+
+	userId, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return Introspection{}, nil
+	}
+
+	user, err := d.db.User(setup.ctx).GetByID(userId)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		return Introspection{}, nil
+	} else if err != nil {
+		err = fmt.Errorf("unable to get user %d %w", userId, err)
+		return
+	}
+
+	introspection.Username = user.Username
+	introspection.Role = user.Role
+
+	return
+}
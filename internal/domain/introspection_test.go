@@ -0,0 +1,228 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntrospect(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com", Username: "djvukovic", Role: "admin", Verified: true}
+	secretHash := sha256.Sum256([]byte("secret"))
+	resourceServer := OAuthClient{ID: "api", SecretHash: hex.EncodeToString(secretHash[:])}
+	service := OAuthClient{ID: "billing", SecretHash: resourceServer.SecretHash, Scopes: []string{"reports:read"}}
+	createdAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	// keys are generated by each domain so tokens are signed by the one verifying them
+	userToken := func(d *domain) string {
+		token, _ := d.signAccessToken(setup, user)
+		return token
+	}
+	clientToken := func(d *domain) string {
+		tokens, _ := d.clientCredentialsToken(setup, service, "")
+		return tokens.AccessToken
+	}
+
+	type testCase struct {
+		name          string
+		client        OAuthClient
+		secret        string
+		token         string
+		sign          func(*domain) string
+		setupModels   func(*MockRepositorySession, *MockRepositoryAPIKey, *MockRepositoryUser)
+		introspection Introspection
+		returnError   error
+	}
+
+	tests := []testCase{
+		{
+			name:   "active session",
+			client: resourceServer,
+			secret: "secret",
+			token:  "session",
+			setupModels: func(rs *MockRepositorySession, ra *MockRepositoryAPIKey, ru *MockRepositoryUser) {
+				rs.EXPECT().Peek("session").Return(Session{ID: "session", User: user, CreatedAt: createdAt, ExpiresAt: expiresAt}, nil)
+			},
+			introspection: Introspection{
+				Active:    true,
+				TokenType: tokenTypeSession,
+				Subject:   "452",
+				Username:  user.Username,
+				Role:      user.Role,
+				IssuedAt:  createdAt,
+				ExpiresAt: expiresAt,
+			},
+		},
+		{
+			name:   "session ending when idle",
+			client: resourceServer,
+			secret: "secret",
+			token:  "session",
+			setupModels: func(rs *MockRepositorySession, ra *MockRepositoryAPIKey, ru *MockRepositoryUser) {
+				rs.EXPECT().Peek("session").Return(Session{ID: "session", User: user, CreatedAt: createdAt, ExpiresAt: expiresAt, IdleExpiresAt: createdAt.Add(time.Minute)}, nil)
+			},
+			introspection: Introspection{
+				Active:    true,
+				TokenType: tokenTypeSession,
+				Subject:   "452",
+				Username:  user.Username,
+				Role:      user.Role,
+				IssuedAt:  createdAt,
+				ExpiresAt: createdAt.Add(time.Minute),
+			},
+		},
+		{
+			name:   "expired session",
+			client: resourceServer,
+			secret: "secret",
+			token:  "session",
+			setupModels: func(rs *MockRepositorySession, ra *MockRepositoryAPIKey, ru *MockRepositoryUser) {
+				rs.EXPECT().Peek("session").Return(Session{}, modelErrors.ErrNotFound)
+			},
+		},
+		{
+			name:   "active api key",
+			client: resourceServer,
+			secret: "secret",
+			token:  "ak_secret",
+			setupModels: func(rs *MockRepositorySession, ra *MockRepositoryAPIKey, ru *MockRepositoryUser) {
+				ra.EXPECT().GetByHash(hashToken("ak_secret")).Return(APIKey{ID: 1, UserID: user.ID, Scopes: []string{"/reports"}, CreatedAt: createdAt}, nil)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+			},
+			introspection: Introspection{
+				Active:    true,
+				TokenType: tokenTypeAPIKey,
+				Subject:   "452",
+				Username:  user.Username,
+				Role:      user.Role,
+				Scopes:    []string{"/reports"},
+				IssuedAt:  createdAt,
+			},
+		},
+		{
+			name:   "expired api key",
+			client: resourceServer,
+			secret: "secret",
+			token:  "ak_secret",
+			setupModels: func(rs *MockRepositorySession, ra *MockRepositoryAPIKey, ru *MockRepositoryUser) {
+				ra.EXPECT().GetByHash(hashToken("ak_secret")).Return(APIKey{ID: 1, UserID: user.ID, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+			},
+		},
+		{
+			name:   "user access token",
+			client: resourceServer,
+			secret: "secret",
+			sign:   userToken,
+			setupModels: func(rs *MockRepositorySession, ra *MockRepositoryAPIKey, ru *MockRepositoryUser) {
+				ru.EXPECT().GetByID(user.ID).Return(User{ID: user.ID, Username: user.Username, Role: "user"}, nil)
+			},
+			introspection: Introspection{
+				Active:    true,
+				TokenType: tokenTypeAccessToken,
+				Subject:   "452",
+				Username:  user.Username,
+				Role:      "user",
+				Scopes:    []string{},
+			},
+		},
+		{
+			name:   "access token of deleted user",
+			client: resourceServer,
+			secret: "secret",
+			sign:   userToken,
+			setupModels: func(rs *MockRepositorySession, ra *MockRepositoryAPIKey, ru *MockRepositoryUser) {
+				ru.EXPECT().GetByID(user.ID).Return(User{}, modelErrors.ErrNotFound)
+			},
+		},
+		{
+			name:   "client access token",
+			client: resourceServer,
+			secret: "secret",
+			sign:   clientToken,
+			introspection: Introspection{
+				Active:    true,
+				TokenType: tokenTypeAccessToken,
+				Subject:   "billing",
+				ClientID:  "billing",
+				Scopes:    []string{"reports:read"},
+			},
+		},
+		{
+			name:   "tampered access token",
+			client: resourceServer,
+			secret: "secret",
+			sign: func(d *domain) string {
+				token := userToken(d)
+				return token[:len(token)-4] + "AAAA"
+			},
+		},
+		{
+			name:        "invalid secret",
+			client:      resourceServer,
+			secret:      "guess",
+			token:       "session",
+			returnError: ErrInvalidClient,
+		},
+		{
+			name:        "public client",
+			client:      OAuthClient{ID: "api"},
+			token:       "session",
+			returnError: ErrInvalidClient,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			clientRepository := NewMockRepositoryOAuthClient(t)
+			sessionRepository := NewMockRepositorySession(t)
+			apiKeyRepository := NewMockRepositoryAPIKey(t)
+			userRepository := NewMockRepositoryUser(t)
+
+			// Setup mocks
+			repository.EXPECT().OAuthClient(context.TODO()).Return(clientRepository)
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository).Maybe()
+			repository.EXPECT().APIKey(context.TODO()).Return(apiKeyRepository).Maybe()
+			repository.EXPECT().User(context.TODO()).Return(userRepository).Maybe()
+			clientRepository.EXPECT().Get("api").Return(tc.client, nil)
+
+			if tc.setupModels != nil {
+				tc.setupModels(sessionRepository, apiKeyRepository, userRepository)
+			}
+
+			// Run
+			d := NewDomain(repository, providerConfig, NewMockNotifier(t)).(*domain)
+
+			token := tc.token
+			if tc.sign != nil {
+				token = tc.sign(d)
+			}
+
+			introspection, err := d.Introspect(setup, "api", tc.secret, token)
+
+			// Assertions
+			require.Equal(t, tc.returnError, err)
+
+			// times of access tokens are set when they are signed
+			if tc.introspection.TokenType == tokenTypeAccessToken {
+				require.WithinDuration(t, time.Now(), introspection.IssuedAt, time.Minute)
+				require.True(t, introspection.ExpiresAt.After(time.Now()))
+				introspection.IssuedAt = time.Time{}
+				introspection.ExpiresAt = time.Time{}
+			}
+
+			require.Equal(t, tc.introspection, introspection)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package domain
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ldapProvider is the identity provider name directory accounts are linked under
+const ldapProvider = "ldap"
+
+type directoryEntry struct {
+	DN     string
+	Email  string
+	Groups []string
+}
+
+// logInDirectory authenticates user against LDAP directory and returns the local user
+// linked to the directory account. User is provisioned on the first login.
+func (d *domain) logInDirectory(setup Setup, user User) (existing User, err error) {
+	login := user.Username
+	if login == "" {
+		login = user.Email
+	}
+
+	entry, err := d.authenticateDirectory(login, user.Password)
+	if err != nil {
+		return
+	}
+
+	role, mapped := d.directoryRole(entry)
+
+	identity := Identity{Provider: ldapProvider, Subject: strings.ToLower(entry.DN), Email: entry.Email}
+	profile := User{Email: entry.Email, Username: user.Username, Role: role, Verified: true}
+
+	// directory is managed by the operator so its emails are trusted
+	existing, err = d.resolveIdentity(setup, d.config.LDAP.AutoLink, 0, identity, profile)
+	if err != nil {
+		return
+	}
+
+	if mapped {
+		existing, err = d.syncRole(setup, existing, role)
+	}
+
+	return
+}
+
+// authenticateDirectory binds as the user either directly with DN built from the template or
+// with DN found by searching the directory. Unknown users and wrong passwords are reported as
+// invalid credentials.
+func (d *domain) authenticateDirectory(login string, password string) (entry directoryEntry, err error) {
+	// most servers accept bind without password as anonymous
+	if login == "" || password == "" {
+		err = ErrInvalidCredentials
+		return
+	}
+
+	config := d.config.LDAP
+
+	conn, err := dialDirectory(config)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	attributes := []string{config.EmailAttr, config.GroupAttr}
+
+	var request *ldap.SearchRequest
+	if config.UserDN != "" {
+		dn := strings.ReplaceAll(config.UserDN, "%s", ldap.EscapeDN(login))
+
+		err = bindDirectory(conn, dn, password)
+		if err != nil {
+			return
+		}
+
+		request = ldap.NewSearchRequest(dn, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1, 0, false, "(objectClass=*)", attributes, nil)
+	} else {
+		if config.BindDN != "" {
+			err = conn.Bind(config.BindDN, config.BindPassword)
+			if err != nil {
+				err = fmt.Errorf("unable to bind ldap service account %w", err)
+				return
+			}
+		}
+
+		filter := strings.ReplaceAll(config.Filter, "%s", ldap.EscapeFilter(login))
+		request = ldap.NewSearchRequest(config.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false, filter, attributes, nil)
+	}
+
+	result, err := conn.Search(request)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		err = ErrInvalidCredentials
+		return
+	} else if err != nil {
+		err = fmt.Errorf("unable to search ldap for %s %w", login, err)
+		return
+	}
+
+	// login has to identify exactly one account
+	if len(result.Entries) != 1 {
+		err = ErrInvalidCredentials
+		return
+	}
+
+	found := result.Entries[0]
+
+	if config.UserDN == "" {
+		err = bindDirectory(conn, found.DN, password)
+		if err != nil {
+			return
+		}
+	}
+
+	entry = directoryEntry{
+		DN:     found.DN,
+		Email:  found.GetAttributeValue(config.EmailAttr),
+		Groups: found.GetAttributeValues(config.GroupAttr),
+	}
+
+	return
+}
+
+// directoryRole returns role of the first configured group user is member of.
+// Roles are not managed by the directory if there's no mapping.
+func (d *domain) directoryRole(entry directoryEntry) (role string, mapped bool) {
+	if len(d.config.LDAP.Roles) == 0 {
+		return
+	}
+
+	mapped = true
+
+	for _, mapping := range d.config.LDAP.Roles {
+		group, err := ldap.ParseDN(mapping.Group)
+		if err != nil {
+			continue
+		}
+
+		for _, member := range entry.Groups {
+			memberDN, err := ldap.ParseDN(member)
+			if err == nil && group.EqualFold(memberDN) {
+				role = mapping.Role
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// isDirectoryUser reports whether user is linked to directory account.
+// Such users can't log in with local password.
+func (d *domain) isDirectoryUser(setup Setup, user User) (linked bool, err error) {
+	identities, err := d.db.Identity(setup.ctx).GetByUser(user.ID)
+	if err != nil {
+		err = fmt.Errorf("unable to get identities of user %d %w", user.ID, err)
+		return
+	}
+
+	for _, identity := range identities {
+		if identity.Provider == ldapProvider {
+			return true, nil
+		}
+	}
+
+	return
+}
+
+func dialDirectory(config utils.LDAP) (conn *ldap.Conn, err error) {
+	conn, err = ldap.DialURL(config.URL, ldap.DialWithDialer(&net.Dialer{Timeout: utils.LDAP_TIMEOUT}))
+	if err != nil {
+		err = fmt.Errorf("unable to connect to ldap %w", err)
+		return
+	}
+
+	conn.SetTimeout(utils.LDAP_TIMEOUT)
+
+	if config.StartTLS {
+		parsed, e := url.Parse(config.URL)
+		if e == nil {
+			e = conn.StartTLS(&tls.Config{ServerName: parsed.Hostname()})
+		}
+
+		if e != nil {
+			conn.Close()
+			conn = nil
+			err = fmt.Errorf("unable to start tls with ldap %w", e)
+		}
+	}
+
+	return
+}
+
+func bindDirectory(conn *ldap.Conn, dn string, password string) error {
+	err := conn.Bind(dn, password)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return ErrInvalidCredentials
+	} else if err != nil {
+		return fmt.Errorf("unable to bind ldap user %s %w", dn, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,545 @@
+package domain
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testDirectory is a minimal stand-in LDAP server answering simple binds and searches
+type testDirectory struct {
+	url     string
+	entries map[string]testDirectoryEntry
+}
+
+type testDirectoryEntry struct {
+	password   string
+	attributes map[string][]string
+}
+
+func newTestDirectory(t *testing.T) *testDirectory {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	directory := &testDirectory{
+		url: "ldap://" + listener.Addr().String(),
+		entries: map[string]testDirectoryEntry{
+			"cn=service,dc=example,dc=com": {password: "service"},
+			"uid=djvukovic,ou=people,dc=example,dc=com": {
+				password: "testee",
+				attributes: map[string][]string{
+					"uid":      {"djvukovic"},
+					"mail":     {"djvukovic@gmail.com"},
+					"memberOf": {"cn=Developers,ou=groups,dc=example,dc=com", "cn=Admins,ou=groups,dc=example,dc=com"},
+				},
+			},
+			"uid=twin,ou=people,dc=example,dc=com":      {password: "testee", attributes: map[string][]string{"uid": {"twin"}}},
+			"uid=twin,ou=contractors,dc=example,dc=com": {password: "testee", attributes: map[string][]string{"uid": {"twin"}}},
+		},
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go directory.serve(conn)
+		}
+	}()
+
+	return directory
+}
+
+func (d *testDirectory) serve(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+
+		id := packet.Children[0].Value.(int64)
+		request := packet.Children[1]
+
+		switch request.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := request.Children[1].Value.(string)
+			password := request.Children[2].Data.String()
+
+			code := int64(ldap.LDAPResultSuccess)
+			if entry, ok := d.entries[strings.ToLower(dn)]; dn != "" && (!ok || entry.password != password) {
+				code = ldap.LDAPResultInvalidCredentials
+			}
+
+			d.write(conn, id, testDirectoryResult(ldap.ApplicationBindResponse, code))
+		case ldap.ApplicationSearchRequest:
+			base := strings.ToLower(request.Children[0].Value.(string))
+			scope := request.Children[1].Value.(int64)
+			sizeLimit := request.Children[3].Value.(int64)
+
+			var found []string
+			for dn, entry := range d.entries {
+				inScope := dn == base || (scope == ldap.ScopeWholeSubtree && strings.HasSuffix(dn, ","+base))
+				if inScope && testDirectoryMatch(request.Children[6], entry) {
+					found = append(found, dn)
+				}
+			}
+
+			if scope == ldap.ScopeBaseObject && len(found) == 0 {
+				d.write(conn, id, testDirectoryResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultNoSuchObject))
+				continue
+			}
+
+			code := int64(ldap.LDAPResultSuccess)
+			if sizeLimit > 0 && int64(len(found)) > sizeLimit {
+				found = found[:sizeLimit]
+				code = ldap.LDAPResultSizeLimitExceeded
+			}
+
+			for _, dn := range found {
+				d.write(conn, id, testDirectorySearchEntry(dn, d.entries[dn]))
+			}
+
+			d.write(conn, id, testDirectoryResult(ldap.ApplicationSearchResultDone, code))
+		default:
+			return
+		}
+	}
+}
+
+func (d *testDirectory) write(conn net.Conn, id int64, response *ber.Packet) {
+	packet := ber.NewSequence("")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+	packet.AppendChild(response)
+
+	conn.Write(packet.Bytes())
+}
+
+func testDirectoryResult(tag ber.Tag, code int64) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, ""))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+
+	return result
+}
+
+func testDirectorySearchEntry(dn string, entry testDirectoryEntry) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "")
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, ""))
+
+	attributes := ber.NewSequence("")
+	for name, values := range entry.attributes {
+		attribute := ber.NewSequence("")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, ""))
+
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, ""))
+		}
+
+		attribute.AppendChild(set)
+		attributes.AppendChild(attribute)
+	}
+
+	result.AppendChild(attributes)
+
+	return result
+}
+
+// testDirectoryMatch supports and, or, equality and presence filters
+func testDirectoryMatch(filter *ber.Packet, entry testDirectoryEntry) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !testDirectoryMatch(child, entry) {
+				return false
+			}
+		}
+
+		return true
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if testDirectoryMatch(child, entry) {
+				return true
+			}
+		}
+
+		return false
+	case ldap.FilterEqualityMatch:
+		name := filter.Children[0].Data.String()
+		value := filter.Children[1].Data.String()
+
+		for attribute, values := range entry.attributes {
+			if strings.EqualFold(attribute, name) {
+				for _, v := range values {
+					if strings.EqualFold(v, value) {
+						return true
+					}
+				}
+			}
+		}
+
+		return false
+	case ldap.FilterPresent:
+		return true
+	}
+
+	return false
+}
+
+func ldapConfig(directory *testDirectory) utils.Config {
+	return utils.Config{LDAP: utils.LDAP{
+		URL:          directory.url,
+		BindDN:       "cn=service,dc=example,dc=com",
+		BindPassword: "service",
+		BaseDN:       "dc=example,dc=com",
+		Filter:       "(|(uid=%s)(mail=%s))",
+		EmailAttr:    "mail",
+		GroupAttr:    "memberOf",
+		Roles: []utils.LDAPRole{
+			{Group: "cn=admins,ou=groups,dc=example,dc=com", Role: "admin"},
+			{Group: "cn=developers,ou=groups,dc=example,dc=com", Role: "developer"},
+		},
+	}}
+}
+
+func TestAuthenticateDirectory(t *testing.T) {
+	t.Parallel()
+
+	directory := newTestDirectory(t)
+
+	search := ldapConfig(directory)
+
+	bind := ldapConfig(directory)
+	bind.LDAP.UserDN = "uid=%s,ou=people,dc=example,dc=com"
+	bind.LDAP.BaseDN = ""
+
+	wrongService := ldapConfig(directory)
+	wrongService.LDAP.BindPassword = "wrong"
+
+	djvukovic := directoryEntry{
+		DN:     "uid=djvukovic,ou=people,dc=example,dc=com",
+		Email:  "djvukovic@gmail.com",
+		Groups: []string{"cn=Developers,ou=groups,dc=example,dc=com", "cn=Admins,ou=groups,dc=example,dc=com"},
+	}
+
+	tests := []struct {
+		name        string
+		config      utils.Config
+		login       string
+		password    string
+		entry       directoryEntry
+		returnError error
+	}{
+		{name: "search then bind", config: search, login: "djvukovic", password: "testee", entry: djvukovic},
+		{name: "search by email", config: search, login: "djvukovic@gmail.com", password: "testee", entry: djvukovic},
+		{name: "direct bind", config: bind, login: "djvukovic", password: "testee", entry: djvukovic},
+		{name: "wrong password", config: search, login: "djvukovic", password: "wrong", returnError: ErrInvalidCredentials},
+		{name: "wrong password direct bind", config: bind, login: "djvukovic", password: "wrong", returnError: ErrInvalidCredentials},
+		{name: "unknown user", config: search, login: "unknown", password: "testee", returnError: ErrInvalidCredentials},
+		{name: "unknown user direct bind", config: bind, login: "unknown", password: "testee", returnError: ErrInvalidCredentials},
+		{name: "ambiguous login", config: search, login: "twin", password: "testee", returnError: ErrInvalidCredentials},
+		{name: "empty password", config: search, login: "djvukovic", password: "", returnError: ErrInvalidCredentials},
+		{name: "filter injection", config: search, login: "*", password: "testee", returnError: ErrInvalidCredentials},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			domain := &domain{config: tc.config}
+
+			entry, err := domain.authenticateDirectory(tc.login, tc.password)
+
+			require.Equal(t, tc.returnError, err)
+			require.Equal(t, tc.entry, entry)
+		})
+	}
+
+	// misconfigured service account is not reported as user error
+	_, err := (&domain{config: wrongService}).authenticateDirectory("djvukovic", "testee")
+	require.Error(t, err)
+	require.NotEqual(t, ErrInvalidCredentials, err)
+}
+
+func TestDirectoryRole(t *testing.T) {
+	t.Parallel()
+
+	config := utils.Config{LDAP: utils.LDAP{Roles: []utils.LDAPRole{
+		{Group: "cn=admins,ou=groups,dc=example,dc=com", Role: "admin"},
+		{Group: "cn=developers,ou=groups,dc=example,dc=com", Role: "developer"},
+	}}}
+
+	tests := []struct {
+		name   string
+		config utils.Config
+		groups []string
+		role   string
+		mapped bool
+	}{
+		{
+			name:   "first configured group wins",
+			config: config,
+			groups: []string{"cn=Developers,ou=groups,dc=example,dc=com", "CN=Admins, OU=groups, DC=example, DC=com"},
+			role:   "admin",
+			mapped: true,
+		},
+		{
+			name:   "no matching group",
+			config: config,
+			groups: []string{"cn=sales,ou=groups,dc=example,dc=com"},
+			role:   "",
+			mapped: true,
+		},
+		{
+			name:   "roles not mapped",
+			config: utils.Config{},
+			groups: []string{"cn=admins,ou=groups,dc=example,dc=com"},
+			role:   "",
+			mapped: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			domain := &domain{config: tc.config}
+
+			role, mapped := domain.directoryRole(directoryEntry{Groups: tc.groups})
+
+			require.Equal(t, tc.role, role)
+			require.Equal(t, tc.mapped, mapped)
+		})
+	}
+}
+
+func TestLogInDirectory(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	directory := newTestDirectory(t)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("local"), 14)
+
+	user := User{ID: 452, Email: "djvukovic@gmail.com", Username: "djvukovic", Role: "admin", Verified: true}
+	local := User{ID: 884, Email: "local@example.com", Username: "local", Password: string(hash)}
+	identity := Identity{UserID: user.ID, Provider: "ldap", Subject: "uid=djvukovic,ou=people,dc=example,dc=com", Email: user.Email}
+
+	type testCase struct {
+		name        string
+		autoLink    bool
+		login       User
+		setupModels func(*MockRepository, *MockRepositoryUser, *MockRepositoryIdentity, *MockRepositorySession)
+		returnUser  User
+		returnKey   string
+		returnError error
+	}
+
+	noSecondFactor := func(r *MockRepository, userId uint64) {
+		totpRepository := NewMockRepositoryTOTP(t)
+		passkeyRepository := NewMockRepositoryPasskey(t)
+
+		r.EXPECT().TOTP(context.TODO()).Return(totpRepository)
+		r.EXPECT().Passkey(context.TODO()).Return(passkeyRepository)
+		totpRepository.EXPECT().Get(userId).Return(TOTP{}, modelErrors.ErrNotFound)
+		passkeyRepository.EXPECT().GetByUser(userId).Return([]Passkey{}, nil)
+	}
+
+	tests := []testCase{
+		{
+			name:  "linked user with synced role",
+			login: User{Username: "djvukovic", Password: "testee"},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession) {
+				demoted := user
+				demoted.Role = "developer"
+
+				ri.EXPECT().Get("ldap", identity.Subject).Return(identity, nil)
+				ru.EXPECT().GetByID(user.ID).Return(demoted, nil)
+				ru.EXPECT().SetRole(demoted, "admin").Return(nil)
+				rs.EXPECT().UpdateUser(user).Return(nil)
+				noSecondFactor(r, user.ID)
+				rs.EXPECT().Create(user, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: user}, nil)
+			},
+			returnUser: user,
+			returnKey:  "session",
+		},
+		{
+			name:  "provisions user on first login",
+			login: User{Username: "djvukovic", Password: "testee"},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession) {
+				ri.EXPECT().Get("ldap", identity.Subject).Return(Identity{}, modelErrors.ErrNotFound)
+				ru.EXPECT().GetByEmail(user.Email).Return(User{}, modelErrors.ErrNotFound)
+				r.EXPECT().Atomic(mock.Anything).RunAndReturn(func(f func(Repository) error) error {
+					return f(r)
+				})
+				ru.EXPECT().Create(mock.MatchedBy(func(u User) bool {
+					return u.Email == user.Email && u.Username == user.Username && u.Role == "admin" && u.Verified && u.Password == ""
+				})).Return(user, nil)
+				ri.EXPECT().Create(Identity{UserID: user.ID, Provider: "ldap", Subject: identity.Subject, Email: user.Email}).Return(identity, nil)
+				noSecondFactor(r, user.ID)
+				rs.EXPECT().Create(user, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: user}, nil)
+			},
+			returnUser: user,
+			returnKey:  "session",
+		},
+		{
+			name:  "email taken",
+			login: User{Username: "djvukovic", Password: "testee"},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession) {
+				ri.EXPECT().Get("ldap", identity.Subject).Return(Identity{}, modelErrors.ErrNotFound)
+				ru.EXPECT().GetByEmail(user.Email).Return(user, nil)
+			},
+			returnError: ErrIdentityLinkRequired,
+		},
+		{
+			name:     "links existing account",
+			autoLink: true,
+			login:    User{Username: "djvukovic", Password: "testee"},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession) {
+				ri.EXPECT().Get("ldap", identity.Subject).Return(Identity{}, modelErrors.ErrNotFound)
+				ru.EXPECT().GetByEmail(user.Email).Return(user, nil)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+				ri.EXPECT().Create(Identity{UserID: user.ID, Provider: "ldap", Subject: identity.Subject, Email: user.Email}).Return(identity, nil)
+				noSecondFactor(r, user.ID)
+				rs.EXPECT().Create(user, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: user}, nil)
+			},
+			returnUser: user,
+			returnKey:  "session",
+		},
+		{
+			name:  "local account outside of directory",
+			login: User{Username: "local", Password: "local"},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession) {
+				ru.EXPECT().GetByUsername("local").Return(local, nil)
+				ri.EXPECT().GetByUser(local.ID).Return([]Identity{}, nil)
+				noSecondFactor(r, local.ID)
+				rs.EXPECT().Create(local, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: local}, nil)
+			},
+			returnUser: local,
+			returnKey:  "session",
+		},
+		{
+			name:  "directory user can't use local password",
+			login: User{Username: "djvukovic", Password: "local"},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession) {
+				withPassword := user
+				withPassword.Password = string(hash)
+
+				ru.EXPECT().GetByUsername("djvukovic").Return(withPassword, nil)
+				ri.EXPECT().GetByUser(user.ID).Return([]Identity{identity}, nil)
+			},
+			returnError: ErrInvalidCredentials,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			userRepository := NewMockRepositoryUser(t)
+			identityRepository := NewMockRepositoryIdentity(t)
+			sessionRepository := NewMockRepositorySession(t)
+
+			// Setup mocks
+			repository.EXPECT().User(context.TODO()).Return(userRepository)
+			repository.EXPECT().Identity(context.TODO()).Return(identityRepository)
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository).Maybe()
+			tc.setupModels(repository, userRepository, identityRepository, sessionRepository)
+
+			config := ldapConfig(directory)
+			config.LDAP.AutoLink = tc.autoLink
+
+			// Run
+			domain := NewDomain(repository, config, NewMockNotifier(t))
+			existing, key, err := domain.LogIn(setup, tc.login)
+
+			// Assertions
+			require.Equal(t, tc.returnError, err)
+			require.Equal(t, tc.returnUser, existing)
+			require.Equal(t, tc.returnKey, key)
+		})
+	}
+}
+
+func TestLogInDirectoryUnavailable(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+
+	// nothing listens on the port once the listener is closed
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listener.Close()
+
+	config := ldapConfig(&testDirectory{url: "ldap://" + listener.Addr().String()})
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("local"), 14)
+	local := User{ID: 884, Email: "local@example.com", Username: "local", Password: string(hash)}
+	directoryUser := User{ID: 452, Email: "djvukovic@gmail.com", Username: "djvukovic", Password: string(hash)}
+	identity := Identity{UserID: directoryUser.ID, Provider: "ldap", Subject: "uid=djvukovic,ou=people,dc=example,dc=com"}
+
+	t.Run("local account logs in", func(t *testing.T) {
+		// Create mocks
+		repository := NewMockRepository(t)
+		userRepository := NewMockRepositoryUser(t)
+		identityRepository := NewMockRepositoryIdentity(t)
+		totpRepository := NewMockRepositoryTOTP(t)
+		passkeyRepository := NewMockRepositoryPasskey(t)
+		sessionRepository := NewMockRepositorySession(t)
+
+		// Setup mocks
+		repository.EXPECT().User(context.TODO()).Return(userRepository)
+		repository.EXPECT().Identity(context.TODO()).Return(identityRepository)
+		repository.EXPECT().TOTP(context.TODO()).Return(totpRepository)
+		repository.EXPECT().Passkey(context.TODO()).Return(passkeyRepository)
+		repository.EXPECT().Session(context.TODO()).Return(sessionRepository)
+		userRepository.EXPECT().GetByUsername("local").Return(local, nil)
+		identityRepository.EXPECT().GetByUser(local.ID).Return([]Identity{}, nil)
+		totpRepository.EXPECT().Get(local.ID).Return(TOTP{}, modelErrors.ErrNotFound)
+		passkeyRepository.EXPECT().GetByUser(local.ID).Return([]Passkey{}, nil)
+		sessionRepository.EXPECT().Create(local, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: local}, nil)
+
+		// Run
+		domain := NewDomain(repository, config, NewMockNotifier(t))
+		existing, key, err := domain.LogIn(setup, User{Username: "local", Password: "local"})
+
+		// Assertions
+		require.NoError(t, err)
+		require.Equal(t, local, existing)
+		require.Equal(t, "session", key)
+	})
+
+	t.Run("directory account fails closed", func(t *testing.T) {
+		// Create mocks
+		repository := NewMockRepository(t)
+		userRepository := NewMockRepositoryUser(t)
+		identityRepository := NewMockRepositoryIdentity(t)
+
+		// Setup mocks
+		repository.EXPECT().User(context.TODO()).Return(userRepository)
+		repository.EXPECT().Identity(context.TODO()).Return(identityRepository)
+		userRepository.EXPECT().GetByUsername("djvukovic").Return(directoryUser, nil)
+		identityRepository.EXPECT().GetByUser(directoryUser.ID).Return([]Identity{identity}, nil)
+
+		// Run
+		domain := NewDomain(repository, config, NewMockNotifier(t))
+		existing, key, err := domain.LogIn(setup, User{Username: "djvukovic", Password: "local"})
+
+		// Assertions
+		require.ErrorContains(t, err, "unable to connect to ldap")
+		require.NotErrorIs(t, err, ErrInvalidCredentials)
+		require.Equal(t, User{}, existing)
+		require.Empty(t, key)
+	})
+}
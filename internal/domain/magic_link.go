@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/google/uuid"
+)
+
+func (d *domain) MagicLinkRequest(setup Setup, user User) (sentTo User, err error) {
+	if d.config.MagicLink == "" {
+		err = ErrMagicLinkNotConfigured
+		return
+	}
+
+	sentTo, err = d.findUser(setup, user)
+	if errors.Is(err, ErrUserNotExist) {
+		return
+	} else if err != nil {
+		err = fmt.Errorf("domain MagicLinkRequest -> %w", err)
+		return
+	}
+
+	// random (v4) token since it's the only thing needed to log in
+	token, err := uuid.NewRandom()
+	if err != nil {
+		sentTo = User{}
+		err = fmt.Errorf("domain MagicLinkRequest -> failed to generate token %w", err)
+		return
+	}
+
+	link, err := d.db.MagicLink(setup.ctx).Create(token.String(), sentTo.ID, time.Now().Add(utils.MAGIC_LINK_TTL))
+	if err != nil {
+		sentTo = User{}
+		err = fmt.Errorf("domain MagicLinkRequest -> %w", err)
+		return
+	}
+
+	loginLink := fmt.Sprintf("%s?t=%s", d.config.MagicLink, link.Token)
+	minutes := int(utils.MAGIC_LINK_TTL.Minutes())
+	text := fmt.Sprintf(magicLinkTextTemplate, sentTo.Email, loginLink, minutes)
+	html := fmt.Sprintf(magicLinkHtmlTemplate, sentTo.Email, minutes, loginLink)
+
+	err = d.notifier.Send(sentTo.Email, "Log in link", text, html)
+	if err != nil {
+		sentTo = User{}
+		err = fmt.Errorf("domain MagicLinkRequest -> failed to send login link %w", err)
+		return
+	}
+
+	return
+}
+
+// LogInMagicLink consumes login link and treats it as the first factor. Since
+// link was delivered to user's inbox email is marked as verified as well.
+func (d *domain) LogInMagicLink(setup Setup, token string) (existing User, sessionKey string, err error) {
+	var user User
+	var verified bool
+
+	err = d.db.Atomic(func(txRepo Repository) error {
+		link, e := txRepo.MagicLink(setup.ctx).Use(token)
+		if errors.Is(e, modelErrors.ErrNotFound) {
+			return ErrInvalidToken
+		} else if e != nil {
+			return fmt.Errorf("domain LogInMagicLink -> failed to use login link %w", e)
+		}
+
+		userModel := txRepo.User(setup.ctx)
+
+		user, e = userModel.GetByID(link.UserID)
+		if e != nil {
+			return fmt.Errorf("domain LogInMagicLink -> failed to fetch user %d %w", link.UserID, e)
+		}
+
+		if user.Verified {
+			return nil
+		}
+
+		e = userModel.Verify(user)
+		if e != nil {
+			return fmt.Errorf("domain LogInMagicLink -> failed to verify user %d %w", user.ID, e)
+		}
+
+		user.Verified = true
+		verified = true
+		return nil
+	})
+
+	if err != nil {
+		return
+	}
+
+	if verified {
+		d.refreshSessions(setup, user)
+	}
+
+	return d.completeLogIn(setup, user, "")
+}
@@ -0,0 +1,228 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMagicLinkRequest(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com", Username: "djvukovic"}
+	config := utils.Config{MagicLink: "https://example.com/magic"}
+
+	type testCase struct {
+		name        string
+		config      utils.Config
+		user        User
+		setupModels func(*MockRepositoryUser, *MockRepositoryMagicLink, *MockNotifier, *testCase)
+		returnUser  User
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name:   "success",
+			config: config,
+			user:   User{Email: user.Email},
+			setupModels: func(ru *MockRepositoryUser, rm *MockRepositoryMagicLink, mn *MockNotifier, tc *testCase) {
+				ru.EXPECT().GetByEmail(user.Email).Return(user, nil)
+				rm.EXPECT().Create(mock.Anything, user.ID, mock.MatchedBy(func(expiresAt time.Time) bool {
+					return time.Until(expiresAt) > utils.MAGIC_LINK_TTL-time.Minute
+				})).RunAndReturn(func(token string, userId uint64, expiresAt time.Time) (MagicLink, error) {
+					return MagicLink{ID: 1, Token: token, UserID: userId}, nil
+				})
+				mn.EXPECT().Send(user.Email, mock.Anything, mock.MatchedBy(func(text string) bool {
+					return strings.Contains(text, "https://example.com/magic?t=")
+				}), mock.Anything).Return(nil)
+			},
+			returnUser: user,
+		},
+		{
+			name:   "by username",
+			config: config,
+			user:   User{Username: user.Username},
+			setupModels: func(ru *MockRepositoryUser, rm *MockRepositoryMagicLink, mn *MockNotifier, tc *testCase) {
+				ru.EXPECT().GetByUsername(user.Username).Return(user, nil)
+				rm.EXPECT().Create(mock.Anything, user.ID, mock.Anything).Return(MagicLink{Token: "token", UserID: user.ID}, nil)
+				mn.EXPECT().Send(user.Email, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			},
+			returnUser: user,
+		},
+		{
+			name:   "user does not exist",
+			config: config,
+			user:   User{Email: user.Email},
+			setupModels: func(ru *MockRepositoryUser, rm *MockRepositoryMagicLink, mn *MockNotifier, tc *testCase) {
+				ru.EXPECT().GetByEmail(user.Email).Return(User{}, modelErrors.ErrNotFound)
+			},
+			returnError: ErrUserNotExist,
+		},
+		{
+			name:   "sending fails",
+			config: config,
+			user:   User{Email: user.Email},
+			setupModels: func(ru *MockRepositoryUser, rm *MockRepositoryMagicLink, mn *MockNotifier, tc *testCase) {
+				ru.EXPECT().GetByEmail(user.Email).Return(user, nil)
+				rm.EXPECT().Create(mock.Anything, user.ID, mock.Anything).Return(MagicLink{Token: "token", UserID: user.ID}, nil)
+				mn.EXPECT().Send(user.Email, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("mailjet down"))
+			},
+			returnError: errors.New("domain MagicLinkRequest -> failed to send login link mailjet down"),
+		},
+		{
+			name:        "not configured",
+			user:        User{Email: user.Email},
+			setupModels: func(ru *MockRepositoryUser, rm *MockRepositoryMagicLink, mn *MockNotifier, tc *testCase) {},
+			returnError: ErrMagicLinkNotConfigured,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			userRepository := NewMockRepositoryUser(t)
+			magicLinkRepository := NewMockRepositoryMagicLink(t)
+			notifier := NewMockNotifier(t)
+
+			// Setup mocks
+			repository.EXPECT().User(context.TODO()).Return(userRepository).Maybe()
+			repository.EXPECT().MagicLink(context.TODO()).Return(magicLinkRepository).Maybe()
+			tc.setupModels(userRepository, magicLinkRepository, notifier, &tc)
+
+			// Run
+			domain := NewDomain(repository, tc.config, notifier)
+			sentTo, err := domain.MagicLinkRequest(setup, tc.user)
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorContains(t, err, tc.returnError.Error())
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.returnUser, sentTo)
+		})
+	}
+}
+
+func TestLogInMagicLink(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com", Verified: true}
+	unverified := User{ID: 452, Email: "djvukovic@gmail.com", Verified: false}
+	employee := User{ID: 453, Email: "employee@bigcustomer.com", Verified: true}
+	config := utils.Config{SAMLProviders: []utils.SAMLProvider{{Name: "okta", Domains: []string{"bigcustomer.com"}}}}
+
+	type testCase struct {
+		name        string
+		setupModels func(*MockRepositoryMagicLink, *MockRepositoryUser, *MockRepositoryTOTP, *MockRepositoryPasskey, *MockRepositoryChallenge, *MockRepositorySession, *testCase)
+		returnUser  User
+		returnKey   string
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name: "success",
+			setupModels: func(rm *MockRepositoryMagicLink, ru *MockRepositoryUser, rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rc *MockRepositoryChallenge, rs *MockRepositorySession, tc *testCase) {
+				rm.EXPECT().Use("token").Return(MagicLink{Token: "token", UserID: user.ID}, nil)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+				rt.EXPECT().Get(user.ID).Return(TOTP{}, modelErrors.ErrNotFound)
+				rp.EXPECT().GetByUser(user.ID).Return([]Passkey{}, nil)
+				rs.EXPECT().Create(user, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: user}, nil)
+			},
+			returnUser: user,
+			returnKey:  "session",
+		},
+		{
+			name: "verifies email on first use",
+			setupModels: func(rm *MockRepositoryMagicLink, ru *MockRepositoryUser, rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rc *MockRepositoryChallenge, rs *MockRepositorySession, tc *testCase) {
+				rm.EXPECT().Use("token").Return(MagicLink{Token: "token", UserID: user.ID}, nil)
+				ru.EXPECT().GetByID(user.ID).Return(unverified, nil)
+				ru.EXPECT().Verify(unverified).Return(nil)
+				rs.EXPECT().UpdateUser(user).Return(nil)
+				rt.EXPECT().Get(user.ID).Return(TOTP{}, modelErrors.ErrNotFound)
+				rp.EXPECT().GetByUser(user.ID).Return([]Passkey{}, nil)
+				rs.EXPECT().Create(user, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: user}, nil)
+			},
+			returnUser: user,
+			returnKey:  "session",
+		},
+		{
+			name: "second factor required",
+			setupModels: func(rm *MockRepositoryMagicLink, ru *MockRepositoryUser, rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rc *MockRepositoryChallenge, rs *MockRepositorySession, tc *testCase) {
+				rm.EXPECT().Use("token").Return(MagicLink{Token: "token", UserID: user.ID}, nil)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+				rt.EXPECT().Get(user.ID).Return(TOTP{UserID: user.ID, Confirmed: true}, nil)
+				rc.EXPECT().Create(user).Return(Challenge{ID: "challenge", UserID: user.ID}, nil)
+			},
+			returnKey:   "challenge",
+			returnError: ErrSecondFactorRequired,
+		},
+		{
+			name: "enterprise connection user",
+			setupModels: func(rm *MockRepositoryMagicLink, ru *MockRepositoryUser, rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rc *MockRepositoryChallenge, rs *MockRepositorySession, tc *testCase) {
+				rm.EXPECT().Use("token").Return(MagicLink{Token: "token", UserID: employee.ID}, nil)
+				ru.EXPECT().GetByID(employee.ID).Return(employee, nil)
+			},
+			returnError: ErrSSORequired,
+		},
+		{
+			name: "used or expired link",
+			setupModels: func(rm *MockRepositoryMagicLink, ru *MockRepositoryUser, rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rc *MockRepositoryChallenge, rs *MockRepositorySession, tc *testCase) {
+				rm.EXPECT().Use("token").Return(MagicLink{}, modelErrors.ErrNotFound)
+			},
+			returnError: ErrInvalidToken,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			magicLinkRepository := NewMockRepositoryMagicLink(t)
+			userRepository := NewMockRepositoryUser(t)
+			totpRepository := NewMockRepositoryTOTP(t)
+			passkeyRepository := NewMockRepositoryPasskey(t)
+			challengeRepository := NewMockRepositoryChallenge(t)
+			sessionRepository := NewMockRepositorySession(t)
+
+			// Setup mocks
+			repository.EXPECT().Atomic(mock.Anything).RunAndReturn(func(f func(Repository) error) error {
+				return f(repository)
+			})
+			repository.EXPECT().MagicLink(context.TODO()).Return(magicLinkRepository)
+			repository.EXPECT().User(context.TODO()).Return(userRepository).Maybe()
+			repository.EXPECT().TOTP(context.TODO()).Return(totpRepository).Maybe()
+			repository.EXPECT().Passkey(context.TODO()).Return(passkeyRepository).Maybe()
+			repository.EXPECT().Challenge(context.TODO()).Return(challengeRepository).Maybe()
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository).Maybe()
+			tc.setupModels(magicLinkRepository, userRepository, totpRepository, passkeyRepository, challengeRepository, sessionRepository, &tc)
+
+			// Run
+			domain := NewDomain(repository, config, NewMockNotifier(t))
+			existing, key, err := domain.LogInMagicLink(setup, "token")
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorIs(t, err, tc.returnError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.returnUser, existing)
+			require.Equal(t, tc.returnKey, key)
+		})
+	}
+}
@@ -2,38 +2,2444 @@
 
 package domain
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	json "encoding/json"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // MockDomain is an autogenerated mock type for the Domain type
 type MockDomain struct {
 	mock.Mock
 }
 
-type MockDomain_Expecter struct {
-	mock *mock.Mock
+type MockDomain_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockDomain) EXPECT() *MockDomain_Expecter {
+	return &MockDomain_Expecter{mock: &_m.Mock}
+}
+
+// APIKeys provides a mock function with given fields: setup, token
+func (_m *MockDomain) APIKeys(setup Setup, token string) ([]APIKey, error) {
+	ret := _m.Called(setup, token)
+
+	var r0 []APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, string) ([]APIKey, error)); ok {
+		return rf(setup, token)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string) []APIKey); ok {
+		r0 = rf(setup, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string) error); ok {
+		r1 = rf(setup, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_APIKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'APIKeys'
+type MockDomain_APIKeys_Call struct {
+	*mock.Call
+}
+
+// APIKeys is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+func (_e *MockDomain_Expecter) APIKeys(setup interface{}, token interface{}) *MockDomain_APIKeys_Call {
+	return &MockDomain_APIKeys_Call{Call: _e.mock.On("APIKeys", setup, token)}
+}
+
+func (_c *MockDomain_APIKeys_Call) Run(run func(setup Setup, token string)) *MockDomain_APIKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_APIKeys_Call) Return(keys []APIKey, err error) *MockDomain_APIKeys_Call {
+	_c.Call.Return(keys, err)
+	return _c
+}
+
+func (_c *MockDomain_APIKeys_Call) RunAndReturn(run func(Setup, string) ([]APIKey, error)) *MockDomain_APIKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Authorize provides a mock function with given fields: setup, token, request
+func (_m *MockDomain) Authorize(setup Setup, token string, request AuthorizationRequest) (string, string, error) {
+	ret := _m.Called(setup, token, request)
+
+	var r0 string
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(Setup, string, AuthorizationRequest) (string, string, error)); ok {
+		return rf(setup, token, request)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string, AuthorizationRequest) string); ok {
+		r0 = rf(setup, token, request)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string, AuthorizationRequest) string); ok {
+		r1 = rf(setup, token, request)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(Setup, string, AuthorizationRequest) error); ok {
+		r2 = rf(setup, token, request)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDomain_Authorize_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Authorize'
+type MockDomain_Authorize_Call struct {
+	*mock.Call
+}
+
+// Authorize is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+//   - request AuthorizationRequest
+func (_e *MockDomain_Expecter) Authorize(setup interface{}, token interface{}, request interface{}) *MockDomain_Authorize_Call {
+	return &MockDomain_Authorize_Call{Call: _e.mock.On("Authorize", setup, token, request)}
+}
+
+func (_c *MockDomain_Authorize_Call) Run(run func(setup Setup, token string, request AuthorizationRequest)) *MockDomain_Authorize_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].(AuthorizationRequest))
+	})
+	return _c
+}
+
+func (_c *MockDomain_Authorize_Call) Return(redirect string, consent string, err error) *MockDomain_Authorize_Call {
+	_c.Call.Return(redirect, consent, err)
+	return _c
+}
+
+func (_c *MockDomain_Authorize_Call) RunAndReturn(run func(Setup, string, AuthorizationRequest) (string, string, error)) *MockDomain_Authorize_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AuthorizeDevice provides a mock function with given fields: setup, clientId, clientSecret, scope
+func (_m *MockDomain) AuthorizeDevice(setup Setup, clientId string, clientSecret string, scope string) (DeviceAuthorization, error) {
+	ret := _m.Called(setup, clientId, clientSecret, scope)
+
+	var r0 DeviceAuthorization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, string, string, string) (DeviceAuthorization, error)); ok {
+		return rf(setup, clientId, clientSecret, scope)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string, string, string) DeviceAuthorization); ok {
+		r0 = rf(setup, clientId, clientSecret, scope)
+	} else {
+		r0 = ret.Get(0).(DeviceAuthorization)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string, string, string) error); ok {
+		r1 = rf(setup, clientId, clientSecret, scope)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_AuthorizeDevice_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AuthorizeDevice'
+type MockDomain_AuthorizeDevice_Call struct {
+	*mock.Call
+}
+
+// AuthorizeDevice is a helper method to define mock.On call
+//   - setup Setup
+//   - clientId string
+//   - clientSecret string
+//   - scope string
+func (_e *MockDomain_Expecter) AuthorizeDevice(setup interface{}, clientId interface{}, clientSecret interface{}, scope interface{}) *MockDomain_AuthorizeDevice_Call {
+	return &MockDomain_AuthorizeDevice_Call{Call: _e.mock.On("AuthorizeDevice", setup, clientId, clientSecret, scope)}
+}
+
+func (_c *MockDomain_AuthorizeDevice_Call) Run(run func(setup Setup, clientId string, clientSecret string, scope string)) *MockDomain_AuthorizeDevice_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_AuthorizeDevice_Call) Return(device DeviceAuthorization, err error) *MockDomain_AuthorizeDevice_Call {
+	_c.Call.Return(device, err)
+	return _c
+}
+
+func (_c *MockDomain_AuthorizeDevice_Call) RunAndReturn(run func(Setup, string, string, string) (DeviceAuthorization, error)) *MockDomain_AuthorizeDevice_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// BeginOIDCLogIn provides a mock function with given fields: setup, provider, token
+func (_m *MockDomain) BeginOIDCLogIn(setup Setup, provider string, token string) (string, string, error) {
+	ret := _m.Called(setup, provider, token)
+
+	var r0 string
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(Setup, string, string) (string, string, error)); ok {
+		return rf(setup, provider, token)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string, string) string); ok {
+		r0 = rf(setup, provider, token)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string, string) string); ok {
+		r1 = rf(setup, provider, token)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(Setup, string, string) error); ok {
+		r2 = rf(setup, provider, token)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDomain_BeginOIDCLogIn_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BeginOIDCLogIn'
+type MockDomain_BeginOIDCLogIn_Call struct {
+	*mock.Call
+}
+
+// BeginOIDCLogIn is a helper method to define mock.On call
+//   - setup Setup
+//   - provider string
+//   - token string
+func (_e *MockDomain_Expecter) BeginOIDCLogIn(setup interface{}, provider interface{}, token interface{}) *MockDomain_BeginOIDCLogIn_Call {
+	return &MockDomain_BeginOIDCLogIn_Call{Call: _e.mock.On("BeginOIDCLogIn", setup, provider, token)}
+}
+
+func (_c *MockDomain_BeginOIDCLogIn_Call) Run(run func(setup Setup, provider string, token string)) *MockDomain_BeginOIDCLogIn_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_BeginOIDCLogIn_Call) Return(authURL string, state string, err error) *MockDomain_BeginOIDCLogIn_Call {
+	_c.Call.Return(authURL, state, err)
+	return _c
+}
+
+func (_c *MockDomain_BeginOIDCLogIn_Call) RunAndReturn(run func(Setup, string, string) (string, string, error)) *MockDomain_BeginOIDCLogIn_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// BeginPasskeyLogIn provides a mock function with given fields: setup, challenge
+func (_m *MockDomain) BeginPasskeyLogIn(setup Setup, challenge string) (json.RawMessage, string, error) {
+	ret := _m.Called(setup, challenge)
+
+	var r0 json.RawMessage
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(Setup, string) (json.RawMessage, string, error)); ok {
+		return rf(setup, challenge)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string) json.RawMessage); ok {
+		r0 = rf(setup, challenge)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(json.RawMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string) string); ok {
+		r1 = rf(setup, challenge)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(Setup, string) error); ok {
+		r2 = rf(setup, challenge)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDomain_BeginPasskeyLogIn_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BeginPasskeyLogIn'
+type MockDomain_BeginPasskeyLogIn_Call struct {
+	*mock.Call
+}
+
+// BeginPasskeyLogIn is a helper method to define mock.On call
+//   - setup Setup
+//   - challenge string
+func (_e *MockDomain_Expecter) BeginPasskeyLogIn(setup interface{}, challenge interface{}) *MockDomain_BeginPasskeyLogIn_Call {
+	return &MockDomain_BeginPasskeyLogIn_Call{Call: _e.mock.On("BeginPasskeyLogIn", setup, challenge)}
+}
+
+func (_c *MockDomain_BeginPasskeyLogIn_Call) Run(run func(setup Setup, challenge string)) *MockDomain_BeginPasskeyLogIn_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_BeginPasskeyLogIn_Call) Return(options json.RawMessage, ceremony string, err error) *MockDomain_BeginPasskeyLogIn_Call {
+	_c.Call.Return(options, ceremony, err)
+	return _c
+}
+
+func (_c *MockDomain_BeginPasskeyLogIn_Call) RunAndReturn(run func(Setup, string) (json.RawMessage, string, error)) *MockDomain_BeginPasskeyLogIn_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// BeginPasskeyRegistration provides a mock function with given fields: setup, token
+func (_m *MockDomain) BeginPasskeyRegistration(setup Setup, token string) (json.RawMessage, string, error) {
+	ret := _m.Called(setup, token)
+
+	var r0 json.RawMessage
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(Setup, string) (json.RawMessage, string, error)); ok {
+		return rf(setup, token)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string) json.RawMessage); ok {
+		r0 = rf(setup, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(json.RawMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string) string); ok {
+		r1 = rf(setup, token)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(Setup, string) error); ok {
+		r2 = rf(setup, token)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDomain_BeginPasskeyRegistration_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BeginPasskeyRegistration'
+type MockDomain_BeginPasskeyRegistration_Call struct {
+	*mock.Call
+}
+
+// BeginPasskeyRegistration is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+func (_e *MockDomain_Expecter) BeginPasskeyRegistration(setup interface{}, token interface{}) *MockDomain_BeginPasskeyRegistration_Call {
+	return &MockDomain_BeginPasskeyRegistration_Call{Call: _e.mock.On("BeginPasskeyRegistration", setup, token)}
+}
+
+func (_c *MockDomain_BeginPasskeyRegistration_Call) Run(run func(setup Setup, token string)) *MockDomain_BeginPasskeyRegistration_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_BeginPasskeyRegistration_Call) Return(options json.RawMessage, ceremony string, err error) *MockDomain_BeginPasskeyRegistration_Call {
+	_c.Call.Return(options, ceremony, err)
+	return _c
+}
+
+func (_c *MockDomain_BeginPasskeyRegistration_Call) RunAndReturn(run func(Setup, string) (json.RawMessage, string, error)) *MockDomain_BeginPasskeyRegistration_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// BeginSAMLLogIn provides a mock function with given fields: setup, provider, token
+func (_m *MockDomain) BeginSAMLLogIn(setup Setup, provider string, token string) (string, string, error) {
+	ret := _m.Called(setup, provider, token)
+
+	var r0 string
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(Setup, string, string) (string, string, error)); ok {
+		return rf(setup, provider, token)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string, string) string); ok {
+		r0 = rf(setup, provider, token)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string, string) string); ok {
+		r1 = rf(setup, provider, token)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(Setup, string, string) error); ok {
+		r2 = rf(setup, provider, token)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDomain_BeginSAMLLogIn_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BeginSAMLLogIn'
+type MockDomain_BeginSAMLLogIn_Call struct {
+	*mock.Call
+}
+
+// BeginSAMLLogIn is a helper method to define mock.On call
+//   - setup Setup
+//   - provider string
+//   - token string
+func (_e *MockDomain_Expecter) BeginSAMLLogIn(setup interface{}, provider interface{}, token interface{}) *MockDomain_BeginSAMLLogIn_Call {
+	return &MockDomain_BeginSAMLLogIn_Call{Call: _e.mock.On("BeginSAMLLogIn", setup, provider, token)}
+}
+
+func (_c *MockDomain_BeginSAMLLogIn_Call) Run(run func(setup Setup, provider string, token string)) *MockDomain_BeginSAMLLogIn_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_BeginSAMLLogIn_Call) Return(redirect string, relayState string, err error) *MockDomain_BeginSAMLLogIn_Call {
+	_c.Call.Return(redirect, relayState, err)
+	return _c
+}
+
+func (_c *MockDomain_BeginSAMLLogIn_Call) RunAndReturn(run func(Setup, string, string) (string, string, error)) *MockDomain_BeginSAMLLogIn_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ConfirmTOTP provides a mock function with given fields: setup, token, code
+func (_m *MockDomain) ConfirmTOTP(setup Setup, token string, code string) ([]string, error) {
+	ret := _m.Called(setup, token, code)
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, string, string) ([]string, error)); ok {
+		return rf(setup, token, code)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string, string) []string); ok {
+		r0 = rf(setup, token, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string, string) error); ok {
+		r1 = rf(setup, token, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_ConfirmTOTP_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConfirmTOTP'
+type MockDomain_ConfirmTOTP_Call struct {
+	*mock.Call
+}
+
+// ConfirmTOTP is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+//   - code string
+func (_e *MockDomain_Expecter) ConfirmTOTP(setup interface{}, token interface{}, code interface{}) *MockDomain_ConfirmTOTP_Call {
+	return &MockDomain_ConfirmTOTP_Call{Call: _e.mock.On("ConfirmTOTP", setup, token, code)}
+}
+
+func (_c *MockDomain_ConfirmTOTP_Call) Run(run func(setup Setup, token string, code string)) *MockDomain_ConfirmTOTP_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_ConfirmTOTP_Call) Return(recoveryCodes []string, err error) *MockDomain_ConfirmTOTP_Call {
+	_c.Call.Return(recoveryCodes, err)
+	return _c
+}
+
+func (_c *MockDomain_ConfirmTOTP_Call) RunAndReturn(run func(Setup, string, string) ([]string, error)) *MockDomain_ConfirmTOTP_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Consent provides a mock function with given fields: setup, token, consent, approve
+func (_m *MockDomain) Consent(setup Setup, token string, consent string, approve bool) (string, error) {
+	ret := _m.Called(setup, token, consent, approve)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, string, string, bool) (string, error)); ok {
+		return rf(setup, token, consent, approve)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string, string, bool) string); ok {
+		r0 = rf(setup, token, consent, approve)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string, string, bool) error); ok {
+		r1 = rf(setup, token, consent, approve)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_Consent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Consent'
+type MockDomain_Consent_Call struct {
+	*mock.Call
+}
+
+// Consent is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+//   - consent string
+//   - approve bool
+func (_e *MockDomain_Expecter) Consent(setup interface{}, token interface{}, consent interface{}, approve interface{}) *MockDomain_Consent_Call {
+	return &MockDomain_Consent_Call{Call: _e.mock.On("Consent", setup, token, consent, approve)}
+}
+
+func (_c *MockDomain_Consent_Call) Run(run func(setup Setup, token string, consent string, approve bool)) *MockDomain_Consent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].(string), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *MockDomain_Consent_Call) Return(redirect string, err error) *MockDomain_Consent_Call {
+	_c.Call.Return(redirect, err)
+	return _c
+}
+
+func (_c *MockDomain_Consent_Call) RunAndReturn(run func(Setup, string, string, bool) (string, error)) *MockDomain_Consent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateAPIKey provides a mock function with given fields: setup, token, key
+func (_m *MockDomain) CreateAPIKey(setup Setup, token string, key APIKey) (APIKey, string, error) {
+	ret := _m.Called(setup, token, key)
+
+	var r0 APIKey
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(Setup, string, APIKey) (APIKey, string, error)); ok {
+		return rf(setup, token, key)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string, APIKey) APIKey); ok {
+		r0 = rf(setup, token, key)
+	} else {
+		r0 = ret.Get(0).(APIKey)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string, APIKey) string); ok {
+		r1 = rf(setup, token, key)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(Setup, string, APIKey) error); ok {
+		r2 = rf(setup, token, key)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDomain_CreateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAPIKey'
+type MockDomain_CreateAPIKey_Call struct {
+	*mock.Call
+}
+
+// CreateAPIKey is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+//   - key APIKey
+func (_e *MockDomain_Expecter) CreateAPIKey(setup interface{}, token interface{}, key interface{}) *MockDomain_CreateAPIKey_Call {
+	return &MockDomain_CreateAPIKey_Call{Call: _e.mock.On("CreateAPIKey", setup, token, key)}
+}
+
+func (_c *MockDomain_CreateAPIKey_Call) Run(run func(setup Setup, token string, key APIKey)) *MockDomain_CreateAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].(APIKey))
+	})
+	return _c
+}
+
+func (_c *MockDomain_CreateAPIKey_Call) Return(created APIKey, secret string, err error) *MockDomain_CreateAPIKey_Call {
+	_c.Call.Return(created, secret, err)
+	return _c
+}
+
+func (_c *MockDomain_CreateAPIKey_Call) RunAndReturn(run func(Setup, string, APIKey) (APIKey, string, error)) *MockDomain_CreateAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateOAuthClient provides a mock function with given fields: setup, client, public
+func (_m *MockDomain) CreateOAuthClient(setup Setup, client OAuthClient, public bool) (OAuthClient, string, error) {
+	ret := _m.Called(setup, client, public)
+
+	var r0 OAuthClient
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(Setup, OAuthClient, bool) (OAuthClient, string, error)); ok {
+		return rf(setup, client, public)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, OAuthClient, bool) OAuthClient); ok {
+		r0 = rf(setup, client, public)
+	} else {
+		r0 = ret.Get(0).(OAuthClient)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, OAuthClient, bool) string); ok {
+		r1 = rf(setup, client, public)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(Setup, OAuthClient, bool) error); ok {
+		r2 = rf(setup, client, public)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDomain_CreateOAuthClient_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateOAuthClient'
+type MockDomain_CreateOAuthClient_Call struct {
+	*mock.Call
+}
+
+// CreateOAuthClient is a helper method to define mock.On call
+//   - setup Setup
+//   - client OAuthClient
+//   - public bool
+func (_e *MockDomain_Expecter) CreateOAuthClient(setup interface{}, client interface{}, public interface{}) *MockDomain_CreateOAuthClient_Call {
+	return &MockDomain_CreateOAuthClient_Call{Call: _e.mock.On("CreateOAuthClient", setup, client, public)}
+}
+
+func (_c *MockDomain_CreateOAuthClient_Call) Run(run func(setup Setup, client OAuthClient, public bool)) *MockDomain_CreateOAuthClient_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(OAuthClient), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *MockDomain_CreateOAuthClient_Call) Return(created OAuthClient, secret string, err error) *MockDomain_CreateOAuthClient_Call {
+	_c.Call.Return(created, secret, err)
+	return _c
+}
+
+func (_c *MockDomain_CreateOAuthClient_Call) RunAndReturn(run func(Setup, OAuthClient, bool) (OAuthClient, string, error)) *MockDomain_CreateOAuthClient_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: setup, user
+func (_m *MockDomain) Delete(setup Setup, user User) (bool, error) {
+	ret := _m.Called(setup, user)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, User) (bool, error)); ok {
+		return rf(setup, user)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, User) bool); ok {
+		r0 = rf(setup, user)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, User) error); ok {
+		r1 = rf(setup, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockDomain_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - setup Setup
+//   - user User
+func (_e *MockDomain_Expecter) Delete(setup interface{}, user interface{}) *MockDomain_Delete_Call {
+	return &MockDomain_Delete_Call{Call: _e.mock.On("Delete", setup, user)}
+}
+
+func (_c *MockDomain_Delete_Call) Run(run func(setup Setup, user User)) *MockDomain_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(User))
+	})
+	return _c
+}
+
+func (_c *MockDomain_Delete_Call) Return(deleted bool, err error) *MockDomain_Delete_Call {
+	_c.Call.Return(deleted, err)
+	return _c
+}
+
+func (_c *MockDomain_Delete_Call) RunAndReturn(run func(Setup, User) (bool, error)) *MockDomain_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeviceVerification provides a mock function with given fields: setup, token, userCode
+func (_m *MockDomain) DeviceVerification(setup Setup, token string, userCode string) (DeviceAuthorization, error) {
+	ret := _m.Called(setup, token, userCode)
+
+	var r0 DeviceAuthorization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, string, string) (DeviceAuthorization, error)); ok {
+		return rf(setup, token, userCode)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string, string) DeviceAuthorization); ok {
+		r0 = rf(setup, token, userCode)
+	} else {
+		r0 = ret.Get(0).(DeviceAuthorization)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string, string) error); ok {
+		r1 = rf(setup, token, userCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_DeviceVerification_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeviceVerification'
+type MockDomain_DeviceVerification_Call struct {
+	*mock.Call
+}
+
+// DeviceVerification is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+//   - userCode string
+func (_e *MockDomain_Expecter) DeviceVerification(setup interface{}, token interface{}, userCode interface{}) *MockDomain_DeviceVerification_Call {
+	return &MockDomain_DeviceVerification_Call{Call: _e.mock.On("DeviceVerification", setup, token, userCode)}
+}
+
+func (_c *MockDomain_DeviceVerification_Call) Run(run func(setup Setup, token string, userCode string)) *MockDomain_DeviceVerification_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_DeviceVerification_Call) Return(device DeviceAuthorization, err error) *MockDomain_DeviceVerification_Call {
+	_c.Call.Return(device, err)
+	return _c
+}
+
+func (_c *MockDomain_DeviceVerification_Call) RunAndReturn(run func(Setup, string, string) (DeviceAuthorization, error)) *MockDomain_DeviceVerification_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DiscoverLogIn provides a mock function with given fields: setup, user
+func (_m *MockDomain) DiscoverLogIn(setup Setup, user User) (LogInMethods, error) {
+	ret := _m.Called(setup, user)
+
+	var r0 LogInMethods
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, User) (LogInMethods, error)); ok {
+		return rf(setup, user)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, User) LogInMethods); ok {
+		r0 = rf(setup, user)
+	} else {
+		r0 = ret.Get(0).(LogInMethods)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, User) error); ok {
+		r1 = rf(setup, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_DiscoverLogIn_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DiscoverLogIn'
+type MockDomain_DiscoverLogIn_Call struct {
+	*mock.Call
+}
+
+// DiscoverLogIn is a helper method to define mock.On call
+//   - setup Setup
+//   - user User
+func (_e *MockDomain_Expecter) DiscoverLogIn(setup interface{}, user interface{}) *MockDomain_DiscoverLogIn_Call {
+	return &MockDomain_DiscoverLogIn_Call{Call: _e.mock.On("DiscoverLogIn", setup, user)}
+}
+
+func (_c *MockDomain_DiscoverLogIn_Call) Run(run func(setup Setup, user User)) *MockDomain_DiscoverLogIn_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(User))
+	})
+	return _c
+}
+
+func (_c *MockDomain_DiscoverLogIn_Call) Return(methods LogInMethods, err error) *MockDomain_DiscoverLogIn_Call {
+	_c.Call.Return(methods, err)
+	return _c
+}
+
+func (_c *MockDomain_DiscoverLogIn_Call) RunAndReturn(run func(Setup, User) (LogInMethods, error)) *MockDomain_DiscoverLogIn_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EnrollTOTP provides a mock function with given fields: setup, token
+func (_m *MockDomain) EnrollTOTP(setup Setup, token string) (string, string, error) {
+	ret := _m.Called(setup, token)
+
+	var r0 string
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(Setup, string) (string, string, error)); ok {
+		return rf(setup, token)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string) string); ok {
+		r0 = rf(setup, token)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string) string); ok {
+		r1 = rf(setup, token)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(Setup, string) error); ok {
+		r2 = rf(setup, token)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDomain_EnrollTOTP_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EnrollTOTP'
+type MockDomain_EnrollTOTP_Call struct {
+	*mock.Call
+}
+
+// EnrollTOTP is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+func (_e *MockDomain_Expecter) EnrollTOTP(setup interface{}, token interface{}) *MockDomain_EnrollTOTP_Call {
+	return &MockDomain_EnrollTOTP_Call{Call: _e.mock.On("EnrollTOTP", setup, token)}
+}
+
+func (_c *MockDomain_EnrollTOTP_Call) Run(run func(setup Setup, token string)) *MockDomain_EnrollTOTP_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_EnrollTOTP_Call) Return(secret string, uri string, err error) *MockDomain_EnrollTOTP_Call {
+	_c.Call.Return(secret, uri, err)
+	return _c
+}
+
+func (_c *MockDomain_EnrollTOTP_Call) RunAndReturn(run func(Setup, string) (string, string, error)) *MockDomain_EnrollTOTP_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExchangeSession provides a mock function with given fields: setup, sessionKey
+func (_m *MockDomain) ExchangeSession(setup Setup, sessionKey string) (SessionTokens, error) {
+	ret := _m.Called(setup, sessionKey)
+
+	var r0 SessionTokens
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, string) (SessionTokens, error)); ok {
+		return rf(setup, sessionKey)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string) SessionTokens); ok {
+		r0 = rf(setup, sessionKey)
+	} else {
+		r0 = ret.Get(0).(SessionTokens)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string) error); ok {
+		r1 = rf(setup, sessionKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_ExchangeSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExchangeSession'
+type MockDomain_ExchangeSession_Call struct {
+	*mock.Call
+}
+
+// ExchangeSession is a helper method to define mock.On call
+//   - setup Setup
+//   - sessionKey string
+func (_e *MockDomain_Expecter) ExchangeSession(setup interface{}, sessionKey interface{}) *MockDomain_ExchangeSession_Call {
+	return &MockDomain_ExchangeSession_Call{Call: _e.mock.On("ExchangeSession", setup, sessionKey)}
+}
+
+func (_c *MockDomain_ExchangeSession_Call) Run(run func(setup Setup, sessionKey string)) *MockDomain_ExchangeSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_ExchangeSession_Call) Return(tokens SessionTokens, err error) *MockDomain_ExchangeSession_Call {
+	_c.Call.Return(tokens, err)
+	return _c
+}
+
+func (_c *MockDomain_ExchangeSession_Call) RunAndReturn(run func(Setup, string) (SessionTokens, error)) *MockDomain_ExchangeSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FinishOIDCLogIn provides a mock function with given fields: setup, provider, state, code
+func (_m *MockDomain) FinishOIDCLogIn(setup Setup, provider string, state string, code string) (User, string, error) {
+	ret := _m.Called(setup, provider, state, code)
+
+	var r0 User
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(Setup, string, string, string) (User, string, error)); ok {
+		return rf(setup, provider, state, code)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string, string, string) User); ok {
+		r0 = rf(setup, provider, state, code)
+	} else {
+		r0 = ret.Get(0).(User)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string, string, string) string); ok {
+		r1 = rf(setup, provider, state, code)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(Setup, string, string, string) error); ok {
+		r2 = rf(setup, provider, state, code)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDomain_FinishOIDCLogIn_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FinishOIDCLogIn'
+type MockDomain_FinishOIDCLogIn_Call struct {
+	*mock.Call
+}
+
+// FinishOIDCLogIn is a helper method to define mock.On call
+//   - setup Setup
+//   - provider string
+//   - state string
+//   - code string
+func (_e *MockDomain_Expecter) FinishOIDCLogIn(setup interface{}, provider interface{}, state interface{}, code interface{}) *MockDomain_FinishOIDCLogIn_Call {
+	return &MockDomain_FinishOIDCLogIn_Call{Call: _e.mock.On("FinishOIDCLogIn", setup, provider, state, code)}
+}
+
+func (_c *MockDomain_FinishOIDCLogIn_Call) Run(run func(setup Setup, provider string, state string, code string)) *MockDomain_FinishOIDCLogIn_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_FinishOIDCLogIn_Call) Return(existing User, sessionKey string, err error) *MockDomain_FinishOIDCLogIn_Call {
+	_c.Call.Return(existing, sessionKey, err)
+	return _c
+}
+
+func (_c *MockDomain_FinishOIDCLogIn_Call) RunAndReturn(run func(Setup, string, string, string) (User, string, error)) *MockDomain_FinishOIDCLogIn_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FinishPasskeyLogIn provides a mock function with given fields: setup, ceremony, response
+func (_m *MockDomain) FinishPasskeyLogIn(setup Setup, ceremony string, response []byte) (User, string, error) {
+	ret := _m.Called(setup, ceremony, response)
+
+	var r0 User
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(Setup, string, []byte) (User, string, error)); ok {
+		return rf(setup, ceremony, response)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string, []byte) User); ok {
+		r0 = rf(setup, ceremony, response)
+	} else {
+		r0 = ret.Get(0).(User)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string, []byte) string); ok {
+		r1 = rf(setup, ceremony, response)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(Setup, string, []byte) error); ok {
+		r2 = rf(setup, ceremony, response)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDomain_FinishPasskeyLogIn_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FinishPasskeyLogIn'
+type MockDomain_FinishPasskeyLogIn_Call struct {
+	*mock.Call
+}
+
+// FinishPasskeyLogIn is a helper method to define mock.On call
+//   - setup Setup
+//   - ceremony string
+//   - response []byte
+func (_e *MockDomain_Expecter) FinishPasskeyLogIn(setup interface{}, ceremony interface{}, response interface{}) *MockDomain_FinishPasskeyLogIn_Call {
+	return &MockDomain_FinishPasskeyLogIn_Call{Call: _e.mock.On("FinishPasskeyLogIn", setup, ceremony, response)}
+}
+
+func (_c *MockDomain_FinishPasskeyLogIn_Call) Run(run func(setup Setup, ceremony string, response []byte)) *MockDomain_FinishPasskeyLogIn_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockDomain_FinishPasskeyLogIn_Call) Return(existing User, sessionKey string, err error) *MockDomain_FinishPasskeyLogIn_Call {
+	_c.Call.Return(existing, sessionKey, err)
+	return _c
+}
+
+func (_c *MockDomain_FinishPasskeyLogIn_Call) RunAndReturn(run func(Setup, string, []byte) (User, string, error)) *MockDomain_FinishPasskeyLogIn_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FinishPasskeyRegistration provides a mock function with given fields: setup, token, ceremony, response
+func (_m *MockDomain) FinishPasskeyRegistration(setup Setup, token string, ceremony string, response []byte) ([]string, error) {
+	ret := _m.Called(setup, token, ceremony, response)
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, string, string, []byte) ([]string, error)); ok {
+		return rf(setup, token, ceremony, response)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string, string, []byte) []string); ok {
+		r0 = rf(setup, token, ceremony, response)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string, string, []byte) error); ok {
+		r1 = rf(setup, token, ceremony, response)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_FinishPasskeyRegistration_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FinishPasskeyRegistration'
+type MockDomain_FinishPasskeyRegistration_Call struct {
+	*mock.Call
+}
+
+// FinishPasskeyRegistration is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+//   - ceremony string
+//   - response []byte
+func (_e *MockDomain_Expecter) FinishPasskeyRegistration(setup interface{}, token interface{}, ceremony interface{}, response interface{}) *MockDomain_FinishPasskeyRegistration_Call {
+	return &MockDomain_FinishPasskeyRegistration_Call{Call: _e.mock.On("FinishPasskeyRegistration", setup, token, ceremony, response)}
+}
+
+func (_c *MockDomain_FinishPasskeyRegistration_Call) Run(run func(setup Setup, token string, ceremony string, response []byte)) *MockDomain_FinishPasskeyRegistration_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].(string), args[3].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockDomain_FinishPasskeyRegistration_Call) Return(recoveryCodes []string, err error) *MockDomain_FinishPasskeyRegistration_Call {
+	_c.Call.Return(recoveryCodes, err)
+	return _c
+}
+
+func (_c *MockDomain_FinishPasskeyRegistration_Call) RunAndReturn(run func(Setup, string, string, []byte) ([]string, error)) *MockDomain_FinishPasskeyRegistration_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FinishSAMLLogIn provides a mock function with given fields: setup, provider, relayState, response
+func (_m *MockDomain) FinishSAMLLogIn(setup Setup, provider string, relayState string, response string) (User, string, error) {
+	ret := _m.Called(setup, provider, relayState, response)
+
+	var r0 User
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(Setup, string, string, string) (User, string, error)); ok {
+		return rf(setup, provider, relayState, response)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string, string, string) User); ok {
+		r0 = rf(setup, provider, relayState, response)
+	} else {
+		r0 = ret.Get(0).(User)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string, string, string) string); ok {
+		r1 = rf(setup, provider, relayState, response)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(Setup, string, string, string) error); ok {
+		r2 = rf(setup, provider, relayState, response)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDomain_FinishSAMLLogIn_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FinishSAMLLogIn'
+type MockDomain_FinishSAMLLogIn_Call struct {
+	*mock.Call
+}
+
+// FinishSAMLLogIn is a helper method to define mock.On call
+//   - setup Setup
+//   - provider string
+//   - relayState string
+//   - response string
+func (_e *MockDomain_Expecter) FinishSAMLLogIn(setup interface{}, provider interface{}, relayState interface{}, response interface{}) *MockDomain_FinishSAMLLogIn_Call {
+	return &MockDomain_FinishSAMLLogIn_Call{Call: _e.mock.On("FinishSAMLLogIn", setup, provider, relayState, response)}
+}
+
+func (_c *MockDomain_FinishSAMLLogIn_Call) Run(run func(setup Setup, provider string, relayState string, response string)) *MockDomain_FinishSAMLLogIn_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_FinishSAMLLogIn_Call) Return(existing User, sessionKey string, err error) *MockDomain_FinishSAMLLogIn_Call {
+	_c.Call.Return(existing, sessionKey, err)
+	return _c
+}
+
+func (_c *MockDomain_FinishSAMLLogIn_Call) RunAndReturn(run func(Setup, string, string, string) (User, string, error)) *MockDomain_FinishSAMLLogIn_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ForwardAuth provides a mock function with given fields: setup, token, requestPath
+func (_m *MockDomain) ForwardAuth(setup Setup, token string, requestPath string) (User, error) {
+	ret := _m.Called(setup, token, requestPath)
+
+	var r0 User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, string, string) (User, error)); ok {
+		return rf(setup, token, requestPath)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string, string) User); ok {
+		r0 = rf(setup, token, requestPath)
+	} else {
+		r0 = ret.Get(0).(User)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string, string) error); ok {
+		r1 = rf(setup, token, requestPath)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_ForwardAuth_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ForwardAuth'
+type MockDomain_ForwardAuth_Call struct {
+	*mock.Call
+}
+
+// ForwardAuth is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+//   - requestPath string
+func (_e *MockDomain_Expecter) ForwardAuth(setup interface{}, token interface{}, requestPath interface{}) *MockDomain_ForwardAuth_Call {
+	return &MockDomain_ForwardAuth_Call{Call: _e.mock.On("ForwardAuth", setup, token, requestPath)}
+}
+
+func (_c *MockDomain_ForwardAuth_Call) Run(run func(setup Setup, token string, requestPath string)) *MockDomain_ForwardAuth_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_ForwardAuth_Call) Return(user User, err error) *MockDomain_ForwardAuth_Call {
+	_c.Call.Return(user, err)
+	return _c
+}
+
+func (_c *MockDomain_ForwardAuth_Call) RunAndReturn(run func(Setup, string, string) (User, error)) *MockDomain_ForwardAuth_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Identities provides a mock function with given fields: setup, token
+func (_m *MockDomain) Identities(setup Setup, token string) ([]Identity, error) {
+	ret := _m.Called(setup, token)
+
+	var r0 []Identity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, string) ([]Identity, error)); ok {
+		return rf(setup, token)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string) []Identity); ok {
+		r0 = rf(setup, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Identity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string) error); ok {
+		r1 = rf(setup, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_Identities_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Identities'
+type MockDomain_Identities_Call struct {
+	*mock.Call
+}
+
+// Identities is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+func (_e *MockDomain_Expecter) Identities(setup interface{}, token interface{}) *MockDomain_Identities_Call {
+	return &MockDomain_Identities_Call{Call: _e.mock.On("Identities", setup, token)}
+}
+
+func (_c *MockDomain_Identities_Call) Run(run func(setup Setup, token string)) *MockDomain_Identities_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_Identities_Call) Return(identities []Identity, err error) *MockDomain_Identities_Call {
+	_c.Call.Return(identities, err)
+	return _c
+}
+
+func (_c *MockDomain_Identities_Call) RunAndReturn(run func(Setup, string) ([]Identity, error)) *MockDomain_Identities_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Introspect provides a mock function with given fields: setup, clientId, clientSecret, token
+func (_m *MockDomain) Introspect(setup Setup, clientId string, clientSecret string, token string) (Introspection, error) {
+	ret := _m.Called(setup, clientId, clientSecret, token)
+
+	var r0 Introspection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, string, string, string) (Introspection, error)); ok {
+		return rf(setup, clientId, clientSecret, token)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string, string, string) Introspection); ok {
+		r0 = rf(setup, clientId, clientSecret, token)
+	} else {
+		r0 = ret.Get(0).(Introspection)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string, string, string) error); ok {
+		r1 = rf(setup, clientId, clientSecret, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_Introspect_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Introspect'
+type MockDomain_Introspect_Call struct {
+	*mock.Call
+}
+
+// Introspect is a helper method to define mock.On call
+//   - setup Setup
+//   - clientId string
+//   - clientSecret string
+//   - token string
+func (_e *MockDomain_Expecter) Introspect(setup interface{}, clientId interface{}, clientSecret interface{}, token interface{}) *MockDomain_Introspect_Call {
+	return &MockDomain_Introspect_Call{Call: _e.mock.On("Introspect", setup, clientId, clientSecret, token)}
+}
+
+func (_c *MockDomain_Introspect_Call) Run(run func(setup Setup, clientId string, clientSecret string, token string)) *MockDomain_Introspect_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_Introspect_Call) Return(introspection Introspection, err error) *MockDomain_Introspect_Call {
+	_c.Call.Return(introspection, err)
+	return _c
+}
+
+func (_c *MockDomain_Introspect_Call) RunAndReturn(run func(Setup, string, string, string) (Introspection, error)) *MockDomain_Introspect_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// JWKS provides a mock function with given fields: setup
+func (_m *MockDomain) JWKS(setup Setup) (json.RawMessage, error) {
+	ret := _m.Called(setup)
+
+	var r0 json.RawMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup) (json.RawMessage, error)); ok {
+		return rf(setup)
+	}
+	if rf, ok := ret.Get(0).(func(Setup) json.RawMessage); ok {
+		r0 = rf(setup)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(json.RawMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup) error); ok {
+		r1 = rf(setup)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_JWKS_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'JWKS'
+type MockDomain_JWKS_Call struct {
+	*mock.Call
+}
+
+// JWKS is a helper method to define mock.On call
+//   - setup Setup
+func (_e *MockDomain_Expecter) JWKS(setup interface{}) *MockDomain_JWKS_Call {
+	return &MockDomain_JWKS_Call{Call: _e.mock.On("JWKS", setup)}
+}
+
+func (_c *MockDomain_JWKS_Call) Run(run func(setup Setup)) *MockDomain_JWKS_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup))
+	})
+	return _c
+}
+
+func (_c *MockDomain_JWKS_Call) Return(keys json.RawMessage, err error) *MockDomain_JWKS_Call {
+	_c.Call.Return(keys, err)
+	return _c
+}
+
+func (_c *MockDomain_JWKS_Call) RunAndReturn(run func(Setup) (json.RawMessage, error)) *MockDomain_JWKS_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LogIn provides a mock function with given fields: setup, user
+func (_m *MockDomain) LogIn(setup Setup, user User) (User, string, error) {
+	ret := _m.Called(setup, user)
+
+	var r0 User
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(Setup, User) (User, string, error)); ok {
+		return rf(setup, user)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, User) User); ok {
+		r0 = rf(setup, user)
+	} else {
+		r0 = ret.Get(0).(User)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, User) string); ok {
+		r1 = rf(setup, user)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(Setup, User) error); ok {
+		r2 = rf(setup, user)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDomain_LogIn_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LogIn'
+type MockDomain_LogIn_Call struct {
+	*mock.Call
+}
+
+// LogIn is a helper method to define mock.On call
+//   - setup Setup
+//   - user User
+func (_e *MockDomain_Expecter) LogIn(setup interface{}, user interface{}) *MockDomain_LogIn_Call {
+	return &MockDomain_LogIn_Call{Call: _e.mock.On("LogIn", setup, user)}
+}
+
+func (_c *MockDomain_LogIn_Call) Run(run func(setup Setup, user User)) *MockDomain_LogIn_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(User))
+	})
+	return _c
+}
+
+func (_c *MockDomain_LogIn_Call) Return(existing User, sessionKey string, err error) *MockDomain_LogIn_Call {
+	_c.Call.Return(existing, sessionKey, err)
+	return _c
+}
+
+func (_c *MockDomain_LogIn_Call) RunAndReturn(run func(Setup, User) (User, string, error)) *MockDomain_LogIn_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LogInMagicLink provides a mock function with given fields: setup, token
+func (_m *MockDomain) LogInMagicLink(setup Setup, token string) (User, string, error) {
+	ret := _m.Called(setup, token)
+
+	var r0 User
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(Setup, string) (User, string, error)); ok {
+		return rf(setup, token)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string) User); ok {
+		r0 = rf(setup, token)
+	} else {
+		r0 = ret.Get(0).(User)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string) string); ok {
+		r1 = rf(setup, token)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(Setup, string) error); ok {
+		r2 = rf(setup, token)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDomain_LogInMagicLink_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LogInMagicLink'
+type MockDomain_LogInMagicLink_Call struct {
+	*mock.Call
+}
+
+// LogInMagicLink is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+func (_e *MockDomain_Expecter) LogInMagicLink(setup interface{}, token interface{}) *MockDomain_LogInMagicLink_Call {
+	return &MockDomain_LogInMagicLink_Call{Call: _e.mock.On("LogInMagicLink", setup, token)}
+}
+
+func (_c *MockDomain_LogInMagicLink_Call) Run(run func(setup Setup, token string)) *MockDomain_LogInMagicLink_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_LogInMagicLink_Call) Return(existing User, sessionKey string, err error) *MockDomain_LogInMagicLink_Call {
+	_c.Call.Return(existing, sessionKey, err)
+	return _c
+}
+
+func (_c *MockDomain_LogInMagicLink_Call) RunAndReturn(run func(Setup, string) (User, string, error)) *MockDomain_LogInMagicLink_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LogInOneTimeCode provides a mock function with given fields: setup, user, code
+func (_m *MockDomain) LogInOneTimeCode(setup Setup, user User, code string) (User, string, error) {
+	ret := _m.Called(setup, user, code)
+
+	var r0 User
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(Setup, User, string) (User, string, error)); ok {
+		return rf(setup, user, code)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, User, string) User); ok {
+		r0 = rf(setup, user, code)
+	} else {
+		r0 = ret.Get(0).(User)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, User, string) string); ok {
+		r1 = rf(setup, user, code)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(Setup, User, string) error); ok {
+		r2 = rf(setup, user, code)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDomain_LogInOneTimeCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LogInOneTimeCode'
+type MockDomain_LogInOneTimeCode_Call struct {
+	*mock.Call
+}
+
+// LogInOneTimeCode is a helper method to define mock.On call
+//   - setup Setup
+//   - user User
+//   - code string
+func (_e *MockDomain_Expecter) LogInOneTimeCode(setup interface{}, user interface{}, code interface{}) *MockDomain_LogInOneTimeCode_Call {
+	return &MockDomain_LogInOneTimeCode_Call{Call: _e.mock.On("LogInOneTimeCode", setup, user, code)}
+}
+
+func (_c *MockDomain_LogInOneTimeCode_Call) Run(run func(setup Setup, user User, code string)) *MockDomain_LogInOneTimeCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(User), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_LogInOneTimeCode_Call) Return(existing User, sessionKey string, err error) *MockDomain_LogInOneTimeCode_Call {
+	_c.Call.Return(existing, sessionKey, err)
+	return _c
+}
+
+func (_c *MockDomain_LogInOneTimeCode_Call) RunAndReturn(run func(Setup, User, string) (User, string, error)) *MockDomain_LogInOneTimeCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LogInRecoveryCode provides a mock function with given fields: setup, challenge, code
+func (_m *MockDomain) LogInRecoveryCode(setup Setup, challenge string, code string) (User, string, error) {
+	ret := _m.Called(setup, challenge, code)
+
+	var r0 User
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(Setup, string, string) (User, string, error)); ok {
+		return rf(setup, challenge, code)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string, string) User); ok {
+		r0 = rf(setup, challenge, code)
+	} else {
+		r0 = ret.Get(0).(User)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string, string) string); ok {
+		r1 = rf(setup, challenge, code)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(Setup, string, string) error); ok {
+		r2 = rf(setup, challenge, code)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDomain_LogInRecoveryCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LogInRecoveryCode'
+type MockDomain_LogInRecoveryCode_Call struct {
+	*mock.Call
+}
+
+// LogInRecoveryCode is a helper method to define mock.On call
+//   - setup Setup
+//   - challenge string
+//   - code string
+func (_e *MockDomain_Expecter) LogInRecoveryCode(setup interface{}, challenge interface{}, code interface{}) *MockDomain_LogInRecoveryCode_Call {
+	return &MockDomain_LogInRecoveryCode_Call{Call: _e.mock.On("LogInRecoveryCode", setup, challenge, code)}
+}
+
+func (_c *MockDomain_LogInRecoveryCode_Call) Run(run func(setup Setup, challenge string, code string)) *MockDomain_LogInRecoveryCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_LogInRecoveryCode_Call) Return(existing User, sessionKey string, err error) *MockDomain_LogInRecoveryCode_Call {
+	_c.Call.Return(existing, sessionKey, err)
+	return _c
+}
+
+func (_c *MockDomain_LogInRecoveryCode_Call) RunAndReturn(run func(Setup, string, string) (User, string, error)) *MockDomain_LogInRecoveryCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LogInTOTP provides a mock function with given fields: setup, challenge, code
+func (_m *MockDomain) LogInTOTP(setup Setup, challenge string, code string) (User, string, error) {
+	ret := _m.Called(setup, challenge, code)
+
+	var r0 User
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(Setup, string, string) (User, string, error)); ok {
+		return rf(setup, challenge, code)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string, string) User); ok {
+		r0 = rf(setup, challenge, code)
+	} else {
+		r0 = ret.Get(0).(User)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string, string) string); ok {
+		r1 = rf(setup, challenge, code)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(Setup, string, string) error); ok {
+		r2 = rf(setup, challenge, code)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDomain_LogInTOTP_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LogInTOTP'
+type MockDomain_LogInTOTP_Call struct {
+	*mock.Call
+}
+
+// LogInTOTP is a helper method to define mock.On call
+//   - setup Setup
+//   - challenge string
+//   - code string
+func (_e *MockDomain_Expecter) LogInTOTP(setup interface{}, challenge interface{}, code interface{}) *MockDomain_LogInTOTP_Call {
+	return &MockDomain_LogInTOTP_Call{Call: _e.mock.On("LogInTOTP", setup, challenge, code)}
+}
+
+func (_c *MockDomain_LogInTOTP_Call) Run(run func(setup Setup, challenge string, code string)) *MockDomain_LogInTOTP_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_LogInTOTP_Call) Return(existing User, sessionKey string, err error) *MockDomain_LogInTOTP_Call {
+	_c.Call.Return(existing, sessionKey, err)
+	return _c
+}
+
+func (_c *MockDomain_LogInTOTP_Call) RunAndReturn(run func(Setup, string, string) (User, string, error)) *MockDomain_LogInTOTP_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Logout provides a mock function with given fields: setup, token
+func (_m *MockDomain) Logout(setup Setup, token string) error {
+	ret := _m.Called(setup, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(Setup, string) error); ok {
+		r0 = rf(setup, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDomain_Logout_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Logout'
+type MockDomain_Logout_Call struct {
+	*mock.Call
+}
+
+// Logout is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+func (_e *MockDomain_Expecter) Logout(setup interface{}, token interface{}) *MockDomain_Logout_Call {
+	return &MockDomain_Logout_Call{Call: _e.mock.On("Logout", setup, token)}
+}
+
+func (_c *MockDomain_Logout_Call) Run(run func(setup Setup, token string)) *MockDomain_Logout_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_Logout_Call) Return(err error) *MockDomain_Logout_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockDomain_Logout_Call) RunAndReturn(run func(Setup, string) error) *MockDomain_Logout_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MagicLinkRequest provides a mock function with given fields: setup, user
+func (_m *MockDomain) MagicLinkRequest(setup Setup, user User) (User, error) {
+	ret := _m.Called(setup, user)
+
+	var r0 User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, User) (User, error)); ok {
+		return rf(setup, user)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, User) User); ok {
+		r0 = rf(setup, user)
+	} else {
+		r0 = ret.Get(0).(User)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, User) error); ok {
+		r1 = rf(setup, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_MagicLinkRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MagicLinkRequest'
+type MockDomain_MagicLinkRequest_Call struct {
+	*mock.Call
+}
+
+// MagicLinkRequest is a helper method to define mock.On call
+//   - setup Setup
+//   - user User
+func (_e *MockDomain_Expecter) MagicLinkRequest(setup interface{}, user interface{}) *MockDomain_MagicLinkRequest_Call {
+	return &MockDomain_MagicLinkRequest_Call{Call: _e.mock.On("MagicLinkRequest", setup, user)}
+}
+
+func (_c *MockDomain_MagicLinkRequest_Call) Run(run func(setup Setup, user User)) *MockDomain_MagicLinkRequest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(User))
+	})
+	return _c
+}
+
+func (_c *MockDomain_MagicLinkRequest_Call) Return(sentTo User, err error) *MockDomain_MagicLinkRequest_Call {
+	_c.Call.Return(sentTo, err)
+	return _c
+}
+
+func (_c *MockDomain_MagicLinkRequest_Call) RunAndReturn(run func(Setup, User) (User, error)) *MockDomain_MagicLinkRequest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OneTimeCodeRequest provides a mock function with given fields: setup, user
+func (_m *MockDomain) OneTimeCodeRequest(setup Setup, user User) (User, error) {
+	ret := _m.Called(setup, user)
+
+	var r0 User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, User) (User, error)); ok {
+		return rf(setup, user)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, User) User); ok {
+		r0 = rf(setup, user)
+	} else {
+		r0 = ret.Get(0).(User)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, User) error); ok {
+		r1 = rf(setup, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_OneTimeCodeRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OneTimeCodeRequest'
+type MockDomain_OneTimeCodeRequest_Call struct {
+	*mock.Call
+}
+
+// OneTimeCodeRequest is a helper method to define mock.On call
+//   - setup Setup
+//   - user User
+func (_e *MockDomain_Expecter) OneTimeCodeRequest(setup interface{}, user interface{}) *MockDomain_OneTimeCodeRequest_Call {
+	return &MockDomain_OneTimeCodeRequest_Call{Call: _e.mock.On("OneTimeCodeRequest", setup, user)}
+}
+
+func (_c *MockDomain_OneTimeCodeRequest_Call) Run(run func(setup Setup, user User)) *MockDomain_OneTimeCodeRequest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(User))
+	})
+	return _c
+}
+
+func (_c *MockDomain_OneTimeCodeRequest_Call) Return(sentTo User, err error) *MockDomain_OneTimeCodeRequest_Call {
+	_c.Call.Return(sentTo, err)
+	return _c
+}
+
+func (_c *MockDomain_OneTimeCodeRequest_Call) RunAndReturn(run func(Setup, User) (User, error)) *MockDomain_OneTimeCodeRequest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecoveryCodesCount provides a mock function with given fields: setup, token
+func (_m *MockDomain) RecoveryCodesCount(setup Setup, token string) (int, error) {
+	ret := _m.Called(setup, token)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, string) (int, error)); ok {
+		return rf(setup, token)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string) int); ok {
+		r0 = rf(setup, token)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string) error); ok {
+		r1 = rf(setup, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_RecoveryCodesCount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecoveryCodesCount'
+type MockDomain_RecoveryCodesCount_Call struct {
+	*mock.Call
+}
+
+// RecoveryCodesCount is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+func (_e *MockDomain_Expecter) RecoveryCodesCount(setup interface{}, token interface{}) *MockDomain_RecoveryCodesCount_Call {
+	return &MockDomain_RecoveryCodesCount_Call{Call: _e.mock.On("RecoveryCodesCount", setup, token)}
+}
+
+func (_c *MockDomain_RecoveryCodesCount_Call) Run(run func(setup Setup, token string)) *MockDomain_RecoveryCodesCount_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_RecoveryCodesCount_Call) Return(remaining int, err error) *MockDomain_RecoveryCodesCount_Call {
+	_c.Call.Return(remaining, err)
+	return _c
+}
+
+func (_c *MockDomain_RecoveryCodesCount_Call) RunAndReturn(run func(Setup, string) (int, error)) *MockDomain_RecoveryCodesCount_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RefreshTokens provides a mock function with given fields: setup, refreshToken
+func (_m *MockDomain) RefreshTokens(setup Setup, refreshToken string) (User, SessionTokens, error) {
+	ret := _m.Called(setup, refreshToken)
+
+	var r0 User
+	var r1 SessionTokens
+	var r2 error
+	if rf, ok := ret.Get(0).(func(Setup, string) (User, SessionTokens, error)); ok {
+		return rf(setup, refreshToken)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string) User); ok {
+		r0 = rf(setup, refreshToken)
+	} else {
+		r0 = ret.Get(0).(User)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string) SessionTokens); ok {
+		r1 = rf(setup, refreshToken)
+	} else {
+		r1 = ret.Get(1).(SessionTokens)
+	}
+
+	if rf, ok := ret.Get(2).(func(Setup, string) error); ok {
+		r2 = rf(setup, refreshToken)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDomain_RefreshTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RefreshTokens'
+type MockDomain_RefreshTokens_Call struct {
+	*mock.Call
+}
+
+// RefreshTokens is a helper method to define mock.On call
+//   - setup Setup
+//   - refreshToken string
+func (_e *MockDomain_Expecter) RefreshTokens(setup interface{}, refreshToken interface{}) *MockDomain_RefreshTokens_Call {
+	return &MockDomain_RefreshTokens_Call{Call: _e.mock.On("RefreshTokens", setup, refreshToken)}
+}
+
+func (_c *MockDomain_RefreshTokens_Call) Run(run func(setup Setup, refreshToken string)) *MockDomain_RefreshTokens_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_RefreshTokens_Call) Return(user User, tokens SessionTokens, err error) *MockDomain_RefreshTokens_Call {
+	_c.Call.Return(user, tokens, err)
+	return _c
+}
+
+func (_c *MockDomain_RefreshTokens_Call) RunAndReturn(run func(Setup, string) (User, SessionTokens, error)) *MockDomain_RefreshTokens_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RegenerateRecoveryCodes provides a mock function with given fields: setup, token
+func (_m *MockDomain) RegenerateRecoveryCodes(setup Setup, token string) ([]string, error) {
+	ret := _m.Called(setup, token)
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, string) ([]string, error)); ok {
+		return rf(setup, token)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string) []string); ok {
+		r0 = rf(setup, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string) error); ok {
+		r1 = rf(setup, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_RegenerateRecoveryCodes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RegenerateRecoveryCodes'
+type MockDomain_RegenerateRecoveryCodes_Call struct {
+	*mock.Call
+}
+
+// RegenerateRecoveryCodes is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+func (_e *MockDomain_Expecter) RegenerateRecoveryCodes(setup interface{}, token interface{}) *MockDomain_RegenerateRecoveryCodes_Call {
+	return &MockDomain_RegenerateRecoveryCodes_Call{Call: _e.mock.On("RegenerateRecoveryCodes", setup, token)}
+}
+
+func (_c *MockDomain_RegenerateRecoveryCodes_Call) Run(run func(setup Setup, token string)) *MockDomain_RegenerateRecoveryCodes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_RegenerateRecoveryCodes_Call) Return(recoveryCodes []string, err error) *MockDomain_RegenerateRecoveryCodes_Call {
+	_c.Call.Return(recoveryCodes, err)
+	return _c
+}
+
+func (_c *MockDomain_RegenerateRecoveryCodes_Call) RunAndReturn(run func(Setup, string) ([]string, error)) *MockDomain_RegenerateRecoveryCodes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResetPasswordRequest provides a mock function with given fields: setup, user
+func (_m *MockDomain) ResetPasswordRequest(setup Setup, user User) (User, error) {
+	ret := _m.Called(setup, user)
+
+	var r0 User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, User) (User, error)); ok {
+		return rf(setup, user)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, User) User); ok {
+		r0 = rf(setup, user)
+	} else {
+		r0 = ret.Get(0).(User)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, User) error); ok {
+		r1 = rf(setup, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_ResetPasswordRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResetPasswordRequest'
+type MockDomain_ResetPasswordRequest_Call struct {
+	*mock.Call
+}
+
+// ResetPasswordRequest is a helper method to define mock.On call
+//   - setup Setup
+//   - user User
+func (_e *MockDomain_Expecter) ResetPasswordRequest(setup interface{}, user interface{}) *MockDomain_ResetPasswordRequest_Call {
+	return &MockDomain_ResetPasswordRequest_Call{Call: _e.mock.On("ResetPasswordRequest", setup, user)}
+}
+
+func (_c *MockDomain_ResetPasswordRequest_Call) Run(run func(setup Setup, user User)) *MockDomain_ResetPasswordRequest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(User))
+	})
+	return _c
+}
+
+func (_c *MockDomain_ResetPasswordRequest_Call) Return(sentTo User, err error) *MockDomain_ResetPasswordRequest_Call {
+	_c.Call.Return(sentTo, err)
+	return _c
+}
+
+func (_c *MockDomain_ResetPasswordRequest_Call) RunAndReturn(run func(Setup, User) (User, error)) *MockDomain_ResetPasswordRequest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeAPIKey provides a mock function with given fields: setup, token, id
+func (_m *MockDomain) RevokeAPIKey(setup Setup, token string, id uint64) error {
+	ret := _m.Called(setup, token, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(Setup, string, uint64) error); ok {
+		r0 = rf(setup, token, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDomain_RevokeAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAPIKey'
+type MockDomain_RevokeAPIKey_Call struct {
+	*mock.Call
+}
+
+// RevokeAPIKey is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+//   - id uint64
+func (_e *MockDomain_Expecter) RevokeAPIKey(setup interface{}, token interface{}, id interface{}) *MockDomain_RevokeAPIKey_Call {
+	return &MockDomain_RevokeAPIKey_Call{Call: _e.mock.On("RevokeAPIKey", setup, token, id)}
+}
+
+func (_c *MockDomain_RevokeAPIKey_Call) Run(run func(setup Setup, token string, id uint64)) *MockDomain_RevokeAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].(uint64))
+	})
+	return _c
+}
+
+func (_c *MockDomain_RevokeAPIKey_Call) Return(err error) *MockDomain_RevokeAPIKey_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockDomain_RevokeAPIKey_Call) RunAndReturn(run func(Setup, string, uint64) error) *MockDomain_RevokeAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeOtherSessions provides a mock function with given fields: setup, token
+func (_m *MockDomain) RevokeOtherSessions(setup Setup, token string) (int, error) {
+	ret := _m.Called(setup, token)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, string) (int, error)); ok {
+		return rf(setup, token)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string) int); ok {
+		r0 = rf(setup, token)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string) error); ok {
+		r1 = rf(setup, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_RevokeOtherSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeOtherSessions'
+type MockDomain_RevokeOtherSessions_Call struct {
+	*mock.Call
+}
+
+// RevokeOtherSessions is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+func (_e *MockDomain_Expecter) RevokeOtherSessions(setup interface{}, token interface{}) *MockDomain_RevokeOtherSessions_Call {
+	return &MockDomain_RevokeOtherSessions_Call{Call: _e.mock.On("RevokeOtherSessions", setup, token)}
+}
+
+func (_c *MockDomain_RevokeOtherSessions_Call) Run(run func(setup Setup, token string)) *MockDomain_RevokeOtherSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_RevokeOtherSessions_Call) Return(revoked int, err error) *MockDomain_RevokeOtherSessions_Call {
+	_c.Call.Return(revoked, err)
+	return _c
+}
+
+func (_c *MockDomain_RevokeOtherSessions_Call) RunAndReturn(run func(Setup, string) (int, error)) *MockDomain_RevokeOtherSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeRefreshToken provides a mock function with given fields: setup, refreshToken
+func (_m *MockDomain) RevokeRefreshToken(setup Setup, refreshToken string) error {
+	ret := _m.Called(setup, refreshToken)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(Setup, string) error); ok {
+		r0 = rf(setup, refreshToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDomain_RevokeRefreshToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeRefreshToken'
+type MockDomain_RevokeRefreshToken_Call struct {
+	*mock.Call
+}
+
+// RevokeRefreshToken is a helper method to define mock.On call
+//   - setup Setup
+//   - refreshToken string
+func (_e *MockDomain_Expecter) RevokeRefreshToken(setup interface{}, refreshToken interface{}) *MockDomain_RevokeRefreshToken_Call {
+	return &MockDomain_RevokeRefreshToken_Call{Call: _e.mock.On("RevokeRefreshToken", setup, refreshToken)}
+}
+
+func (_c *MockDomain_RevokeRefreshToken_Call) Run(run func(setup Setup, refreshToken string)) *MockDomain_RevokeRefreshToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_RevokeRefreshToken_Call) Return(err error) *MockDomain_RevokeRefreshToken_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockDomain_RevokeRefreshToken_Call) RunAndReturn(run func(Setup, string) error) *MockDomain_RevokeRefreshToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeSession provides a mock function with given fields: setup, token, id
+func (_m *MockDomain) RevokeSession(setup Setup, token string, id string) error {
+	ret := _m.Called(setup, token, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(Setup, string, string) error); ok {
+		r0 = rf(setup, token, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDomain_RevokeSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeSession'
+type MockDomain_RevokeSession_Call struct {
+	*mock.Call
+}
+
+// RevokeSession is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+//   - id string
+func (_e *MockDomain_Expecter) RevokeSession(setup interface{}, token interface{}, id interface{}) *MockDomain_RevokeSession_Call {
+	return &MockDomain_RevokeSession_Call{Call: _e.mock.On("RevokeSession", setup, token, id)}
+}
+
+func (_c *MockDomain_RevokeSession_Call) Run(run func(setup Setup, token string, id string)) *MockDomain_RevokeSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_RevokeSession_Call) Return(err error) *MockDomain_RevokeSession_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockDomain_RevokeSession_Call) RunAndReturn(run func(Setup, string, string) error) *MockDomain_RevokeSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RotateSigningKey provides a mock function with given fields: setup
+func (_m *MockDomain) RotateSigningKey(setup Setup) (string, error) {
+	ret := _m.Called(setup)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup) (string, error)); ok {
+		return rf(setup)
+	}
+	if rf, ok := ret.Get(0).(func(Setup) string); ok {
+		r0 = rf(setup)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup) error); ok {
+		r1 = rf(setup)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_RotateSigningKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RotateSigningKey'
+type MockDomain_RotateSigningKey_Call struct {
+	*mock.Call
+}
+
+// RotateSigningKey is a helper method to define mock.On call
+//   - setup Setup
+func (_e *MockDomain_Expecter) RotateSigningKey(setup interface{}) *MockDomain_RotateSigningKey_Call {
+	return &MockDomain_RotateSigningKey_Call{Call: _e.mock.On("RotateSigningKey", setup)}
+}
+
+func (_c *MockDomain_RotateSigningKey_Call) Run(run func(setup Setup)) *MockDomain_RotateSigningKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup))
+	})
+	return _c
+}
+
+func (_c *MockDomain_RotateSigningKey_Call) Return(kid string, err error) *MockDomain_RotateSigningKey_Call {
+	_c.Call.Return(kid, err)
+	return _c
+}
+
+func (_c *MockDomain_RotateSigningKey_Call) RunAndReturn(run func(Setup) (string, error)) *MockDomain_RotateSigningKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SAMLMetadata provides a mock function with given fields: setup, provider
+func (_m *MockDomain) SAMLMetadata(setup Setup, provider string) ([]byte, error) {
+	ret := _m.Called(setup, provider)
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, string) ([]byte, error)); ok {
+		return rf(setup, provider)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string) []byte); ok {
+		r0 = rf(setup, provider)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string) error); ok {
+		r1 = rf(setup, provider)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_SAMLMetadata_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SAMLMetadata'
+type MockDomain_SAMLMetadata_Call struct {
+	*mock.Call
+}
+
+// SAMLMetadata is a helper method to define mock.On call
+//   - setup Setup
+//   - provider string
+func (_e *MockDomain_Expecter) SAMLMetadata(setup interface{}, provider interface{}) *MockDomain_SAMLMetadata_Call {
+	return &MockDomain_SAMLMetadata_Call{Call: _e.mock.On("SAMLMetadata", setup, provider)}
+}
+
+func (_c *MockDomain_SAMLMetadata_Call) Run(run func(setup Setup, provider string)) *MockDomain_SAMLMetadata_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDomain_SAMLMetadata_Call) Return(metadata []byte, err error) *MockDomain_SAMLMetadata_Call {
+	_c.Call.Return(metadata, err)
+	return _c
+}
+
+func (_c *MockDomain_SAMLMetadata_Call) RunAndReturn(run func(Setup, string) ([]byte, error)) *MockDomain_SAMLMetadata_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Session provides a mock function with given fields: setup, token
+func (_m *MockDomain) Session(setup Setup, token string) (User, error) {
+	ret := _m.Called(setup, token)
+
+	var r0 User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, string) (User, error)); ok {
+		return rf(setup, token)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, string) User); ok {
+		r0 = rf(setup, token)
+	} else {
+		r0 = ret.Get(0).(User)
+	}
+
+	if rf, ok := ret.Get(1).(func(Setup, string) error); ok {
+		r1 = rf(setup, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDomain_Session_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Session'
+type MockDomain_Session_Call struct {
+	*mock.Call
 }
 
-func (_m *MockDomain) EXPECT() *MockDomain_Expecter {
-	return &MockDomain_Expecter{mock: &_m.Mock}
+// Session is a helper method to define mock.On call
+//   - setup Setup
+//   - token string
+func (_e *MockDomain_Expecter) Session(setup interface{}, token interface{}) *MockDomain_Session_Call {
+	return &MockDomain_Session_Call{Call: _e.mock.On("Session", setup, token)}
 }
 
-// Delete provides a mock function with given fields: setup, user
-func (_m *MockDomain) Delete(setup Setup, user User) (bool, error) {
-	ret := _m.Called(setup, user)
+func (_c *MockDomain_Session_Call) Run(run func(setup Setup, token string)) *MockDomain_Session_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(string))
+	})
+	return _c
+}
 
-	var r0 bool
+func (_c *MockDomain_Session_Call) Return(user User, err error) *MockDomain_Session_Call {
+	_c.Call.Return(user, err)
+	return _c
+}
+
+func (_c *MockDomain_Session_Call) RunAndReturn(run func(Setup, string) (User, error)) *MockDomain_Session_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Sessions provides a mock function with given fields: setup, token
+func (_m *MockDomain) Sessions(setup Setup, token string) ([]Session, error) {
+	ret := _m.Called(setup, token)
+
+	var r0 []Session
 	var r1 error
-	if rf, ok := ret.Get(0).(func(Setup, User) (bool, error)); ok {
-		return rf(setup, user)
+	if rf, ok := ret.Get(0).(func(Setup, string) ([]Session, error)); ok {
+		return rf(setup, token)
 	}
-	if rf, ok := ret.Get(0).(func(Setup, User) bool); ok {
-		r0 = rf(setup, user)
+	if rf, ok := ret.Get(0).(func(Setup, string) []Session); ok {
+		r0 = rf(setup, token)
 	} else {
-		r0 = ret.Get(0).(bool)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Session)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(Setup, User) error); ok {
-		r1 = rf(setup, user)
+	if rf, ok := ret.Get(1).(func(Setup, string) error); ok {
+		r1 = rf(setup, token)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -41,43 +2447,42 @@ func (_m *MockDomain) Delete(setup Setup, user User) (bool, error) {
 	return r0, r1
 }
 
-// MockDomain_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
-type MockDomain_Delete_Call struct {
+// MockDomain_Sessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Sessions'
+type MockDomain_Sessions_Call struct {
 	*mock.Call
 }
 
-// Delete is a helper method to define mock.On call
+// Sessions is a helper method to define mock.On call
 //   - setup Setup
-//   - user User
-func (_e *MockDomain_Expecter) Delete(setup interface{}, user interface{}) *MockDomain_Delete_Call {
-	return &MockDomain_Delete_Call{Call: _e.mock.On("Delete", setup, user)}
+//   - token string
+func (_e *MockDomain_Expecter) Sessions(setup interface{}, token interface{}) *MockDomain_Sessions_Call {
+	return &MockDomain_Sessions_Call{Call: _e.mock.On("Sessions", setup, token)}
 }
 
-func (_c *MockDomain_Delete_Call) Run(run func(setup Setup, user User)) *MockDomain_Delete_Call {
+func (_c *MockDomain_Sessions_Call) Run(run func(setup Setup, token string)) *MockDomain_Sessions_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(Setup), args[1].(User))
+		run(args[0].(Setup), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockDomain_Delete_Call) Return(deleted bool, err error) *MockDomain_Delete_Call {
-	_c.Call.Return(deleted, err)
+func (_c *MockDomain_Sessions_Call) Return(sessions []Session, err error) *MockDomain_Sessions_Call {
+	_c.Call.Return(sessions, err)
 	return _c
 }
 
-func (_c *MockDomain_Delete_Call) RunAndReturn(run func(Setup, User) (bool, error)) *MockDomain_Delete_Call {
+func (_c *MockDomain_Sessions_Call) RunAndReturn(run func(Setup, string) ([]Session, error)) *MockDomain_Sessions_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// LogIn provides a mock function with given fields: setup, user
-func (_m *MockDomain) LogIn(setup Setup, user User) (User, string, error) {
+// SignUp provides a mock function with given fields: setup, user
+func (_m *MockDomain) SignUp(setup Setup, user User) (User, error) {
 	ret := _m.Called(setup, user)
 
 	var r0 User
-	var r1 string
-	var r2 error
-	if rf, ok := ret.Get(0).(func(Setup, User) (User, string, error)); ok {
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, User) (User, error)); ok {
 		return rf(setup, user)
 	}
 	if rf, ok := ret.Get(0).(func(Setup, User) User); ok {
@@ -86,57 +2491,104 @@ func (_m *MockDomain) LogIn(setup Setup, user User) (User, string, error) {
 		r0 = ret.Get(0).(User)
 	}
 
-	if rf, ok := ret.Get(1).(func(Setup, User) string); ok {
+	if rf, ok := ret.Get(1).(func(Setup, User) error); ok {
 		r1 = rf(setup, user)
 	} else {
-		r1 = ret.Get(1).(string)
+		r1 = ret.Error(1)
 	}
 
-	if rf, ok := ret.Get(2).(func(Setup, User) error); ok {
-		r2 = rf(setup, user)
+	return r0, r1
+}
+
+// MockDomain_SignUp_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SignUp'
+type MockDomain_SignUp_Call struct {
+	*mock.Call
+}
+
+// SignUp is a helper method to define mock.On call
+//   - setup Setup
+//   - user User
+func (_e *MockDomain_Expecter) SignUp(setup interface{}, user interface{}) *MockDomain_SignUp_Call {
+	return &MockDomain_SignUp_Call{Call: _e.mock.On("SignUp", setup, user)}
+}
+
+func (_c *MockDomain_SignUp_Call) Run(run func(setup Setup, user User)) *MockDomain_SignUp_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Setup), args[1].(User))
+	})
+	return _c
+}
+
+func (_c *MockDomain_SignUp_Call) Return(newUser User, err error) *MockDomain_SignUp_Call {
+	_c.Call.Return(newUser, err)
+	return _c
+}
+
+func (_c *MockDomain_SignUp_Call) RunAndReturn(run func(Setup, User) (User, error)) *MockDomain_SignUp_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Token provides a mock function with given fields: setup, request
+func (_m *MockDomain) Token(setup Setup, request TokenRequest) (Tokens, error) {
+	ret := _m.Called(setup, request)
+
+	var r0 Tokens
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Setup, TokenRequest) (Tokens, error)); ok {
+		return rf(setup, request)
+	}
+	if rf, ok := ret.Get(0).(func(Setup, TokenRequest) Tokens); ok {
+		r0 = rf(setup, request)
 	} else {
-		r2 = ret.Error(2)
+		r0 = ret.Get(0).(Tokens)
 	}
 
-	return r0, r1, r2
+	if rf, ok := ret.Get(1).(func(Setup, TokenRequest) error); ok {
+		r1 = rf(setup, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// MockDomain_LogIn_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LogIn'
-type MockDomain_LogIn_Call struct {
+// MockDomain_Token_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Token'
+type MockDomain_Token_Call struct {
 	*mock.Call
 }
 
-// LogIn is a helper method to define mock.On call
+// Token is a helper method to define mock.On call
 //   - setup Setup
-//   - user User
-func (_e *MockDomain_Expecter) LogIn(setup interface{}, user interface{}) *MockDomain_LogIn_Call {
-	return &MockDomain_LogIn_Call{Call: _e.mock.On("LogIn", setup, user)}
+//   - request TokenRequest
+func (_e *MockDomain_Expecter) Token(setup interface{}, request interface{}) *MockDomain_Token_Call {
+	return &MockDomain_Token_Call{Call: _e.mock.On("Token", setup, request)}
 }
 
-func (_c *MockDomain_LogIn_Call) Run(run func(setup Setup, user User)) *MockDomain_LogIn_Call {
+func (_c *MockDomain_Token_Call) Run(run func(setup Setup, request TokenRequest)) *MockDomain_Token_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(Setup), args[1].(User))
+		run(args[0].(Setup), args[1].(TokenRequest))
 	})
 	return _c
 }
 
-func (_c *MockDomain_LogIn_Call) Return(existing User, sessionKey string, err error) *MockDomain_LogIn_Call {
-	_c.Call.Return(existing, sessionKey, err)
+func (_c *MockDomain_Token_Call) Return(tokens Tokens, err error) *MockDomain_Token_Call {
+	_c.Call.Return(tokens, err)
 	return _c
 }
 
-func (_c *MockDomain_LogIn_Call) RunAndReturn(run func(Setup, User) (User, string, error)) *MockDomain_LogIn_Call {
+func (_c *MockDomain_Token_Call) RunAndReturn(run func(Setup, TokenRequest) (Tokens, error)) *MockDomain_Token_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Logout provides a mock function with given fields: setup, token
-func (_m *MockDomain) Logout(setup Setup, token string) error {
-	ret := _m.Called(setup, token)
+// UnlinkIdentity provides a mock function with given fields: setup, token, provider
+func (_m *MockDomain) UnlinkIdentity(setup Setup, token string, provider string) error {
+	ret := _m.Called(setup, token, provider)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(Setup, string) error); ok {
-		r0 = rf(setup, token)
+	if rf, ok := ret.Get(0).(func(Setup, string, string) error); ok {
+		r0 = rf(setup, token, provider)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -144,52 +2596,55 @@ func (_m *MockDomain) Logout(setup Setup, token string) error {
 	return r0
 }
 
-// MockDomain_Logout_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Logout'
-type MockDomain_Logout_Call struct {
+// MockDomain_UnlinkIdentity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnlinkIdentity'
+type MockDomain_UnlinkIdentity_Call struct {
 	*mock.Call
 }
 
-// Logout is a helper method to define mock.On call
+// UnlinkIdentity is a helper method to define mock.On call
 //   - setup Setup
 //   - token string
-func (_e *MockDomain_Expecter) Logout(setup interface{}, token interface{}) *MockDomain_Logout_Call {
-	return &MockDomain_Logout_Call{Call: _e.mock.On("Logout", setup, token)}
+//   - provider string
+func (_e *MockDomain_Expecter) UnlinkIdentity(setup interface{}, token interface{}, provider interface{}) *MockDomain_UnlinkIdentity_Call {
+	return &MockDomain_UnlinkIdentity_Call{Call: _e.mock.On("UnlinkIdentity", setup, token, provider)}
 }
 
-func (_c *MockDomain_Logout_Call) Run(run func(setup Setup, token string)) *MockDomain_Logout_Call {
+func (_c *MockDomain_UnlinkIdentity_Call) Run(run func(setup Setup, token string, provider string)) *MockDomain_UnlinkIdentity_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(Setup), args[1].(string))
+		run(args[0].(Setup), args[1].(string), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *MockDomain_Logout_Call) Return(err error) *MockDomain_Logout_Call {
+func (_c *MockDomain_UnlinkIdentity_Call) Return(err error) *MockDomain_UnlinkIdentity_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockDomain_Logout_Call) RunAndReturn(run func(Setup, string) error) *MockDomain_Logout_Call {
+func (_c *MockDomain_UnlinkIdentity_Call) RunAndReturn(run func(Setup, string, string) error) *MockDomain_UnlinkIdentity_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ResetPasswordRequest provides a mock function with given fields: setup, user
-func (_m *MockDomain) ResetPasswordRequest(setup Setup, user User) (User, error) {
-	ret := _m.Called(setup, user)
+// UserInfo provides a mock function with given fields: setup, accessToken
+func (_m *MockDomain) UserInfo(setup Setup, accessToken string) (map[string]interface{}, error) {
+	ret := _m.Called(setup, accessToken)
 
-	var r0 User
+	var r0 map[string]interface{}
 	var r1 error
-	if rf, ok := ret.Get(0).(func(Setup, User) (User, error)); ok {
-		return rf(setup, user)
+	if rf, ok := ret.Get(0).(func(Setup, string) (map[string]interface{}, error)); ok {
+		return rf(setup, accessToken)
 	}
-	if rf, ok := ret.Get(0).(func(Setup, User) User); ok {
-		r0 = rf(setup, user)
+	if rf, ok := ret.Get(0).(func(Setup, string) map[string]interface{}); ok {
+		r0 = rf(setup, accessToken)
 	} else {
-		r0 = ret.Get(0).(User)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]interface{})
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(Setup, User) error); ok {
-		r1 = rf(setup, user)
+	if rf, ok := ret.Get(1).(func(Setup, string) error); ok {
+		r1 = rf(setup, accessToken)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -197,48 +2652,48 @@ func (_m *MockDomain) ResetPasswordRequest(setup Setup, user User) (User, error)
 	return r0, r1
 }
 
-// MockDomain_ResetPasswordRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResetPasswordRequest'
-type MockDomain_ResetPasswordRequest_Call struct {
+// MockDomain_UserInfo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UserInfo'
+type MockDomain_UserInfo_Call struct {
 	*mock.Call
 }
 
-// ResetPasswordRequest is a helper method to define mock.On call
+// UserInfo is a helper method to define mock.On call
 //   - setup Setup
-//   - user User
-func (_e *MockDomain_Expecter) ResetPasswordRequest(setup interface{}, user interface{}) *MockDomain_ResetPasswordRequest_Call {
-	return &MockDomain_ResetPasswordRequest_Call{Call: _e.mock.On("ResetPasswordRequest", setup, user)}
+//   - accessToken string
+func (_e *MockDomain_Expecter) UserInfo(setup interface{}, accessToken interface{}) *MockDomain_UserInfo_Call {
+	return &MockDomain_UserInfo_Call{Call: _e.mock.On("UserInfo", setup, accessToken)}
 }
 
-func (_c *MockDomain_ResetPasswordRequest_Call) Run(run func(setup Setup, user User)) *MockDomain_ResetPasswordRequest_Call {
+func (_c *MockDomain_UserInfo_Call) Run(run func(setup Setup, accessToken string)) *MockDomain_UserInfo_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(Setup), args[1].(User))
+		run(args[0].(Setup), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockDomain_ResetPasswordRequest_Call) Return(sentTo User, err error) *MockDomain_ResetPasswordRequest_Call {
-	_c.Call.Return(sentTo, err)
+func (_c *MockDomain_UserInfo_Call) Return(claims map[string]interface{}, err error) *MockDomain_UserInfo_Call {
+	_c.Call.Return(claims, err)
 	return _c
 }
 
-func (_c *MockDomain_ResetPasswordRequest_Call) RunAndReturn(run func(Setup, User) (User, error)) *MockDomain_ResetPasswordRequest_Call {
+func (_c *MockDomain_UserInfo_Call) RunAndReturn(run func(Setup, string) (map[string]interface{}, error)) *MockDomain_UserInfo_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Session provides a mock function with given fields: setup, token
-func (_m *MockDomain) Session(setup Setup, token string) (User, error) {
+// VerifyAccount provides a mock function with given fields: setup, token
+func (_m *MockDomain) VerifyAccount(setup Setup, token string) (bool, error) {
 	ret := _m.Called(setup, token)
 
-	var r0 User
+	var r0 bool
 	var r1 error
-	if rf, ok := ret.Get(0).(func(Setup, string) (User, error)); ok {
+	if rf, ok := ret.Get(0).(func(Setup, string) (bool, error)); ok {
 		return rf(setup, token)
 	}
-	if rf, ok := ret.Get(0).(func(Setup, string) User); ok {
+	if rf, ok := ret.Get(0).(func(Setup, string) bool); ok {
 		r0 = rf(setup, token)
 	} else {
-		r0 = ret.Get(0).(User)
+		r0 = ret.Get(0).(bool)
 	}
 
 	if rf, ok := ret.Get(1).(func(Setup, string) error); ok {
@@ -250,52 +2705,52 @@ func (_m *MockDomain) Session(setup Setup, token string) (User, error) {
 	return r0, r1
 }
 
-// MockDomain_Session_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Session'
-type MockDomain_Session_Call struct {
+// MockDomain_VerifyAccount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyAccount'
+type MockDomain_VerifyAccount_Call struct {
 	*mock.Call
 }
 
-// Session is a helper method to define mock.On call
+// VerifyAccount is a helper method to define mock.On call
 //   - setup Setup
 //   - token string
-func (_e *MockDomain_Expecter) Session(setup interface{}, token interface{}) *MockDomain_Session_Call {
-	return &MockDomain_Session_Call{Call: _e.mock.On("Session", setup, token)}
+func (_e *MockDomain_Expecter) VerifyAccount(setup interface{}, token interface{}) *MockDomain_VerifyAccount_Call {
+	return &MockDomain_VerifyAccount_Call{Call: _e.mock.On("VerifyAccount", setup, token)}
 }
 
-func (_c *MockDomain_Session_Call) Run(run func(setup Setup, token string)) *MockDomain_Session_Call {
+func (_c *MockDomain_VerifyAccount_Call) Run(run func(setup Setup, token string)) *MockDomain_VerifyAccount_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(Setup), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockDomain_Session_Call) Return(user User, err error) *MockDomain_Session_Call {
-	_c.Call.Return(user, err)
+func (_c *MockDomain_VerifyAccount_Call) Return(verified bool, err error) *MockDomain_VerifyAccount_Call {
+	_c.Call.Return(verified, err)
 	return _c
 }
 
-func (_c *MockDomain_Session_Call) RunAndReturn(run func(Setup, string) (User, error)) *MockDomain_Session_Call {
+func (_c *MockDomain_VerifyAccount_Call) RunAndReturn(run func(Setup, string) (bool, error)) *MockDomain_VerifyAccount_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// SignUp provides a mock function with given fields: setup, user
-func (_m *MockDomain) SignUp(setup Setup, user User) (User, error) {
-	ret := _m.Called(setup, user)
+// VerifyAccountCode provides a mock function with given fields: setup, user, code
+func (_m *MockDomain) VerifyAccountCode(setup Setup, user User, code string) (bool, error) {
+	ret := _m.Called(setup, user, code)
 
-	var r0 User
+	var r0 bool
 	var r1 error
-	if rf, ok := ret.Get(0).(func(Setup, User) (User, error)); ok {
-		return rf(setup, user)
+	if rf, ok := ret.Get(0).(func(Setup, User, string) (bool, error)); ok {
+		return rf(setup, user, code)
 	}
-	if rf, ok := ret.Get(0).(func(Setup, User) User); ok {
-		r0 = rf(setup, user)
+	if rf, ok := ret.Get(0).(func(Setup, User, string) bool); ok {
+		r0 = rf(setup, user, code)
 	} else {
-		r0 = ret.Get(0).(User)
+		r0 = ret.Get(0).(bool)
 	}
 
-	if rf, ok := ret.Get(1).(func(Setup, User) error); ok {
-		r1 = rf(setup, user)
+	if rf, ok := ret.Get(1).(func(Setup, User, string) error); ok {
+		r1 = rf(setup, user, code)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -303,52 +2758,53 @@ func (_m *MockDomain) SignUp(setup Setup, user User) (User, error) {
 	return r0, r1
 }
 
-// MockDomain_SignUp_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SignUp'
-type MockDomain_SignUp_Call struct {
+// MockDomain_VerifyAccountCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyAccountCode'
+type MockDomain_VerifyAccountCode_Call struct {
 	*mock.Call
 }
 
-// SignUp is a helper method to define mock.On call
+// VerifyAccountCode is a helper method to define mock.On call
 //   - setup Setup
 //   - user User
-func (_e *MockDomain_Expecter) SignUp(setup interface{}, user interface{}) *MockDomain_SignUp_Call {
-	return &MockDomain_SignUp_Call{Call: _e.mock.On("SignUp", setup, user)}
+//   - code string
+func (_e *MockDomain_Expecter) VerifyAccountCode(setup interface{}, user interface{}, code interface{}) *MockDomain_VerifyAccountCode_Call {
+	return &MockDomain_VerifyAccountCode_Call{Call: _e.mock.On("VerifyAccountCode", setup, user, code)}
 }
 
-func (_c *MockDomain_SignUp_Call) Run(run func(setup Setup, user User)) *MockDomain_SignUp_Call {
+func (_c *MockDomain_VerifyAccountCode_Call) Run(run func(setup Setup, user User, code string)) *MockDomain_VerifyAccountCode_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(Setup), args[1].(User))
+		run(args[0].(Setup), args[1].(User), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *MockDomain_SignUp_Call) Return(newUser User, err error) *MockDomain_SignUp_Call {
-	_c.Call.Return(newUser, err)
+func (_c *MockDomain_VerifyAccountCode_Call) Return(verified bool, err error) *MockDomain_VerifyAccountCode_Call {
+	_c.Call.Return(verified, err)
 	return _c
 }
 
-func (_c *MockDomain_SignUp_Call) RunAndReturn(run func(Setup, User) (User, error)) *MockDomain_SignUp_Call {
+func (_c *MockDomain_VerifyAccountCode_Call) RunAndReturn(run func(Setup, User, string) (bool, error)) *MockDomain_VerifyAccountCode_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// VerifyAccount provides a mock function with given fields: setup, token
-func (_m *MockDomain) VerifyAccount(setup Setup, token string) (bool, error) {
-	ret := _m.Called(setup, token)
+// VerifyDevice provides a mock function with given fields: setup, token, userCode, approve
+func (_m *MockDomain) VerifyDevice(setup Setup, token string, userCode string, approve bool) (DeviceAuthorization, error) {
+	ret := _m.Called(setup, token, userCode, approve)
 
-	var r0 bool
+	var r0 DeviceAuthorization
 	var r1 error
-	if rf, ok := ret.Get(0).(func(Setup, string) (bool, error)); ok {
-		return rf(setup, token)
+	if rf, ok := ret.Get(0).(func(Setup, string, string, bool) (DeviceAuthorization, error)); ok {
+		return rf(setup, token, userCode, approve)
 	}
-	if rf, ok := ret.Get(0).(func(Setup, string) bool); ok {
-		r0 = rf(setup, token)
+	if rf, ok := ret.Get(0).(func(Setup, string, string, bool) DeviceAuthorization); ok {
+		r0 = rf(setup, token, userCode, approve)
 	} else {
-		r0 = ret.Get(0).(bool)
+		r0 = ret.Get(0).(DeviceAuthorization)
 	}
 
-	if rf, ok := ret.Get(1).(func(Setup, string) error); ok {
-		r1 = rf(setup, token)
+	if rf, ok := ret.Get(1).(func(Setup, string, string, bool) error); ok {
+		r1 = rf(setup, token, userCode, approve)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -356,52 +2812,54 @@ func (_m *MockDomain) VerifyAccount(setup Setup, token string) (bool, error) {
 	return r0, r1
 }
 
-// MockDomain_VerifyAccount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyAccount'
-type MockDomain_VerifyAccount_Call struct {
+// MockDomain_VerifyDevice_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyDevice'
+type MockDomain_VerifyDevice_Call struct {
 	*mock.Call
 }
 
-// VerifyAccount is a helper method to define mock.On call
+// VerifyDevice is a helper method to define mock.On call
 //   - setup Setup
 //   - token string
-func (_e *MockDomain_Expecter) VerifyAccount(setup interface{}, token interface{}) *MockDomain_VerifyAccount_Call {
-	return &MockDomain_VerifyAccount_Call{Call: _e.mock.On("VerifyAccount", setup, token)}
+//   - userCode string
+//   - approve bool
+func (_e *MockDomain_Expecter) VerifyDevice(setup interface{}, token interface{}, userCode interface{}, approve interface{}) *MockDomain_VerifyDevice_Call {
+	return &MockDomain_VerifyDevice_Call{Call: _e.mock.On("VerifyDevice", setup, token, userCode, approve)}
 }
 
-func (_c *MockDomain_VerifyAccount_Call) Run(run func(setup Setup, token string)) *MockDomain_VerifyAccount_Call {
+func (_c *MockDomain_VerifyDevice_Call) Run(run func(setup Setup, token string, userCode string, approve bool)) *MockDomain_VerifyDevice_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(Setup), args[1].(string))
+		run(args[0].(Setup), args[1].(string), args[2].(string), args[3].(bool))
 	})
 	return _c
 }
 
-func (_c *MockDomain_VerifyAccount_Call) Return(verified bool, err error) *MockDomain_VerifyAccount_Call {
-	_c.Call.Return(verified, err)
+func (_c *MockDomain_VerifyDevice_Call) Return(device DeviceAuthorization, err error) *MockDomain_VerifyDevice_Call {
+	_c.Call.Return(device, err)
 	return _c
 }
 
-func (_c *MockDomain_VerifyAccount_Call) RunAndReturn(run func(Setup, string) (bool, error)) *MockDomain_VerifyAccount_Call {
+func (_c *MockDomain_VerifyDevice_Call) RunAndReturn(run func(Setup, string, string, bool) (DeviceAuthorization, error)) *MockDomain_VerifyDevice_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// VerifyPasswordReset provides a mock function with given fields: setup, token, password
-func (_m *MockDomain) VerifyPasswordReset(setup Setup, token string, password string) (User, error) {
-	ret := _m.Called(setup, token, password)
+// VerifyPasswordReset provides a mock function with given fields: setup, token, password, keepSession
+func (_m *MockDomain) VerifyPasswordReset(setup Setup, token string, password string, keepSession string) (User, error) {
+	ret := _m.Called(setup, token, password, keepSession)
 
 	var r0 User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(Setup, string, string) (User, error)); ok {
-		return rf(setup, token, password)
+	if rf, ok := ret.Get(0).(func(Setup, string, string, string) (User, error)); ok {
+		return rf(setup, token, password, keepSession)
 	}
-	if rf, ok := ret.Get(0).(func(Setup, string, string) User); ok {
-		r0 = rf(setup, token, password)
+	if rf, ok := ret.Get(0).(func(Setup, string, string, string) User); ok {
+		r0 = rf(setup, token, password, keepSession)
 	} else {
 		r0 = ret.Get(0).(User)
 	}
 
-	if rf, ok := ret.Get(1).(func(Setup, string, string) error); ok {
-		r1 = rf(setup, token, password)
+	if rf, ok := ret.Get(1).(func(Setup, string, string, string) error); ok {
+		r1 = rf(setup, token, password, keepSession)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -418,13 +2876,14 @@ type MockDomain_VerifyPasswordReset_Call struct {
 //   - setup Setup
 //   - token string
 //   - password string
-func (_e *MockDomain_Expecter) VerifyPasswordReset(setup interface{}, token interface{}, password interface{}) *MockDomain_VerifyPasswordReset_Call {
-	return &MockDomain_VerifyPasswordReset_Call{Call: _e.mock.On("VerifyPasswordReset", setup, token, password)}
+//   - keepSession string
+func (_e *MockDomain_Expecter) VerifyPasswordReset(setup interface{}, token interface{}, password interface{}, keepSession interface{}) *MockDomain_VerifyPasswordReset_Call {
+	return &MockDomain_VerifyPasswordReset_Call{Call: _e.mock.On("VerifyPasswordReset", setup, token, password, keepSession)}
 }
 
-func (_c *MockDomain_VerifyPasswordReset_Call) Run(run func(setup Setup, token string, password string)) *MockDomain_VerifyPasswordReset_Call {
+func (_c *MockDomain_VerifyPasswordReset_Call) Run(run func(setup Setup, token string, password string, keepSession string)) *MockDomain_VerifyPasswordReset_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(Setup), args[1].(string), args[2].(string))
+		run(args[0].(Setup), args[1].(string), args[2].(string), args[3].(string))
 	})
 	return _c
 }
@@ -434,7 +2893,7 @@ func (_c *MockDomain_VerifyPasswordReset_Call) Return(updated User, err error) *
 	return _c
 }
 
-func (_c *MockDomain_VerifyPasswordReset_Call) RunAndReturn(run func(Setup, string, string) (User, error)) *MockDomain_VerifyPasswordReset_Call {
+func (_c *MockDomain_VerifyPasswordReset_Call) RunAndReturn(run func(Setup, string, string, string) (User, error)) *MockDomain_VerifyPasswordReset_Call {
 	_c.Call.Return(run)
 	return _c
 }
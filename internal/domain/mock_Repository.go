@@ -21,6 +21,50 @@ func (_m *MockRepository) EXPECT() *MockRepository_Expecter {
 	return &MockRepository_Expecter{mock: &_m.Mock}
 }
 
+// APIKey provides a mock function with given fields: ctx
+func (_m *MockRepository) APIKey(ctx context.Context) RepositoryAPIKey {
+	ret := _m.Called(ctx)
+
+	var r0 RepositoryAPIKey
+	if rf, ok := ret.Get(0).(func(context.Context) RepositoryAPIKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(RepositoryAPIKey)
+		}
+	}
+
+	return r0
+}
+
+// MockRepository_APIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'APIKey'
+type MockRepository_APIKey_Call struct {
+	*mock.Call
+}
+
+// APIKey is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) APIKey(ctx interface{}) *MockRepository_APIKey_Call {
+	return &MockRepository_APIKey_Call{Call: _e.mock.On("APIKey", ctx)}
+}
+
+func (_c *MockRepository_APIKey_Call) Run(run func(ctx context.Context)) *MockRepository_APIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRepository_APIKey_Call) Return(_a0 RepositoryAPIKey) *MockRepository_APIKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_APIKey_Call) RunAndReturn(run func(context.Context) RepositoryAPIKey) *MockRepository_APIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Atomic provides a mock function with given fields: fn
 func (_m *MockRepository) Atomic(fn func(Repository) error) error {
 	ret := _m.Called(fn)
@@ -63,6 +107,182 @@ func (_c *MockRepository_Atomic_Call) RunAndReturn(run func(func(Repository) err
 	return _c
 }
 
+// Ceremony provides a mock function with given fields: ctx
+func (_m *MockRepository) Ceremony(ctx context.Context) RepositoryCeremony {
+	ret := _m.Called(ctx)
+
+	var r0 RepositoryCeremony
+	if rf, ok := ret.Get(0).(func(context.Context) RepositoryCeremony); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(RepositoryCeremony)
+		}
+	}
+
+	return r0
+}
+
+// MockRepository_Ceremony_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Ceremony'
+type MockRepository_Ceremony_Call struct {
+	*mock.Call
+}
+
+// Ceremony is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) Ceremony(ctx interface{}) *MockRepository_Ceremony_Call {
+	return &MockRepository_Ceremony_Call{Call: _e.mock.On("Ceremony", ctx)}
+}
+
+func (_c *MockRepository_Ceremony_Call) Run(run func(ctx context.Context)) *MockRepository_Ceremony_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRepository_Ceremony_Call) Return(_a0 RepositoryCeremony) *MockRepository_Ceremony_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_Ceremony_Call) RunAndReturn(run func(context.Context) RepositoryCeremony) *MockRepository_Ceremony_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Challenge provides a mock function with given fields: ctx
+func (_m *MockRepository) Challenge(ctx context.Context) RepositoryChallenge {
+	ret := _m.Called(ctx)
+
+	var r0 RepositoryChallenge
+	if rf, ok := ret.Get(0).(func(context.Context) RepositoryChallenge); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(RepositoryChallenge)
+		}
+	}
+
+	return r0
+}
+
+// MockRepository_Challenge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Challenge'
+type MockRepository_Challenge_Call struct {
+	*mock.Call
+}
+
+// Challenge is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) Challenge(ctx interface{}) *MockRepository_Challenge_Call {
+	return &MockRepository_Challenge_Call{Call: _e.mock.On("Challenge", ctx)}
+}
+
+func (_c *MockRepository_Challenge_Call) Run(run func(ctx context.Context)) *MockRepository_Challenge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRepository_Challenge_Call) Return(_a0 RepositoryChallenge) *MockRepository_Challenge_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_Challenge_Call) RunAndReturn(run func(context.Context) RepositoryChallenge) *MockRepository_Challenge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Consent provides a mock function with given fields: ctx
+func (_m *MockRepository) Consent(ctx context.Context) RepositoryConsent {
+	ret := _m.Called(ctx)
+
+	var r0 RepositoryConsent
+	if rf, ok := ret.Get(0).(func(context.Context) RepositoryConsent); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(RepositoryConsent)
+		}
+	}
+
+	return r0
+}
+
+// MockRepository_Consent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Consent'
+type MockRepository_Consent_Call struct {
+	*mock.Call
+}
+
+// Consent is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) Consent(ctx interface{}) *MockRepository_Consent_Call {
+	return &MockRepository_Consent_Call{Call: _e.mock.On("Consent", ctx)}
+}
+
+func (_c *MockRepository_Consent_Call) Run(run func(ctx context.Context)) *MockRepository_Consent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRepository_Consent_Call) Return(_a0 RepositoryConsent) *MockRepository_Consent_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_Consent_Call) RunAndReturn(run func(context.Context) RepositoryConsent) *MockRepository_Consent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeviceAuthorization provides a mock function with given fields: ctx
+func (_m *MockRepository) DeviceAuthorization(ctx context.Context) RepositoryDeviceAuthorization {
+	ret := _m.Called(ctx)
+
+	var r0 RepositoryDeviceAuthorization
+	if rf, ok := ret.Get(0).(func(context.Context) RepositoryDeviceAuthorization); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(RepositoryDeviceAuthorization)
+		}
+	}
+
+	return r0
+}
+
+// MockRepository_DeviceAuthorization_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeviceAuthorization'
+type MockRepository_DeviceAuthorization_Call struct {
+	*mock.Call
+}
+
+// DeviceAuthorization is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) DeviceAuthorization(ctx interface{}) *MockRepository_DeviceAuthorization_Call {
+	return &MockRepository_DeviceAuthorization_Call{Call: _e.mock.On("DeviceAuthorization", ctx)}
+}
+
+func (_c *MockRepository_DeviceAuthorization_Call) Run(run func(ctx context.Context)) *MockRepository_DeviceAuthorization_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRepository_DeviceAuthorization_Call) Return(_a0 RepositoryDeviceAuthorization) *MockRepository_DeviceAuthorization_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_DeviceAuthorization_Call) RunAndReturn(run func(context.Context) RepositoryDeviceAuthorization) *MockRepository_DeviceAuthorization_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ForgetPassword provides a mock function with given fields: ctx
 func (_m *MockRepository) ForgetPassword(ctx context.Context) RepositoryForgetPassword {
 	ret := _m.Called(ctx)
@@ -107,6 +327,314 @@ func (_c *MockRepository_ForgetPassword_Call) RunAndReturn(run func(context.Cont
 	return _c
 }
 
+// Identity provides a mock function with given fields: ctx
+func (_m *MockRepository) Identity(ctx context.Context) RepositoryIdentity {
+	ret := _m.Called(ctx)
+
+	var r0 RepositoryIdentity
+	if rf, ok := ret.Get(0).(func(context.Context) RepositoryIdentity); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(RepositoryIdentity)
+		}
+	}
+
+	return r0
+}
+
+// MockRepository_Identity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Identity'
+type MockRepository_Identity_Call struct {
+	*mock.Call
+}
+
+// Identity is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) Identity(ctx interface{}) *MockRepository_Identity_Call {
+	return &MockRepository_Identity_Call{Call: _e.mock.On("Identity", ctx)}
+}
+
+func (_c *MockRepository_Identity_Call) Run(run func(ctx context.Context)) *MockRepository_Identity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRepository_Identity_Call) Return(_a0 RepositoryIdentity) *MockRepository_Identity_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_Identity_Call) RunAndReturn(run func(context.Context) RepositoryIdentity) *MockRepository_Identity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MagicLink provides a mock function with given fields: ctx
+func (_m *MockRepository) MagicLink(ctx context.Context) RepositoryMagicLink {
+	ret := _m.Called(ctx)
+
+	var r0 RepositoryMagicLink
+	if rf, ok := ret.Get(0).(func(context.Context) RepositoryMagicLink); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(RepositoryMagicLink)
+		}
+	}
+
+	return r0
+}
+
+// MockRepository_MagicLink_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MagicLink'
+type MockRepository_MagicLink_Call struct {
+	*mock.Call
+}
+
+// MagicLink is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) MagicLink(ctx interface{}) *MockRepository_MagicLink_Call {
+	return &MockRepository_MagicLink_Call{Call: _e.mock.On("MagicLink", ctx)}
+}
+
+func (_c *MockRepository_MagicLink_Call) Run(run func(ctx context.Context)) *MockRepository_MagicLink_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRepository_MagicLink_Call) Return(_a0 RepositoryMagicLink) *MockRepository_MagicLink_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_MagicLink_Call) RunAndReturn(run func(context.Context) RepositoryMagicLink) *MockRepository_MagicLink_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OAuthClient provides a mock function with given fields: ctx
+func (_m *MockRepository) OAuthClient(ctx context.Context) RepositoryOAuthClient {
+	ret := _m.Called(ctx)
+
+	var r0 RepositoryOAuthClient
+	if rf, ok := ret.Get(0).(func(context.Context) RepositoryOAuthClient); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(RepositoryOAuthClient)
+		}
+	}
+
+	return r0
+}
+
+// MockRepository_OAuthClient_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OAuthClient'
+type MockRepository_OAuthClient_Call struct {
+	*mock.Call
+}
+
+// OAuthClient is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) OAuthClient(ctx interface{}) *MockRepository_OAuthClient_Call {
+	return &MockRepository_OAuthClient_Call{Call: _e.mock.On("OAuthClient", ctx)}
+}
+
+func (_c *MockRepository_OAuthClient_Call) Run(run func(ctx context.Context)) *MockRepository_OAuthClient_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRepository_OAuthClient_Call) Return(_a0 RepositoryOAuthClient) *MockRepository_OAuthClient_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_OAuthClient_Call) RunAndReturn(run func(context.Context) RepositoryOAuthClient) *MockRepository_OAuthClient_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OneTimeCode provides a mock function with given fields: ctx
+func (_m *MockRepository) OneTimeCode(ctx context.Context) RepositoryOneTimeCode {
+	ret := _m.Called(ctx)
+
+	var r0 RepositoryOneTimeCode
+	if rf, ok := ret.Get(0).(func(context.Context) RepositoryOneTimeCode); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(RepositoryOneTimeCode)
+		}
+	}
+
+	return r0
+}
+
+// MockRepository_OneTimeCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OneTimeCode'
+type MockRepository_OneTimeCode_Call struct {
+	*mock.Call
+}
+
+// OneTimeCode is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) OneTimeCode(ctx interface{}) *MockRepository_OneTimeCode_Call {
+	return &MockRepository_OneTimeCode_Call{Call: _e.mock.On("OneTimeCode", ctx)}
+}
+
+func (_c *MockRepository_OneTimeCode_Call) Run(run func(ctx context.Context)) *MockRepository_OneTimeCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRepository_OneTimeCode_Call) Return(_a0 RepositoryOneTimeCode) *MockRepository_OneTimeCode_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_OneTimeCode_Call) RunAndReturn(run func(context.Context) RepositoryOneTimeCode) *MockRepository_OneTimeCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Passkey provides a mock function with given fields: ctx
+func (_m *MockRepository) Passkey(ctx context.Context) RepositoryPasskey {
+	ret := _m.Called(ctx)
+
+	var r0 RepositoryPasskey
+	if rf, ok := ret.Get(0).(func(context.Context) RepositoryPasskey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(RepositoryPasskey)
+		}
+	}
+
+	return r0
+}
+
+// MockRepository_Passkey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Passkey'
+type MockRepository_Passkey_Call struct {
+	*mock.Call
+}
+
+// Passkey is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) Passkey(ctx interface{}) *MockRepository_Passkey_Call {
+	return &MockRepository_Passkey_Call{Call: _e.mock.On("Passkey", ctx)}
+}
+
+func (_c *MockRepository_Passkey_Call) Run(run func(ctx context.Context)) *MockRepository_Passkey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRepository_Passkey_Call) Return(_a0 RepositoryPasskey) *MockRepository_Passkey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_Passkey_Call) RunAndReturn(run func(context.Context) RepositoryPasskey) *MockRepository_Passkey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecoveryCode provides a mock function with given fields: ctx
+func (_m *MockRepository) RecoveryCode(ctx context.Context) RepositoryRecoveryCode {
+	ret := _m.Called(ctx)
+
+	var r0 RepositoryRecoveryCode
+	if rf, ok := ret.Get(0).(func(context.Context) RepositoryRecoveryCode); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(RepositoryRecoveryCode)
+		}
+	}
+
+	return r0
+}
+
+// MockRepository_RecoveryCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecoveryCode'
+type MockRepository_RecoveryCode_Call struct {
+	*mock.Call
+}
+
+// RecoveryCode is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) RecoveryCode(ctx interface{}) *MockRepository_RecoveryCode_Call {
+	return &MockRepository_RecoveryCode_Call{Call: _e.mock.On("RecoveryCode", ctx)}
+}
+
+func (_c *MockRepository_RecoveryCode_Call) Run(run func(ctx context.Context)) *MockRepository_RecoveryCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRepository_RecoveryCode_Call) Return(_a0 RepositoryRecoveryCode) *MockRepository_RecoveryCode_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_RecoveryCode_Call) RunAndReturn(run func(context.Context) RepositoryRecoveryCode) *MockRepository_RecoveryCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RefreshToken provides a mock function with given fields: ctx
+func (_m *MockRepository) RefreshToken(ctx context.Context) RepositoryRefreshToken {
+	ret := _m.Called(ctx)
+
+	var r0 RepositoryRefreshToken
+	if rf, ok := ret.Get(0).(func(context.Context) RepositoryRefreshToken); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(RepositoryRefreshToken)
+		}
+	}
+
+	return r0
+}
+
+// MockRepository_RefreshToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RefreshToken'
+type MockRepository_RefreshToken_Call struct {
+	*mock.Call
+}
+
+// RefreshToken is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) RefreshToken(ctx interface{}) *MockRepository_RefreshToken_Call {
+	return &MockRepository_RefreshToken_Call{Call: _e.mock.On("RefreshToken", ctx)}
+}
+
+func (_c *MockRepository_RefreshToken_Call) Run(run func(ctx context.Context)) *MockRepository_RefreshToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRepository_RefreshToken_Call) Return(_a0 RepositoryRefreshToken) *MockRepository_RefreshToken_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_RefreshToken_Call) RunAndReturn(run func(context.Context) RepositoryRefreshToken) *MockRepository_RefreshToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Session provides a mock function with given fields: ctx
 func (_m *MockRepository) Session(ctx context.Context) RepositorySession {
 	ret := _m.Called(ctx)
@@ -151,6 +679,94 @@ func (_c *MockRepository_Session_Call) RunAndReturn(run func(context.Context) Re
 	return _c
 }
 
+// SigningKey provides a mock function with given fields: ctx
+func (_m *MockRepository) SigningKey(ctx context.Context) RepositorySigningKey {
+	ret := _m.Called(ctx)
+
+	var r0 RepositorySigningKey
+	if rf, ok := ret.Get(0).(func(context.Context) RepositorySigningKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(RepositorySigningKey)
+		}
+	}
+
+	return r0
+}
+
+// MockRepository_SigningKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SigningKey'
+type MockRepository_SigningKey_Call struct {
+	*mock.Call
+}
+
+// SigningKey is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) SigningKey(ctx interface{}) *MockRepository_SigningKey_Call {
+	return &MockRepository_SigningKey_Call{Call: _e.mock.On("SigningKey", ctx)}
+}
+
+func (_c *MockRepository_SigningKey_Call) Run(run func(ctx context.Context)) *MockRepository_SigningKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRepository_SigningKey_Call) Return(_a0 RepositorySigningKey) *MockRepository_SigningKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_SigningKey_Call) RunAndReturn(run func(context.Context) RepositorySigningKey) *MockRepository_SigningKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TOTP provides a mock function with given fields: ctx
+func (_m *MockRepository) TOTP(ctx context.Context) RepositoryTOTP {
+	ret := _m.Called(ctx)
+
+	var r0 RepositoryTOTP
+	if rf, ok := ret.Get(0).(func(context.Context) RepositoryTOTP); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(RepositoryTOTP)
+		}
+	}
+
+	return r0
+}
+
+// MockRepository_TOTP_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TOTP'
+type MockRepository_TOTP_Call struct {
+	*mock.Call
+}
+
+// TOTP is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRepository_Expecter) TOTP(ctx interface{}) *MockRepository_TOTP_Call {
+	return &MockRepository_TOTP_Call{Call: _e.mock.On("TOTP", ctx)}
+}
+
+func (_c *MockRepository_TOTP_Call) Run(run func(ctx context.Context)) *MockRepository_TOTP_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRepository_TOTP_Call) Return(_a0 RepositoryTOTP) *MockRepository_TOTP_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepository_TOTP_Call) RunAndReturn(run func(context.Context) RepositoryTOTP) *MockRepository_TOTP_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // User provides a mock function with given fields: ctx
 func (_m *MockRepository) User(ctx context.Context) RepositoryUser {
 	ret := _m.Called(ctx)
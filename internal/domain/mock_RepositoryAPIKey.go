@@ -0,0 +1,275 @@
+// Code generated by mockery v2.34.2. DO NOT EDIT.
+
+package domain
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockRepositoryAPIKey is an autogenerated mock type for the RepositoryAPIKey type
+type MockRepositoryAPIKey struct {
+	mock.Mock
+}
+
+type MockRepositoryAPIKey_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRepositoryAPIKey) EXPECT() *MockRepositoryAPIKey_Expecter {
+	return &MockRepositoryAPIKey_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: key
+func (_m *MockRepositoryAPIKey) Create(key APIKey) (APIKey, error) {
+	ret := _m.Called(key)
+
+	var r0 APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(APIKey) (APIKey, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(APIKey) APIKey); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Get(0).(APIKey)
+	}
+
+	if rf, ok := ret.Get(1).(func(APIKey) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryAPIKey_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockRepositoryAPIKey_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - key APIKey
+func (_e *MockRepositoryAPIKey_Expecter) Create(key interface{}) *MockRepositoryAPIKey_Create_Call {
+	return &MockRepositoryAPIKey_Create_Call{Call: _e.mock.On("Create", key)}
+}
+
+func (_c *MockRepositoryAPIKey_Create_Call) Run(run func(key APIKey)) *MockRepositoryAPIKey_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(APIKey))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryAPIKey_Create_Call) Return(created APIKey, err error) *MockRepositoryAPIKey_Create_Call {
+	_c.Call.Return(created, err)
+	return _c
+}
+
+func (_c *MockRepositoryAPIKey_Create_Call) RunAndReturn(run func(APIKey) (APIKey, error)) *MockRepositoryAPIKey_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: userId, id
+func (_m *MockRepositoryAPIKey) Delete(userId uint64, id uint64) error {
+	ret := _m.Called(userId, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint64, uint64) error); ok {
+		r0 = rf(userId, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositoryAPIKey_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockRepositoryAPIKey_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - userId uint64
+//   - id uint64
+func (_e *MockRepositoryAPIKey_Expecter) Delete(userId interface{}, id interface{}) *MockRepositoryAPIKey_Delete_Call {
+	return &MockRepositoryAPIKey_Delete_Call{Call: _e.mock.On("Delete", userId, id)}
+}
+
+func (_c *MockRepositoryAPIKey_Delete_Call) Run(run func(userId uint64, id uint64)) *MockRepositoryAPIKey_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64), args[1].(uint64))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryAPIKey_Delete_Call) Return(_a0 error) *MockRepositoryAPIKey_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositoryAPIKey_Delete_Call) RunAndReturn(run func(uint64, uint64) error) *MockRepositoryAPIKey_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteByUser provides a mock function with given fields: userId
+func (_m *MockRepositoryAPIKey) DeleteByUser(userId uint64) error {
+	ret := _m.Called(userId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint64) error); ok {
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositoryAPIKey_DeleteByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByUser'
+type MockRepositoryAPIKey_DeleteByUser_Call struct {
+	*mock.Call
+}
+
+// DeleteByUser is a helper method to define mock.On call
+//   - userId uint64
+func (_e *MockRepositoryAPIKey_Expecter) DeleteByUser(userId interface{}) *MockRepositoryAPIKey_DeleteByUser_Call {
+	return &MockRepositoryAPIKey_DeleteByUser_Call{Call: _e.mock.On("DeleteByUser", userId)}
+}
+
+func (_c *MockRepositoryAPIKey_DeleteByUser_Call) Run(run func(userId uint64)) *MockRepositoryAPIKey_DeleteByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryAPIKey_DeleteByUser_Call) Return(_a0 error) *MockRepositoryAPIKey_DeleteByUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositoryAPIKey_DeleteByUser_Call) RunAndReturn(run func(uint64) error) *MockRepositoryAPIKey_DeleteByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByHash provides a mock function with given fields: hash
+func (_m *MockRepositoryAPIKey) GetByHash(hash string) (APIKey, error) {
+	ret := _m.Called(hash)
+
+	var r0 APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (APIKey, error)); ok {
+		return rf(hash)
+	}
+	if rf, ok := ret.Get(0).(func(string) APIKey); ok {
+		r0 = rf(hash)
+	} else {
+		r0 = ret.Get(0).(APIKey)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryAPIKey_GetByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByHash'
+type MockRepositoryAPIKey_GetByHash_Call struct {
+	*mock.Call
+}
+
+// GetByHash is a helper method to define mock.On call
+//   - hash string
+func (_e *MockRepositoryAPIKey_Expecter) GetByHash(hash interface{}) *MockRepositoryAPIKey_GetByHash_Call {
+	return &MockRepositoryAPIKey_GetByHash_Call{Call: _e.mock.On("GetByHash", hash)}
+}
+
+func (_c *MockRepositoryAPIKey_GetByHash_Call) Run(run func(hash string)) *MockRepositoryAPIKey_GetByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryAPIKey_GetByHash_Call) Return(key APIKey, err error) *MockRepositoryAPIKey_GetByHash_Call {
+	_c.Call.Return(key, err)
+	return _c
+}
+
+func (_c *MockRepositoryAPIKey_GetByHash_Call) RunAndReturn(run func(string) (APIKey, error)) *MockRepositoryAPIKey_GetByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByUser provides a mock function with given fields: userId
+func (_m *MockRepositoryAPIKey) GetByUser(userId uint64) ([]APIKey, error) {
+	ret := _m.Called(userId)
+
+	var r0 []APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint64) ([]APIKey, error)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(uint64) []APIKey); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint64) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryAPIKey_GetByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByUser'
+type MockRepositoryAPIKey_GetByUser_Call struct {
+	*mock.Call
+}
+
+// GetByUser is a helper method to define mock.On call
+//   - userId uint64
+func (_e *MockRepositoryAPIKey_Expecter) GetByUser(userId interface{}) *MockRepositoryAPIKey_GetByUser_Call {
+	return &MockRepositoryAPIKey_GetByUser_Call{Call: _e.mock.On("GetByUser", userId)}
+}
+
+func (_c *MockRepositoryAPIKey_GetByUser_Call) Run(run func(userId uint64)) *MockRepositoryAPIKey_GetByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryAPIKey_GetByUser_Call) Return(keys []APIKey, err error) *MockRepositoryAPIKey_GetByUser_Call {
+	_c.Call.Return(keys, err)
+	return _c
+}
+
+func (_c *MockRepositoryAPIKey_GetByUser_Call) RunAndReturn(run func(uint64) ([]APIKey, error)) *MockRepositoryAPIKey_GetByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRepositoryAPIKey creates a new instance of MockRepositoryAPIKey. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRepositoryAPIKey(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRepositoryAPIKey {
+	mock := &MockRepositoryAPIKey{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,138 @@
+// Code generated by mockery v2.34.2. DO NOT EDIT.
+
+package domain
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockRepositoryCeremony is an autogenerated mock type for the RepositoryCeremony type
+type MockRepositoryCeremony struct {
+	mock.Mock
+}
+
+type MockRepositoryCeremony_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRepositoryCeremony) EXPECT() *MockRepositoryCeremony_Expecter {
+	return &MockRepositoryCeremony_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: data
+func (_m *MockRepositoryCeremony) Create(data []byte) (string, error) {
+	ret := _m.Called(data)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]byte) (string, error)); ok {
+		return rf(data)
+	}
+	if rf, ok := ret.Get(0).(func([]byte) string); ok {
+		r0 = rf(data)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func([]byte) error); ok {
+		r1 = rf(data)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryCeremony_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockRepositoryCeremony_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - data []byte
+func (_e *MockRepositoryCeremony_Expecter) Create(data interface{}) *MockRepositoryCeremony_Create_Call {
+	return &MockRepositoryCeremony_Create_Call{Call: _e.mock.On("Create", data)}
+}
+
+func (_c *MockRepositoryCeremony_Create_Call) Run(run func(data []byte)) *MockRepositoryCeremony_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryCeremony_Create_Call) Return(key string, err error) *MockRepositoryCeremony_Create_Call {
+	_c.Call.Return(key, err)
+	return _c
+}
+
+func (_c *MockRepositoryCeremony_Create_Call) RunAndReturn(run func([]byte) (string, error)) *MockRepositoryCeremony_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Take provides a mock function with given fields: key
+func (_m *MockRepositoryCeremony) Take(key string) ([]byte, error) {
+	ret := _m.Called(key)
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]byte, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) []byte); ok {
+		r0 = rf(key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryCeremony_Take_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Take'
+type MockRepositoryCeremony_Take_Call struct {
+	*mock.Call
+}
+
+// Take is a helper method to define mock.On call
+//   - key string
+func (_e *MockRepositoryCeremony_Expecter) Take(key interface{}) *MockRepositoryCeremony_Take_Call {
+	return &MockRepositoryCeremony_Take_Call{Call: _e.mock.On("Take", key)}
+}
+
+func (_c *MockRepositoryCeremony_Take_Call) Run(run func(key string)) *MockRepositoryCeremony_Take_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryCeremony_Take_Call) Return(data []byte, err error) *MockRepositoryCeremony_Take_Call {
+	_c.Call.Return(data, err)
+	return _c
+}
+
+func (_c *MockRepositoryCeremony_Take_Call) RunAndReturn(run func(string) ([]byte, error)) *MockRepositoryCeremony_Take_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRepositoryCeremony creates a new instance of MockRepositoryCeremony. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRepositoryCeremony(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRepositoryCeremony {
+	mock := &MockRepositoryCeremony{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,230 @@
+// Code generated by mockery v2.34.2. DO NOT EDIT.
+
+package domain
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockRepositoryChallenge is an autogenerated mock type for the RepositoryChallenge type
+type MockRepositoryChallenge struct {
+	mock.Mock
+}
+
+type MockRepositoryChallenge_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRepositoryChallenge) EXPECT() *MockRepositoryChallenge_Expecter {
+	return &MockRepositoryChallenge_Expecter{mock: &_m.Mock}
+}
+
+// Attempt provides a mock function with given fields: key
+func (_m *MockRepositoryChallenge) Attempt(key string) (int64, error) {
+	ret := _m.Called(key)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (int64, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) int64); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryChallenge_Attempt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Attempt'
+type MockRepositoryChallenge_Attempt_Call struct {
+	*mock.Call
+}
+
+// Attempt is a helper method to define mock.On call
+//   - key string
+func (_e *MockRepositoryChallenge_Expecter) Attempt(key interface{}) *MockRepositoryChallenge_Attempt_Call {
+	return &MockRepositoryChallenge_Attempt_Call{Call: _e.mock.On("Attempt", key)}
+}
+
+func (_c *MockRepositoryChallenge_Attempt_Call) Run(run func(key string)) *MockRepositoryChallenge_Attempt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryChallenge_Attempt_Call) Return(attempts int64, err error) *MockRepositoryChallenge_Attempt_Call {
+	_c.Call.Return(attempts, err)
+	return _c
+}
+
+func (_c *MockRepositoryChallenge_Attempt_Call) RunAndReturn(run func(string) (int64, error)) *MockRepositoryChallenge_Attempt_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Create provides a mock function with given fields: user
+func (_m *MockRepositoryChallenge) Create(user User) (Challenge, error) {
+	ret := _m.Called(user)
+
+	var r0 Challenge
+	var r1 error
+	if rf, ok := ret.Get(0).(func(User) (Challenge, error)); ok {
+		return rf(user)
+	}
+	if rf, ok := ret.Get(0).(func(User) Challenge); ok {
+		r0 = rf(user)
+	} else {
+		r0 = ret.Get(0).(Challenge)
+	}
+
+	if rf, ok := ret.Get(1).(func(User) error); ok {
+		r1 = rf(user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryChallenge_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockRepositoryChallenge_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - user User
+func (_e *MockRepositoryChallenge_Expecter) Create(user interface{}) *MockRepositoryChallenge_Create_Call {
+	return &MockRepositoryChallenge_Create_Call{Call: _e.mock.On("Create", user)}
+}
+
+func (_c *MockRepositoryChallenge_Create_Call) Run(run func(user User)) *MockRepositoryChallenge_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(User))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryChallenge_Create_Call) Return(challenge Challenge, err error) *MockRepositoryChallenge_Create_Call {
+	_c.Call.Return(challenge, err)
+	return _c
+}
+
+func (_c *MockRepositoryChallenge_Create_Call) RunAndReturn(run func(User) (Challenge, error)) *MockRepositoryChallenge_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: key
+func (_m *MockRepositoryChallenge) Delete(key string) error {
+	ret := _m.Called(key)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositoryChallenge_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockRepositoryChallenge_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - key string
+func (_e *MockRepositoryChallenge_Expecter) Delete(key interface{}) *MockRepositoryChallenge_Delete_Call {
+	return &MockRepositoryChallenge_Delete_Call{Call: _e.mock.On("Delete", key)}
+}
+
+func (_c *MockRepositoryChallenge_Delete_Call) Run(run func(key string)) *MockRepositoryChallenge_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryChallenge_Delete_Call) Return(_a0 error) *MockRepositoryChallenge_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositoryChallenge_Delete_Call) RunAndReturn(run func(string) error) *MockRepositoryChallenge_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function with given fields: key
+func (_m *MockRepositoryChallenge) Get(key string) (Challenge, error) {
+	ret := _m.Called(key)
+
+	var r0 Challenge
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (Challenge, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) Challenge); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Get(0).(Challenge)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryChallenge_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockRepositoryChallenge_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - key string
+func (_e *MockRepositoryChallenge_Expecter) Get(key interface{}) *MockRepositoryChallenge_Get_Call {
+	return &MockRepositoryChallenge_Get_Call{Call: _e.mock.On("Get", key)}
+}
+
+func (_c *MockRepositoryChallenge_Get_Call) Run(run func(key string)) *MockRepositoryChallenge_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryChallenge_Get_Call) Return(challenge Challenge, err error) *MockRepositoryChallenge_Get_Call {
+	_c.Call.Return(challenge, err)
+	return _c
+}
+
+func (_c *MockRepositoryChallenge_Get_Call) RunAndReturn(run func(string) (Challenge, error)) *MockRepositoryChallenge_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRepositoryChallenge creates a new instance of MockRepositoryChallenge. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRepositoryChallenge(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRepositoryChallenge {
+	mock := &MockRepositoryChallenge{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,127 @@
+// Code generated by mockery v2.34.2. DO NOT EDIT.
+
+package domain
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockRepositoryConsent is an autogenerated mock type for the RepositoryConsent type
+type MockRepositoryConsent struct {
+	mock.Mock
+}
+
+type MockRepositoryConsent_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRepositoryConsent) EXPECT() *MockRepositoryConsent_Expecter {
+	return &MockRepositoryConsent_Expecter{mock: &_m.Mock}
+}
+
+// Get provides a mock function with given fields: userId, clientId
+func (_m *MockRepositoryConsent) Get(userId uint64, clientId string) (Consent, error) {
+	ret := _m.Called(userId, clientId)
+
+	var r0 Consent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint64, string) (Consent, error)); ok {
+		return rf(userId, clientId)
+	}
+	if rf, ok := ret.Get(0).(func(uint64, string) Consent); ok {
+		r0 = rf(userId, clientId)
+	} else {
+		r0 = ret.Get(0).(Consent)
+	}
+
+	if rf, ok := ret.Get(1).(func(uint64, string) error); ok {
+		r1 = rf(userId, clientId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryConsent_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockRepositoryConsent_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - userId uint64
+//   - clientId string
+func (_e *MockRepositoryConsent_Expecter) Get(userId interface{}, clientId interface{}) *MockRepositoryConsent_Get_Call {
+	return &MockRepositoryConsent_Get_Call{Call: _e.mock.On("Get", userId, clientId)}
+}
+
+func (_c *MockRepositoryConsent_Get_Call) Run(run func(userId uint64, clientId string)) *MockRepositoryConsent_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryConsent_Get_Call) Return(consent Consent, err error) *MockRepositoryConsent_Get_Call {
+	_c.Call.Return(consent, err)
+	return _c
+}
+
+func (_c *MockRepositoryConsent_Get_Call) RunAndReturn(run func(uint64, string) (Consent, error)) *MockRepositoryConsent_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Save provides a mock function with given fields: consent
+func (_m *MockRepositoryConsent) Save(consent Consent) error {
+	ret := _m.Called(consent)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(Consent) error); ok {
+		r0 = rf(consent)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositoryConsent_Save_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Save'
+type MockRepositoryConsent_Save_Call struct {
+	*mock.Call
+}
+
+// Save is a helper method to define mock.On call
+//   - consent Consent
+func (_e *MockRepositoryConsent_Expecter) Save(consent interface{}) *MockRepositoryConsent_Save_Call {
+	return &MockRepositoryConsent_Save_Call{Call: _e.mock.On("Save", consent)}
+}
+
+func (_c *MockRepositoryConsent_Save_Call) Run(run func(consent Consent)) *MockRepositoryConsent_Save_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Consent))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryConsent_Save_Call) Return(_a0 error) *MockRepositoryConsent_Save_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositoryConsent_Save_Call) RunAndReturn(run func(Consent) error) *MockRepositoryConsent_Save_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRepositoryConsent creates a new instance of MockRepositoryConsent. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRepositoryConsent(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRepositoryConsent {
+	mock := &MockRepositoryConsent{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
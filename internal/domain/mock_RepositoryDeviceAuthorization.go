@@ -0,0 +1,384 @@
+// Code generated by mockery v2.34.2. DO NOT EDIT.
+
+package domain
+
+import (
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockRepositoryDeviceAuthorization is an autogenerated mock type for the RepositoryDeviceAuthorization type
+type MockRepositoryDeviceAuthorization struct {
+	mock.Mock
+}
+
+type MockRepositoryDeviceAuthorization_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRepositoryDeviceAuthorization) EXPECT() *MockRepositoryDeviceAuthorization_Expecter {
+	return &MockRepositoryDeviceAuthorization_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: device
+func (_m *MockRepositoryDeviceAuthorization) Create(device DeviceAuthorization) (DeviceAuthorization, error) {
+	ret := _m.Called(device)
+
+	var r0 DeviceAuthorization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(DeviceAuthorization) (DeviceAuthorization, error)); ok {
+		return rf(device)
+	}
+	if rf, ok := ret.Get(0).(func(DeviceAuthorization) DeviceAuthorization); ok {
+		r0 = rf(device)
+	} else {
+		r0 = ret.Get(0).(DeviceAuthorization)
+	}
+
+	if rf, ok := ret.Get(1).(func(DeviceAuthorization) error); ok {
+		r1 = rf(device)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryDeviceAuthorization_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockRepositoryDeviceAuthorization_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - device DeviceAuthorization
+func (_e *MockRepositoryDeviceAuthorization_Expecter) Create(device interface{}) *MockRepositoryDeviceAuthorization_Create_Call {
+	return &MockRepositoryDeviceAuthorization_Create_Call{Call: _e.mock.On("Create", device)}
+}
+
+func (_c *MockRepositoryDeviceAuthorization_Create_Call) Run(run func(device DeviceAuthorization)) *MockRepositoryDeviceAuthorization_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(DeviceAuthorization))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryDeviceAuthorization_Create_Call) Return(created DeviceAuthorization, err error) *MockRepositoryDeviceAuthorization_Create_Call {
+	_c.Call.Return(created, err)
+	return _c
+}
+
+func (_c *MockRepositoryDeviceAuthorization_Create_Call) RunAndReturn(run func(DeviceAuthorization) (DeviceAuthorization, error)) *MockRepositoryDeviceAuthorization_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Decide provides a mock function with given fields: deviceCode, status, userId
+func (_m *MockRepositoryDeviceAuthorization) Decide(deviceCode string, status string, userId uint64) error {
+	ret := _m.Called(deviceCode, status, userId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, uint64) error); ok {
+		r0 = rf(deviceCode, status, userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositoryDeviceAuthorization_Decide_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Decide'
+type MockRepositoryDeviceAuthorization_Decide_Call struct {
+	*mock.Call
+}
+
+// Decide is a helper method to define mock.On call
+//   - deviceCode string
+//   - status string
+//   - userId uint64
+func (_e *MockRepositoryDeviceAuthorization_Expecter) Decide(deviceCode interface{}, status interface{}, userId interface{}) *MockRepositoryDeviceAuthorization_Decide_Call {
+	return &MockRepositoryDeviceAuthorization_Decide_Call{Call: _e.mock.On("Decide", deviceCode, status, userId)}
+}
+
+func (_c *MockRepositoryDeviceAuthorization_Decide_Call) Run(run func(deviceCode string, status string, userId uint64)) *MockRepositoryDeviceAuthorization_Decide_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(uint64))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryDeviceAuthorization_Decide_Call) Return(_a0 error) *MockRepositoryDeviceAuthorization_Decide_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositoryDeviceAuthorization_Decide_Call) RunAndReturn(run func(string, string, uint64) error) *MockRepositoryDeviceAuthorization_Decide_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: deviceCode
+func (_m *MockRepositoryDeviceAuthorization) Delete(deviceCode string) error {
+	ret := _m.Called(deviceCode)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(deviceCode)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositoryDeviceAuthorization_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockRepositoryDeviceAuthorization_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - deviceCode string
+func (_e *MockRepositoryDeviceAuthorization_Expecter) Delete(deviceCode interface{}) *MockRepositoryDeviceAuthorization_Delete_Call {
+	return &MockRepositoryDeviceAuthorization_Delete_Call{Call: _e.mock.On("Delete", deviceCode)}
+}
+
+func (_c *MockRepositoryDeviceAuthorization_Delete_Call) Run(run func(deviceCode string)) *MockRepositoryDeviceAuthorization_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryDeviceAuthorization_Delete_Call) Return(_a0 error) *MockRepositoryDeviceAuthorization_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositoryDeviceAuthorization_Delete_Call) RunAndReturn(run func(string) error) *MockRepositoryDeviceAuthorization_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function with given fields: deviceCode
+func (_m *MockRepositoryDeviceAuthorization) Get(deviceCode string) (DeviceAuthorization, error) {
+	ret := _m.Called(deviceCode)
+
+	var r0 DeviceAuthorization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (DeviceAuthorization, error)); ok {
+		return rf(deviceCode)
+	}
+	if rf, ok := ret.Get(0).(func(string) DeviceAuthorization); ok {
+		r0 = rf(deviceCode)
+	} else {
+		r0 = ret.Get(0).(DeviceAuthorization)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(deviceCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryDeviceAuthorization_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockRepositoryDeviceAuthorization_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - deviceCode string
+func (_e *MockRepositoryDeviceAuthorization_Expecter) Get(deviceCode interface{}) *MockRepositoryDeviceAuthorization_Get_Call {
+	return &MockRepositoryDeviceAuthorization_Get_Call{Call: _e.mock.On("Get", deviceCode)}
+}
+
+func (_c *MockRepositoryDeviceAuthorization_Get_Call) Run(run func(deviceCode string)) *MockRepositoryDeviceAuthorization_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryDeviceAuthorization_Get_Call) Return(device DeviceAuthorization, err error) *MockRepositoryDeviceAuthorization_Get_Call {
+	_c.Call.Return(device, err)
+	return _c
+}
+
+func (_c *MockRepositoryDeviceAuthorization_Get_Call) RunAndReturn(run func(string) (DeviceAuthorization, error)) *MockRepositoryDeviceAuthorization_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByUserCode provides a mock function with given fields: userCode
+func (_m *MockRepositoryDeviceAuthorization) GetByUserCode(userCode string) (DeviceAuthorization, error) {
+	ret := _m.Called(userCode)
+
+	var r0 DeviceAuthorization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (DeviceAuthorization, error)); ok {
+		return rf(userCode)
+	}
+	if rf, ok := ret.Get(0).(func(string) DeviceAuthorization); ok {
+		r0 = rf(userCode)
+	} else {
+		r0 = ret.Get(0).(DeviceAuthorization)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryDeviceAuthorization_GetByUserCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByUserCode'
+type MockRepositoryDeviceAuthorization_GetByUserCode_Call struct {
+	*mock.Call
+}
+
+// GetByUserCode is a helper method to define mock.On call
+//   - userCode string
+func (_e *MockRepositoryDeviceAuthorization_Expecter) GetByUserCode(userCode interface{}) *MockRepositoryDeviceAuthorization_GetByUserCode_Call {
+	return &MockRepositoryDeviceAuthorization_GetByUserCode_Call{Call: _e.mock.On("GetByUserCode", userCode)}
+}
+
+func (_c *MockRepositoryDeviceAuthorization_GetByUserCode_Call) Run(run func(userCode string)) *MockRepositoryDeviceAuthorization_GetByUserCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryDeviceAuthorization_GetByUserCode_Call) Return(device DeviceAuthorization, err error) *MockRepositoryDeviceAuthorization_GetByUserCode_Call {
+	_c.Call.Return(device, err)
+	return _c
+}
+
+func (_c *MockRepositoryDeviceAuthorization_GetByUserCode_Call) RunAndReturn(run func(string) (DeviceAuthorization, error)) *MockRepositoryDeviceAuthorization_GetByUserCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Poll provides a mock function with given fields: deviceCode, at
+func (_m *MockRepositoryDeviceAuthorization) Poll(deviceCode string, at time.Time) (time.Time, error) {
+	ret := _m.Called(deviceCode, at)
+
+	var r0 time.Time
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, time.Time) (time.Time, error)); ok {
+		return rf(deviceCode, at)
+	}
+	if rf, ok := ret.Get(0).(func(string, time.Time) time.Time); ok {
+		r0 = rf(deviceCode, at)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, time.Time) error); ok {
+		r1 = rf(deviceCode, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryDeviceAuthorization_Poll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Poll'
+type MockRepositoryDeviceAuthorization_Poll_Call struct {
+	*mock.Call
+}
+
+// Poll is a helper method to define mock.On call
+//   - deviceCode string
+//   - at time.Time
+func (_e *MockRepositoryDeviceAuthorization_Expecter) Poll(deviceCode interface{}, at interface{}) *MockRepositoryDeviceAuthorization_Poll_Call {
+	return &MockRepositoryDeviceAuthorization_Poll_Call{Call: _e.mock.On("Poll", deviceCode, at)}
+}
+
+func (_c *MockRepositoryDeviceAuthorization_Poll_Call) Run(run func(deviceCode string, at time.Time)) *MockRepositoryDeviceAuthorization_Poll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryDeviceAuthorization_Poll_Call) Return(previous time.Time, err error) *MockRepositoryDeviceAuthorization_Poll_Call {
+	_c.Call.Return(previous, err)
+	return _c
+}
+
+func (_c *MockRepositoryDeviceAuthorization_Poll_Call) RunAndReturn(run func(string, time.Time) (time.Time, error)) *MockRepositoryDeviceAuthorization_Poll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SlowDown provides a mock function with given fields: deviceCode, by
+func (_m *MockRepositoryDeviceAuthorization) SlowDown(deviceCode string, by int64) (int64, error) {
+	ret := _m.Called(deviceCode, by)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int64) (int64, error)); ok {
+		return rf(deviceCode, by)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64) int64); ok {
+		r0 = rf(deviceCode, by)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64) error); ok {
+		r1 = rf(deviceCode, by)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryDeviceAuthorization_SlowDown_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SlowDown'
+type MockRepositoryDeviceAuthorization_SlowDown_Call struct {
+	*mock.Call
+}
+
+// SlowDown is a helper method to define mock.On call
+//   - deviceCode string
+//   - by int64
+func (_e *MockRepositoryDeviceAuthorization_Expecter) SlowDown(deviceCode interface{}, by interface{}) *MockRepositoryDeviceAuthorization_SlowDown_Call {
+	return &MockRepositoryDeviceAuthorization_SlowDown_Call{Call: _e.mock.On("SlowDown", deviceCode, by)}
+}
+
+func (_c *MockRepositoryDeviceAuthorization_SlowDown_Call) Run(run func(deviceCode string, by int64)) *MockRepositoryDeviceAuthorization_SlowDown_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryDeviceAuthorization_SlowDown_Call) Return(interval int64, err error) *MockRepositoryDeviceAuthorization_SlowDown_Call {
+	_c.Call.Return(interval, err)
+	return _c
+}
+
+func (_c *MockRepositoryDeviceAuthorization_SlowDown_Call) RunAndReturn(run func(string, int64) (int64, error)) *MockRepositoryDeviceAuthorization_SlowDown_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRepositoryDeviceAuthorization creates a new instance of MockRepositoryDeviceAuthorization. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRepositoryDeviceAuthorization(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRepositoryDeviceAuthorization {
+	mock := &MockRepositoryDeviceAuthorization{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
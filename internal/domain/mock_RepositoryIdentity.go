@@ -0,0 +1,234 @@
+// Code generated by mockery v2.34.2. DO NOT EDIT.
+
+package domain
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockRepositoryIdentity is an autogenerated mock type for the RepositoryIdentity type
+type MockRepositoryIdentity struct {
+	mock.Mock
+}
+
+type MockRepositoryIdentity_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRepositoryIdentity) EXPECT() *MockRepositoryIdentity_Expecter {
+	return &MockRepositoryIdentity_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: identity
+func (_m *MockRepositoryIdentity) Create(identity Identity) (Identity, error) {
+	ret := _m.Called(identity)
+
+	var r0 Identity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Identity) (Identity, error)); ok {
+		return rf(identity)
+	}
+	if rf, ok := ret.Get(0).(func(Identity) Identity); ok {
+		r0 = rf(identity)
+	} else {
+		r0 = ret.Get(0).(Identity)
+	}
+
+	if rf, ok := ret.Get(1).(func(Identity) error); ok {
+		r1 = rf(identity)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryIdentity_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockRepositoryIdentity_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - identity Identity
+func (_e *MockRepositoryIdentity_Expecter) Create(identity interface{}) *MockRepositoryIdentity_Create_Call {
+	return &MockRepositoryIdentity_Create_Call{Call: _e.mock.On("Create", identity)}
+}
+
+func (_c *MockRepositoryIdentity_Create_Call) Run(run func(identity Identity)) *MockRepositoryIdentity_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Identity))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryIdentity_Create_Call) Return(created Identity, err error) *MockRepositoryIdentity_Create_Call {
+	_c.Call.Return(created, err)
+	return _c
+}
+
+func (_c *MockRepositoryIdentity_Create_Call) RunAndReturn(run func(Identity) (Identity, error)) *MockRepositoryIdentity_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: userId, provider
+func (_m *MockRepositoryIdentity) Delete(userId uint64, provider string) error {
+	ret := _m.Called(userId, provider)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint64, string) error); ok {
+		r0 = rf(userId, provider)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositoryIdentity_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockRepositoryIdentity_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - userId uint64
+//   - provider string
+func (_e *MockRepositoryIdentity_Expecter) Delete(userId interface{}, provider interface{}) *MockRepositoryIdentity_Delete_Call {
+	return &MockRepositoryIdentity_Delete_Call{Call: _e.mock.On("Delete", userId, provider)}
+}
+
+func (_c *MockRepositoryIdentity_Delete_Call) Run(run func(userId uint64, provider string)) *MockRepositoryIdentity_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryIdentity_Delete_Call) Return(_a0 error) *MockRepositoryIdentity_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositoryIdentity_Delete_Call) RunAndReturn(run func(uint64, string) error) *MockRepositoryIdentity_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function with given fields: provider, subject
+func (_m *MockRepositoryIdentity) Get(provider string, subject string) (Identity, error) {
+	ret := _m.Called(provider, subject)
+
+	var r0 Identity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (Identity, error)); ok {
+		return rf(provider, subject)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) Identity); ok {
+		r0 = rf(provider, subject)
+	} else {
+		r0 = ret.Get(0).(Identity)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(provider, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryIdentity_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockRepositoryIdentity_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - provider string
+//   - subject string
+func (_e *MockRepositoryIdentity_Expecter) Get(provider interface{}, subject interface{}) *MockRepositoryIdentity_Get_Call {
+	return &MockRepositoryIdentity_Get_Call{Call: _e.mock.On("Get", provider, subject)}
+}
+
+func (_c *MockRepositoryIdentity_Get_Call) Run(run func(provider string, subject string)) *MockRepositoryIdentity_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryIdentity_Get_Call) Return(identity Identity, err error) *MockRepositoryIdentity_Get_Call {
+	_c.Call.Return(identity, err)
+	return _c
+}
+
+func (_c *MockRepositoryIdentity_Get_Call) RunAndReturn(run func(string, string) (Identity, error)) *MockRepositoryIdentity_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByUser provides a mock function with given fields: userId
+func (_m *MockRepositoryIdentity) GetByUser(userId uint64) ([]Identity, error) {
+	ret := _m.Called(userId)
+
+	var r0 []Identity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint64) ([]Identity, error)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(uint64) []Identity); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Identity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint64) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryIdentity_GetByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByUser'
+type MockRepositoryIdentity_GetByUser_Call struct {
+	*mock.Call
+}
+
+// GetByUser is a helper method to define mock.On call
+//   - userId uint64
+func (_e *MockRepositoryIdentity_Expecter) GetByUser(userId interface{}) *MockRepositoryIdentity_GetByUser_Call {
+	return &MockRepositoryIdentity_GetByUser_Call{Call: _e.mock.On("GetByUser", userId)}
+}
+
+func (_c *MockRepositoryIdentity_GetByUser_Call) Run(run func(userId uint64)) *MockRepositoryIdentity_GetByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryIdentity_GetByUser_Call) Return(identities []Identity, err error) *MockRepositoryIdentity_GetByUser_Call {
+	_c.Call.Return(identities, err)
+	return _c
+}
+
+func (_c *MockRepositoryIdentity_GetByUser_Call) RunAndReturn(run func(uint64) ([]Identity, error)) *MockRepositoryIdentity_GetByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRepositoryIdentity creates a new instance of MockRepositoryIdentity. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRepositoryIdentity(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRepositoryIdentity {
+	mock := &MockRepositoryIdentity{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
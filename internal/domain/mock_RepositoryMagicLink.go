@@ -0,0 +1,142 @@
+// Code generated by mockery v2.34.2. DO NOT EDIT.
+
+package domain
+
+import (
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockRepositoryMagicLink is an autogenerated mock type for the RepositoryMagicLink type
+type MockRepositoryMagicLink struct {
+	mock.Mock
+}
+
+type MockRepositoryMagicLink_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRepositoryMagicLink) EXPECT() *MockRepositoryMagicLink_Expecter {
+	return &MockRepositoryMagicLink_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: token, userId, expiresAt
+func (_m *MockRepositoryMagicLink) Create(token string, userId uint64, expiresAt time.Time) (MagicLink, error) {
+	ret := _m.Called(token, userId, expiresAt)
+
+	var r0 MagicLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, uint64, time.Time) (MagicLink, error)); ok {
+		return rf(token, userId, expiresAt)
+	}
+	if rf, ok := ret.Get(0).(func(string, uint64, time.Time) MagicLink); ok {
+		r0 = rf(token, userId, expiresAt)
+	} else {
+		r0 = ret.Get(0).(MagicLink)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, uint64, time.Time) error); ok {
+		r1 = rf(token, userId, expiresAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryMagicLink_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockRepositoryMagicLink_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - token string
+//   - userId uint64
+//   - expiresAt time.Time
+func (_e *MockRepositoryMagicLink_Expecter) Create(token interface{}, userId interface{}, expiresAt interface{}) *MockRepositoryMagicLink_Create_Call {
+	return &MockRepositoryMagicLink_Create_Call{Call: _e.mock.On("Create", token, userId, expiresAt)}
+}
+
+func (_c *MockRepositoryMagicLink_Create_Call) Run(run func(token string, userId uint64, expiresAt time.Time)) *MockRepositoryMagicLink_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(uint64), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryMagicLink_Create_Call) Return(link MagicLink, err error) *MockRepositoryMagicLink_Create_Call {
+	_c.Call.Return(link, err)
+	return _c
+}
+
+func (_c *MockRepositoryMagicLink_Create_Call) RunAndReturn(run func(string, uint64, time.Time) (MagicLink, error)) *MockRepositoryMagicLink_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Use provides a mock function with given fields: token
+func (_m *MockRepositoryMagicLink) Use(token string) (MagicLink, error) {
+	ret := _m.Called(token)
+
+	var r0 MagicLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (MagicLink, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(string) MagicLink); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Get(0).(MagicLink)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryMagicLink_Use_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Use'
+type MockRepositoryMagicLink_Use_Call struct {
+	*mock.Call
+}
+
+// Use is a helper method to define mock.On call
+//   - token string
+func (_e *MockRepositoryMagicLink_Expecter) Use(token interface{}) *MockRepositoryMagicLink_Use_Call {
+	return &MockRepositoryMagicLink_Use_Call{Call: _e.mock.On("Use", token)}
+}
+
+func (_c *MockRepositoryMagicLink_Use_Call) Run(run func(token string)) *MockRepositoryMagicLink_Use_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryMagicLink_Use_Call) Return(link MagicLink, err error) *MockRepositoryMagicLink_Use_Call {
+	_c.Call.Return(link, err)
+	return _c
+}
+
+func (_c *MockRepositoryMagicLink_Use_Call) RunAndReturn(run func(string) (MagicLink, error)) *MockRepositoryMagicLink_Use_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRepositoryMagicLink creates a new instance of MockRepositoryMagicLink. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRepositoryMagicLink(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRepositoryMagicLink {
+	mock := &MockRepositoryMagicLink{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
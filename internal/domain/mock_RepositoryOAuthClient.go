@@ -0,0 +1,136 @@
+// Code generated by mockery v2.34.2. DO NOT EDIT.
+
+package domain
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockRepositoryOAuthClient is an autogenerated mock type for the RepositoryOAuthClient type
+type MockRepositoryOAuthClient struct {
+	mock.Mock
+}
+
+type MockRepositoryOAuthClient_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRepositoryOAuthClient) EXPECT() *MockRepositoryOAuthClient_Expecter {
+	return &MockRepositoryOAuthClient_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: client
+func (_m *MockRepositoryOAuthClient) Create(client OAuthClient) (OAuthClient, error) {
+	ret := _m.Called(client)
+
+	var r0 OAuthClient
+	var r1 error
+	if rf, ok := ret.Get(0).(func(OAuthClient) (OAuthClient, error)); ok {
+		return rf(client)
+	}
+	if rf, ok := ret.Get(0).(func(OAuthClient) OAuthClient); ok {
+		r0 = rf(client)
+	} else {
+		r0 = ret.Get(0).(OAuthClient)
+	}
+
+	if rf, ok := ret.Get(1).(func(OAuthClient) error); ok {
+		r1 = rf(client)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryOAuthClient_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockRepositoryOAuthClient_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - client OAuthClient
+func (_e *MockRepositoryOAuthClient_Expecter) Create(client interface{}) *MockRepositoryOAuthClient_Create_Call {
+	return &MockRepositoryOAuthClient_Create_Call{Call: _e.mock.On("Create", client)}
+}
+
+func (_c *MockRepositoryOAuthClient_Create_Call) Run(run func(client OAuthClient)) *MockRepositoryOAuthClient_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(OAuthClient))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryOAuthClient_Create_Call) Return(created OAuthClient, err error) *MockRepositoryOAuthClient_Create_Call {
+	_c.Call.Return(created, err)
+	return _c
+}
+
+func (_c *MockRepositoryOAuthClient_Create_Call) RunAndReturn(run func(OAuthClient) (OAuthClient, error)) *MockRepositoryOAuthClient_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function with given fields: id
+func (_m *MockRepositoryOAuthClient) Get(id string) (OAuthClient, error) {
+	ret := _m.Called(id)
+
+	var r0 OAuthClient
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (OAuthClient, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(string) OAuthClient); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(OAuthClient)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryOAuthClient_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockRepositoryOAuthClient_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - id string
+func (_e *MockRepositoryOAuthClient_Expecter) Get(id interface{}) *MockRepositoryOAuthClient_Get_Call {
+	return &MockRepositoryOAuthClient_Get_Call{Call: _e.mock.On("Get", id)}
+}
+
+func (_c *MockRepositoryOAuthClient_Get_Call) Run(run func(id string)) *MockRepositoryOAuthClient_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryOAuthClient_Get_Call) Return(client OAuthClient, err error) *MockRepositoryOAuthClient_Get_Call {
+	_c.Call.Return(client, err)
+	return _c
+}
+
+func (_c *MockRepositoryOAuthClient_Get_Call) RunAndReturn(run func(string) (OAuthClient, error)) *MockRepositoryOAuthClient_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRepositoryOAuthClient creates a new instance of MockRepositoryOAuthClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRepositoryOAuthClient(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRepositoryOAuthClient {
+	mock := &MockRepositoryOAuthClient{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
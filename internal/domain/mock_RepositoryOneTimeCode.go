@@ -0,0 +1,235 @@
+// Code generated by mockery v2.34.2. DO NOT EDIT.
+
+package domain
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockRepositoryOneTimeCode is an autogenerated mock type for the RepositoryOneTimeCode type
+type MockRepositoryOneTimeCode struct {
+	mock.Mock
+}
+
+type MockRepositoryOneTimeCode_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRepositoryOneTimeCode) EXPECT() *MockRepositoryOneTimeCode_Expecter {
+	return &MockRepositoryOneTimeCode_Expecter{mock: &_m.Mock}
+}
+
+// Attempt provides a mock function with given fields: purpose, userId
+func (_m *MockRepositoryOneTimeCode) Attempt(purpose string, userId uint64) (int64, error) {
+	ret := _m.Called(purpose, userId)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, uint64) (int64, error)); ok {
+		return rf(purpose, userId)
+	}
+	if rf, ok := ret.Get(0).(func(string, uint64) int64); ok {
+		r0 = rf(purpose, userId)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, uint64) error); ok {
+		r1 = rf(purpose, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryOneTimeCode_Attempt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Attempt'
+type MockRepositoryOneTimeCode_Attempt_Call struct {
+	*mock.Call
+}
+
+// Attempt is a helper method to define mock.On call
+//   - purpose string
+//   - userId uint64
+func (_e *MockRepositoryOneTimeCode_Expecter) Attempt(purpose interface{}, userId interface{}) *MockRepositoryOneTimeCode_Attempt_Call {
+	return &MockRepositoryOneTimeCode_Attempt_Call{Call: _e.mock.On("Attempt", purpose, userId)}
+}
+
+func (_c *MockRepositoryOneTimeCode_Attempt_Call) Run(run func(purpose string, userId uint64)) *MockRepositoryOneTimeCode_Attempt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(uint64))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryOneTimeCode_Attempt_Call) Return(attempts int64, err error) *MockRepositoryOneTimeCode_Attempt_Call {
+	_c.Call.Return(attempts, err)
+	return _c
+}
+
+func (_c *MockRepositoryOneTimeCode_Attempt_Call) RunAndReturn(run func(string, uint64) (int64, error)) *MockRepositoryOneTimeCode_Attempt_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Create provides a mock function with given fields: purpose, userId, code
+func (_m *MockRepositoryOneTimeCode) Create(purpose string, userId uint64, code string) (OneTimeCode, error) {
+	ret := _m.Called(purpose, userId, code)
+
+	var r0 OneTimeCode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, uint64, string) (OneTimeCode, error)); ok {
+		return rf(purpose, userId, code)
+	}
+	if rf, ok := ret.Get(0).(func(string, uint64, string) OneTimeCode); ok {
+		r0 = rf(purpose, userId, code)
+	} else {
+		r0 = ret.Get(0).(OneTimeCode)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, uint64, string) error); ok {
+		r1 = rf(purpose, userId, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryOneTimeCode_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockRepositoryOneTimeCode_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - purpose string
+//   - userId uint64
+//   - code string
+func (_e *MockRepositoryOneTimeCode_Expecter) Create(purpose interface{}, userId interface{}, code interface{}) *MockRepositoryOneTimeCode_Create_Call {
+	return &MockRepositoryOneTimeCode_Create_Call{Call: _e.mock.On("Create", purpose, userId, code)}
+}
+
+func (_c *MockRepositoryOneTimeCode_Create_Call) Run(run func(purpose string, userId uint64, code string)) *MockRepositoryOneTimeCode_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(uint64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryOneTimeCode_Create_Call) Return(otc OneTimeCode, err error) *MockRepositoryOneTimeCode_Create_Call {
+	_c.Call.Return(otc, err)
+	return _c
+}
+
+func (_c *MockRepositoryOneTimeCode_Create_Call) RunAndReturn(run func(string, uint64, string) (OneTimeCode, error)) *MockRepositoryOneTimeCode_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: purpose, userId
+func (_m *MockRepositoryOneTimeCode) Delete(purpose string, userId uint64) error {
+	ret := _m.Called(purpose, userId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, uint64) error); ok {
+		r0 = rf(purpose, userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositoryOneTimeCode_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockRepositoryOneTimeCode_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - purpose string
+//   - userId uint64
+func (_e *MockRepositoryOneTimeCode_Expecter) Delete(purpose interface{}, userId interface{}) *MockRepositoryOneTimeCode_Delete_Call {
+	return &MockRepositoryOneTimeCode_Delete_Call{Call: _e.mock.On("Delete", purpose, userId)}
+}
+
+func (_c *MockRepositoryOneTimeCode_Delete_Call) Run(run func(purpose string, userId uint64)) *MockRepositoryOneTimeCode_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(uint64))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryOneTimeCode_Delete_Call) Return(_a0 error) *MockRepositoryOneTimeCode_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositoryOneTimeCode_Delete_Call) RunAndReturn(run func(string, uint64) error) *MockRepositoryOneTimeCode_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function with given fields: purpose, userId
+func (_m *MockRepositoryOneTimeCode) Get(purpose string, userId uint64) (OneTimeCode, error) {
+	ret := _m.Called(purpose, userId)
+
+	var r0 OneTimeCode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, uint64) (OneTimeCode, error)); ok {
+		return rf(purpose, userId)
+	}
+	if rf, ok := ret.Get(0).(func(string, uint64) OneTimeCode); ok {
+		r0 = rf(purpose, userId)
+	} else {
+		r0 = ret.Get(0).(OneTimeCode)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, uint64) error); ok {
+		r1 = rf(purpose, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryOneTimeCode_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockRepositoryOneTimeCode_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - purpose string
+//   - userId uint64
+func (_e *MockRepositoryOneTimeCode_Expecter) Get(purpose interface{}, userId interface{}) *MockRepositoryOneTimeCode_Get_Call {
+	return &MockRepositoryOneTimeCode_Get_Call{Call: _e.mock.On("Get", purpose, userId)}
+}
+
+func (_c *MockRepositoryOneTimeCode_Get_Call) Run(run func(purpose string, userId uint64)) *MockRepositoryOneTimeCode_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(uint64))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryOneTimeCode_Get_Call) Return(otc OneTimeCode, err error) *MockRepositoryOneTimeCode_Get_Call {
+	_c.Call.Return(otc, err)
+	return _c
+}
+
+func (_c *MockRepositoryOneTimeCode_Get_Call) RunAndReturn(run func(string, uint64) (OneTimeCode, error)) *MockRepositoryOneTimeCode_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRepositoryOneTimeCode creates a new instance of MockRepositoryOneTimeCode. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRepositoryOneTimeCode(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRepositoryOneTimeCode {
+	mock := &MockRepositoryOneTimeCode{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,234 @@
+// Code generated by mockery v2.34.2. DO NOT EDIT.
+
+package domain
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockRepositoryPasskey is an autogenerated mock type for the RepositoryPasskey type
+type MockRepositoryPasskey struct {
+	mock.Mock
+}
+
+type MockRepositoryPasskey_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRepositoryPasskey) EXPECT() *MockRepositoryPasskey_Expecter {
+	return &MockRepositoryPasskey_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: passkey
+func (_m *MockRepositoryPasskey) Create(passkey Passkey) (Passkey, error) {
+	ret := _m.Called(passkey)
+
+	var r0 Passkey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(Passkey) (Passkey, error)); ok {
+		return rf(passkey)
+	}
+	if rf, ok := ret.Get(0).(func(Passkey) Passkey); ok {
+		r0 = rf(passkey)
+	} else {
+		r0 = ret.Get(0).(Passkey)
+	}
+
+	if rf, ok := ret.Get(1).(func(Passkey) error); ok {
+		r1 = rf(passkey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryPasskey_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockRepositoryPasskey_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - passkey Passkey
+func (_e *MockRepositoryPasskey_Expecter) Create(passkey interface{}) *MockRepositoryPasskey_Create_Call {
+	return &MockRepositoryPasskey_Create_Call{Call: _e.mock.On("Create", passkey)}
+}
+
+func (_c *MockRepositoryPasskey_Create_Call) Run(run func(passkey Passkey)) *MockRepositoryPasskey_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Passkey))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryPasskey_Create_Call) Return(created Passkey, err error) *MockRepositoryPasskey_Create_Call {
+	_c.Call.Return(created, err)
+	return _c
+}
+
+func (_c *MockRepositoryPasskey_Create_Call) RunAndReturn(run func(Passkey) (Passkey, error)) *MockRepositoryPasskey_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByCredentialID provides a mock function with given fields: credentialId
+func (_m *MockRepositoryPasskey) GetByCredentialID(credentialId []byte) (Passkey, error) {
+	ret := _m.Called(credentialId)
+
+	var r0 Passkey
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]byte) (Passkey, error)); ok {
+		return rf(credentialId)
+	}
+	if rf, ok := ret.Get(0).(func([]byte) Passkey); ok {
+		r0 = rf(credentialId)
+	} else {
+		r0 = ret.Get(0).(Passkey)
+	}
+
+	if rf, ok := ret.Get(1).(func([]byte) error); ok {
+		r1 = rf(credentialId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryPasskey_GetByCredentialID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByCredentialID'
+type MockRepositoryPasskey_GetByCredentialID_Call struct {
+	*mock.Call
+}
+
+// GetByCredentialID is a helper method to define mock.On call
+//   - credentialId []byte
+func (_e *MockRepositoryPasskey_Expecter) GetByCredentialID(credentialId interface{}) *MockRepositoryPasskey_GetByCredentialID_Call {
+	return &MockRepositoryPasskey_GetByCredentialID_Call{Call: _e.mock.On("GetByCredentialID", credentialId)}
+}
+
+func (_c *MockRepositoryPasskey_GetByCredentialID_Call) Run(run func(credentialId []byte)) *MockRepositoryPasskey_GetByCredentialID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryPasskey_GetByCredentialID_Call) Return(passkey Passkey, err error) *MockRepositoryPasskey_GetByCredentialID_Call {
+	_c.Call.Return(passkey, err)
+	return _c
+}
+
+func (_c *MockRepositoryPasskey_GetByCredentialID_Call) RunAndReturn(run func([]byte) (Passkey, error)) *MockRepositoryPasskey_GetByCredentialID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByUser provides a mock function with given fields: userId
+func (_m *MockRepositoryPasskey) GetByUser(userId uint64) ([]Passkey, error) {
+	ret := _m.Called(userId)
+
+	var r0 []Passkey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint64) ([]Passkey, error)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(uint64) []Passkey); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Passkey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint64) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryPasskey_GetByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByUser'
+type MockRepositoryPasskey_GetByUser_Call struct {
+	*mock.Call
+}
+
+// GetByUser is a helper method to define mock.On call
+//   - userId uint64
+func (_e *MockRepositoryPasskey_Expecter) GetByUser(userId interface{}) *MockRepositoryPasskey_GetByUser_Call {
+	return &MockRepositoryPasskey_GetByUser_Call{Call: _e.mock.On("GetByUser", userId)}
+}
+
+func (_c *MockRepositoryPasskey_GetByUser_Call) Run(run func(userId uint64)) *MockRepositoryPasskey_GetByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryPasskey_GetByUser_Call) Return(passkeys []Passkey, err error) *MockRepositoryPasskey_GetByUser_Call {
+	_c.Call.Return(passkeys, err)
+	return _c
+}
+
+func (_c *MockRepositoryPasskey_GetByUser_Call) RunAndReturn(run func(uint64) ([]Passkey, error)) *MockRepositoryPasskey_GetByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateSignCount provides a mock function with given fields: credentialId, signCount, backupState
+func (_m *MockRepositoryPasskey) UpdateSignCount(credentialId []byte, signCount uint32, backupState bool) error {
+	ret := _m.Called(credentialId, signCount, backupState)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]byte, uint32, bool) error); ok {
+		r0 = rf(credentialId, signCount, backupState)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositoryPasskey_UpdateSignCount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateSignCount'
+type MockRepositoryPasskey_UpdateSignCount_Call struct {
+	*mock.Call
+}
+
+// UpdateSignCount is a helper method to define mock.On call
+//   - credentialId []byte
+//   - signCount uint32
+//   - backupState bool
+func (_e *MockRepositoryPasskey_Expecter) UpdateSignCount(credentialId interface{}, signCount interface{}, backupState interface{}) *MockRepositoryPasskey_UpdateSignCount_Call {
+	return &MockRepositoryPasskey_UpdateSignCount_Call{Call: _e.mock.On("UpdateSignCount", credentialId, signCount, backupState)}
+}
+
+func (_c *MockRepositoryPasskey_UpdateSignCount_Call) Run(run func(credentialId []byte, signCount uint32, backupState bool)) *MockRepositoryPasskey_UpdateSignCount_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]byte), args[1].(uint32), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryPasskey_UpdateSignCount_Call) Return(_a0 error) *MockRepositoryPasskey_UpdateSignCount_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositoryPasskey_UpdateSignCount_Call) RunAndReturn(run func([]byte, uint32, bool) error) *MockRepositoryPasskey_UpdateSignCount_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRepositoryPasskey creates a new instance of MockRepositoryPasskey. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRepositoryPasskey(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRepositoryPasskey {
+	mock := &MockRepositoryPasskey{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,180 @@
+// Code generated by mockery v2.34.2. DO NOT EDIT.
+
+package domain
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockRepositoryRecoveryCode is an autogenerated mock type for the RepositoryRecoveryCode type
+type MockRepositoryRecoveryCode struct {
+	mock.Mock
+}
+
+type MockRepositoryRecoveryCode_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRepositoryRecoveryCode) EXPECT() *MockRepositoryRecoveryCode_Expecter {
+	return &MockRepositoryRecoveryCode_Expecter{mock: &_m.Mock}
+}
+
+// Count provides a mock function with given fields: userId
+func (_m *MockRepositoryRecoveryCode) Count(userId uint64) (int, error) {
+	ret := _m.Called(userId)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint64) (int, error)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(uint64) int); ok {
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(uint64) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryRecoveryCode_Count_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Count'
+type MockRepositoryRecoveryCode_Count_Call struct {
+	*mock.Call
+}
+
+// Count is a helper method to define mock.On call
+//   - userId uint64
+func (_e *MockRepositoryRecoveryCode_Expecter) Count(userId interface{}) *MockRepositoryRecoveryCode_Count_Call {
+	return &MockRepositoryRecoveryCode_Count_Call{Call: _e.mock.On("Count", userId)}
+}
+
+func (_c *MockRepositoryRecoveryCode_Count_Call) Run(run func(userId uint64)) *MockRepositoryRecoveryCode_Count_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryRecoveryCode_Count_Call) Return(remaining int, err error) *MockRepositoryRecoveryCode_Count_Call {
+	_c.Call.Return(remaining, err)
+	return _c
+}
+
+func (_c *MockRepositoryRecoveryCode_Count_Call) RunAndReturn(run func(uint64) (int, error)) *MockRepositoryRecoveryCode_Count_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Replace provides a mock function with given fields: userId, hashes
+func (_m *MockRepositoryRecoveryCode) Replace(userId uint64, hashes []string) error {
+	ret := _m.Called(userId, hashes)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint64, []string) error); ok {
+		r0 = rf(userId, hashes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositoryRecoveryCode_Replace_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Replace'
+type MockRepositoryRecoveryCode_Replace_Call struct {
+	*mock.Call
+}
+
+// Replace is a helper method to define mock.On call
+//   - userId uint64
+//   - hashes []string
+func (_e *MockRepositoryRecoveryCode_Expecter) Replace(userId interface{}, hashes interface{}) *MockRepositoryRecoveryCode_Replace_Call {
+	return &MockRepositoryRecoveryCode_Replace_Call{Call: _e.mock.On("Replace", userId, hashes)}
+}
+
+func (_c *MockRepositoryRecoveryCode_Replace_Call) Run(run func(userId uint64, hashes []string)) *MockRepositoryRecoveryCode_Replace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryRecoveryCode_Replace_Call) Return(_a0 error) *MockRepositoryRecoveryCode_Replace_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositoryRecoveryCode_Replace_Call) RunAndReturn(run func(uint64, []string) error) *MockRepositoryRecoveryCode_Replace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Use provides a mock function with given fields: userId, hash
+func (_m *MockRepositoryRecoveryCode) Use(userId uint64, hash string) (bool, error) {
+	ret := _m.Called(userId, hash)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint64, string) (bool, error)); ok {
+		return rf(userId, hash)
+	}
+	if rf, ok := ret.Get(0).(func(uint64, string) bool); ok {
+		r0 = rf(userId, hash)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(uint64, string) error); ok {
+		r1 = rf(userId, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryRecoveryCode_Use_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Use'
+type MockRepositoryRecoveryCode_Use_Call struct {
+	*mock.Call
+}
+
+// Use is a helper method to define mock.On call
+//   - userId uint64
+//   - hash string
+func (_e *MockRepositoryRecoveryCode_Expecter) Use(userId interface{}, hash interface{}) *MockRepositoryRecoveryCode_Use_Call {
+	return &MockRepositoryRecoveryCode_Use_Call{Call: _e.mock.On("Use", userId, hash)}
+}
+
+func (_c *MockRepositoryRecoveryCode_Use_Call) Run(run func(userId uint64, hash string)) *MockRepositoryRecoveryCode_Use_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryRecoveryCode_Use_Call) Return(used bool, err error) *MockRepositoryRecoveryCode_Use_Call {
+	_c.Call.Return(used, err)
+	return _c
+}
+
+func (_c *MockRepositoryRecoveryCode_Use_Call) RunAndReturn(run func(uint64, string) (bool, error)) *MockRepositoryRecoveryCode_Use_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRepositoryRecoveryCode creates a new instance of MockRepositoryRecoveryCode. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRepositoryRecoveryCode(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRepositoryRecoveryCode {
+	mock := &MockRepositoryRecoveryCode{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
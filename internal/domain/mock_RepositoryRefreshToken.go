@@ -0,0 +1,262 @@
+// Code generated by mockery v2.34.2. DO NOT EDIT.
+
+package domain
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockRepositoryRefreshToken is an autogenerated mock type for the RepositoryRefreshToken type
+type MockRepositoryRefreshToken struct {
+	mock.Mock
+}
+
+type MockRepositoryRefreshToken_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRepositoryRefreshToken) EXPECT() *MockRepositoryRefreshToken_Expecter {
+	return &MockRepositoryRefreshToken_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: token
+func (_m *MockRepositoryRefreshToken) Create(token RefreshToken) (RefreshToken, error) {
+	ret := _m.Called(token)
+
+	var r0 RefreshToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(RefreshToken) (RefreshToken, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(RefreshToken) RefreshToken); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Get(0).(RefreshToken)
+	}
+
+	if rf, ok := ret.Get(1).(func(RefreshToken) error); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryRefreshToken_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockRepositoryRefreshToken_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - token RefreshToken
+func (_e *MockRepositoryRefreshToken_Expecter) Create(token interface{}) *MockRepositoryRefreshToken_Create_Call {
+	return &MockRepositoryRefreshToken_Create_Call{Call: _e.mock.On("Create", token)}
+}
+
+func (_c *MockRepositoryRefreshToken_Create_Call) Run(run func(token RefreshToken)) *MockRepositoryRefreshToken_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(RefreshToken))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryRefreshToken_Create_Call) Return(created RefreshToken, err error) *MockRepositoryRefreshToken_Create_Call {
+	_c.Call.Return(created, err)
+	return _c
+}
+
+func (_c *MockRepositoryRefreshToken_Create_Call) RunAndReturn(run func(RefreshToken) (RefreshToken, error)) *MockRepositoryRefreshToken_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByHash provides a mock function with given fields: hash
+func (_m *MockRepositoryRefreshToken) GetByHash(hash string) (RefreshToken, error) {
+	ret := _m.Called(hash)
+
+	var r0 RefreshToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (RefreshToken, error)); ok {
+		return rf(hash)
+	}
+	if rf, ok := ret.Get(0).(func(string) RefreshToken); ok {
+		r0 = rf(hash)
+	} else {
+		r0 = ret.Get(0).(RefreshToken)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryRefreshToken_GetByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByHash'
+type MockRepositoryRefreshToken_GetByHash_Call struct {
+	*mock.Call
+}
+
+// GetByHash is a helper method to define mock.On call
+//   - hash string
+func (_e *MockRepositoryRefreshToken_Expecter) GetByHash(hash interface{}) *MockRepositoryRefreshToken_GetByHash_Call {
+	return &MockRepositoryRefreshToken_GetByHash_Call{Call: _e.mock.On("GetByHash", hash)}
+}
+
+func (_c *MockRepositoryRefreshToken_GetByHash_Call) Run(run func(hash string)) *MockRepositoryRefreshToken_GetByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryRefreshToken_GetByHash_Call) Return(token RefreshToken, err error) *MockRepositoryRefreshToken_GetByHash_Call {
+	_c.Call.Return(token, err)
+	return _c
+}
+
+func (_c *MockRepositoryRefreshToken_GetByHash_Call) RunAndReturn(run func(string) (RefreshToken, error)) *MockRepositoryRefreshToken_GetByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeFamily provides a mock function with given fields: family
+func (_m *MockRepositoryRefreshToken) RevokeFamily(family string) error {
+	ret := _m.Called(family)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(family)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositoryRefreshToken_RevokeFamily_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeFamily'
+type MockRepositoryRefreshToken_RevokeFamily_Call struct {
+	*mock.Call
+}
+
+// RevokeFamily is a helper method to define mock.On call
+//   - family string
+func (_e *MockRepositoryRefreshToken_Expecter) RevokeFamily(family interface{}) *MockRepositoryRefreshToken_RevokeFamily_Call {
+	return &MockRepositoryRefreshToken_RevokeFamily_Call{Call: _e.mock.On("RevokeFamily", family)}
+}
+
+func (_c *MockRepositoryRefreshToken_RevokeFamily_Call) Run(run func(family string)) *MockRepositoryRefreshToken_RevokeFamily_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryRefreshToken_RevokeFamily_Call) Return(_a0 error) *MockRepositoryRefreshToken_RevokeFamily_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositoryRefreshToken_RevokeFamily_Call) RunAndReturn(run func(string) error) *MockRepositoryRefreshToken_RevokeFamily_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeUser provides a mock function with given fields: userId
+func (_m *MockRepositoryRefreshToken) RevokeUser(userId uint64) error {
+	ret := _m.Called(userId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint64) error); ok {
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositoryRefreshToken_RevokeUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeUser'
+type MockRepositoryRefreshToken_RevokeUser_Call struct {
+	*mock.Call
+}
+
+// RevokeUser is a helper method to define mock.On call
+//   - userId uint64
+func (_e *MockRepositoryRefreshToken_Expecter) RevokeUser(userId interface{}) *MockRepositoryRefreshToken_RevokeUser_Call {
+	return &MockRepositoryRefreshToken_RevokeUser_Call{Call: _e.mock.On("RevokeUser", userId)}
+}
+
+func (_c *MockRepositoryRefreshToken_RevokeUser_Call) Run(run func(userId uint64)) *MockRepositoryRefreshToken_RevokeUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryRefreshToken_RevokeUser_Call) Return(_a0 error) *MockRepositoryRefreshToken_RevokeUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositoryRefreshToken_RevokeUser_Call) RunAndReturn(run func(uint64) error) *MockRepositoryRefreshToken_RevokeUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Use provides a mock function with given fields: id
+func (_m *MockRepositoryRefreshToken) Use(id uint64) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint64) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositoryRefreshToken_Use_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Use'
+type MockRepositoryRefreshToken_Use_Call struct {
+	*mock.Call
+}
+
+// Use is a helper method to define mock.On call
+//   - id uint64
+func (_e *MockRepositoryRefreshToken_Expecter) Use(id interface{}) *MockRepositoryRefreshToken_Use_Call {
+	return &MockRepositoryRefreshToken_Use_Call{Call: _e.mock.On("Use", id)}
+}
+
+func (_c *MockRepositoryRefreshToken_Use_Call) Run(run func(id uint64)) *MockRepositoryRefreshToken_Use_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryRefreshToken_Use_Call) Return(_a0 error) *MockRepositoryRefreshToken_Use_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositoryRefreshToken_Use_Call) RunAndReturn(run func(uint64) error) *MockRepositoryRefreshToken_Use_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRepositoryRefreshToken creates a new instance of MockRepositoryRefreshToken. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRepositoryRefreshToken(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRepositoryRefreshToken {
+	mock := &MockRepositoryRefreshToken{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -2,7 +2,13 @@
 
 package domain
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+
+	utils "github.com/djordjev/auth/internal/utils"
+)
 
 // MockRepositorySession is an autogenerated mock type for the RepositorySession type
 type MockRepositorySession struct {
@@ -17,23 +23,23 @@ func (_m *MockRepositorySession) EXPECT() *MockRepositorySession_Expecter {
 	return &MockRepositorySession_Expecter{mock: &_m.Mock}
 }
 
-// Create provides a mock function with given fields: user
-func (_m *MockRepositorySession) Create(user User) (Session, error) {
-	ret := _m.Called(user)
+// Create provides a mock function with given fields: user, client, idle, lifetime
+func (_m *MockRepositorySession) Create(user User, client utils.Client, idle time.Duration, lifetime time.Duration) (Session, error) {
+	ret := _m.Called(user, client, idle, lifetime)
 
 	var r0 Session
 	var r1 error
-	if rf, ok := ret.Get(0).(func(User) (Session, error)); ok {
-		return rf(user)
+	if rf, ok := ret.Get(0).(func(User, utils.Client, time.Duration, time.Duration) (Session, error)); ok {
+		return rf(user, client, idle, lifetime)
 	}
-	if rf, ok := ret.Get(0).(func(User) Session); ok {
-		r0 = rf(user)
+	if rf, ok := ret.Get(0).(func(User, utils.Client, time.Duration, time.Duration) Session); ok {
+		r0 = rf(user, client, idle, lifetime)
 	} else {
 		r0 = ret.Get(0).(Session)
 	}
 
-	if rf, ok := ret.Get(1).(func(User) error); ok {
-		r1 = rf(user)
+	if rf, ok := ret.Get(1).(func(User, utils.Client, time.Duration, time.Duration) error); ok {
+		r1 = rf(user, client, idle, lifetime)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -48,13 +54,16 @@ type MockRepositorySession_Create_Call struct {
 
 // Create is a helper method to define mock.On call
 //   - user User
-func (_e *MockRepositorySession_Expecter) Create(user interface{}) *MockRepositorySession_Create_Call {
-	return &MockRepositorySession_Create_Call{Call: _e.mock.On("Create", user)}
+//   - client utils.Client
+//   - idle time.Duration
+//   - lifetime time.Duration
+func (_e *MockRepositorySession_Expecter) Create(user interface{}, client interface{}, idle interface{}, lifetime interface{}) *MockRepositorySession_Create_Call {
+	return &MockRepositorySession_Create_Call{Call: _e.mock.On("Create", user, client, idle, lifetime)}
 }
 
-func (_c *MockRepositorySession_Create_Call) Run(run func(user User)) *MockRepositorySession_Create_Call {
+func (_c *MockRepositorySession_Create_Call) Run(run func(user User, client utils.Client, idle time.Duration, lifetime time.Duration)) *MockRepositorySession_Create_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(User))
+		run(args[0].(User), args[1].(utils.Client), args[2].(time.Duration), args[3].(time.Duration))
 	})
 	return _c
 }
@@ -64,7 +73,7 @@ func (_c *MockRepositorySession_Create_Call) Return(session Session, err error)
 	return _c
 }
 
-func (_c *MockRepositorySession_Create_Call) RunAndReturn(run func(User) (Session, error)) *MockRepositorySession_Create_Call {
+func (_c *MockRepositorySession_Create_Call) RunAndReturn(run func(User, utils.Client, time.Duration, time.Duration) (Session, error)) *MockRepositorySession_Create_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -163,6 +172,154 @@ func (_c *MockRepositorySession_Get_Call) RunAndReturn(run func(string) (User, e
 	return _c
 }
 
+// GetByUser provides a mock function with given fields: userId
+func (_m *MockRepositorySession) GetByUser(userId uint64) ([]Session, error) {
+	ret := _m.Called(userId)
+
+	var r0 []Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint64) ([]Session, error)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(uint64) []Session); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint64) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositorySession_GetByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByUser'
+type MockRepositorySession_GetByUser_Call struct {
+	*mock.Call
+}
+
+// GetByUser is a helper method to define mock.On call
+//   - userId uint64
+func (_e *MockRepositorySession_Expecter) GetByUser(userId interface{}) *MockRepositorySession_GetByUser_Call {
+	return &MockRepositorySession_GetByUser_Call{Call: _e.mock.On("GetByUser", userId)}
+}
+
+func (_c *MockRepositorySession_GetByUser_Call) Run(run func(userId uint64)) *MockRepositorySession_GetByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64))
+	})
+	return _c
+}
+
+func (_c *MockRepositorySession_GetByUser_Call) Return(sessions []Session, err error) *MockRepositorySession_GetByUser_Call {
+	_c.Call.Return(sessions, err)
+	return _c
+}
+
+func (_c *MockRepositorySession_GetByUser_Call) RunAndReturn(run func(uint64) ([]Session, error)) *MockRepositorySession_GetByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Peek provides a mock function with given fields: key
+func (_m *MockRepositorySession) Peek(key string) (Session, error) {
+	ret := _m.Called(key)
+
+	var r0 Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (Session, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) Session); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Get(0).(Session)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositorySession_Peek_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Peek'
+type MockRepositorySession_Peek_Call struct {
+	*mock.Call
+}
+
+// Peek is a helper method to define mock.On call
+//   - key string
+func (_e *MockRepositorySession_Expecter) Peek(key interface{}) *MockRepositorySession_Peek_Call {
+	return &MockRepositorySession_Peek_Call{Call: _e.mock.On("Peek", key)}
+}
+
+func (_c *MockRepositorySession_Peek_Call) Run(run func(key string)) *MockRepositorySession_Peek_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositorySession_Peek_Call) Return(session Session, err error) *MockRepositorySession_Peek_Call {
+	_c.Call.Return(session, err)
+	return _c
+}
+
+func (_c *MockRepositorySession_Peek_Call) RunAndReturn(run func(string) (Session, error)) *MockRepositorySession_Peek_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateUser provides a mock function with given fields: user
+func (_m *MockRepositorySession) UpdateUser(user User) error {
+	ret := _m.Called(user)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(User) error); ok {
+		r0 = rf(user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositorySession_UpdateUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateUser'
+type MockRepositorySession_UpdateUser_Call struct {
+	*mock.Call
+}
+
+// UpdateUser is a helper method to define mock.On call
+//   - user User
+func (_e *MockRepositorySession_Expecter) UpdateUser(user interface{}) *MockRepositorySession_UpdateUser_Call {
+	return &MockRepositorySession_UpdateUser_Call{Call: _e.mock.On("UpdateUser", user)}
+}
+
+func (_c *MockRepositorySession_UpdateUser_Call) Run(run func(user User)) *MockRepositorySession_UpdateUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(User))
+	})
+	return _c
+}
+
+func (_c *MockRepositorySession_UpdateUser_Call) Return(_a0 error) *MockRepositorySession_UpdateUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositorySession_UpdateUser_Call) RunAndReturn(run func(User) error) *MockRepositorySession_UpdateUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockRepositorySession creates a new instance of MockRepositorySession. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockRepositorySession(t interface {
@@ -0,0 +1,267 @@
+// Code generated by mockery v2.34.2. DO NOT EDIT.
+
+package domain
+
+import (
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockRepositorySigningKey is an autogenerated mock type for the RepositorySigningKey type
+type MockRepositorySigningKey struct {
+	mock.Mock
+}
+
+type MockRepositorySigningKey_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRepositorySigningKey) EXPECT() *MockRepositorySigningKey_Expecter {
+	return &MockRepositorySigningKey_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: key
+func (_m *MockRepositorySigningKey) Create(key SigningKey) (SigningKey, error) {
+	ret := _m.Called(key)
+
+	var r0 SigningKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(SigningKey) (SigningKey, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(SigningKey) SigningKey); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Get(0).(SigningKey)
+	}
+
+	if rf, ok := ret.Get(1).(func(SigningKey) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositorySigningKey_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockRepositorySigningKey_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - key SigningKey
+func (_e *MockRepositorySigningKey_Expecter) Create(key interface{}) *MockRepositorySigningKey_Create_Call {
+	return &MockRepositorySigningKey_Create_Call{Call: _e.mock.On("Create", key)}
+}
+
+func (_c *MockRepositorySigningKey_Create_Call) Run(run func(key SigningKey)) *MockRepositorySigningKey_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(SigningKey))
+	})
+	return _c
+}
+
+func (_c *MockRepositorySigningKey_Create_Call) Return(created SigningKey, err error) *MockRepositorySigningKey_Create_Call {
+	_c.Call.Return(created, err)
+	return _c
+}
+
+func (_c *MockRepositorySigningKey_Create_Call) RunAndReturn(run func(SigningKey) (SigningKey, error)) *MockRepositorySigningKey_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteRetired provides a mock function with given fields: before
+func (_m *MockRepositorySigningKey) DeleteRetired(before time.Time) error {
+	ret := _m.Called(before)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(time.Time) error); ok {
+		r0 = rf(before)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositorySigningKey_DeleteRetired_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteRetired'
+type MockRepositorySigningKey_DeleteRetired_Call struct {
+	*mock.Call
+}
+
+// DeleteRetired is a helper method to define mock.On call
+//   - before time.Time
+func (_e *MockRepositorySigningKey_Expecter) DeleteRetired(before interface{}) *MockRepositorySigningKey_DeleteRetired_Call {
+	return &MockRepositorySigningKey_DeleteRetired_Call{Call: _e.mock.On("DeleteRetired", before)}
+}
+
+func (_c *MockRepositorySigningKey_DeleteRetired_Call) Run(run func(before time.Time)) *MockRepositorySigningKey_DeleteRetired_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockRepositorySigningKey_DeleteRetired_Call) Return(_a0 error) *MockRepositorySigningKey_DeleteRetired_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositorySigningKey_DeleteRetired_Call) RunAndReturn(run func(time.Time) error) *MockRepositorySigningKey_DeleteRetired_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPublished provides a mock function with given fields: retiredAfter
+func (_m *MockRepositorySigningKey) GetPublished(retiredAfter time.Time) ([]SigningKey, error) {
+	ret := _m.Called(retiredAfter)
+
+	var r0 []SigningKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) ([]SigningKey, error)); ok {
+		return rf(retiredAfter)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) []SigningKey); ok {
+		r0 = rf(retiredAfter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]SigningKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(retiredAfter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositorySigningKey_GetPublished_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPublished'
+type MockRepositorySigningKey_GetPublished_Call struct {
+	*mock.Call
+}
+
+// GetPublished is a helper method to define mock.On call
+//   - retiredAfter time.Time
+func (_e *MockRepositorySigningKey_Expecter) GetPublished(retiredAfter interface{}) *MockRepositorySigningKey_GetPublished_Call {
+	return &MockRepositorySigningKey_GetPublished_Call{Call: _e.mock.On("GetPublished", retiredAfter)}
+}
+
+func (_c *MockRepositorySigningKey_GetPublished_Call) Run(run func(retiredAfter time.Time)) *MockRepositorySigningKey_GetPublished_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockRepositorySigningKey_GetPublished_Call) Return(keys []SigningKey, err error) *MockRepositorySigningKey_GetPublished_Call {
+	_c.Call.Return(keys, err)
+	return _c
+}
+
+func (_c *MockRepositorySigningKey_GetPublished_Call) RunAndReturn(run func(time.Time) ([]SigningKey, error)) *MockRepositorySigningKey_GetPublished_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Lock provides a mock function with given fields:
+func (_m *MockRepositorySigningKey) Lock() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositorySigningKey_Lock_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Lock'
+type MockRepositorySigningKey_Lock_Call struct {
+	*mock.Call
+}
+
+// Lock is a helper method to define mock.On call
+func (_e *MockRepositorySigningKey_Expecter) Lock() *MockRepositorySigningKey_Lock_Call {
+	return &MockRepositorySigningKey_Lock_Call{Call: _e.mock.On("Lock")}
+}
+
+func (_c *MockRepositorySigningKey_Lock_Call) Run(run func()) *MockRepositorySigningKey_Lock_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockRepositorySigningKey_Lock_Call) Return(_a0 error) *MockRepositorySigningKey_Lock_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositorySigningKey_Lock_Call) RunAndReturn(run func() error) *MockRepositorySigningKey_Lock_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Retire provides a mock function with given fields: at
+func (_m *MockRepositorySigningKey) Retire(at time.Time) error {
+	ret := _m.Called(at)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(time.Time) error); ok {
+		r0 = rf(at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositorySigningKey_Retire_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Retire'
+type MockRepositorySigningKey_Retire_Call struct {
+	*mock.Call
+}
+
+// Retire is a helper method to define mock.On call
+//   - at time.Time
+func (_e *MockRepositorySigningKey_Expecter) Retire(at interface{}) *MockRepositorySigningKey_Retire_Call {
+	return &MockRepositorySigningKey_Retire_Call{Call: _e.mock.On("Retire", at)}
+}
+
+func (_c *MockRepositorySigningKey_Retire_Call) Run(run func(at time.Time)) *MockRepositorySigningKey_Retire_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockRepositorySigningKey_Retire_Call) Return(_a0 error) *MockRepositorySigningKey_Retire_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositorySigningKey_Retire_Call) RunAndReturn(run func(time.Time) error) *MockRepositorySigningKey_Retire_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRepositorySigningKey creates a new instance of MockRepositorySigningKey. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRepositorySigningKey(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRepositorySigningKey {
+	mock := &MockRepositorySigningKey{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
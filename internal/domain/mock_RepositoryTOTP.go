@@ -0,0 +1,223 @@
+// Code generated by mockery v2.34.2. DO NOT EDIT.
+
+package domain
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockRepositoryTOTP is an autogenerated mock type for the RepositoryTOTP type
+type MockRepositoryTOTP struct {
+	mock.Mock
+}
+
+type MockRepositoryTOTP_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRepositoryTOTP) EXPECT() *MockRepositoryTOTP_Expecter {
+	return &MockRepositoryTOTP_Expecter{mock: &_m.Mock}
+}
+
+// Confirm provides a mock function with given fields: userId, step
+func (_m *MockRepositoryTOTP) Confirm(userId uint64, step int64) error {
+	ret := _m.Called(userId, step)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint64, int64) error); ok {
+		r0 = rf(userId, step)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositoryTOTP_Confirm_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Confirm'
+type MockRepositoryTOTP_Confirm_Call struct {
+	*mock.Call
+}
+
+// Confirm is a helper method to define mock.On call
+//   - userId uint64
+//   - step int64
+func (_e *MockRepositoryTOTP_Expecter) Confirm(userId interface{}, step interface{}) *MockRepositoryTOTP_Confirm_Call {
+	return &MockRepositoryTOTP_Confirm_Call{Call: _e.mock.On("Confirm", userId, step)}
+}
+
+func (_c *MockRepositoryTOTP_Confirm_Call) Run(run func(userId uint64, step int64)) *MockRepositoryTOTP_Confirm_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryTOTP_Confirm_Call) Return(_a0 error) *MockRepositoryTOTP_Confirm_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositoryTOTP_Confirm_Call) RunAndReturn(run func(uint64, int64) error) *MockRepositoryTOTP_Confirm_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Create provides a mock function with given fields: userId, secret
+func (_m *MockRepositoryTOTP) Create(userId uint64, secret string) (TOTP, error) {
+	ret := _m.Called(userId, secret)
+
+	var r0 TOTP
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint64, string) (TOTP, error)); ok {
+		return rf(userId, secret)
+	}
+	if rf, ok := ret.Get(0).(func(uint64, string) TOTP); ok {
+		r0 = rf(userId, secret)
+	} else {
+		r0 = ret.Get(0).(TOTP)
+	}
+
+	if rf, ok := ret.Get(1).(func(uint64, string) error); ok {
+		r1 = rf(userId, secret)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryTOTP_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockRepositoryTOTP_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - userId uint64
+//   - secret string
+func (_e *MockRepositoryTOTP_Expecter) Create(userId interface{}, secret interface{}) *MockRepositoryTOTP_Create_Call {
+	return &MockRepositoryTOTP_Create_Call{Call: _e.mock.On("Create", userId, secret)}
+}
+
+func (_c *MockRepositoryTOTP_Create_Call) Run(run func(userId uint64, secret string)) *MockRepositoryTOTP_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryTOTP_Create_Call) Return(totp TOTP, err error) *MockRepositoryTOTP_Create_Call {
+	_c.Call.Return(totp, err)
+	return _c
+}
+
+func (_c *MockRepositoryTOTP_Create_Call) RunAndReturn(run func(uint64, string) (TOTP, error)) *MockRepositoryTOTP_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function with given fields: userId
+func (_m *MockRepositoryTOTP) Get(userId uint64) (TOTP, error) {
+	ret := _m.Called(userId)
+
+	var r0 TOTP
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint64) (TOTP, error)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(uint64) TOTP); ok {
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Get(0).(TOTP)
+	}
+
+	if rf, ok := ret.Get(1).(func(uint64) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryTOTP_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockRepositoryTOTP_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - userId uint64
+func (_e *MockRepositoryTOTP_Expecter) Get(userId interface{}) *MockRepositoryTOTP_Get_Call {
+	return &MockRepositoryTOTP_Get_Call{Call: _e.mock.On("Get", userId)}
+}
+
+func (_c *MockRepositoryTOTP_Get_Call) Run(run func(userId uint64)) *MockRepositoryTOTP_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryTOTP_Get_Call) Return(totp TOTP, err error) *MockRepositoryTOTP_Get_Call {
+	_c.Call.Return(totp, err)
+	return _c
+}
+
+func (_c *MockRepositoryTOTP_Get_Call) RunAndReturn(run func(uint64) (TOTP, error)) *MockRepositoryTOTP_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetLastStep provides a mock function with given fields: userId, step
+func (_m *MockRepositoryTOTP) SetLastStep(userId uint64, step int64) error {
+	ret := _m.Called(userId, step)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint64, int64) error); ok {
+		r0 = rf(userId, step)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositoryTOTP_SetLastStep_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetLastStep'
+type MockRepositoryTOTP_SetLastStep_Call struct {
+	*mock.Call
+}
+
+// SetLastStep is a helper method to define mock.On call
+//   - userId uint64
+//   - step int64
+func (_e *MockRepositoryTOTP_Expecter) SetLastStep(userId interface{}, step interface{}) *MockRepositoryTOTP_SetLastStep_Call {
+	return &MockRepositoryTOTP_SetLastStep_Call{Call: _e.mock.On("SetLastStep", userId, step)}
+}
+
+func (_c *MockRepositoryTOTP_SetLastStep_Call) Run(run func(userId uint64, step int64)) *MockRepositoryTOTP_SetLastStep_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryTOTP_SetLastStep_Call) Return(_a0 error) *MockRepositoryTOTP_SetLastStep_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositoryTOTP_SetLastStep_Call) RunAndReturn(run func(uint64, int64) error) *MockRepositoryTOTP_SetLastStep_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRepositoryTOTP creates a new instance of MockRepositoryTOTP. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRepositoryTOTP(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRepositoryTOTP {
+	mock := &MockRepositoryTOTP{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
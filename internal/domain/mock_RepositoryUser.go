@@ -173,6 +173,58 @@ func (_c *MockRepositoryUser_GetByEmail_Call) RunAndReturn(run func(string) (Use
 	return _c
 }
 
+// GetByID provides a mock function with given fields: id
+func (_m *MockRepositoryUser) GetByID(id uint64) (User, error) {
+	ret := _m.Called(id)
+
+	var r0 User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint64) (User, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uint64) User); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(User)
+	}
+
+	if rf, ok := ret.Get(1).(func(uint64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRepositoryUser_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockRepositoryUser_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - id uint64
+func (_e *MockRepositoryUser_Expecter) GetByID(id interface{}) *MockRepositoryUser_GetByID_Call {
+	return &MockRepositoryUser_GetByID_Call{Call: _e.mock.On("GetByID", id)}
+}
+
+func (_c *MockRepositoryUser_GetByID_Call) Run(run func(id uint64)) *MockRepositoryUser_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint64))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryUser_GetByID_Call) Return(user User, err error) *MockRepositoryUser_GetByID_Call {
+	_c.Call.Return(user, err)
+	return _c
+}
+
+func (_c *MockRepositoryUser_GetByID_Call) RunAndReturn(run func(uint64) (User, error)) *MockRepositoryUser_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetByUsername provides a mock function with given fields: username
 func (_m *MockRepositoryUser) GetByUsername(username string) (User, error) {
 	ret := _m.Called(username)
@@ -268,6 +320,92 @@ func (_c *MockRepositoryUser_SetPassword_Call) RunAndReturn(run func(User, strin
 	return _c
 }
 
+// SetPayload provides a mock function with given fields: user, payload
+func (_m *MockRepositoryUser) SetPayload(user User, payload map[string]interface{}) error {
+	ret := _m.Called(user, payload)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(User, map[string]interface{}) error); ok {
+		r0 = rf(user, payload)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositoryUser_SetPayload_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetPayload'
+type MockRepositoryUser_SetPayload_Call struct {
+	*mock.Call
+}
+
+// SetPayload is a helper method to define mock.On call
+//   - user User
+//   - payload map[string]interface{}
+func (_e *MockRepositoryUser_Expecter) SetPayload(user interface{}, payload interface{}) *MockRepositoryUser_SetPayload_Call {
+	return &MockRepositoryUser_SetPayload_Call{Call: _e.mock.On("SetPayload", user, payload)}
+}
+
+func (_c *MockRepositoryUser_SetPayload_Call) Run(run func(user User, payload map[string]interface{})) *MockRepositoryUser_SetPayload_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(User), args[1].(map[string]interface{}))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryUser_SetPayload_Call) Return(_a0 error) *MockRepositoryUser_SetPayload_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositoryUser_SetPayload_Call) RunAndReturn(run func(User, map[string]interface{}) error) *MockRepositoryUser_SetPayload_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetRole provides a mock function with given fields: user, role
+func (_m *MockRepositoryUser) SetRole(user User, role string) error {
+	ret := _m.Called(user, role)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(User, string) error); ok {
+		r0 = rf(user, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRepositoryUser_SetRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetRole'
+type MockRepositoryUser_SetRole_Call struct {
+	*mock.Call
+}
+
+// SetRole is a helper method to define mock.On call
+//   - user User
+//   - role string
+func (_e *MockRepositoryUser_Expecter) SetRole(user interface{}, role interface{}) *MockRepositoryUser_SetRole_Call {
+	return &MockRepositoryUser_SetRole_Call{Call: _e.mock.On("SetRole", user, role)}
+}
+
+func (_c *MockRepositoryUser_SetRole_Call) Run(run func(user User, role string)) *MockRepositoryUser_SetRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(User), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRepositoryUser_SetRole_Call) Return(_a0 error) *MockRepositoryUser_SetRole_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRepositoryUser_SetRole_Call) RunAndReturn(run func(User, string) error) *MockRepositoryUser_SetRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Verify provides a mock function with given fields: user
 func (_m *MockRepositoryUser) Verify(user User) error {
 	ret := _m.Called(user)
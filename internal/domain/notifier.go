@@ -4,5 +4,11 @@ type Notifier interface {
 	Send(to string, subject string, text string, html string) error
 }
 
-var verifyTextTemplate = "Hello %s, thanks for creating new account. Please follow this link %s to verify it."
-var verifyHtmlTemplate = `<h2>Hello %s</h2><p>thanks for creating a new account. Please click the button below to verify it</p><a href="%s">Verify</a>`
+var verifyTextTemplate = "Hello %s, thanks for creating new account. Please follow this link %s to verify it or enter code %s in the app."
+var verifyHtmlTemplate = `<h2>Hello %s</h2><p>thanks for creating a new account. Please click the button below to verify it</p><a href="%s">Verify</a><p>or enter code <b>%s</b> in the app.</p>`
+
+var magicLinkTextTemplate = "Hello %s, follow this link %s to log in. Link expires in %d minutes and can be used only once."
+var magicLinkHtmlTemplate = `<h2>Hello %s</h2><p>please click the button below to log in. Link expires in %d minutes and can be used only once.</p><a href="%s">Log in</a>`
+
+var oneTimeCodeTextTemplate = "Hello %s, your login code is %s. Code expires in %d minutes."
+var oneTimeCodeHtmlTemplate = `<h2>Hello %s</h2><p>your login code is</p><h1>%s</h1><p>Code expires in %d minutes.</p>`
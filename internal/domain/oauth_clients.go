@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+const clientSecretBytes = 32
+
+// CreateOAuthClient registers client of the OpenID provider. Confidential clients get a
+// secret that is returned only once, afterwards only its hash is known. Public clients
+// have no secret and have to use PKCE.
+func (d *domain) CreateOAuthClient(setup Setup, client OAuthClient, public bool) (created OAuthClient, secret string, err error) {
+	client.ID = uuid.NewString()
+	client.SecretHash = ""
+
+	if !public {
+		random := make([]byte, clientSecretBytes)
+		if _, err = rand.Read(random); err != nil {
+			err = fmt.Errorf("domain CreateOAuthClient -> unable to generate secret %w", err)
+			return
+		}
+
+		secret = base64.RawURLEncoding.EncodeToString(random)
+		client.SecretHash = hashToken(secret)
+	}
+
+	created, err = d.db.OAuthClient(setup.ctx).Create(client)
+	if err != nil {
+		secret = ""
+		err = fmt.Errorf("domain CreateOAuthClient -> %w", err)
+	}
+
+	return
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateOAuthClient(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	client := OAuthClient{Name: "wiki", RedirectURIs: []string{"https://wiki.example.com/callback"}, Trusted: true}
+
+	tests := []struct {
+		name   string
+		public bool
+	}{
+		{name: "confidential"},
+		{name: "public", public: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			clientRepository := NewMockRepositoryOAuthClient(t)
+
+			// Setup mocks
+			var stored OAuthClient
+			repository.EXPECT().OAuthClient(context.TODO()).Return(clientRepository)
+			clientRepository.EXPECT().Create(mock.Anything).RunAndReturn(func(c OAuthClient) (OAuthClient, error) {
+				stored = c
+				return c, nil
+			})
+
+			// Run
+			domain := NewDomain(repository, utils.Config{}, NewMockNotifier(t))
+			created, secret, err := domain.CreateOAuthClient(setup, client, tc.public)
+
+			// Assertions
+			require.NoError(t, err)
+			require.NotEmpty(t, created.ID)
+			require.Equal(t, stored, created)
+			require.Equal(t, client.Name, stored.Name)
+			require.Equal(t, client.RedirectURIs, stored.RedirectURIs)
+			require.True(t, stored.Trusted)
+
+			if tc.public {
+				require.Empty(t, secret)
+				require.Empty(t, stored.SecretHash)
+				return
+			}
+
+			require.NotEmpty(t, secret)
+			require.Equal(t, hashToken(secret), stored.SecretHash)
+		})
+	}
+}
@@ -0,0 +1,421 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+)
+
+// oidcState is kept between redirect to the provider and its callback.
+// UserID is set when signed in user links new provider to the account.
+type oidcState struct {
+	Provider string `json:"provider"`
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+	UserID   uint64 `json:"user_id"`
+}
+
+type oidcClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+type oidcClient struct {
+	config   utils.OIDCProvider
+	provider *oidc.Provider
+	oauth    oauth2.Config
+}
+
+type discoveredProvider struct {
+	provider     *oidc.Provider
+	discoveredAt time.Time
+}
+
+// oidcProviders caches discovered providers so discovery document isn't fetched on every
+// redirect and callback. Providers are discovered again after OIDC_DISCOVERY_REFRESH.
+type oidcProviders struct {
+	mu         sync.Mutex
+	discovered map[string]discoveredProvider
+}
+
+func (p *oidcProviders) get(setup Setup, config utils.OIDCProvider) (provider *oidc.Provider, err error) {
+	p.mu.Lock()
+	cached, ok := p.discovered[config.Name]
+	p.mu.Unlock()
+
+	if ok && time.Since(cached.discoveredAt) < utils.OIDC_DISCOVERY_REFRESH {
+		return cached.provider, nil
+	}
+
+	provider, err = oidc.NewProvider(setup.ctx, config.Issuer)
+	if err != nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovered == nil {
+		p.discovered = map[string]discoveredProvider{}
+	}
+
+	p.discovered[config.Name] = discoveredProvider{provider: provider, discoveredAt: time.Now()}
+
+	return
+}
+
+func (d *domain) oidcClient(setup Setup, name string) (client oidcClient, err error) {
+	config, ok := d.config.GetOIDCProvider(name)
+	if !ok {
+		err = ErrUnknownProvider
+		return
+	}
+
+	provider, err := d.oidc.get(setup, config)
+	if err != nil {
+		err = fmt.Errorf("unable to discover provider %s %w", name, err)
+		return
+	}
+
+	client.config = config
+	client.provider = provider
+	client.oauth = oauth2.Config{
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		Endpoint:     provider.Endpoint(),
+		RedirectURL:  fmt.Sprintf("%s/%s/callback", d.config.OIDCRedirectURL, name),
+		Scopes:       config.Scopes,
+	}
+
+	return
+}
+
+// BeginOIDCLogIn returns provider's authorization URL and the state it carries. When called
+// with session token identity from the provider gets linked to the signed in user.
+func (d *domain) BeginOIDCLogIn(setup Setup, provider string, token string) (authURL string, stateKey string, err error) {
+	client, err := d.oidcClient(setup, provider)
+	if err != nil {
+		return
+	}
+
+	state := oidcState{
+		Provider: provider,
+		Verifier: oauth2.GenerateVerifier(),
+		Nonce:    uuid.NewString(),
+	}
+
+	if token != "" {
+		user, e := d.loginSession(setup, token)
+		if e != nil {
+			err = e
+			return
+		}
+
+		state.UserID = user.ID
+	}
+
+	stateKey, err = d.storeCeremony(setup, state)
+	if err != nil {
+		err = fmt.Errorf("domain BeginOIDCLogIn -> %w", err)
+		return
+	}
+
+	authURL = client.oauth.AuthCodeURL(stateKey, oidc.Nonce(state.Nonce), oauth2.S256ChallengeOption(state.Verifier))
+
+	return
+}
+
+func (d *domain) FinishOIDCLogIn(setup Setup, provider string, stateKey string, code string) (existing User, sessionKey string, err error) {
+	var state oidcState
+	err = d.takeCeremonyState(setup, stateKey, &state)
+	if err != nil {
+		return
+	}
+
+	if state.Provider != provider {
+		err = ErrInvalidCeremony
+		return
+	}
+
+	client, err := d.oidcClient(setup, provider)
+	if err != nil {
+		return
+	}
+
+	token, err := client.oauth.Exchange(setup.ctx, code, oauth2.VerifierOption(state.Verifier))
+	if err != nil {
+		err = fmt.Errorf("%w: code exchange with %s failed %w", ErrInvalidIdentity, provider, err)
+		return
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		err = fmt.Errorf("%w: %s did not return id token", ErrInvalidIdentity, provider)
+		return
+	}
+
+	idToken, err := client.provider.Verifier(&oidc.Config{ClientID: client.config.ClientID}).Verify(setup.ctx, rawIDToken)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", ErrInvalidIdentity, err)
+		return
+	}
+
+	if idToken.Nonce != state.Nonce {
+		err = fmt.Errorf("%w: nonce mismatch", ErrInvalidIdentity)
+		return
+	}
+
+	var claims oidcClaims
+	err = idToken.Claims(&claims)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", ErrInvalidIdentity, err)
+		return
+	}
+
+	identity := Identity{Provider: provider, Subject: idToken.Subject, Email: claims.Email}
+
+	profile := User{Email: claims.Email, Verified: claims.EmailVerified}
+
+	user, err := d.resolveIdentity(setup, client.config.AutoLink, state.UserID, identity, profile)
+	if err != nil {
+		return
+	}
+
+	// linking is done by already authenticated user so there's no second factor
+	if state.UserID != 0 {
+		sessionKey, err = d.startSession(setup, user)
+		if err == nil {
+			existing = user
+		}
+
+		return
+	}
+
+	return d.completeLogIn(setup, user, provider)
+}
+
+// resolveIdentity finds user the external identity belongs to. Unknown identity is linked to
+// the signed in user starting the linking, to the existing account with the same email if provider allows auto
+// linking and email is verified or to a new account created from the profile if the email is verified and not taken.
+func (d *domain) resolveIdentity(setup Setup, autoLink bool, linkTo uint64, identity Identity, profile User) (user User, err error) {
+	userModel := d.db.User(setup.ctx)
+	identityModel := d.db.Identity(setup.ctx)
+
+	linked, err := identityModel.Get(identity.Provider, identity.Subject)
+	if err == nil {
+		if linkTo != 0 && linkTo != linked.UserID {
+			err = ErrIdentityInUse
+			return
+		}
+
+		user, err = userModel.GetByID(linked.UserID)
+		if err != nil {
+			err = fmt.Errorf("unable to fetch user %d for identity %w", linked.UserID, err)
+		}
+
+		return
+	} else if !errors.Is(err, modelErrors.ErrNotFound) {
+		err = fmt.Errorf("unable to get %s identity %w", identity.Provider, err)
+		return
+	}
+
+	if linkTo != 0 {
+		identity.UserID = linkTo
+		return d.linkIdentity(setup, identity)
+	}
+
+	if profile.Email == "" {
+		err = fmt.Errorf("%w: %s did not return email", ErrInvalidIdentity, identity.Provider)
+		return
+	}
+
+	user, err = userModel.GetByEmail(profile.Email)
+	if err == nil {
+		// users of provider's domains can't log in with password so they couldn't link it themselves
+		connection, owned := d.connectionOf(profile.Email)
+		owned = owned && connection.Provider == identity.Provider
+
+		if !(autoLink || owned) || !profile.Verified {
+			user = User{}
+			err = ErrIdentityLinkRequired
+			return
+		}
+
+		identity.UserID = user.ID
+		return d.linkIdentity(setup, identity)
+	} else if !errors.Is(err, modelErrors.ErrNotFound) {
+		err = fmt.Errorf("unable to fetch user %s %w", profile.Email, err)
+		return
+	}
+
+	// account created from unverified email would belong to whoever claimed it at the provider
+	if !profile.Verified {
+		err = fmt.Errorf("%w: %s did not verify email %s", ErrInvalidIdentity, identity.Provider, profile.Email)
+		return
+	}
+
+	return d.createIdentityUser(setup, identity, profile)
+}
+
+// syncRole updates role of the user managed by external identity provider
+func (d *domain) syncRole(setup Setup, user User, role string) (synced User, err error) {
+	if user.Role == role {
+		return user, nil
+	}
+
+	err = d.db.User(setup.ctx).SetRole(user, role)
+	if err != nil {
+		err = fmt.Errorf("unable to sync role of user %d %w", user.ID, err)
+		return
+	}
+
+	synced = user
+	synced.Role = role
+	d.refreshSessions(setup, synced)
+
+	return
+}
+
+func (d *domain) linkIdentity(setup Setup, identity Identity) (user User, err error) {
+	user, err = d.db.User(setup.ctx).GetByID(identity.UserID)
+	if err != nil {
+		err = fmt.Errorf("unable to fetch user %d %w", identity.UserID, err)
+		return
+	}
+
+	_, err = d.db.Identity(setup.ctx).Create(identity)
+	if err != nil {
+		user = User{}
+		err = fmt.Errorf("unable to link identity %w", err)
+	}
+
+	return
+}
+
+// createIdentityUser signs up a new user without password so the account can be used
+// only through linked identity until password is set with reset
+func (d *domain) createIdentityUser(setup Setup, identity Identity, profile User) (user User, err error) {
+	err = d.db.Atomic(func(txRepo Repository) error {
+		created, e := txRepo.User(setup.ctx).Create(profile)
+		if e != nil {
+			return fmt.Errorf("unable to create user for %s identity %w", identity.Provider, e)
+		}
+
+		identity.UserID = created.ID
+		_, e = txRepo.Identity(setup.ctx).Create(identity)
+		if e != nil {
+			return fmt.Errorf("unable to link identity %w", e)
+		}
+
+		user = created
+		return nil
+	})
+
+	if err != nil {
+		user = User{}
+	}
+
+	return
+}
+
+func (d *domain) Identities(setup Setup, token string) (identities []Identity, err error) {
+	user, err := d.loginSession(setup, token)
+	if err != nil {
+		return
+	}
+
+	identities, err = d.db.Identity(setup.ctx).GetByUser(user.ID)
+	if err != nil {
+		err = fmt.Errorf("domain Identities -> failed to get identities of user %d %w", user.ID, err)
+	}
+
+	return
+}
+
+// UnlinkIdentity removes provider from the account of signed in user. Directory identities
+// are kept in sync with the directory and can't be unlinked, and the last identity is kept
+// unless user can log in with password or passkey.
+func (d *domain) UnlinkIdentity(setup Setup, token string, provider string) (err error) {
+	user, err := d.loginSession(setup, token)
+	if err != nil {
+		return
+	}
+
+	if provider == ldapProvider {
+		err = ErrIdentityManaged
+		return
+	}
+
+	identityModel := d.db.Identity(setup.ctx)
+
+	identities, err := identityModel.GetByUser(user.ID)
+	if err != nil {
+		err = fmt.Errorf("domain UnlinkIdentity -> failed to get identities of user %d %w", user.ID, err)
+		return
+	}
+
+	linked, others := false, 0
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			linked = true
+		} else {
+			others++
+		}
+	}
+
+	if !linked {
+		err = ErrIdentityNotFound
+		return
+	}
+
+	if others == 0 {
+		usable, e := d.hasLocalLogIn(setup, user)
+		if e != nil {
+			err = fmt.Errorf("domain UnlinkIdentity -> %w", e)
+			return
+		} else if !usable {
+			err = ErrLastLogInMethod
+			return
+		}
+	}
+
+	err = identityModel.Delete(user.ID, provider)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrIdentityNotFound
+	} else if err != nil {
+		err = fmt.Errorf("domain UnlinkIdentity -> %w", err)
+	}
+
+	return
+}
+
+// hasLocalLogIn checks if user can log in without identity provider, either with
+// password or with passkey
+func (d *domain) hasLocalLogIn(setup Setup, user User) (usable bool, err error) {
+	existing, err := d.db.User(setup.ctx).GetByID(user.ID)
+	if err != nil {
+		err = fmt.Errorf("unable to get user %d %w", user.ID, err)
+		return
+	}
+
+	if existing.Password != "" {
+		usable = true
+		return
+	}
+
+	passkeys, err := d.db.Passkey(setup.ctx).GetByUser(user.ID)
+	if err != nil {
+		err = fmt.Errorf("unable to get passkeys for user %d %w", user.ID, err)
+		return
+	}
+
+	usable = len(passkeys) > 0
+	return
+}
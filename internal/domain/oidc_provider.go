@@ -0,0 +1,501 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const (
+	authorizationConsent = "consent"
+	authorizationCode    = "code"
+)
+
+const accessTokenType = "at+jwt"
+
+// authorization is kept while the user is asked for consent and after that
+// until the client redeems issued code. Kind prevents using one for another.
+type authorization struct {
+	Kind     string               `json:"kind"`
+	Request  AuthorizationRequest `json:"request"`
+	UserID   uint64               `json:"user_id"`
+	AuthTime int64                `json:"auth_time"`
+}
+
+type accessTokenClaims struct {
+	jwt.Claims
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope"`
+}
+
+// Authorize handles authorization code request of a relying party. Errors that can be
+// reported to the client are returned as redirect, otherwise err is set. ErrConsentRequired
+// comes with the key of pending request which needs to be passed to Consent.
+func (d *domain) Authorize(setup Setup, token string, request AuthorizationRequest) (redirect string, consent string, err error) {
+	if !d.config.IsOIDCProvider() {
+		err = ErrProviderNotConfigured
+		return
+	}
+
+	client, err := d.authorizationClient(setup, request.ClientID, request.RedirectURI)
+	if err != nil {
+		return
+	}
+
+	scopes := strings.Fields(request.Scope)
+
+	if request.ResponseType != "code" {
+		redirect = authorizationError(request, "unsupported_response_type", "only code response type is supported")
+		return
+	}
+
+	if !slices.Contains(scopes, "openid") {
+		redirect = authorizationError(request, "invalid_scope", "openid scope is required")
+		return
+	}
+
+	if request.CodeChallenge == "" || request.CodeChallengeMethod != "S256" {
+		redirect = authorizationError(request, "invalid_request", "S256 code challenge is required")
+		return
+	}
+
+	user, err := d.authorizationUser(setup, token)
+	if err == ErrNoSession {
+		err = nil
+		if request.Prompt == "none" {
+			redirect = authorizationError(request, "login_required", "user is not logged in")
+			return
+		}
+
+		err = ErrLoginRequired
+		return
+	} else if err != nil {
+		return
+	}
+
+	state := authorization{Request: request, UserID: user.ID, AuthTime: time.Now().Unix()}
+
+	if !client.Trusted {
+		granted, e := d.db.Consent(setup.ctx).Get(user.ID, client.ID)
+		if e != nil && !errors.Is(e, modelErrors.ErrNotFound) {
+			err = fmt.Errorf("domain Authorize -> unable to get consent %w", e)
+			return
+		}
+
+		if !coversScopes(granted.Scopes, scopes) {
+			if request.Prompt == "none" {
+				redirect = authorizationError(request, "consent_required", "user did not grant access")
+				return
+			}
+
+			state.Kind = authorizationConsent
+			consent, err = d.storeCeremony(setup, state)
+			if err != nil {
+				err = fmt.Errorf("domain Authorize -> %w", err)
+				return
+			}
+
+			err = ErrConsentRequired
+			return
+		}
+	}
+
+	redirect, err = d.issueCode(setup, state)
+
+	return
+}
+
+// Consent finishes authorization request that was waiting for the user to grant access
+func (d *domain) Consent(setup Setup, token string, consent string, approve bool) (redirect string, err error) {
+	user, err := d.loginSession(setup, token)
+	if err != nil {
+		return
+	}
+
+	var state authorization
+	err = d.takeCeremonyState(setup, consent, &state)
+	if err != nil {
+		return
+	}
+
+	if state.Kind != authorizationConsent || state.UserID != user.ID {
+		err = ErrInvalidCeremony
+		return
+	}
+
+	if !approve {
+		redirect = authorizationError(state.Request, "access_denied", "user denied access")
+		return
+	}
+
+	err = d.db.Consent(setup.ctx).Save(Consent{
+		UserID:   user.ID,
+		ClientID: state.Request.ClientID,
+		Scopes:   strings.Fields(state.Request.Scope),
+	})
+	if err != nil {
+		err = fmt.Errorf("domain Consent -> %w", err)
+		return
+	}
+
+	redirect, err = d.issueCode(setup, state)
+
+	return
+}
+
+// Token issues tokens for authorization code, approved device code or, to
+// confidential clients acting on their own behalf, for client credentials
+func (d *domain) Token(setup Setup, request TokenRequest) (tokens Tokens, err error) {
+	if !d.config.IsOIDCProvider() {
+		err = ErrProviderNotConfigured
+		return
+	}
+
+	if !d.supportsGrant(request.GrantType) {
+		err = ErrUnsupportedGrantType
+		return
+	}
+
+	client, err := d.authenticateClient(setup, request.ClientID, request.ClientSecret)
+	if err != nil {
+		return
+	}
+
+	if request.GrantType == "client_credentials" {
+		return d.clientCredentialsToken(setup, client, request.Scope)
+	} else if request.GrantType == deviceCodeGrantType {
+		return d.deviceToken(setup, client, request.DeviceCode)
+	}
+
+	var state authorization
+	err = d.takeCeremonyState(setup, request.Code, &state)
+	if err == ErrInvalidCeremony {
+		err = ErrInvalidGrant
+		return
+	} else if err != nil {
+		err = fmt.Errorf("domain Token -> %w", err)
+		return
+	}
+
+	if state.Kind != authorizationCode ||
+		state.Request.ClientID != client.ID ||
+		state.Request.RedirectURI != request.RedirectURI ||
+		!verifyCodeChallenge(state.Request.CodeChallenge, request.CodeVerifier) {
+		err = ErrInvalidGrant
+		return
+	}
+
+	user, err := d.db.User(setup.ctx).GetByID(state.UserID)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrInvalidGrant
+		return
+	} else if err != nil {
+		err = fmt.Errorf("domain Token -> unable to fetch user %d %w", state.UserID, err)
+		return
+	}
+
+	tokens, err = d.issueTokens(setup, client, user, state)
+	if err != nil {
+		err = fmt.Errorf("domain Token -> %w", err)
+	}
+
+	return
+}
+
+// UserInfo returns claims about the user access token was issued for
+func (d *domain) UserInfo(setup Setup, accessToken string) (claims map[string]any, err error) {
+	if !d.config.IsOIDCProvider() {
+		err = ErrProviderNotConfigured
+		return
+	}
+
+	var token accessTokenClaims
+	token.Claims, err = d.verifyAccessToken(setup, accessToken, &token)
+	if err != nil {
+		if err != ErrInvalidAccessToken {
+			err = fmt.Errorf("domain UserInfo -> %w", err)
+		}
+		return
+	}
+
+	// only tokens issued on behalf of the user carry openid scope
+	if !slices.Contains(strings.Fields(token.Scope), "openid") {
+		err = ErrInvalidAccessToken
+		return
+	}
+
+	userId, err := strconv.ParseUint(token.Subject, 10, 64)
+	if err != nil {
+		err = ErrInvalidAccessToken
+		return
+	}
+
+	user, err := d.db.User(setup.ctx).GetByID(userId)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrInvalidAccessToken
+		return
+	} else if err != nil {
+		err = fmt.Errorf("domain UserInfo -> unable to fetch user %d %w", userId, err)
+		return
+	}
+
+	claims = userClaims(user, strings.Fields(token.Scope))
+
+	return
+}
+
+// JWKS returns public parts of the active and previous signing keys as JSON web key set
+func (d *domain) JWKS(setup Setup) (keys json.RawMessage, err error) {
+	set, err := d.publicKeys(setup)
+	if err != nil {
+		err = fmt.Errorf("domain JWKS -> %w", err)
+		return
+	}
+
+	keys, err = json.Marshal(set)
+	if err != nil {
+		err = fmt.Errorf("domain JWKS -> unable to serialize keys %w", err)
+	}
+
+	return
+}
+
+// verifyAccessToken checks signature, type and expiration of access token issued by this app.
+// Claims other than the registered ones are decoded into extra.
+func (d *domain) verifyAccessToken(setup Setup, accessToken string, extra any) (claims jwt.Claims, err error) {
+	keys, err := d.publicKeys(setup)
+	if err != nil {
+		return
+	}
+
+	parsed, err := jwt.ParseSigned(accessToken, signingAlgorithms)
+	if err != nil || len(parsed.Headers) != 1 || parsed.Headers[0].ExtraHeaders[jose.HeaderType] != accessTokenType {
+		err = ErrInvalidAccessToken
+		return
+	}
+
+	err = parsed.Claims(keys, &claims, extra)
+	if err != nil {
+		err = ErrInvalidAccessToken
+		return
+	}
+
+	err = claims.Validate(jwt.Expected{Issuer: d.config.OIDCIssuer, Time: time.Now()})
+	if err != nil {
+		claims = jwt.Claims{}
+		err = ErrInvalidAccessToken
+	}
+
+	return
+}
+
+func (d *domain) supportsGrant(grantType string) bool {
+	switch grantType {
+	case "authorization_code", "client_credentials":
+		return true
+	case deviceCodeGrantType:
+		return d.config.HasDeviceFlow()
+	}
+
+	return false
+}
+
+// authorizationClient returns client only if redirect uri is registered for it
+// since errors can't be safely reported back to unknown redirect uri
+func (d *domain) authorizationClient(setup Setup, clientId string, redirectURI string) (client OAuthClient, err error) {
+	client, err = d.db.OAuthClient(setup.ctx).Get(clientId)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrInvalidClient
+		return
+	} else if err != nil {
+		err = fmt.Errorf("unable to get client %s %w", clientId, err)
+		return
+	}
+
+	if !slices.Contains(client.RedirectURIs, redirectURI) {
+		client = OAuthClient{}
+		err = ErrInvalidClient
+	}
+
+	return
+}
+
+func (d *domain) authorizationUser(setup Setup, token string) (user User, err error) {
+	if token == "" {
+		err = ErrNoSession
+		return
+	}
+
+	return d.loginSession(setup, token)
+}
+
+// authenticateClient checks secret of confidential clients. Public clients
+// have no secret and rely on PKCE which is required for every request.
+func (d *domain) authenticateClient(setup Setup, clientId string, secret string) (client OAuthClient, err error) {
+	client, err = d.db.OAuthClient(setup.ctx).Get(clientId)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrInvalidClient
+		return
+	} else if err != nil {
+		err = fmt.Errorf("unable to get client %s %w", clientId, err)
+		return
+	}
+
+	if client.SecretHash == "" {
+		return
+	}
+
+	hash := sha256.Sum256([]byte(secret))
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(hash[:])), []byte(client.SecretHash)) != 1 {
+		client = OAuthClient{}
+		err = ErrInvalidClient
+	}
+
+	return
+}
+
+func (d *domain) issueCode(setup Setup, state authorization) (redirect string, err error) {
+	state.Kind = authorizationCode
+
+	code, err := d.storeCeremony(setup, state)
+	if err != nil {
+		err = fmt.Errorf("unable to issue code %w", err)
+		return
+	}
+
+	redirect = authorizationRedirect(state.Request, url.Values{"code": {code}})
+
+	return
+}
+
+func (d *domain) issueTokens(setup Setup, client OAuthClient, user User, state authorization) (tokens Tokens, err error) {
+	now := time.Now()
+	subject := strconv.FormatUint(user.ID, 10)
+	registered := jwt.Claims{
+		Issuer:   d.config.OIDCIssuer,
+		Subject:  subject,
+		Audience: jwt.Audience{client.ID},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(utils.OIDC_TOKEN_TTL)),
+	}
+
+	accessSigner, err := d.tokenSigner(setup, accessTokenType)
+	if err != nil {
+		return
+	}
+
+	tokens.AccessToken, err = jwt.Signed(accessSigner).
+		Claims(accessTokenClaims{Claims: registered, ClientID: client.ID, Scope: state.Request.Scope}).
+		Serialize()
+	if err != nil {
+		err = fmt.Errorf("unable to sign access token %w", err)
+		return
+	}
+
+	tokens.Scope = state.Request.Scope
+	tokens.ExpiresIn = int64(utils.OIDC_TOKEN_TTL.Seconds())
+
+	// id token is issued only to clients that asked for openid scope
+	scopes := strings.Fields(state.Request.Scope)
+	if !slices.Contains(scopes, "openid") {
+		return
+	}
+
+	idSigner, err := d.tokenSigner(setup, "JWT")
+	if err != nil {
+		tokens = Tokens{}
+		return
+	}
+
+	claims := userClaims(user, scopes)
+	claims["auth_time"] = state.AuthTime
+	if state.Request.Nonce != "" {
+		claims["nonce"] = state.Request.Nonce
+	}
+
+	tokens.IDToken, err = jwt.Signed(idSigner).Claims(registered).Claims(claims).Serialize()
+	if err != nil {
+		tokens = Tokens{}
+		err = fmt.Errorf("unable to sign id token %w", err)
+	}
+
+	return
+}
+
+// userClaims maps user into standard claims allowed by granted scopes
+func userClaims(user User, scopes []string) map[string]any {
+	claims := map[string]any{"sub": strconv.FormatUint(user.ID, 10)}
+
+	if slices.Contains(scopes, "email") {
+		claims["email"] = user.Email
+		claims["email_verified"] = user.Verified
+	}
+
+	if slices.Contains(scopes, "profile") {
+		if user.Username != "" {
+			claims["preferred_username"] = user.Username
+		}
+
+		if user.Role != "" {
+			claims["role"] = user.Role
+		}
+
+		if user.Payload != nil {
+			claims["payload"] = user.Payload
+		}
+	}
+
+	return claims
+}
+
+func coversScopes(granted []string, requested []string) bool {
+	for _, scope := range requested {
+		if !slices.Contains(granted, scope) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func verifyCodeChallenge(challenge string, verifier string) bool {
+	if verifier == "" {
+		return false
+	}
+
+	hash := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(hash[:])
+
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+func authorizationError(request AuthorizationRequest, code string, description string) string {
+	return authorizationRedirect(request, url.Values{"error": {code}, "error_description": {description}})
+}
+
+func authorizationRedirect(request AuthorizationRequest, params url.Values) string {
+	if request.State != "" {
+		params.Set("state", request.State)
+	}
+
+	separator := "?"
+	if strings.Contains(request.RedirectURI, "?") {
+		separator = "&"
+	}
+
+	return request.RedirectURI + separator + params.Encode()
+}
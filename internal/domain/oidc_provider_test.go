@@ -0,0 +1,494 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var providerConfig = utils.Config{OIDCIssuer: "https://auth.example.com"}
+
+const codeVerifier = "dBjftJeZ4CVP-mJ0vOS6bmjr4f6RzoINTPrXctuwj1c"
+
+func codeChallenge(verifier string) string {
+	hash := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+func newAuthorizationRequest() AuthorizationRequest {
+	return AuthorizationRequest{
+		ResponseType:        "code",
+		ClientID:            "app",
+		RedirectURI:         "https://app.example.com/callback",
+		Scope:               "openid email profile",
+		State:               "xyz",
+		Nonce:               "n-0S6",
+		CodeChallenge:       codeChallenge(codeVerifier),
+		CodeChallengeMethod: "S256",
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com"}
+	client := OAuthClient{ID: "app", RedirectURIs: []string{"https://app.example.com/callback"}}
+	trusted := client
+	trusted.Trusted = true
+
+	type testCase struct {
+		name          string
+		config        utils.Config
+		token         string
+		request       func(*AuthorizationRequest)
+		setupModels   func(*MockRepositoryOAuthClient, *MockRepositorySession, *MockRepositoryConsent, *MockRepositoryCeremony, *testCase)
+		returnQuery   url.Values
+		returnConsent string
+		returnError   error
+	}
+
+	tests := []testCase{
+		{
+			name:   "trusted client",
+			config: providerConfig,
+			token:  "session",
+			setupModels: func(ro *MockRepositoryOAuthClient, rs *MockRepositorySession, rc *MockRepositoryConsent, rce *MockRepositoryCeremony, tc *testCase) {
+				ro.EXPECT().Get("app").Return(trusted, nil)
+				rs.EXPECT().Get("session").Return(user, nil)
+				rce.EXPECT().Create(mock.MatchedBy(func(data []byte) bool {
+					var state authorization
+					require.NoError(t, json.Unmarshal(data, &state))
+					return state.Kind == authorizationCode && state.UserID == user.ID
+				})).Return("code", nil)
+			},
+			returnQuery: url.Values{"code": {"code"}, "state": {"xyz"}},
+		},
+		{
+			name:   "consent already granted",
+			config: providerConfig,
+			token:  "session",
+			setupModels: func(ro *MockRepositoryOAuthClient, rs *MockRepositorySession, rc *MockRepositoryConsent, rce *MockRepositoryCeremony, tc *testCase) {
+				ro.EXPECT().Get("app").Return(client, nil)
+				rs.EXPECT().Get("session").Return(user, nil)
+				rc.EXPECT().Get(user.ID, "app").Return(Consent{Scopes: []string{"openid", "email", "profile"}}, nil)
+				rce.EXPECT().Create(mock.Anything).Return("code", nil)
+			},
+			returnQuery: url.Values{"code": {"code"}, "state": {"xyz"}},
+		},
+		{
+			name:   "consent required",
+			config: providerConfig,
+			token:  "session",
+			setupModels: func(ro *MockRepositoryOAuthClient, rs *MockRepositorySession, rc *MockRepositoryConsent, rce *MockRepositoryCeremony, tc *testCase) {
+				ro.EXPECT().Get("app").Return(client, nil)
+				rs.EXPECT().Get("session").Return(user, nil)
+				rc.EXPECT().Get(user.ID, "app").Return(Consent{Scopes: []string{"openid"}}, nil)
+				rce.EXPECT().Create(mock.MatchedBy(func(data []byte) bool {
+					var state authorization
+					require.NoError(t, json.Unmarshal(data, &state))
+					return state.Kind == authorizationConsent
+				})).Return("consent", nil)
+			},
+			returnConsent: "consent",
+			returnError:   ErrConsentRequired,
+		},
+		{
+			name:    "consent required without prompt",
+			config:  providerConfig,
+			token:   "session",
+			request: func(r *AuthorizationRequest) { r.Prompt = "none" },
+			setupModels: func(ro *MockRepositoryOAuthClient, rs *MockRepositorySession, rc *MockRepositoryConsent, rce *MockRepositoryCeremony, tc *testCase) {
+				ro.EXPECT().Get("app").Return(client, nil)
+				rs.EXPECT().Get("session").Return(user, nil)
+				rc.EXPECT().Get(user.ID, "app").Return(Consent{}, modelErrors.ErrNotFound)
+			},
+			returnQuery: url.Values{"error": {"consent_required"}, "error_description": {"user did not grant access"}, "state": {"xyz"}},
+		},
+		{
+			name:   "not logged in",
+			config: providerConfig,
+			setupModels: func(ro *MockRepositoryOAuthClient, rs *MockRepositorySession, rc *MockRepositoryConsent, rce *MockRepositoryCeremony, tc *testCase) {
+				ro.EXPECT().Get("app").Return(client, nil)
+			},
+			returnError: ErrLoginRequired,
+		},
+		{
+			name:    "not logged in without prompt",
+			config:  providerConfig,
+			token:   "expired",
+			request: func(r *AuthorizationRequest) { r.Prompt = "none" },
+			setupModels: func(ro *MockRepositoryOAuthClient, rs *MockRepositorySession, rc *MockRepositoryConsent, rce *MockRepositoryCeremony, tc *testCase) {
+				ro.EXPECT().Get("app").Return(client, nil)
+				rs.EXPECT().Get("expired").Return(User{}, modelErrors.ErrNotFound)
+			},
+			returnQuery: url.Values{"error": {"login_required"}, "error_description": {"user is not logged in"}, "state": {"xyz"}},
+		},
+		{
+			name:    "missing code challenge",
+			config:  providerConfig,
+			token:   "session",
+			request: func(r *AuthorizationRequest) { r.CodeChallenge = "" },
+			setupModels: func(ro *MockRepositoryOAuthClient, rs *MockRepositorySession, rc *MockRepositoryConsent, rce *MockRepositoryCeremony, tc *testCase) {
+				ro.EXPECT().Get("app").Return(client, nil)
+			},
+			returnQuery: url.Values{"error": {"invalid_request"}, "error_description": {"S256 code challenge is required"}, "state": {"xyz"}},
+		},
+		{
+			name:    "missing openid scope",
+			config:  providerConfig,
+			token:   "session",
+			request: func(r *AuthorizationRequest) { r.Scope = "email" },
+			setupModels: func(ro *MockRepositoryOAuthClient, rs *MockRepositorySession, rc *MockRepositoryConsent, rce *MockRepositoryCeremony, tc *testCase) {
+				ro.EXPECT().Get("app").Return(client, nil)
+			},
+			returnQuery: url.Values{"error": {"invalid_scope"}, "error_description": {"openid scope is required"}, "state": {"xyz"}},
+		},
+		{
+			name:    "unregistered redirect uri",
+			config:  providerConfig,
+			token:   "session",
+			request: func(r *AuthorizationRequest) { r.RedirectURI = "https://evil.example.com/callback" },
+			setupModels: func(ro *MockRepositoryOAuthClient, rs *MockRepositorySession, rc *MockRepositoryConsent, rce *MockRepositoryCeremony, tc *testCase) {
+				ro.EXPECT().Get("app").Return(client, nil)
+			},
+			returnError: ErrInvalidClient,
+		},
+		{
+			name:   "unknown client",
+			config: providerConfig,
+			token:  "session",
+			setupModels: func(ro *MockRepositoryOAuthClient, rs *MockRepositorySession, rc *MockRepositoryConsent, rce *MockRepositoryCeremony, tc *testCase) {
+				ro.EXPECT().Get("app").Return(OAuthClient{}, modelErrors.ErrNotFound)
+			},
+			returnError: ErrInvalidClient,
+		},
+		{
+			name: "not configured",
+			setupModels: func(ro *MockRepositoryOAuthClient, rs *MockRepositorySession, rc *MockRepositoryConsent, rce *MockRepositoryCeremony, tc *testCase) {
+			},
+			returnError: ErrProviderNotConfigured,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			clientRepository := NewMockRepositoryOAuthClient(t)
+			sessionRepository := NewMockRepositorySession(t)
+			consentRepository := NewMockRepositoryConsent(t)
+			ceremonyRepository := NewMockRepositoryCeremony(t)
+
+			// Setup mocks
+			repository.EXPECT().OAuthClient(context.TODO()).Return(clientRepository).Maybe()
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository).Maybe()
+			repository.EXPECT().Consent(context.TODO()).Return(consentRepository).Maybe()
+			repository.EXPECT().Ceremony(context.TODO()).Return(ceremonyRepository).Maybe()
+			tc.setupModels(clientRepository, sessionRepository, consentRepository, ceremonyRepository, &tc)
+
+			request := newAuthorizationRequest()
+			if tc.request != nil {
+				tc.request(&request)
+			}
+
+			// Run
+			domain := NewDomain(repository, tc.config, NewMockNotifier(t))
+			redirect, consent, err := domain.Authorize(setup, tc.token, request)
+
+			// Assertions
+			require.Equal(t, tc.returnError, err)
+			require.Equal(t, tc.returnConsent, consent)
+
+			if tc.returnQuery == nil {
+				require.Empty(t, redirect)
+				return
+			}
+
+			parsed, err := url.Parse(redirect)
+			require.NoError(t, err)
+			require.Equal(t, "app.example.com", parsed.Host)
+			require.Equal(t, tc.returnQuery, parsed.Query())
+		})
+	}
+}
+
+func TestConsent(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com"}
+	pending := authorization{Kind: authorizationConsent, Request: newAuthorizationRequest(), UserID: user.ID}
+
+	type testCase struct {
+		name        string
+		approve     bool
+		state       authorization
+		setupModels func(*MockRepositoryConsent, *MockRepositoryCeremony, *testCase)
+		returnQuery url.Values
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name:    "approved",
+			approve: true,
+			state:   pending,
+			setupModels: func(rc *MockRepositoryConsent, rce *MockRepositoryCeremony, tc *testCase) {
+				rc.EXPECT().Save(Consent{UserID: user.ID, ClientID: "app", Scopes: []string{"openid", "email", "profile"}}).Return(nil)
+				rce.EXPECT().Create(mock.Anything).Return("code", nil)
+			},
+			returnQuery: url.Values{"code": {"code"}, "state": {"xyz"}},
+		},
+		{
+			name:        "denied",
+			state:       pending,
+			setupModels: func(rc *MockRepositoryConsent, rce *MockRepositoryCeremony, tc *testCase) {},
+			returnQuery: url.Values{"error": {"access_denied"}, "error_description": {"user denied access"}, "state": {"xyz"}},
+		},
+		{
+			name:        "code used as consent",
+			approve:     true,
+			state:       authorization{Kind: authorizationCode, Request: newAuthorizationRequest(), UserID: user.ID},
+			setupModels: func(rc *MockRepositoryConsent, rce *MockRepositoryCeremony, tc *testCase) {},
+			returnError: ErrInvalidCeremony,
+		},
+		{
+			name:        "another user",
+			approve:     true,
+			state:       authorization{Kind: authorizationConsent, Request: newAuthorizationRequest(), UserID: 7},
+			setupModels: func(rc *MockRepositoryConsent, rce *MockRepositoryCeremony, tc *testCase) {},
+			returnError: ErrInvalidCeremony,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			sessionRepository := NewMockRepositorySession(t)
+			consentRepository := NewMockRepositoryConsent(t)
+			ceremonyRepository := NewMockRepositoryCeremony(t)
+
+			// Setup mocks
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository)
+			repository.EXPECT().Consent(context.TODO()).Return(consentRepository).Maybe()
+			repository.EXPECT().Ceremony(context.TODO()).Return(ceremonyRepository)
+			sessionRepository.EXPECT().Get("session").Return(user, nil)
+
+			data, err := json.Marshal(tc.state)
+			require.NoError(t, err)
+			ceremonyRepository.EXPECT().Take("consent").Return(data, nil)
+			tc.setupModels(consentRepository, ceremonyRepository, &tc)
+
+			// Run
+			domain := NewDomain(repository, providerConfig, NewMockNotifier(t))
+			redirect, err := domain.Consent(setup, "session", "consent", tc.approve)
+
+			// Assertions
+			require.Equal(t, tc.returnError, err)
+
+			if tc.returnQuery == nil {
+				require.Empty(t, redirect)
+				return
+			}
+
+			parsed, err := url.Parse(redirect)
+			require.NoError(t, err)
+			require.Equal(t, tc.returnQuery, parsed.Query())
+		})
+	}
+}
+
+func TestTokenAndUserInfo(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{
+		ID:       452,
+		Email:    "djvukovic@gmail.com",
+		Username: "djvukovic",
+		Role:     "admin",
+		Verified: true,
+		Payload:  map[string]any{"team": "core"},
+	}
+	secretHash := sha256.Sum256([]byte("secret"))
+	client := OAuthClient{ID: "app", SecretHash: hex.EncodeToString(secretHash[:])}
+	code := authorization{Kind: authorizationCode, Request: newAuthorizationRequest(), UserID: user.ID, AuthTime: 1700000000}
+	request := TokenRequest{
+		GrantType:    "authorization_code",
+		Code:         "code",
+		RedirectURI:  "https://app.example.com/callback",
+		ClientID:     "app",
+		ClientSecret: "secret",
+		CodeVerifier: codeVerifier,
+	}
+
+	type testCase struct {
+		name        string
+		request     func(*TokenRequest)
+		state       authorization
+		setupModels func(*MockRepositoryOAuthClient, *MockRepositoryCeremony, *MockRepositoryUser, *testCase)
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name: "success",
+			setupModels: func(ro *MockRepositoryOAuthClient, rc *MockRepositoryCeremony, ru *MockRepositoryUser, tc *testCase) {
+				ro.EXPECT().Get("app").Return(client, nil)
+				data, _ := json.Marshal(code)
+				rc.EXPECT().Take("code").Return(data, nil)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+			},
+		},
+		{
+			name:    "invalid code verifier",
+			request: func(r *TokenRequest) { r.CodeVerifier = "wrong" },
+			setupModels: func(ro *MockRepositoryOAuthClient, rc *MockRepositoryCeremony, ru *MockRepositoryUser, tc *testCase) {
+				ro.EXPECT().Get("app").Return(client, nil)
+				data, _ := json.Marshal(code)
+				rc.EXPECT().Take("code").Return(data, nil)
+			},
+			returnError: ErrInvalidGrant,
+		},
+		{
+			name:    "redirect uri mismatch",
+			request: func(r *TokenRequest) { r.RedirectURI = "https://app.example.com/other" },
+			setupModels: func(ro *MockRepositoryOAuthClient, rc *MockRepositoryCeremony, ru *MockRepositoryUser, tc *testCase) {
+				ro.EXPECT().Get("app").Return(client, nil)
+				data, _ := json.Marshal(code)
+				rc.EXPECT().Take("code").Return(data, nil)
+			},
+			returnError: ErrInvalidGrant,
+		},
+		{
+			name: "consent used as code",
+			setupModels: func(ro *MockRepositoryOAuthClient, rc *MockRepositoryCeremony, ru *MockRepositoryUser, tc *testCase) {
+				ro.EXPECT().Get("app").Return(client, nil)
+				consent := code
+				consent.Kind = authorizationConsent
+				data, _ := json.Marshal(consent)
+				rc.EXPECT().Take("code").Return(data, nil)
+			},
+			returnError: ErrInvalidGrant,
+		},
+		{
+			name: "used code",
+			setupModels: func(ro *MockRepositoryOAuthClient, rc *MockRepositoryCeremony, ru *MockRepositoryUser, tc *testCase) {
+				ro.EXPECT().Get("app").Return(client, nil)
+				rc.EXPECT().Take("code").Return(nil, modelErrors.ErrNotFound)
+			},
+			returnError: ErrInvalidGrant,
+		},
+		{
+			name:    "invalid secret",
+			request: func(r *TokenRequest) { r.ClientSecret = "guess" },
+			setupModels: func(ro *MockRepositoryOAuthClient, rc *MockRepositoryCeremony, ru *MockRepositoryUser, tc *testCase) {
+				ro.EXPECT().Get("app").Return(client, nil)
+			},
+			returnError: ErrInvalidClient,
+		},
+		{
+			name:    "unsupported grant",
+			request: func(r *TokenRequest) { r.GrantType = "password" },
+			setupModels: func(ro *MockRepositoryOAuthClient, rc *MockRepositoryCeremony, ru *MockRepositoryUser, tc *testCase) {
+			},
+			returnError: ErrUnsupportedGrantType,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			clientRepository := NewMockRepositoryOAuthClient(t)
+			ceremonyRepository := NewMockRepositoryCeremony(t)
+			userRepository := NewMockRepositoryUser(t)
+
+			// Setup mocks
+			repository.EXPECT().OAuthClient(context.TODO()).Return(clientRepository).Maybe()
+			repository.EXPECT().Ceremony(context.TODO()).Return(ceremonyRepository).Maybe()
+			repository.EXPECT().User(context.TODO()).Return(userRepository).Maybe()
+			tc.setupModels(clientRepository, ceremonyRepository, userRepository, &tc)
+
+			tokenRequest := request
+			if tc.request != nil {
+				tc.request(&tokenRequest)
+			}
+
+			// Run
+			domain := NewDomain(repository, providerConfig, NewMockNotifier(t))
+			tokens, err := domain.Token(setup, tokenRequest)
+
+			// Assertions
+			require.Equal(t, tc.returnError, err)
+			if tc.returnError != nil {
+				require.Empty(t, tokens)
+				return
+			}
+
+			require.Equal(t, "openid email profile", tokens.Scope)
+
+			keys, err := domain.JWKS(setup)
+			require.NoError(t, err)
+
+			var set jose.JSONWebKeySet
+			require.NoError(t, json.Unmarshal(keys, &set))
+			require.Len(t, set.Keys, 1)
+
+			idToken, err := jwt.ParseSigned(tokens.IDToken, []jose.SignatureAlgorithm{jose.RS256})
+			require.NoError(t, err)
+			require.Equal(t, set.Keys[0].KeyID, idToken.Headers[0].KeyID)
+
+			var registered jwt.Claims
+			claims := map[string]any{}
+			require.NoError(t, idToken.Claims(set.Keys[0].Key, &registered, &claims))
+			require.NoError(t, registered.Validate(jwt.Expected{Issuer: providerConfig.OIDCIssuer, AnyAudience: jwt.Audience{"app"}}))
+			require.Equal(t, "452", registered.Subject)
+			require.Equal(t, "n-0S6", claims["nonce"])
+			require.Equal(t, user.Email, claims["email"])
+			require.Equal(t, true, claims["email_verified"])
+			require.Equal(t, user.Username, claims["preferred_username"])
+			require.Equal(t, user.Role, claims["role"])
+			require.Equal(t, user.Payload, claims["payload"])
+
+			// id token can't be used to access user info
+			_, err = domain.UserInfo(setup, tokens.IDToken)
+			require.Equal(t, ErrInvalidAccessToken, err)
+
+			userInfo, err := domain.UserInfo(setup, tokens.AccessToken)
+			require.NoError(t, err)
+			require.Equal(t, userClaims(user, []string{"openid", "email", "profile"}), userInfo)
+		})
+	}
+}
+
+func TestUserClaims(t *testing.T) {
+	t.Parallel()
+
+	user := User{ID: 452, Email: "djvukovic@gmail.com", Username: "djvukovic", Role: "admin"}
+
+	require.Equal(t, map[string]any{"sub": "452"}, userClaims(user, []string{"openid"}))
+	require.Equal(
+		t,
+		map[string]any{"sub": "452", "email": user.Email, "email_verified": false},
+		userClaims(user, []string{"openid", "email"}),
+	)
+	require.Equal(
+		t,
+		map[string]any{"sub": "452", "preferred_username": user.Username, "role": user.Role},
+		userClaims(user, []string{"openid", "profile"}),
+	)
+}
@@ -0,0 +1,487 @@
+package domain
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testProvider is a minimal stand-in OpenID Connect provider issuing RS256 signed ID tokens
+type testProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu          sync.Mutex
+	grants      map[string]testGrant
+	discoveries int
+}
+
+type testGrant struct {
+	challenge string
+	claims    map[string]any
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	provider := &testProvider{key: key, grants: map[string]testGrant{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", provider.discovery)
+	mux.HandleFunc("/jwks", provider.jwks)
+	mux.HandleFunc("/token", provider.token)
+
+	provider.server = httptest.NewServer(mux)
+	t.Cleanup(provider.server.Close)
+
+	return provider
+}
+
+// authorize simulates user consenting at the provider for the given authorization URL
+func (p *testProvider) authorize(t *testing.T, authURL string, claims map[string]any) (state string, code string) {
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+
+	query := parsed.Query()
+	require.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	if _, ok := claims["nonce"]; !ok {
+		claims["nonce"] = query.Get("nonce")
+	}
+
+	code = query.Get("state") + "-code"
+
+	p.mu.Lock()
+	p.grants[code] = testGrant{challenge: query.Get("code_challenge"), claims: claims}
+	p.mu.Unlock()
+
+	return query.Get("state"), code
+}
+
+func (p *testProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.discoveries++
+	p.mu.Unlock()
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"issuer":                                p.server.URL,
+		"authorization_endpoint":                p.server.URL + "/authorize",
+		"token_endpoint":                        p.server.URL + "/token",
+		"jwks_uri":                              p.server.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (p *testProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]any{
+		"keys": []map[string]any{{
+			"kty": "RSA",
+			"kid": "test",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (p *testProvider) token(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+
+	p.mu.Lock()
+	grant, ok := p.grants[r.Form.Get("code")]
+	delete(p.grants, r.Form.Get("code"))
+	p.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(verifier[:]) != grant.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant"}`))
+		return
+	}
+
+	claims := map[string]any{
+		"iss": p.server.URL,
+		"aud": "client",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range grant.claims {
+		claims[k] = v
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     p.sign(claims),
+	})
+}
+
+func (p *testProvider) sign(claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "test", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestOIDCLogIn(t *testing.T) {
+	t.Parallel()
+
+	provider := newTestProvider(t)
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com", Verified: true}
+	identity := Identity{UserID: user.ID, Provider: "test", Subject: "subject-1", Email: user.Email}
+
+	providerConfig := utils.OIDCProvider{
+		Name:     "test",
+		Issuer:   provider.server.URL,
+		ClientID: "client",
+		Scopes:   []string{"openid", "email"},
+	}
+
+	type testCase struct {
+		name        string
+		autoLink    bool
+		token       string
+		claims      map[string]any
+		setupModels func(*MockRepository, *MockRepositoryUser, *MockRepositoryIdentity, *MockRepositorySession, *testCase)
+		returnUser  User
+		returnKey   string
+		returnError error
+	}
+
+	noSecondFactor := func(r *MockRepository) {
+		totpRepository := NewMockRepositoryTOTP(t)
+		passkeyRepository := NewMockRepositoryPasskey(t)
+
+		r.EXPECT().TOTP(context.TODO()).Return(totpRepository)
+		r.EXPECT().Passkey(context.TODO()).Return(passkeyRepository)
+		totpRepository.EXPECT().Get(user.ID).Return(TOTP{}, modelErrors.ErrNotFound)
+		passkeyRepository.EXPECT().GetByUser(user.ID).Return([]Passkey{}, nil)
+	}
+
+	tests := []testCase{
+		{
+			name:   "linked identity",
+			claims: map[string]any{"sub": "subject-1"},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession, tc *testCase) {
+				ri.EXPECT().Get("test", "subject-1").Return(identity, nil)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+				noSecondFactor(r)
+				rs.EXPECT().Create(user, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: user}, nil)
+			},
+			returnUser: user,
+			returnKey:  "session",
+		},
+		{
+			name:   "new user",
+			claims: map[string]any{"sub": "subject-1", "email": user.Email, "email_verified": true},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession, tc *testCase) {
+				ri.EXPECT().Get("test", "subject-1").Return(Identity{}, modelErrors.ErrNotFound)
+				ru.EXPECT().GetByEmail(user.Email).Return(User{}, modelErrors.ErrNotFound)
+				r.EXPECT().Atomic(mock.Anything).RunAndReturn(func(f func(Repository) error) error {
+					return f(r)
+				})
+				ru.EXPECT().Create(mock.MatchedBy(func(u User) bool {
+					return u.Email == user.Email && u.Verified && u.Password == ""
+				})).Return(user, nil)
+				ri.EXPECT().Create(identity).Return(identity, nil)
+				noSecondFactor(r)
+				rs.EXPECT().Create(user, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: user}, nil)
+			},
+			returnUser: user,
+			returnKey:  "session",
+		},
+		{
+			name:   "existing email without auto link",
+			claims: map[string]any{"sub": "subject-1", "email": user.Email, "email_verified": true},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession, tc *testCase) {
+				ri.EXPECT().Get("test", "subject-1").Return(Identity{}, modelErrors.ErrNotFound)
+				ru.EXPECT().GetByEmail(user.Email).Return(user, nil)
+			},
+			returnError: ErrIdentityLinkRequired,
+		},
+		{
+			name:     "existing email with unverified email",
+			autoLink: true,
+			claims:   map[string]any{"sub": "subject-1", "email": user.Email, "email_verified": false},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession, tc *testCase) {
+				ri.EXPECT().Get("test", "subject-1").Return(Identity{}, modelErrors.ErrNotFound)
+				ru.EXPECT().GetByEmail(user.Email).Return(user, nil)
+			},
+			returnError: ErrIdentityLinkRequired,
+		},
+		{
+			name:   "new user with unverified email",
+			claims: map[string]any{"sub": "subject-1", "email": user.Email, "email_verified": false},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession, tc *testCase) {
+				ri.EXPECT().Get("test", "subject-1").Return(Identity{}, modelErrors.ErrNotFound)
+				ru.EXPECT().GetByEmail(user.Email).Return(User{}, modelErrors.ErrNotFound)
+			},
+			returnError: ErrInvalidIdentity,
+		},
+		{
+			name:     "existing email with auto link",
+			autoLink: true,
+			claims:   map[string]any{"sub": "subject-1", "email": user.Email, "email_verified": true},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession, tc *testCase) {
+				ri.EXPECT().Get("test", "subject-1").Return(Identity{}, modelErrors.ErrNotFound)
+				ru.EXPECT().GetByEmail(user.Email).Return(user, nil)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+				ri.EXPECT().Create(identity).Return(identity, nil)
+				noSecondFactor(r)
+				rs.EXPECT().Create(user, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: user}, nil)
+			},
+			returnUser: user,
+			returnKey:  "session",
+		},
+		{
+			name:   "linking from signed in account",
+			token:  "current",
+			claims: map[string]any{"sub": "subject-1", "email": "other@gmail.com"},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession, tc *testCase) {
+				rs.EXPECT().Get("current").Return(user, nil)
+				ri.EXPECT().Get("test", "subject-1").Return(Identity{}, modelErrors.ErrNotFound)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+				ri.EXPECT().Create(Identity{UserID: user.ID, Provider: "test", Subject: "subject-1", Email: "other@gmail.com"}).Return(identity, nil)
+				rs.EXPECT().Create(user, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: user}, nil)
+			},
+			returnUser: user,
+			returnKey:  "session",
+		},
+		{
+			name:   "identity linked to another user",
+			token:  "current",
+			claims: map[string]any{"sub": "subject-1"},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession, tc *testCase) {
+				rs.EXPECT().Get("current").Return(user, nil)
+				ri.EXPECT().Get("test", "subject-1").Return(Identity{UserID: 999, Provider: "test", Subject: "subject-1"}, nil)
+			},
+			returnError: ErrIdentityInUse,
+		},
+		{
+			name:   "nonce mismatch",
+			claims: map[string]any{"sub": "subject-1", "nonce": "replayed"},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession, tc *testCase) {
+			},
+			returnError: ErrInvalidIdentity,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			userRepository := NewMockRepositoryUser(t)
+			identityRepository := NewMockRepositoryIdentity(t)
+			sessionRepository := NewMockRepositorySession(t)
+			ceremonyRepository := NewMockRepositoryCeremony(t)
+
+			// Setup mocks
+			var stored []byte
+			ceremonyRepository.EXPECT().Create(mock.Anything).RunAndReturn(func(data []byte) (string, error) {
+				stored = data
+				return "state", nil
+			})
+			ceremonyRepository.EXPECT().Take("state").RunAndReturn(func(key string) ([]byte, error) {
+				return stored, nil
+			})
+
+			repository.EXPECT().Ceremony(context.TODO()).Return(ceremonyRepository)
+			repository.EXPECT().User(context.TODO()).Return(userRepository).Maybe()
+			repository.EXPECT().Identity(context.TODO()).Return(identityRepository).Maybe()
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository).Maybe()
+			tc.setupModels(repository, userRepository, identityRepository, sessionRepository, &tc)
+
+			config := providerConfig
+			config.AutoLink = tc.autoLink
+
+			// Run
+			domain := NewDomain(repository, utils.Config{
+				OIDCRedirectURL: "https://example.com/oidc",
+				OIDCProviders:   []utils.OIDCProvider{config},
+			}, NewMockNotifier(t))
+
+			authURL, stateKey, err := domain.BeginOIDCLogIn(setup, "test", tc.token)
+			require.NoError(t, err)
+			require.Contains(t, authURL, url.QueryEscape("https://example.com/oidc/test/callback"))
+
+			state, code := provider.authorize(t, authURL, tc.claims)
+			require.Equal(t, stateKey, state)
+			existing, key, err := domain.FinishOIDCLogIn(setup, "test", state, code)
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorIs(t, err, tc.returnError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.returnUser, existing)
+			require.Equal(t, tc.returnKey, key)
+		})
+	}
+}
+
+func TestOIDCUnknownProvider(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	domain := NewDomain(NewMockRepository(t), utils.Config{}, NewMockNotifier(t))
+
+	_, _, err := domain.BeginOIDCLogIn(setup, "missing", "")
+	require.ErrorIs(t, err, ErrUnknownProvider)
+}
+
+func TestOIDCDiscoveryCached(t *testing.T) {
+	t.Parallel()
+
+	provider := newTestProvider(t)
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+
+	// Create mocks
+	repository := NewMockRepository(t)
+	ceremonyRepository := NewMockRepositoryCeremony(t)
+
+	// Setup mocks
+	repository.EXPECT().Ceremony(context.TODO()).Return(ceremonyRepository)
+	ceremonyRepository.EXPECT().Create(mock.Anything).Return("state", nil)
+
+	// Run
+	domain := NewDomain(repository, utils.Config{
+		OIDCRedirectURL: "https://example.com/oidc",
+		OIDCProviders:   []utils.OIDCProvider{{Name: "test", Issuer: provider.server.URL, ClientID: "client"}},
+	}, NewMockNotifier(t))
+
+	for i := 0; i < 3; i++ {
+		_, _, err := domain.BeginOIDCLogIn(setup, "test", "")
+		require.NoError(t, err)
+	}
+
+	// Assertions
+	require.Equal(t, 1, provider.discoveries)
+}
+
+func TestUnlinkIdentity(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com"}
+	google := Identity{UserID: user.ID, Provider: "google", Subject: "1"}
+	azure := Identity{UserID: user.ID, Provider: "azure", Subject: "2"}
+
+	type testCase struct {
+		name        string
+		provider    string
+		setupModels func(*MockRepositoryUser, *MockRepositoryIdentity, *MockRepositoryPasskey)
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name:     "success",
+			provider: "google",
+			setupModels: func(ru *MockRepositoryUser, ri *MockRepositoryIdentity, rp *MockRepositoryPasskey) {
+				ri.EXPECT().GetByUser(user.ID).Return([]Identity{google, azure}, nil)
+				ri.EXPECT().Delete(user.ID, "google").Return(nil)
+			},
+		},
+		{
+			name:     "last identity of user with password",
+			provider: "google",
+			setupModels: func(ru *MockRepositoryUser, ri *MockRepositoryIdentity, rp *MockRepositoryPasskey) {
+				ri.EXPECT().GetByUser(user.ID).Return([]Identity{google}, nil)
+				ru.EXPECT().GetByID(user.ID).Return(User{ID: user.ID, Email: user.Email, Password: "hash"}, nil)
+				ri.EXPECT().Delete(user.ID, "google").Return(nil)
+			},
+		},
+		{
+			name:     "last identity of user with passkey",
+			provider: "google",
+			setupModels: func(ru *MockRepositoryUser, ri *MockRepositoryIdentity, rp *MockRepositoryPasskey) {
+				ri.EXPECT().GetByUser(user.ID).Return([]Identity{google}, nil)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+				rp.EXPECT().GetByUser(user.ID).Return([]Passkey{{ID: 1, UserID: user.ID}}, nil)
+				ri.EXPECT().Delete(user.ID, "google").Return(nil)
+			},
+		},
+		{
+			name:     "last log in method",
+			provider: "google",
+			setupModels: func(ru *MockRepositoryUser, ri *MockRepositoryIdentity, rp *MockRepositoryPasskey) {
+				ri.EXPECT().GetByUser(user.ID).Return([]Identity{google}, nil)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+				rp.EXPECT().GetByUser(user.ID).Return([]Passkey{}, nil)
+			},
+			returnError: ErrLastLogInMethod,
+		},
+		{
+			name:        "directory identity",
+			provider:    "ldap",
+			setupModels: func(ru *MockRepositoryUser, ri *MockRepositoryIdentity, rp *MockRepositoryPasskey) {},
+			returnError: ErrIdentityManaged,
+		},
+		{
+			name:     "not linked",
+			provider: "google",
+			setupModels: func(ru *MockRepositoryUser, ri *MockRepositoryIdentity, rp *MockRepositoryPasskey) {
+				ri.EXPECT().GetByUser(user.ID).Return([]Identity{azure}, nil)
+			},
+			returnError: ErrIdentityNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			sessionRepository := NewMockRepositorySession(t)
+			userRepository := NewMockRepositoryUser(t)
+			identityRepository := NewMockRepositoryIdentity(t)
+			passkeyRepository := NewMockRepositoryPasskey(t)
+
+			// Setup mocks
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository)
+			repository.EXPECT().User(context.TODO()).Return(userRepository).Maybe()
+			repository.EXPECT().Identity(context.TODO()).Return(identityRepository).Maybe()
+			repository.EXPECT().Passkey(context.TODO()).Return(passkeyRepository).Maybe()
+			sessionRepository.EXPECT().Get("session").Return(user, nil)
+			tc.setupModels(userRepository, identityRepository, passkeyRepository)
+
+			// Run
+			domain := NewDomain(repository, utils.Config{}, NewMockNotifier(t))
+			err := domain.UnlinkIdentity(setup, "session", tc.provider)
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorIs(t, err, tc.returnError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
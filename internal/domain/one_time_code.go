@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+)
+
+const (
+	oneTimeCodeDigits = 6
+	oneTimeCodeModulo = 1000000
+
+	oneTimeCodeLogIn  = "login"
+	oneTimeCodeVerify = "verify"
+)
+
+func generateOneTimeCode() (string, error) {
+	value, err := rand.Int(rand.Reader, big.NewInt(oneTimeCodeModulo))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", oneTimeCodeDigits, value.Int64()), nil
+}
+
+// createOneTimeCode generates new code for the purpose invalidating previous one
+func createOneTimeCode(repo RepositoryOneTimeCode, purpose string, userId uint64) (code string, err error) {
+	code, err = generateOneTimeCode()
+	if err != nil {
+		err = fmt.Errorf("failed to generate %s code %w", purpose, err)
+		return
+	}
+
+	_, err = repo.Create(purpose, userId, code)
+	if err != nil {
+		code = ""
+		err = fmt.Errorf("failed to store %s code %w", purpose, err)
+	}
+
+	return
+}
+
+// useOneTimeCode checks code against the pending one counting the attempt.
+// Code is discarded once it's used or runs out of attempts. Attempts are
+// counted per user and purpose so requesting new code doesn't reset them.
+func (d *domain) useOneTimeCode(setup Setup, purpose string, userId uint64, code string) error {
+	codeModel := d.db.OneTimeCode(setup.ctx)
+
+	pending, err := codeModel.Get(purpose, userId)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		return ErrInvalidCode
+	} else if err != nil {
+		return fmt.Errorf("unable to get %s code for user %d %w", purpose, userId, err)
+	}
+
+	attempts, err := codeModel.Attempt(purpose, userId)
+	if err != nil {
+		return fmt.Errorf("unable to record attempt for %s code %w", purpose, err)
+	}
+
+	if attempts > maxChallengeAttempts {
+		codeModel.Delete(purpose, userId)
+		return ErrInvalidCode
+	}
+
+	if subtle.ConstantTimeCompare([]byte(pending.Code), []byte(code)) != 1 {
+		return ErrInvalidCode
+	}
+
+	err = codeModel.Delete(purpose, userId)
+	if err != nil {
+		return fmt.Errorf("unable to delete used %s code %w", purpose, err)
+	}
+
+	return nil
+}
+
+func (d *domain) OneTimeCodeRequest(setup Setup, user User) (sentTo User, err error) {
+	sentTo, err = d.findUser(setup, user)
+	if errors.Is(err, ErrUserNotExist) {
+		return
+	} else if err != nil {
+		err = fmt.Errorf("domain OneTimeCodeRequest -> %w", err)
+		return
+	}
+
+	code, err := createOneTimeCode(d.db.OneTimeCode(setup.ctx), oneTimeCodeLogIn, sentTo.ID)
+	if err != nil {
+		sentTo = User{}
+		err = fmt.Errorf("domain OneTimeCodeRequest -> %w", err)
+		return
+	}
+
+	minutes := int(utils.ONE_TIME_CODE_TTL.Minutes())
+	text := fmt.Sprintf(oneTimeCodeTextTemplate, sentTo.Email, code, minutes)
+	html := fmt.Sprintf(oneTimeCodeHtmlTemplate, sentTo.Email, code, minutes)
+
+	err = d.notifier.Send(sentTo.Email, "Log in code", text, html)
+	if err != nil {
+		sentTo = User{}
+		err = fmt.Errorf("domain OneTimeCodeRequest -> failed to send login code %w", err)
+		return
+	}
+
+	return
+}
+
+// LogInOneTimeCode treats emailed code as the first factor. Same as with magic
+// link, receiving the code proves ownership of the email so it gets verified.
+func (d *domain) LogInOneTimeCode(setup Setup, user User, code string) (existing User, sessionKey string, err error) {
+	found, err := d.findUser(setup, user)
+	if errors.Is(err, ErrUserNotExist) {
+		err = ErrInvalidCode
+		return
+	} else if err != nil {
+		err = fmt.Errorf("domain LogInOneTimeCode -> %w", err)
+		return
+	}
+
+	err = d.useOneTimeCode(setup, oneTimeCodeLogIn, found.ID, code)
+	if err != nil {
+		return
+	}
+
+	if !found.Verified {
+		err = d.db.User(setup.ctx).Verify(found)
+		if err != nil {
+			err = fmt.Errorf("domain LogInOneTimeCode -> failed to verify user %d %w", found.ID, err)
+			return
+		}
+
+		found.Verified = true
+		d.refreshSessions(setup, found)
+	}
+
+	return d.completeLogIn(setup, found, "")
+}
+
+func (d *domain) VerifyAccountCode(setup Setup, user User, code string) (verified bool, err error) {
+	found, err := d.findUser(setup, user)
+	if errors.Is(err, ErrUserNotExist) {
+		err = ErrInvalidCode
+		return
+	} else if err != nil {
+		err = fmt.Errorf("domain VerifyAccountCode -> %w", err)
+		return
+	}
+
+	err = d.useOneTimeCode(setup, oneTimeCodeVerify, found.ID, code)
+	if err != nil {
+		return
+	}
+
+	err = d.db.User(setup.ctx).Verify(found)
+	if err != nil {
+		err = fmt.Errorf("domain VerifyAccountCode -> failed to verify user %d %w", found.ID, err)
+		return
+	}
+
+	found.Verified = true
+	d.refreshSessions(setup, found)
+
+	verified = true
+	return
+}
@@ -0,0 +1,302 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateOneTimeCode(t *testing.T) {
+	t.Parallel()
+
+	for i := 0; i < 100; i++ {
+		code, err := generateOneTimeCode()
+
+		require.NoError(t, err)
+		require.Regexp(t, regexp.MustCompile(`^\d{6}$`), code)
+	}
+}
+
+func TestOneTimeCodeRequest(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com", Username: "djvukovic"}
+
+	type testCase struct {
+		name        string
+		setupModels func(*MockRepositoryUser, *MockRepositoryOneTimeCode, *MockNotifier, *testCase)
+		returnUser  User
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name: "success",
+			setupModels: func(ru *MockRepositoryUser, rc *MockRepositoryOneTimeCode, mn *MockNotifier, tc *testCase) {
+				var sent string
+
+				ru.EXPECT().GetByEmail(user.Email).Return(user, nil)
+				rc.EXPECT().Create(oneTimeCodeLogIn, user.ID, mock.Anything).RunAndReturn(func(purpose string, userId uint64, code string) (OneTimeCode, error) {
+					sent = code
+					return OneTimeCode{Purpose: purpose, UserID: userId, Code: code}, nil
+				})
+				mn.EXPECT().Send(user.Email, "Log in code", mock.MatchedBy(func(text string) bool {
+					return sent != "" && strings.Contains(text, sent)
+				}), mock.Anything).Return(nil)
+			},
+			returnUser: user,
+		},
+		{
+			name: "user does not exist",
+			setupModels: func(ru *MockRepositoryUser, rc *MockRepositoryOneTimeCode, mn *MockNotifier, tc *testCase) {
+				ru.EXPECT().GetByEmail(user.Email).Return(User{}, modelErrors.ErrNotFound)
+			},
+			returnError: ErrUserNotExist,
+		},
+		{
+			name: "storing code fails",
+			setupModels: func(ru *MockRepositoryUser, rc *MockRepositoryOneTimeCode, mn *MockNotifier, tc *testCase) {
+				ru.EXPECT().GetByEmail(user.Email).Return(user, nil)
+				rc.EXPECT().Create(oneTimeCodeLogIn, user.ID, mock.Anything).Return(OneTimeCode{}, errors.New("redis down"))
+			},
+			returnError: errors.New("redis down"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			userRepository := NewMockRepositoryUser(t)
+			codeRepository := NewMockRepositoryOneTimeCode(t)
+			notifier := NewMockNotifier(t)
+
+			// Setup mocks
+			repository.EXPECT().User(context.TODO()).Return(userRepository)
+			repository.EXPECT().OneTimeCode(context.TODO()).Return(codeRepository).Maybe()
+			tc.setupModels(userRepository, codeRepository, notifier, &tc)
+
+			// Run
+			domain := NewDomain(repository, utils.Config{}, notifier)
+			sentTo, err := domain.OneTimeCodeRequest(setup, User{Email: user.Email})
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorContains(t, err, tc.returnError.Error())
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.returnUser, sentTo)
+		})
+	}
+}
+
+func TestLogInOneTimeCode(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com", Verified: true}
+	unverified := User{ID: 452, Email: "djvukovic@gmail.com"}
+	pending := OneTimeCode{Purpose: oneTimeCodeLogIn, UserID: user.ID, Code: "123456"}
+	employee := User{ID: 453, Email: "employee@bigcustomer.com", Verified: true}
+	config := utils.Config{SAMLProviders: []utils.SAMLProvider{{Name: "okta", Domains: []string{"bigcustomer.com"}}}}
+
+	type testCase struct {
+		name        string
+		email       string
+		code        string
+		setupModels func(*MockRepositoryUser, *MockRepositoryOneTimeCode, *MockRepositoryTOTP, *MockRepositoryPasskey, *MockRepositorySession, *testCase)
+		returnUser  User
+		returnKey   string
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name: "success",
+			code: "123456",
+			setupModels: func(ru *MockRepositoryUser, rc *MockRepositoryOneTimeCode, rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rs *MockRepositorySession, tc *testCase) {
+				ru.EXPECT().GetByEmail(user.Email).Return(unverified, nil)
+				rc.EXPECT().Get(oneTimeCodeLogIn, user.ID).Return(pending, nil)
+				rc.EXPECT().Attempt(oneTimeCodeLogIn, user.ID).Return(1, nil)
+				rc.EXPECT().Delete(oneTimeCodeLogIn, user.ID).Return(nil)
+				ru.EXPECT().Verify(unverified).Return(nil)
+				rs.EXPECT().UpdateUser(user).Return(nil)
+				rt.EXPECT().Get(user.ID).Return(TOTP{}, modelErrors.ErrNotFound)
+				rp.EXPECT().GetByUser(user.ID).Return([]Passkey{}, nil)
+				rs.EXPECT().Create(user, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: user}, nil)
+			},
+			returnUser: user,
+			returnKey:  "session",
+		},
+		{
+			name:  "enterprise connection user",
+			email: employee.Email,
+			code:  "123456",
+			setupModels: func(ru *MockRepositoryUser, rc *MockRepositoryOneTimeCode, rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rs *MockRepositorySession, tc *testCase) {
+				ru.EXPECT().GetByEmail(employee.Email).Return(employee, nil)
+				rc.EXPECT().Get(oneTimeCodeLogIn, employee.ID).Return(OneTimeCode{Purpose: oneTimeCodeLogIn, UserID: employee.ID, Code: "123456"}, nil)
+				rc.EXPECT().Attempt(oneTimeCodeLogIn, employee.ID).Return(1, nil)
+				rc.EXPECT().Delete(oneTimeCodeLogIn, employee.ID).Return(nil)
+			},
+			returnError: ErrSSORequired,
+		},
+		{
+			name: "wrong code",
+			code: "654321",
+			setupModels: func(ru *MockRepositoryUser, rc *MockRepositoryOneTimeCode, rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rs *MockRepositorySession, tc *testCase) {
+				ru.EXPECT().GetByEmail(user.Email).Return(user, nil)
+				rc.EXPECT().Get(oneTimeCodeLogIn, user.ID).Return(pending, nil)
+				rc.EXPECT().Attempt(oneTimeCodeLogIn, user.ID).Return(1, nil)
+			},
+			returnError: ErrInvalidCode,
+		},
+		{
+			name: "too many attempts",
+			code: "123456",
+			setupModels: func(ru *MockRepositoryUser, rc *MockRepositoryOneTimeCode, rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rs *MockRepositorySession, tc *testCase) {
+				ru.EXPECT().GetByEmail(user.Email).Return(user, nil)
+				rc.EXPECT().Get(oneTimeCodeLogIn, user.ID).Return(pending, nil)
+				rc.EXPECT().Attempt(oneTimeCodeLogIn, user.ID).Return(maxChallengeAttempts+1, nil)
+				rc.EXPECT().Delete(oneTimeCodeLogIn, user.ID).Return(nil)
+			},
+			returnError: ErrInvalidCode,
+		},
+		{
+			name: "expired code",
+			code: "123456",
+			setupModels: func(ru *MockRepositoryUser, rc *MockRepositoryOneTimeCode, rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rs *MockRepositorySession, tc *testCase) {
+				ru.EXPECT().GetByEmail(user.Email).Return(user, nil)
+				rc.EXPECT().Get(oneTimeCodeLogIn, user.ID).Return(OneTimeCode{}, modelErrors.ErrNotFound)
+			},
+			returnError: ErrInvalidCode,
+		},
+		{
+			name: "unknown user",
+			code: "123456",
+			setupModels: func(ru *MockRepositoryUser, rc *MockRepositoryOneTimeCode, rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rs *MockRepositorySession, tc *testCase) {
+				ru.EXPECT().GetByEmail(user.Email).Return(User{}, modelErrors.ErrNotFound)
+			},
+			returnError: ErrInvalidCode,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			userRepository := NewMockRepositoryUser(t)
+			codeRepository := NewMockRepositoryOneTimeCode(t)
+			totpRepository := NewMockRepositoryTOTP(t)
+			passkeyRepository := NewMockRepositoryPasskey(t)
+			sessionRepository := NewMockRepositorySession(t)
+
+			// Setup mocks
+			repository.EXPECT().User(context.TODO()).Return(userRepository)
+			repository.EXPECT().OneTimeCode(context.TODO()).Return(codeRepository).Maybe()
+			repository.EXPECT().TOTP(context.TODO()).Return(totpRepository).Maybe()
+			repository.EXPECT().Passkey(context.TODO()).Return(passkeyRepository).Maybe()
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository).Maybe()
+			tc.setupModels(userRepository, codeRepository, totpRepository, passkeyRepository, sessionRepository, &tc)
+
+			// Run
+			email := user.Email
+			if tc.email != "" {
+				email = tc.email
+			}
+
+			domain := NewDomain(repository, config, NewMockNotifier(t))
+			existing, key, err := domain.LogInOneTimeCode(setup, User{Email: email}, tc.code)
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorIs(t, err, tc.returnError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.returnUser, existing)
+			require.Equal(t, tc.returnKey, key)
+		})
+	}
+}
+
+func TestVerifyAccountCode(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Username: "djvukovic", Email: "djvukovic@gmail.com"}
+	pending := OneTimeCode{Purpose: oneTimeCodeVerify, UserID: user.ID, Code: "123456"}
+
+	type testCase struct {
+		name        string
+		code        string
+		setupModels func(*MockRepositoryUser, *MockRepositoryOneTimeCode, *MockRepositorySession, *testCase)
+		verified    bool
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name: "success",
+			code: "123456",
+			setupModels: func(ru *MockRepositoryUser, rc *MockRepositoryOneTimeCode, rs *MockRepositorySession, tc *testCase) {
+				ru.EXPECT().GetByUsername(user.Username).Return(user, nil)
+				rc.EXPECT().Get(oneTimeCodeVerify, user.ID).Return(pending, nil)
+				rc.EXPECT().Attempt(oneTimeCodeVerify, user.ID).Return(1, nil)
+				rc.EXPECT().Delete(oneTimeCodeVerify, user.ID).Return(nil)
+				ru.EXPECT().Verify(user).Return(nil)
+				rs.EXPECT().UpdateUser(User{ID: user.ID, Username: user.Username, Email: user.Email, Verified: true}).Return(nil)
+			},
+			verified: true,
+		},
+		{
+			name: "wrong code",
+			code: "654321",
+			setupModels: func(ru *MockRepositoryUser, rc *MockRepositoryOneTimeCode, rs *MockRepositorySession, tc *testCase) {
+				ru.EXPECT().GetByUsername(user.Username).Return(user, nil)
+				rc.EXPECT().Get(oneTimeCodeVerify, user.ID).Return(pending, nil)
+				rc.EXPECT().Attempt(oneTimeCodeVerify, user.ID).Return(2, nil)
+			},
+			returnError: ErrInvalidCode,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			userRepository := NewMockRepositoryUser(t)
+			codeRepository := NewMockRepositoryOneTimeCode(t)
+			sessionRepository := NewMockRepositorySession(t)
+
+			// Setup mocks
+			repository.EXPECT().User(context.TODO()).Return(userRepository)
+			repository.EXPECT().OneTimeCode(context.TODO()).Return(codeRepository)
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository).Maybe()
+			tc.setupModels(userRepository, codeRepository, sessionRepository, &tc)
+
+			// Run
+			domain := NewDomain(repository, utils.Config{}, NewMockNotifier(t))
+			verified, err := domain.VerifyAccountCode(setup, User{Username: user.Username}, tc.code)
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorIs(t, err, tc.returnError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.verified, verified)
+		})
+	}
+}
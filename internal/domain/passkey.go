@@ -0,0 +1,465 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// passkeyUser adapts domain user and its passkeys to webauthn.User
+type passkeyUser struct {
+	user     User
+	passkeys []Passkey
+}
+
+func (u passkeyUser) WebAuthnID() []byte {
+	return []byte(strconv.FormatUint(u.user.ID, 10))
+}
+
+func (u passkeyUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u passkeyUser) WebAuthnDisplayName() string {
+	if u.user.Username != "" {
+		return u.user.Username
+	}
+
+	return u.user.Email
+}
+
+func (u passkeyUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.passkeys))
+	for _, passkey := range u.passkeys {
+		credentials = append(credentials, passkeyToCredential(passkey))
+	}
+
+	return credentials
+}
+
+func passkeyToCredential(passkey Passkey) webauthn.Credential {
+	transports := make([]protocol.AuthenticatorTransport, 0, len(passkey.Transports))
+	for _, transport := range passkey.Transports {
+		transports = append(transports, protocol.AuthenticatorTransport(transport))
+	}
+
+	return webauthn.Credential{
+		ID:              passkey.CredentialID,
+		PublicKey:       passkey.PublicKey,
+		AttestationType: passkey.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: passkey.BackupEligible,
+			BackupState:    passkey.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    passkey.AAGUID,
+			SignCount: passkey.SignCount,
+		},
+	}
+}
+
+func credentialToPasskey(userId uint64, credential *webauthn.Credential) Passkey {
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	return Passkey{
+		UserID:          userId,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		Transports:      transports,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	}
+}
+
+// ceremony holds state of an ongoing webauthn registration or login between begin and finish requests.
+// Challenge is set when passkey is used as a second factor for pending password login.
+type ceremony struct {
+	Session   webauthn.SessionData `json:"session"`
+	UserID    uint64               `json:"user_id"`
+	Challenge string               `json:"challenge"`
+}
+
+func (d *domain) webAuthn() (*webauthn.WebAuthn, error) {
+	if !d.config.HasWebAuthnSetup() {
+		return nil, ErrPasskeysNotConfigured
+	}
+
+	return webauthn.New(&webauthn.Config{
+		RPID:          d.config.WebAuthn.RPID,
+		RPDisplayName: d.config.WebAuthn.RPDisplayName,
+		RPOrigins:     d.config.WebAuthn.RPOrigins,
+	})
+}
+
+// storeCeremony keeps serialized state of a multi-request flow until it's taken
+func (d *domain) storeCeremony(setup Setup, state any) (key string, err error) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		err = fmt.Errorf("unable to serialize ceremony %w", err)
+		return
+	}
+
+	key, err = d.db.Ceremony(setup.ctx).Create(data)
+	if err != nil {
+		err = fmt.Errorf("unable to store ceremony %w", err)
+	}
+
+	return
+}
+
+func (d *domain) takeCeremony(setup Setup, key string) (state ceremony, err error) {
+	err = d.takeCeremonyState(setup, key, &state)
+	return
+}
+
+// takeCeremonyState removes stored state so it can be used only once
+func (d *domain) takeCeremonyState(setup Setup, key string, state any) error {
+	data, err := d.db.Ceremony(setup.ctx).Take(key)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		return ErrInvalidCeremony
+	} else if err != nil {
+		return fmt.Errorf("unable to get ceremony %s %w", key, err)
+	}
+
+	err = json.Unmarshal(data, state)
+	if err != nil {
+		return fmt.Errorf("invalid ceremony %s %w", key, err)
+	}
+
+	return nil
+}
+
+func (d *domain) passkeyUser(setup Setup, user User) (pu passkeyUser, err error) {
+	passkeys, err := d.db.Passkey(setup.ctx).GetByUser(user.ID)
+	if err != nil {
+		err = fmt.Errorf("unable to get passkeys for user %d %w", user.ID, err)
+		return
+	}
+
+	pu = passkeyUser{user: user, passkeys: passkeys}
+	return
+}
+
+func (d *domain) BeginPasskeyRegistration(setup Setup, token string) (options json.RawMessage, ceremonyKey string, err error) {
+	wa, err := d.webAuthn()
+	if err != nil {
+		return
+	}
+
+	user, err := d.loginSession(setup, token)
+	if err != nil {
+		return
+	}
+
+	pu, err := d.passkeyUser(setup, user)
+	if err != nil {
+		return
+	}
+
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(pu.passkeys))
+	for _, credential := range pu.WebAuthnCredentials() {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+
+	creation, session, err := wa.BeginRegistration(
+		pu,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+	)
+	if err != nil {
+		err = fmt.Errorf("domain BeginPasskeyRegistration -> %w", err)
+		return
+	}
+
+	options, err = json.Marshal(creation)
+	if err != nil {
+		err = fmt.Errorf("domain BeginPasskeyRegistration -> unable to serialize options %w", err)
+		return
+	}
+
+	ceremonyKey, err = d.storeCeremony(setup, ceremony{Session: *session, UserID: user.ID})
+	if err != nil {
+		options = nil
+	}
+
+	return
+}
+
+func (d *domain) FinishPasskeyRegistration(setup Setup, token string, ceremonyKey string, response []byte) (recoveryCodes []string, err error) {
+	wa, err := d.webAuthn()
+	if err != nil {
+		return
+	}
+
+	user, err := d.loginSession(setup, token)
+	if err != nil {
+		return
+	}
+
+	state, err := d.takeCeremony(setup, ceremonyKey)
+	if err != nil {
+		return
+	}
+
+	if state.UserID != user.ID || state.Challenge != "" {
+		err = ErrInvalidCeremony
+		return
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(response))
+	if err != nil {
+		err = ErrInvalidCredentials
+		return
+	}
+
+	pu, err := d.passkeyUser(setup, user)
+	if err != nil {
+		return
+	}
+
+	credential, err := wa.CreateCredential(pu, state.Session, parsed)
+	if err != nil {
+		setup.logger.Info("passkey registration rejected", "user", user.ID, "reason", err.Error())
+		err = ErrInvalidCredentials
+		return
+	}
+
+	enrolled, err := d.hasSecondFactor(setup, user)
+	if err != nil {
+		return
+	}
+
+	err = d.db.Atomic(func(txRepo Repository) error {
+		_, e := txRepo.Passkey(setup.ctx).Create(credentialToPasskey(user.ID, credential))
+		if e != nil {
+			return fmt.Errorf("domain FinishPasskeyRegistration -> failed to store passkey %w", e)
+		}
+
+		// First second factor gets a fresh set of recovery codes
+		if enrolled {
+			return nil
+		}
+
+		codes, e := storeRecoveryCodes(txRepo.RecoveryCode(setup.ctx), user.ID)
+		if e != nil {
+			return fmt.Errorf("domain FinishPasskeyRegistration -> %w", e)
+		}
+
+		recoveryCodes = codes
+		return nil
+	})
+
+	if err != nil {
+		recoveryCodes = nil
+	}
+
+	return
+}
+
+// BeginPasskeyLogIn starts passkey assertion. Without challenge it is a passwordless login
+// with discoverable credential, otherwise it is a second factor for pending password login.
+func (d *domain) BeginPasskeyLogIn(setup Setup, challengeKey string) (options json.RawMessage, ceremonyKey string, err error) {
+	wa, err := d.webAuthn()
+	if err != nil {
+		return
+	}
+
+	var assertion *protocol.CredentialAssertion
+	var session *webauthn.SessionData
+	state := ceremony{Challenge: challengeKey}
+
+	if challengeKey == "" {
+		assertion, session, err = wa.BeginDiscoverableLogin()
+	} else {
+		challenge, e := d.db.Challenge(setup.ctx).Get(challengeKey)
+		if errors.Is(e, modelErrors.ErrNotFound) {
+			err = ErrInvalidChallenge
+			return
+		} else if e != nil {
+			err = fmt.Errorf("domain BeginPasskeyLogIn -> unable to get challenge %w", e)
+			return
+		}
+
+		user, e := d.db.User(setup.ctx).GetByID(challenge.UserID)
+		if e != nil {
+			err = fmt.Errorf("domain BeginPasskeyLogIn -> unable to get user %d %w", challenge.UserID, e)
+			return
+		}
+
+		pu, e := d.passkeyUser(setup, user)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if len(pu.passkeys) == 0 {
+			err = ErrNotEnrolled
+			return
+		}
+
+		state.UserID = user.ID
+		assertion, session, err = wa.BeginLogin(pu)
+	}
+
+	if err != nil {
+		err = fmt.Errorf("domain BeginPasskeyLogIn -> %w", err)
+		return
+	}
+
+	options, err = json.Marshal(assertion)
+	if err != nil {
+		err = fmt.Errorf("domain BeginPasskeyLogIn -> unable to serialize options %w", err)
+		return
+	}
+
+	state.Session = *session
+	ceremonyKey, err = d.storeCeremony(setup, state)
+	if err != nil {
+		options = nil
+	}
+
+	return
+}
+
+func (d *domain) FinishPasskeyLogIn(setup Setup, ceremonyKey string, response []byte) (existing User, sessionKey string, err error) {
+	wa, err := d.webAuthn()
+	if err != nil {
+		return
+	}
+
+	state, err := d.takeCeremony(setup, ceremonyKey)
+	if err != nil {
+		return
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(response))
+	if err != nil {
+		err = ErrInvalidCredentials
+		return
+	}
+
+	if state.Challenge != "" {
+		return d.finishPasskeySecondFactor(setup, wa, state, parsed)
+	}
+
+	userModel := d.db.User(setup.ctx)
+
+	var owner User
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		passkey, e := d.db.Passkey(setup.ctx).GetByCredentialID(rawID)
+		if e != nil {
+			return nil, e
+		}
+
+		owner, e = userModel.GetByID(passkey.UserID)
+		if e != nil {
+			return nil, e
+		}
+
+		pu, e := d.passkeyUser(setup, owner)
+		if e != nil {
+			return nil, e
+		}
+
+		return pu, nil
+	}
+
+	credential, err := wa.ValidateDiscoverableLogin(handler, state.Session, parsed)
+	if err != nil {
+		setup.logger.Info("passkey login rejected", "reason", err.Error())
+		err = ErrInvalidCredentials
+		return
+	}
+
+	err = d.updatePasskeyUsage(setup, credential)
+	if err != nil {
+		return
+	}
+
+	err = d.requireConnection(owner, "")
+	if err != nil {
+		return
+	}
+
+	sessionKey, err = d.startSession(setup, owner)
+	if err != nil {
+		return
+	}
+
+	existing = owner
+	return
+}
+
+func (d *domain) finishPasskeySecondFactor(setup Setup, wa *webauthn.WebAuthn, state ceremony, parsed *protocol.ParsedCredentialAssertionData) (existing User, sessionKey string, err error) {
+	challenge, err := d.attemptChallenge(setup, state.Challenge)
+	if err != nil {
+		return
+	}
+
+	if challenge.UserID != state.UserID {
+		err = ErrInvalidChallenge
+		return
+	}
+
+	user, err := d.db.User(setup.ctx).GetByID(challenge.UserID)
+	if err != nil {
+		err = fmt.Errorf("domain FinishPasskeyLogIn -> unable to get user %d %w", challenge.UserID, err)
+		return
+	}
+
+	pu, err := d.passkeyUser(setup, user)
+	if err != nil {
+		return
+	}
+
+	credential, err := wa.ValidateLogin(pu, state.Session, parsed)
+	if err != nil {
+		setup.logger.Info("passkey second factor rejected", "user", user.ID, "reason", err.Error())
+		err = ErrInvalidCredentials
+		return
+	}
+
+	err = d.updatePasskeyUsage(setup, credential)
+	if err != nil {
+		return
+	}
+
+	return d.completeChallenge(setup, challenge)
+}
+
+// updatePasskeyUsage stores new signature counter. Counter that did not increase
+// indicates possibly cloned authenticator and such login is refused.
+func (d *domain) updatePasskeyUsage(setup Setup, credential *webauthn.Credential) error {
+	if credential.Authenticator.CloneWarning {
+		setup.logger.Warn("passkey sign counter did not increase, possible cloned authenticator")
+		return ErrInvalidCredentials
+	}
+
+	err := d.db.Passkey(setup.ctx).UpdateSignCount(
+		credential.ID,
+		credential.Authenticator.SignCount,
+		credential.Flags.BackupState,
+	)
+	if err != nil {
+		return fmt.Errorf("unable to update passkey usage %w", err)
+	}
+
+	return nil
+}
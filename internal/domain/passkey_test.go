@@ -0,0 +1,411 @@
+package domain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var passkeyConfig = utils.Config{
+	WebAuthn: utils.WebAuthn{
+		RPID:          "localhost",
+		RPDisplayName: "auth",
+		RPOrigins:     []string{"http://localhost:3000"},
+	},
+}
+
+func TestPasskeyCredentialMapping(t *testing.T) {
+	t.Parallel()
+
+	passkey := Passkey{
+		UserID:          452,
+		CredentialID:    []byte("credential"),
+		PublicKey:       []byte("public key"),
+		AttestationType: "none",
+		AAGUID:          []byte("aaguid"),
+		SignCount:       7,
+		Transports:      []string{"internal", "hybrid"},
+		BackupEligible:  true,
+		BackupState:     true,
+	}
+
+	credential := passkeyToCredential(passkey)
+
+	require.Equal(t, passkey, credentialToPasskey(passkey.UserID, &credential))
+	require.Equal(t, []byte("452"), passkeyUser{user: User{ID: 452}}.WebAuthnID())
+}
+
+func TestBeginPasskeyRegistration(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com", Username: "djvukovic"}
+
+	type testCase struct {
+		name        string
+		config      utils.Config
+		setupModels func(*MockRepositorySession, *MockRepositoryPasskey, *MockRepositoryCeremony, *testCase)
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name:   "success",
+			config: passkeyConfig,
+			setupModels: func(rs *MockRepositorySession, rp *MockRepositoryPasskey, rc *MockRepositoryCeremony, tc *testCase) {
+				rs.EXPECT().Get("session").Return(user, nil)
+				rp.EXPECT().GetByUser(user.ID).Return([]Passkey{{CredentialID: []byte("existing")}}, nil)
+				rc.EXPECT().Create(mock.MatchedBy(func(data []byte) bool {
+					var state ceremony
+					json.Unmarshal(data, &state)
+					return state.UserID == user.ID && state.Session.Challenge != ""
+				})).Return("ceremony", nil)
+			},
+		},
+		{
+			name:        "not configured",
+			config:      utils.Config{},
+			setupModels: func(rs *MockRepositorySession, rp *MockRepositoryPasskey, rc *MockRepositoryCeremony, tc *testCase) {},
+			returnError: ErrPasskeysNotConfigured,
+		},
+		{
+			name:   "no session",
+			config: passkeyConfig,
+			setupModels: func(rs *MockRepositorySession, rp *MockRepositoryPasskey, rc *MockRepositoryCeremony, tc *testCase) {
+				rs.EXPECT().Get("session").Return(User{}, modelErrors.ErrNotFound)
+			},
+			returnError: ErrNoSession,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			sessionRepository := NewMockRepositorySession(t)
+			passkeyRepository := NewMockRepositoryPasskey(t)
+			ceremonyRepository := NewMockRepositoryCeremony(t)
+
+			// Setup mocks
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository).Maybe()
+			repository.EXPECT().Passkey(context.TODO()).Return(passkeyRepository).Maybe()
+			repository.EXPECT().Ceremony(context.TODO()).Return(ceremonyRepository).Maybe()
+			tc.setupModels(sessionRepository, passkeyRepository, ceremonyRepository, &tc)
+
+			// Run
+			domain := NewDomain(repository, tc.config, NewMockNotifier(t))
+			options, key, err := domain.BeginPasskeyRegistration(setup, "session")
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorIs(t, err, tc.returnError)
+				require.Nil(t, options)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "ceremony", key)
+
+			var parsed map[string]any
+			require.NoError(t, json.Unmarshal(options, &parsed))
+
+			publicKey := parsed["publicKey"].(map[string]any)
+			require.Equal(t, "localhost", publicKey["rp"].(map[string]any)["id"])
+			require.Equal(t, "djvukovic@gmail.com", publicKey["user"].(map[string]any)["name"])
+			require.Len(t, publicKey["excludeCredentials"], 1)
+		})
+	}
+}
+
+func TestBeginPasskeyLogIn(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com"}
+
+	type testCase struct {
+		name        string
+		challenge   string
+		setupModels func(*MockRepositoryChallenge, *MockRepositoryUser, *MockRepositoryPasskey, *MockRepositoryCeremony, *testCase)
+		allowed     int
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name: "passwordless",
+			setupModels: func(rc *MockRepositoryChallenge, ru *MockRepositoryUser, rp *MockRepositoryPasskey, rcr *MockRepositoryCeremony, tc *testCase) {
+				rcr.EXPECT().Create(mock.MatchedBy(func(data []byte) bool {
+					var state ceremony
+					json.Unmarshal(data, &state)
+					return state.UserID == 0 && state.Challenge == ""
+				})).Return("ceremony", nil)
+			},
+		},
+		{
+			name:      "second factor",
+			challenge: "challenge",
+			setupModels: func(rc *MockRepositoryChallenge, ru *MockRepositoryUser, rp *MockRepositoryPasskey, rcr *MockRepositoryCeremony, tc *testCase) {
+				rc.EXPECT().Get("challenge").Return(Challenge{ID: "challenge", UserID: user.ID}, nil)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+				rp.EXPECT().GetByUser(user.ID).Return([]Passkey{{CredentialID: []byte("first")}, {CredentialID: []byte("second")}}, nil)
+				rcr.EXPECT().Create(mock.MatchedBy(func(data []byte) bool {
+					var state ceremony
+					json.Unmarshal(data, &state)
+					return state.UserID == user.ID && state.Challenge == "challenge"
+				})).Return("ceremony", nil)
+			},
+			allowed: 2,
+		},
+		{
+			name:      "second factor without passkeys",
+			challenge: "challenge",
+			setupModels: func(rc *MockRepositoryChallenge, ru *MockRepositoryUser, rp *MockRepositoryPasskey, rcr *MockRepositoryCeremony, tc *testCase) {
+				rc.EXPECT().Get("challenge").Return(Challenge{ID: "challenge", UserID: user.ID}, nil)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+				rp.EXPECT().GetByUser(user.ID).Return([]Passkey{}, nil)
+			},
+			returnError: ErrNotEnrolled,
+		},
+		{
+			name:      "expired challenge",
+			challenge: "challenge",
+			setupModels: func(rc *MockRepositoryChallenge, ru *MockRepositoryUser, rp *MockRepositoryPasskey, rcr *MockRepositoryCeremony, tc *testCase) {
+				rc.EXPECT().Get("challenge").Return(Challenge{}, modelErrors.ErrNotFound)
+			},
+			returnError: ErrInvalidChallenge,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			challengeRepository := NewMockRepositoryChallenge(t)
+			userRepository := NewMockRepositoryUser(t)
+			passkeyRepository := NewMockRepositoryPasskey(t)
+			ceremonyRepository := NewMockRepositoryCeremony(t)
+
+			// Setup mocks
+			repository.EXPECT().Challenge(context.TODO()).Return(challengeRepository).Maybe()
+			repository.EXPECT().User(context.TODO()).Return(userRepository).Maybe()
+			repository.EXPECT().Passkey(context.TODO()).Return(passkeyRepository).Maybe()
+			repository.EXPECT().Ceremony(context.TODO()).Return(ceremonyRepository).Maybe()
+			tc.setupModels(challengeRepository, userRepository, passkeyRepository, ceremonyRepository, &tc)
+
+			// Run
+			domain := NewDomain(repository, passkeyConfig, NewMockNotifier(t))
+			options, key, err := domain.BeginPasskeyLogIn(setup, tc.challenge)
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorIs(t, err, tc.returnError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "ceremony", key)
+
+			var parsed map[string]any
+			require.NoError(t, json.Unmarshal(options, &parsed))
+
+			publicKey := parsed["publicKey"].(map[string]any)
+			require.NotEmpty(t, publicKey["challenge"])
+			if tc.allowed > 0 {
+				require.Len(t, publicKey["allowCredentials"], tc.allowed)
+			} else {
+				require.Nil(t, publicKey["allowCredentials"])
+			}
+		})
+	}
+}
+
+func TestFinishPasskeyLogIn(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+
+	type testCase struct {
+		name        string
+		response    []byte
+		setupModels func(*MockRepositoryCeremony, *testCase)
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name:     "unknown ceremony",
+			response: []byte(`{}`),
+			setupModels: func(rc *MockRepositoryCeremony, tc *testCase) {
+				rc.EXPECT().Take("ceremony").Return(nil, modelErrors.ErrNotFound)
+			},
+			returnError: ErrInvalidCeremony,
+		},
+		{
+			name:     "malformed assertion",
+			response: []byte(`{ "id": "abc" }`),
+			setupModels: func(rc *MockRepositoryCeremony, tc *testCase) {
+				rc.EXPECT().Take("ceremony").Return([]byte(`{ "session": { "challenge": "abc" } }`), nil)
+			},
+			returnError: ErrInvalidCredentials,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			ceremonyRepository := NewMockRepositoryCeremony(t)
+
+			// Setup mocks
+			repository.EXPECT().Ceremony(context.TODO()).Return(ceremonyRepository)
+			tc.setupModels(ceremonyRepository, &tc)
+
+			// Run
+			domain := NewDomain(repository, passkeyConfig, NewMockNotifier(t))
+			existing, key, err := domain.FinishPasskeyLogIn(setup, "ceremony", tc.response)
+
+			// Assertions
+			require.ErrorIs(t, err, tc.returnError)
+			require.Equal(t, User{}, existing)
+			require.Empty(t, key)
+		})
+	}
+}
+
+// signedPasskeyAssertion creates passkey for the user and its assertion answering the challenge
+func signedPasskeyAssertion(t *testing.T, user User, challenge string) (passkey Passkey, response []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{KeyType: int64(webauthncose.EllipticKey), Algorithm: int64(webauthncose.AlgES256)},
+		Curve:         int64(webauthncose.P256),
+		XCoord:        key.X.FillBytes(make([]byte, 32)),
+		YCoord:        key.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(t, err)
+
+	passkey = Passkey{ID: 1, UserID: user.ID, CredentialID: []byte("credential"), PublicKey: publicKey}
+
+	clientData, err := json.Marshal(map[string]string{
+		"type":      "webauthn.get",
+		"challenge": challenge,
+		"origin":    passkeyConfig.WebAuthn.RPOrigins[0],
+	})
+	require.NoError(t, err)
+
+	// rp id hash, user present and verified flags and sign count of 1
+	rpIDHash := sha256.Sum256([]byte(passkeyConfig.WebAuthn.RPID))
+	authenticatorData := append(rpIDHash[:], 0x05, 0, 0, 0, 1)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(authenticatorData, clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+
+	encode := base64.RawURLEncoding.EncodeToString
+	response, err = json.Marshal(map[string]any{
+		"id":    encode(passkey.CredentialID),
+		"rawId": encode(passkey.CredentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    encode(clientData),
+			"authenticatorData": encode(authenticatorData),
+			"signature":         encode(signature),
+			"userHandle":        encode(passkeyUser{user: user}.WebAuthnID()),
+		},
+	})
+	require.NoError(t, err)
+
+	return
+}
+
+func TestFinishPasskeyLogInDiscoverable(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	challenge := base64.RawURLEncoding.EncodeToString([]byte("passkey challenge"))
+	ceremony := []byte(`{ "session": { "challenge": "` + challenge + `" } }`)
+
+	config := passkeyConfig
+	config.SAMLProviders = []utils.SAMLProvider{{Name: "okta", Domains: []string{"bigcustomer.com"}}}
+
+	type testCase struct {
+		name        string
+		user        User
+		setupModels func(*MockRepositoryUser, *MockRepositoryPasskey, *MockRepositorySession, *testCase)
+		returnUser  User
+		returnKey   string
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name: "success",
+			user: User{ID: 452, Email: "djvukovic@gmail.com", Verified: true},
+			setupModels: func(ru *MockRepositoryUser, rp *MockRepositoryPasskey, rs *MockRepositorySession, tc *testCase) {
+				rs.EXPECT().Create(tc.user, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: tc.user}, nil)
+			},
+			returnUser: User{ID: 452, Email: "djvukovic@gmail.com", Verified: true},
+			returnKey:  "session",
+		},
+		{
+			name:        "enterprise connection user",
+			user:        User{ID: 453, Email: "employee@bigcustomer.com", Verified: true},
+			setupModels: func(ru *MockRepositoryUser, rp *MockRepositoryPasskey, rs *MockRepositorySession, tc *testCase) {},
+			returnError: ErrSSORequired,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			passkey, response := signedPasskeyAssertion(t, tc.user, challenge)
+
+			// Create mocks
+			repository := NewMockRepository(t)
+			ceremonyRepository := NewMockRepositoryCeremony(t)
+			userRepository := NewMockRepositoryUser(t)
+			passkeyRepository := NewMockRepositoryPasskey(t)
+			sessionRepository := NewMockRepositorySession(t)
+
+			// Setup mocks
+			repository.EXPECT().Ceremony(context.TODO()).Return(ceremonyRepository)
+			repository.EXPECT().User(context.TODO()).Return(userRepository)
+			repository.EXPECT().Passkey(context.TODO()).Return(passkeyRepository)
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository).Maybe()
+			ceremonyRepository.EXPECT().Take("ceremony").Return(ceremony, nil)
+			passkeyRepository.EXPECT().GetByCredentialID(passkey.CredentialID).Return(passkey, nil)
+			passkeyRepository.EXPECT().GetByUser(tc.user.ID).Return([]Passkey{passkey}, nil)
+			userRepository.EXPECT().GetByID(tc.user.ID).Return(tc.user, nil)
+			passkeyRepository.EXPECT().UpdateSignCount(passkey.CredentialID, uint32(1), false).Return(nil)
+			tc.setupModels(userRepository, passkeyRepository, sessionRepository, &tc)
+
+			// Run
+			domain := NewDomain(repository, config, NewMockNotifier(t))
+			existing, key, err := domain.FinishPasskeyLogIn(setup, "ceremony", response)
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorIs(t, err, tc.returnError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.returnUser, existing)
+			require.Equal(t, tc.returnKey, key)
+		})
+	}
+}
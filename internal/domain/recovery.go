@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	recoveryCodesCount  = 10
+	recoveryCodeLength  = 10
+	recoveryCodeEntropy = 7
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateRecoveryCode returns code formatted as two groups of five characters (abcde-fghij)
+func generateRecoveryCode() (string, error) {
+	random := make([]byte, recoveryCodeEntropy)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(recoveryEncoding.EncodeToString(random))[:recoveryCodeLength]
+	half := recoveryCodeLength / 2
+
+	return fmt.Sprintf("%s-%s", code[:half], code[half:]), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	code = strings.ReplaceAll(code, " ", "")
+
+	return code
+}
+
+// hashRecoveryCode uses plain sha256 since codes are random with enough entropy
+// and hash has to be looked up directly in the database
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(normalizeRecoveryCode(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// storeRecoveryCodes generates new set of recovery codes invalidating all previous ones
+func storeRecoveryCodes(repo RepositoryRecoveryCode, userId uint64) (codes []string, err error) {
+	codes = make([]string, 0, recoveryCodesCount)
+	hashes := make([]string, 0, recoveryCodesCount)
+
+	for i := 0; i < recoveryCodesCount; i++ {
+		code, e := generateRecoveryCode()
+		if e != nil {
+			err = fmt.Errorf("failed to generate recovery code %w", e)
+			return
+		}
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	err = repo.Replace(userId, hashes)
+	if err != nil {
+		codes = nil
+		err = fmt.Errorf("failed to store recovery codes for user %d %w", userId, err)
+	}
+
+	return
+}
+
+func (d *domain) RegenerateRecoveryCodes(setup Setup, token string) (recoveryCodes []string, err error) {
+	user, err := d.loginSession(setup, token)
+	if err != nil {
+		return
+	}
+
+	enrolled, err := d.hasSecondFactor(setup, user)
+	if err != nil {
+		return
+	}
+
+	if !enrolled {
+		err = ErrNotEnrolled
+		return
+	}
+
+	recoveryCodes, err = storeRecoveryCodes(d.db.RecoveryCode(setup.ctx), user.ID)
+	if err != nil {
+		err = fmt.Errorf("domain RegenerateRecoveryCodes -> %w", err)
+	}
+
+	return
+}
+
+func (d *domain) RecoveryCodesCount(setup Setup, token string) (remaining int, err error) {
+	user, err := d.loginSession(setup, token)
+	if err != nil {
+		return
+	}
+
+	remaining, err = d.db.RecoveryCode(setup.ctx).Count(user.ID)
+	if err != nil {
+		err = fmt.Errorf("domain RecoveryCodesCount -> failed to count codes for user %d %w", user.ID, err)
+	}
+
+	return
+}
+
+func (d *domain) LogInRecoveryCode(setup Setup, challengeKey string, code string) (existing User, sessionKey string, err error) {
+	challenge, err := d.attemptChallenge(setup, challengeKey)
+	if err != nil {
+		return
+	}
+
+	used, err := d.db.RecoveryCode(setup.ctx).Use(challenge.UserID, hashRecoveryCode(code))
+	if err != nil {
+		err = fmt.Errorf("domain LogInRecoveryCode -> failed to use recovery code %w", err)
+		return
+	}
+
+	if !used {
+		err = ErrInvalidCode
+		return
+	}
+
+	return d.completeChallenge(setup, challenge)
+}
@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	t.Parallel()
+
+	code, err := generateRecoveryCode()
+
+	require.NoError(t, err)
+	require.Regexp(t, regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`), code)
+	require.Equal(t, hashRecoveryCode(code), hashRecoveryCode(" "+normalizeRecoveryCode(code)))
+}
+
+func TestRegenerateRecoveryCodes(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com"}
+
+	type testCase struct {
+		name        string
+		setupModels func(*MockRepositoryTOTP, *MockRepositoryPasskey, *MockRepositoryRecoveryCode, *testCase)
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name: "success",
+			setupModels: func(rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rrc *MockRepositoryRecoveryCode, tc *testCase) {
+				rt.EXPECT().Get(user.ID).Return(TOTP{UserID: user.ID, Confirmed: true}, nil)
+				rrc.EXPECT().Replace(user.ID, mock.MatchedBy(func(hashes []string) bool {
+					return len(hashes) == recoveryCodesCount
+				})).Return(nil)
+			},
+		},
+		{
+			name: "no second factor",
+			setupModels: func(rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rrc *MockRepositoryRecoveryCode, tc *testCase) {
+				rt.EXPECT().Get(user.ID).Return(TOTP{}, modelErrors.ErrNotFound)
+				rp.EXPECT().GetByUser(user.ID).Return([]Passkey{}, nil)
+			},
+			returnError: ErrNotEnrolled,
+		},
+		{
+			name: "failed to store",
+			setupModels: func(rt *MockRepositoryTOTP, rp *MockRepositoryPasskey, rrc *MockRepositoryRecoveryCode, tc *testCase) {
+				rt.EXPECT().Get(user.ID).Return(TOTP{UserID: user.ID, Confirmed: true}, nil)
+				rrc.EXPECT().Replace(user.ID, mock.Anything).Return(errModel)
+			},
+			returnError: errModel,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			sessionRepository := NewMockRepositorySession(t)
+			totpRepository := NewMockRepositoryTOTP(t)
+			recoveryRepository := NewMockRepositoryRecoveryCode(t)
+			passkeyRepository := NewMockRepositoryPasskey(t)
+
+			// Setup mocks
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository)
+			repository.EXPECT().Passkey(context.TODO()).Return(passkeyRepository).Maybe()
+			repository.EXPECT().TOTP(context.TODO()).Return(totpRepository).Maybe()
+			repository.EXPECT().RecoveryCode(context.TODO()).Return(recoveryRepository).Maybe()
+			sessionRepository.EXPECT().Get("session").Return(user, nil)
+			tc.setupModels(totpRepository, passkeyRepository, recoveryRepository, &tc)
+
+			// Run
+			domain := NewDomain(repository, utils.Config{}, NewMockNotifier(t))
+			codes, err := domain.RegenerateRecoveryCodes(setup, "session")
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorIs(t, err, tc.returnError)
+				require.Nil(t, codes)
+			} else {
+				require.NoError(t, err)
+				require.Len(t, codes, recoveryCodesCount)
+			}
+		})
+	}
+}
+
+func TestLogInRecoveryCode(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com", Verified: true}
+	challenge := Challenge{ID: "challenge", UserID: user.ID}
+
+	type testCase struct {
+		name        string
+		setupModels func(*MockRepositoryRecoveryCode, *MockRepositoryChallenge, *MockRepositoryUser, *MockRepositorySession, *testCase)
+		returnUser  User
+		returnKey   string
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name: "success",
+			setupModels: func(rrc *MockRepositoryRecoveryCode, rc *MockRepositoryChallenge, ru *MockRepositoryUser, rs *MockRepositorySession, tc *testCase) {
+				rc.EXPECT().Get("challenge").Return(challenge, nil)
+				rc.EXPECT().Attempt("challenge").Return(1, nil)
+				rrc.EXPECT().Use(user.ID, hashRecoveryCode("abcde-fghij")).Return(true, nil)
+				rc.EXPECT().Delete("challenge").Return(nil)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+				rs.EXPECT().Create(user, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: user}, nil)
+			},
+			returnUser: user,
+			returnKey:  "session",
+		},
+		{
+			name: "used or unknown code",
+			setupModels: func(rrc *MockRepositoryRecoveryCode, rc *MockRepositoryChallenge, ru *MockRepositoryUser, rs *MockRepositorySession, tc *testCase) {
+				rc.EXPECT().Get("challenge").Return(challenge, nil)
+				rc.EXPECT().Attempt("challenge").Return(1, nil)
+				rrc.EXPECT().Use(user.ID, hashRecoveryCode("abcde-fghij")).Return(false, nil)
+			},
+			returnError: ErrInvalidCode,
+		},
+		{
+			name: "invalid challenge",
+			setupModels: func(rrc *MockRepositoryRecoveryCode, rc *MockRepositoryChallenge, ru *MockRepositoryUser, rs *MockRepositorySession, tc *testCase) {
+				rc.EXPECT().Get("challenge").Return(Challenge{}, modelErrors.ErrNotFound)
+			},
+			returnError: ErrInvalidChallenge,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			recoveryRepository := NewMockRepositoryRecoveryCode(t)
+			challengeRepository := NewMockRepositoryChallenge(t)
+			userRepository := NewMockRepositoryUser(t)
+			sessionRepository := NewMockRepositorySession(t)
+
+			// Setup mocks
+			repository.EXPECT().RecoveryCode(context.TODO()).Return(recoveryRepository).Maybe()
+			repository.EXPECT().Challenge(context.TODO()).Return(challengeRepository).Maybe()
+			repository.EXPECT().User(context.TODO()).Return(userRepository).Maybe()
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository).Maybe()
+			tc.setupModels(recoveryRepository, challengeRepository, userRepository, sessionRepository, &tc)
+
+			// Run
+			domain := NewDomain(repository, utils.Config{}, NewMockNotifier(t))
+			existing, key, err := domain.LogInRecoveryCode(setup, "challenge", "ABCDE-FGHIJ")
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorIs(t, err, tc.returnError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.returnUser, existing)
+			require.Equal(t, tc.returnKey, key)
+		})
+	}
+}
@@ -2,6 +2,9 @@ package domain
 
 import (
 	"context"
+	"time"
+
+	"github.com/djordjev/auth/internal/utils"
 )
 
 type AtomicFn = func(txRepo Repository) error
@@ -11,7 +14,21 @@ type Repository interface {
 	User(ctx context.Context) RepositoryUser
 	VerifyAccount(ctx context.Context) RepositoryVerifyAccount
 	ForgetPassword(ctx context.Context) RepositoryForgetPassword
+	MagicLink(ctx context.Context) RepositoryMagicLink
+	OneTimeCode(ctx context.Context) RepositoryOneTimeCode
 	Session(ctx context.Context) RepositorySession
+	TOTP(ctx context.Context) RepositoryTOTP
+	Challenge(ctx context.Context) RepositoryChallenge
+	RecoveryCode(ctx context.Context) RepositoryRecoveryCode
+	Passkey(ctx context.Context) RepositoryPasskey
+	Ceremony(ctx context.Context) RepositoryCeremony
+	Identity(ctx context.Context) RepositoryIdentity
+	OAuthClient(ctx context.Context) RepositoryOAuthClient
+	Consent(ctx context.Context) RepositoryConsent
+	RefreshToken(ctx context.Context) RepositoryRefreshToken
+	SigningKey(ctx context.Context) RepositorySigningKey
+	APIKey(ctx context.Context) RepositoryAPIKey
+	DeviceAuthorization(ctx context.Context) RepositoryDeviceAuthorization
 }
 
 type RepositoryUser interface {
@@ -19,8 +36,11 @@ type RepositoryUser interface {
 	Delete(id uint64) (success bool, err error)
 	GetByEmail(email string) (user User, err error)
 	GetByUsername(username string) (user User, err error)
+	GetByID(id uint64) (user User, err error)
 	Verify(user User) error
 	SetPassword(user User, password string) error
+	SetRole(user User, role string) error
+	SetPayload(user User, payload map[string]any) error
 }
 
 type RepositoryVerifyAccount interface {
@@ -33,8 +53,106 @@ type RepositoryForgetPassword interface {
 	Delete(token string) (request ForgetPassword, err error)
 }
 
+type RepositoryMagicLink interface {
+	Create(token string, userId uint64, expiresAt time.Time) (link MagicLink, err error)
+	Use(token string) (link MagicLink, err error)
+}
+
+type RepositoryOneTimeCode interface {
+	Create(purpose string, userId uint64, code string) (otc OneTimeCode, err error)
+	Get(purpose string, userId uint64) (otc OneTimeCode, err error)
+	Attempt(purpose string, userId uint64) (attempts int64, err error)
+	Delete(purpose string, userId uint64) error
+}
+
 type RepositorySession interface {
-	Create(user User) (session Session, err error)
+	Create(user User, client utils.Client, idle time.Duration, lifetime time.Duration) (session Session, err error)
 	Get(key string) (user User, err error)
+	Peek(key string) (session Session, err error)
+	GetByUser(userId uint64) (sessions []Session, err error)
+	UpdateUser(user User) error
 	Delete(key string) error
 }
+
+type RepositoryTOTP interface {
+	Create(userId uint64, secret string) (totp TOTP, err error)
+	Get(userId uint64) (totp TOTP, err error)
+	Confirm(userId uint64, step int64) error
+	SetLastStep(userId uint64, step int64) error
+}
+
+type RepositoryChallenge interface {
+	Create(user User) (challenge Challenge, err error)
+	Get(key string) (challenge Challenge, err error)
+	Attempt(key string) (attempts int64, err error)
+	Delete(key string) error
+}
+
+type RepositoryRecoveryCode interface {
+	Replace(userId uint64, hashes []string) error
+	Count(userId uint64) (remaining int, err error)
+	Use(userId uint64, hash string) (used bool, err error)
+}
+
+type RepositoryPasskey interface {
+	Create(passkey Passkey) (created Passkey, err error)
+	GetByUser(userId uint64) (passkeys []Passkey, err error)
+	GetByCredentialID(credentialId []byte) (passkey Passkey, err error)
+	UpdateSignCount(credentialId []byte, signCount uint32, backupState bool) error
+}
+
+type RepositoryCeremony interface {
+	Create(data []byte) (key string, err error)
+	Take(key string) (data []byte, err error)
+}
+
+type RepositoryIdentity interface {
+	Create(identity Identity) (created Identity, err error)
+	Get(provider string, subject string) (identity Identity, err error)
+	GetByUser(userId uint64) (identities []Identity, err error)
+	Delete(userId uint64, provider string) error
+}
+
+type RepositoryOAuthClient interface {
+	Create(client OAuthClient) (created OAuthClient, err error)
+	Get(id string) (client OAuthClient, err error)
+}
+
+type RepositoryConsent interface {
+	Get(userId uint64, clientId string) (consent Consent, err error)
+	Save(consent Consent) error
+}
+
+type RepositoryRefreshToken interface {
+	Create(token RefreshToken) (created RefreshToken, err error)
+	GetByHash(hash string) (token RefreshToken, err error)
+	Use(id uint64) error
+	RevokeFamily(family string) error
+	RevokeUser(userId uint64) error
+}
+
+type RepositorySigningKey interface {
+	Create(key SigningKey) (created SigningKey, err error)
+	GetPublished(retiredAfter time.Time) (keys []SigningKey, err error)
+	Retire(at time.Time) error
+	DeleteRetired(before time.Time) error
+	Lock() error
+}
+
+type RepositoryAPIKey interface {
+	Create(key APIKey) (created APIKey, err error)
+	GetByHash(hash string) (key APIKey, err error)
+	GetByUser(userId uint64) (keys []APIKey, err error)
+	Delete(userId uint64, id uint64) error
+	DeleteByUser(userId uint64) error
+}
+
+type RepositoryDeviceAuthorization interface {
+	Create(device DeviceAuthorization) (created DeviceAuthorization, err error)
+	Get(deviceCode string) (device DeviceAuthorization, err error)
+	GetByUserCode(userCode string) (device DeviceAuthorization, err error)
+	Poll(deviceCode string, at time.Time) (previous time.Time, err error)
+	SlowDown(deviceCode string, by int64) (interval int64, err error)
+	Decide(deviceCode string, status string, userId uint64) error
+	Delete(deviceCode string) error
+}
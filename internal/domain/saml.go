@@ -0,0 +1,460 @@
+package domain
+
+import (
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/djordjev/auth/internal/utils"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+// samlMetadataLimit caps size of identity provider metadata
+const samlMetadataLimit = 1 << 20
+
+// samlState is kept between redirect to the identity provider and the response
+// posted to ACS. UserID is set when signed in user links provider to the account.
+type samlState struct {
+	Provider  string `json:"provider"`
+	RequestID string `json:"request_id"`
+	UserID    uint64 `json:"user_id"`
+}
+
+type cachedMetadata struct {
+	metadata  *saml.EntityDescriptor
+	expiresAt time.Time
+}
+
+// samlProviders caches metadata of identity providers until it has to be refreshed
+// according to its validUntil and cacheDuration or after SAML_METADATA_REFRESH
+type samlProviders struct {
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata map[string]cachedMetadata
+}
+
+func newSAMLProviders() *samlProviders {
+	return &samlProviders{
+		client:   &http.Client{Timeout: utils.SAML_METADATA_TIMEOUT},
+		metadata: map[string]cachedMetadata{},
+	}
+}
+
+// samlServiceProvider describes this app as service provider of the named identity provider
+func (d *domain) samlServiceProvider(name string) (sp *saml.ServiceProvider, config utils.SAMLProvider, err error) {
+	config, ok := d.config.GetSAMLProvider(name)
+	if !ok || d.config.SAMLURL == "" {
+		err = ErrUnknownProvider
+		return
+	}
+
+	base := fmt.Sprintf("%s/%s", d.config.SAMLURL, name)
+
+	metadataURL, err := url.Parse(base + "/metadata")
+	if err != nil {
+		err = fmt.Errorf("invalid saml url %w", err)
+		return
+	}
+
+	acsURL, err := url.Parse(base + "/acs")
+	if err != nil {
+		err = fmt.Errorf("invalid saml url %w", err)
+		return
+	}
+
+	sp = &saml.ServiceProvider{
+		EntityID:    metadataURL.String(),
+		MetadataURL: *metadataURL,
+		AcsURL:      *acsURL,
+		// identity provider picks the format, transient ids can't be linked to users
+		AuthnNameIDFormat: saml.UnspecifiedNameIDFormat,
+	}
+
+	if d.config.SAMLKey != "" {
+		pair, e := tls.X509KeyPair([]byte(d.config.SAMLCertificate), []byte(d.config.SAMLKey))
+		if e != nil {
+			err = fmt.Errorf("invalid saml key pair %w", e)
+			return
+		}
+
+		key, isRSA := pair.PrivateKey.(*rsa.PrivateKey)
+		if !isRSA {
+			err = fmt.Errorf("saml key has to be rsa key")
+			return
+		}
+
+		sp.Key = key
+		sp.Certificate, err = x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			err = fmt.Errorf("invalid saml certificate %w", err)
+			return
+		}
+
+		sp.SignatureMethod = dsig.RSASHA256SignatureMethod
+	}
+
+	return
+}
+
+// samlIdentityProvider returns metadata with endpoints and signing certificates of the identity provider
+func (d *domain) samlIdentityProvider(setup Setup, config utils.SAMLProvider) (metadata *saml.EntityDescriptor, err error) {
+	providers := d.saml
+
+	providers.mu.Lock()
+	cached, ok := providers.metadata[config.Name]
+	providers.mu.Unlock()
+
+	now := time.Now()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.metadata, nil
+	}
+
+	metadata, err = providers.fetch(setup, config)
+	if err != nil {
+		return
+	}
+
+	expiresAt := now.Add(utils.SAML_METADATA_REFRESH)
+	if metadata.CacheDuration > 0 && now.Add(metadata.CacheDuration).Before(expiresAt) {
+		expiresAt = now.Add(metadata.CacheDuration)
+	}
+
+	if !metadata.ValidUntil.IsZero() {
+		if !now.Before(metadata.ValidUntil) {
+			metadata = nil
+			err = fmt.Errorf("metadata of %s expired", config.Name)
+			return
+		}
+
+		if metadata.ValidUntil.Before(expiresAt) {
+			expiresAt = metadata.ValidUntil
+		}
+	}
+
+	providers.mu.Lock()
+	defer providers.mu.Unlock()
+
+	providers.metadata[config.Name] = cachedMetadata{metadata: metadata, expiresAt: expiresAt}
+
+	return
+}
+
+func (p *samlProviders) fetch(setup Setup, config utils.SAMLProvider) (metadata *saml.EntityDescriptor, err error) {
+	request, err := http.NewRequestWithContext(setup.ctx, http.MethodGet, config.MetadataURL, nil)
+	if err != nil {
+		err = fmt.Errorf("invalid metadata url of %s %w", config.Name, err)
+		return
+	}
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		err = fmt.Errorf("unable to fetch metadata of %s %w", config.Name, err)
+		return
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unable to fetch metadata of %s, status %d", config.Name, response.StatusCode)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, samlMetadataLimit))
+	if err != nil {
+		err = fmt.Errorf("unable to read metadata of %s %w", config.Name, err)
+		return
+	}
+
+	metadata = &saml.EntityDescriptor{}
+	err = xml.Unmarshal(body, metadata)
+	if err != nil {
+		metadata = nil
+		err = fmt.Errorf("invalid metadata of %s %w", config.Name, err)
+	}
+
+	return
+}
+
+// SAMLMetadata returns service provider metadata identity provider is configured with
+func (d *domain) SAMLMetadata(setup Setup, provider string) (metadata []byte, err error) {
+	sp, _, err := d.samlServiceProvider(provider)
+	if err != nil {
+		return
+	}
+
+	metadata, err = xml.MarshalIndent(sp.Metadata(), "", "  ")
+	if err != nil {
+		err = fmt.Errorf("domain SAMLMetadata -> unable to encode metadata %w", err)
+	}
+
+	return
+}
+
+// BeginSAMLLogIn returns identity provider's URL with authentication request and the relay state
+// it carries. When called with session token identity from the provider gets linked to the
+// signed in user.
+func (d *domain) BeginSAMLLogIn(setup Setup, provider string, token string) (redirect string, relayState string, err error) {
+	sp, config, err := d.samlServiceProvider(provider)
+	if err != nil {
+		return
+	}
+
+	state := samlState{Provider: provider}
+
+	if token != "" {
+		user, e := d.loginSession(setup, token)
+		if e != nil {
+			err = e
+			return
+		}
+
+		state.UserID = user.ID
+	}
+
+	sp.IDPMetadata, err = d.samlIdentityProvider(setup, config)
+	if err != nil {
+		err = fmt.Errorf("domain BeginSAMLLogIn -> %w", err)
+		return
+	}
+
+	location := sp.GetSSOBindingLocation(saml.HTTPRedirectBinding)
+	if location == "" {
+		err = fmt.Errorf("domain BeginSAMLLogIn -> %s does not support redirect binding", provider)
+		return
+	}
+
+	request, err := sp.MakeAuthenticationRequest(location, saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+	if err != nil {
+		err = fmt.Errorf("domain BeginSAMLLogIn -> unable to create authentication request %w", err)
+		return
+	}
+
+	state.RequestID = request.ID
+
+	key, err := d.storeCeremony(setup, state)
+	if err != nil {
+		err = fmt.Errorf("domain BeginSAMLLogIn -> %w", err)
+		return
+	}
+
+	redirectURL, err := request.Redirect(url.QueryEscape(key), sp)
+	if err != nil {
+		err = fmt.Errorf("domain BeginSAMLLogIn -> unable to sign authentication request %w", err)
+		return
+	}
+
+	redirect = redirectURL.String()
+	relayState = key
+
+	return
+}
+
+// FinishSAMLLogIn validates response posted by identity provider and logs in the user
+// its assertion is about. Response has to answer the request started with BeginSAMLLogIn.
+func (d *domain) FinishSAMLLogIn(setup Setup, provider string, relayState string, response string) (existing User, sessionKey string, err error) {
+	var state samlState
+	err = d.takeCeremonyState(setup, relayState, &state)
+	if err != nil {
+		return
+	}
+
+	if state.Provider != provider {
+		err = ErrInvalidCeremony
+		return
+	}
+
+	sp, config, err := d.samlServiceProvider(provider)
+	if err != nil {
+		return
+	}
+
+	sp.IDPMetadata, err = d.samlIdentityProvider(setup, config)
+	if err != nil {
+		err = fmt.Errorf("domain FinishSAMLLogIn -> %w", err)
+		return
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(response)
+	if err != nil {
+		err = fmt.Errorf("%w: response of %s is not base64 encoded", ErrInvalidIdentity, provider)
+		return
+	}
+
+	assertion, err := sp.ParseXMLResponse(decoded, []string{state.RequestID})
+	if err != nil {
+		// details of invalid response are kept out of its message
+		var invalid *saml.InvalidResponseError
+		if errors.As(err, &invalid) && invalid.PrivateErr != nil {
+			err = invalid.PrivateErr
+		}
+
+		err = fmt.Errorf("%w: %w", ErrInvalidIdentity, err)
+		return
+	}
+
+	if assertion.Subject == nil || assertion.Subject.NameID == nil || assertion.Subject.NameID.Value == "" {
+		err = fmt.Errorf("%w: %s did not return subject", ErrInvalidIdentity, provider)
+		return
+	}
+
+	subject := assertion.Subject.NameID.Value
+	attributes := samlAttributes(assertion)
+
+	profile := User{Email: samlAttribute(attributes, config.EmailAttr)}
+	if profile.Email == "" && strings.Contains(subject, "@") {
+		profile.Email = subject
+	}
+
+	// provider is trusted only with emails of the domains it's configured for, otherwise
+	// it could assert emails of other organizations and take over their accounts
+	if profile.Email != "" && len(config.Domains) > 0 {
+		if !slices.Contains(config.Domains, emailDomain(profile.Email)) {
+			err = fmt.Errorf("%w: %s asserted email %s outside of its domains", ErrInvalidIdentity, provider, profile.Email)
+			return
+		}
+
+		profile.Verified = true
+	}
+
+	// role is synced only when asserted, provider leaving the attribute out doesn't reset it
+	roleAsserted := config.RoleAttr != "" && len(attributes[config.RoleAttr]) > 0
+	if roleAsserted {
+		profile.Role = samlAttribute(attributes, config.RoleAttr)
+	}
+
+	if len(config.PayloadAttrs) > 0 {
+		profile.Payload = samlPayload(attributes, config.PayloadAttrs)
+	}
+
+	identity := Identity{Provider: provider, Subject: subject, Email: profile.Email}
+
+	user, err := d.resolveIdentity(setup, config.AutoLink, state.UserID, identity, profile)
+	if err != nil {
+		return
+	}
+
+	if roleAsserted {
+		user, err = d.syncRole(setup, user, profile.Role)
+		if err != nil {
+			return
+		}
+	}
+
+	if len(config.PayloadAttrs) > 0 {
+		user, err = d.syncPayload(setup, user, profile.Payload)
+		if err != nil {
+			return
+		}
+	}
+
+	// linking is done by already authenticated user so there's no second factor
+	if state.UserID != 0 {
+		sessionKey, err = d.startSession(setup, user)
+		if err == nil {
+			existing = user
+		}
+
+		return
+	}
+
+	return d.completeLogIn(setup, user, provider)
+}
+
+// syncPayload copies values managed by the identity provider into user's payload
+func (d *domain) syncPayload(setup Setup, user User, values map[string]any) (synced User, err error) {
+	payload := make(map[string]any, len(user.Payload)+len(values))
+	for key, value := range user.Payload {
+		payload[key] = value
+	}
+
+	changed := false
+	for key, value := range values {
+		if !reflect.DeepEqual(payload[key], value) {
+			payload[key] = value
+			changed = true
+		}
+	}
+
+	if !changed {
+		return user, nil
+	}
+
+	err = d.db.User(setup.ctx).SetPayload(user, payload)
+	if err != nil {
+		err = fmt.Errorf("unable to sync payload of user %d %w", user.ID, err)
+		return
+	}
+
+	synced = user
+	synced.Payload = payload
+
+	return
+}
+
+// samlAttributes collects values of assertion attributes by their names and friendly names
+func samlAttributes(assertion *saml.Assertion) map[string][]string {
+	attributes := map[string][]string{}
+
+	for _, statement := range assertion.AttributeStatements {
+		for _, attribute := range statement.Attributes {
+			values := make([]string, 0, len(attribute.Values))
+			for _, value := range attribute.Values {
+				values = append(values, value.Value)
+			}
+
+			attributes[attribute.Name] = values
+			if attribute.FriendlyName != "" {
+				attributes[attribute.FriendlyName] = values
+			}
+		}
+	}
+
+	return attributes
+}
+
+func samlAttribute(attributes map[string][]string, name string) string {
+	if values := attributes[name]; len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// samlPayload keeps single values as strings and multiple as lists the same way they are read from json
+func samlPayload(attributes map[string][]string, names []string) map[string]any {
+	payload := map[string]any{}
+
+	for _, name := range names {
+		values, ok := attributes[name]
+		if !ok {
+			continue
+		}
+
+		if len(values) == 1 {
+			payload[name] = values[0]
+			continue
+		}
+
+		list := make([]any, 0, len(values))
+		for _, value := range values {
+			list = append(list, value)
+		}
+
+		payload[name] = list
+	}
+
+	return payload
+}
@@ -0,0 +1,514 @@
+package domain
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/crewjam/saml"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testIdentityProvider is a stand-in SAML identity provider signing responses with a locally generated certificate
+type testIdentityProvider struct {
+	server  *httptest.Server
+	idp     *saml.IdentityProvider
+	fetches atomic.Int32
+}
+
+func newTestCertificate(t *testing.T) (*rsa.PrivateKey, *x509.Certificate) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return key, certificate
+}
+
+func newTestIdentityProvider(t *testing.T) *testIdentityProvider {
+	key, certificate := newTestCertificate(t)
+	provider := &testIdentityProvider{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metadata", func(w http.ResponseWriter, r *http.Request) {
+		provider.fetches.Add(1)
+		xml.NewEncoder(w).Encode(provider.idp.Metadata())
+	})
+
+	provider.server = httptest.NewServer(mux)
+	t.Cleanup(provider.server.Close)
+
+	base, err := url.Parse(provider.server.URL)
+	require.NoError(t, err)
+
+	provider.idp = &saml.IdentityProvider{
+		Key:         key,
+		Certificate: certificate,
+		MetadataURL: *base.JoinPath("metadata"),
+		SSOURL:      *base.JoinPath("sso"),
+	}
+
+	return provider
+}
+
+// respond simulates user signing in at the identity provider for the given authentication request URL
+func (p *testIdentityProvider) respond(t *testing.T, authURL string, metadata []byte, session *saml.Session) (relayState string, response string) {
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(authURL, p.idp.SSOURL.String()))
+
+	encoded, err := base64.StdEncoding.DecodeString(parsed.Query().Get("SAMLRequest"))
+	require.NoError(t, err)
+
+	inflated, err := io.ReadAll(flate.NewReader(bytes.NewReader(encoded)))
+	require.NoError(t, err)
+
+	var request saml.AuthnRequest
+	require.NoError(t, xml.Unmarshal(inflated, &request))
+
+	var spMetadata saml.EntityDescriptor
+	require.NoError(t, xml.Unmarshal(metadata, &spMetadata))
+
+	req := &saml.IdpAuthnRequest{
+		IDP:                     p.idp,
+		HTTPRequest:             httptest.NewRequest("GET", authURL, nil),
+		Request:                 request,
+		ServiceProviderMetadata: &spMetadata,
+		SPSSODescriptor:         &spMetadata.SPSSODescriptors[0],
+		ACSEndpoint:             &saml.IndexedEndpoint{Binding: saml.HTTPPostBinding, Location: request.AssertionConsumerServiceURL},
+		Now:                     saml.TimeNow(),
+	}
+
+	require.NoError(t, saml.DefaultAssertionMaker{}.MakeAssertion(req, session))
+
+	form, err := req.PostBinding()
+	require.NoError(t, err)
+
+	return parsed.Query().Get("RelayState"), form.SAMLResponse
+}
+
+func samlConfig(provider *testIdentityProvider) utils.Config {
+	return utils.Config{
+		SAMLURL: "https://example.com/saml",
+		SAMLProviders: []utils.SAMLProvider{{
+			Name:         "okta",
+			MetadataURL:  provider.server.URL + "/metadata",
+			EmailAttr:    "email",
+			RoleAttr:     "role",
+			PayloadAttrs: []string{"department", "groups"},
+			Domains:      []string{"gmail.com"},
+		}},
+	}
+}
+
+func samlSession(attributes map[string][]string) *saml.Session {
+	session := &saml.Session{ID: "idp-session", NameID: "00u1abcd", CreateTime: time.Now(), ExpireTime: time.Now().Add(time.Hour)}
+
+	for name, values := range attributes {
+		attribute := saml.Attribute{Name: name}
+		for _, value := range values {
+			attribute.Values = append(attribute.Values, saml.AttributeValue{Type: "xs:string", Value: value})
+		}
+
+		session.CustomAttributes = append(session.CustomAttributes, attribute)
+	}
+
+	return session
+}
+
+func TestSAMLMetadata(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	provider := newTestIdentityProvider(t)
+
+	key, certificate := newTestCertificate(t)
+	signed := samlConfig(provider)
+	signed.SAMLKey = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	signed.SAMLCertificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw}))
+
+	tests := []struct {
+		name        string
+		config      utils.Config
+		provider    string
+		signed      bool
+		returnError error
+	}{
+		{name: "unsigned", config: samlConfig(provider), provider: "okta"},
+		{name: "with certificate", config: signed, provider: "okta", signed: true},
+		{name: "unknown provider", config: samlConfig(provider), provider: "adfs", returnError: ErrUnknownProvider},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			domain := NewDomain(NewMockRepository(t), tc.config, NewMockNotifier(t))
+
+			metadata, err := domain.SAMLMetadata(setup, tc.provider)
+
+			require.Equal(t, tc.returnError, err)
+			if tc.returnError != nil {
+				return
+			}
+
+			var descriptor saml.EntityDescriptor
+			require.NoError(t, xml.Unmarshal(metadata, &descriptor))
+			require.Equal(t, "https://example.com/saml/okta/metadata", descriptor.EntityID)
+			require.Equal(t, "https://example.com/saml/okta/acs", descriptor.SPSSODescriptors[0].AssertionConsumerServices[0].Location)
+			require.Equal(t, tc.signed, len(descriptor.SPSSODescriptors[0].KeyDescriptors) > 0)
+		})
+	}
+}
+
+func TestSAMLLogIn(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	provider := newTestIdentityProvider(t)
+
+	payload := map[string]any{"department": "engineering", "groups": []any{"admins", "developers"}}
+	user := User{ID: 452, Email: "djvukovic@gmail.com", Role: "admin", Verified: true, Payload: payload}
+	identity := Identity{UserID: user.ID, Provider: "okta", Subject: "00u1abcd", Email: user.Email}
+
+	attributes := map[string][]string{
+		"email":      {"djvukovic@gmail.com"},
+		"role":       {"admin"},
+		"department": {"engineering"},
+		"groups":     {"admins", "developers"},
+	}
+
+	type testCase struct {
+		name        string
+		autoLink    bool
+		anyDomain   bool
+		token       string
+		attributes  map[string][]string
+		tamper      func(string) string
+		setupModels func(*MockRepository, *MockRepositoryUser, *MockRepositoryIdentity, *MockRepositorySession)
+		returnUser  User
+		returnKey   string
+		returnError error
+	}
+
+	noSecondFactor := func(r *MockRepository) {
+		totpRepository := NewMockRepositoryTOTP(t)
+		passkeyRepository := NewMockRepositoryPasskey(t)
+
+		r.EXPECT().TOTP(context.TODO()).Return(totpRepository)
+		r.EXPECT().Passkey(context.TODO()).Return(passkeyRepository)
+		totpRepository.EXPECT().Get(user.ID).Return(TOTP{}, modelErrors.ErrNotFound)
+		passkeyRepository.EXPECT().GetByUser(user.ID).Return([]Passkey{}, nil)
+	}
+
+	tests := []testCase{
+		{
+			name:       "new user",
+			attributes: attributes,
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession) {
+				ri.EXPECT().Get("okta", "00u1abcd").Return(Identity{}, modelErrors.ErrNotFound)
+				ru.EXPECT().GetByEmail(user.Email).Return(User{}, modelErrors.ErrNotFound)
+				r.EXPECT().Atomic(mock.Anything).RunAndReturn(func(f func(Repository) error) error {
+					return f(r)
+				})
+				ru.EXPECT().Create(mock.MatchedBy(func(u User) bool {
+					return u.Email == user.Email && u.Role == "admin" && u.Verified && u.Password == "" &&
+						u.Payload["department"] == "engineering"
+				})).Return(user, nil)
+				ri.EXPECT().Create(identity).Return(identity, nil)
+				noSecondFactor(r)
+				rs.EXPECT().Create(user, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: user}, nil)
+			},
+			returnUser: user,
+			returnKey:  "session",
+		},
+		{
+			name:       "linked user gets attributes synced",
+			attributes: attributes,
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession) {
+				stale := User{ID: user.ID, Email: user.Email, Role: "developer", Verified: true, Payload: map[string]any{"department": "sales", "theme": "dark"}}
+				withRole := stale
+				withRole.Role = "admin"
+
+				synced := user
+				synced.Payload = map[string]any{"department": "engineering", "groups": []any{"admins", "developers"}, "theme": "dark"}
+
+				ri.EXPECT().Get("okta", "00u1abcd").Return(identity, nil)
+				ru.EXPECT().GetByID(user.ID).Return(stale, nil)
+				ru.EXPECT().SetRole(stale, "admin").Return(nil)
+				rs.EXPECT().UpdateUser(withRole).Return(nil)
+				ru.EXPECT().SetPayload(withRole, synced.Payload).Return(nil)
+				noSecondFactor(r)
+				rs.EXPECT().Create(synced, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: synced}, nil)
+			},
+			returnUser: User{ID: user.ID, Email: user.Email, Role: "admin", Verified: true, Payload: map[string]any{"department": "engineering", "groups": []any{"admins", "developers"}, "theme": "dark"}},
+			returnKey:  "session",
+		},
+		{
+			name:       "linked user keeps role provider didn't assert",
+			attributes: map[string][]string{"email": {"djvukovic@gmail.com"}, "department": {"engineering"}, "groups": {"admins", "developers"}},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession) {
+				stale := User{ID: user.ID, Email: user.Email, Role: "developer", Verified: true, Payload: map[string]any{"department": "sales"}}
+
+				synced := stale
+				synced.Payload = payload
+
+				ri.EXPECT().Get("okta", "00u1abcd").Return(identity, nil)
+				ru.EXPECT().GetByID(user.ID).Return(stale, nil)
+				ru.EXPECT().SetPayload(stale, payload).Return(nil)
+				noSecondFactor(r)
+				rs.EXPECT().Create(synced, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: synced}, nil)
+			},
+			returnUser: User{ID: user.ID, Email: user.Email, Role: "developer", Verified: true, Payload: payload},
+			returnKey:  "session",
+		},
+		{
+			name:       "email taken",
+			anyDomain:  true,
+			attributes: attributes,
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession) {
+				ri.EXPECT().Get("okta", "00u1abcd").Return(Identity{}, modelErrors.ErrNotFound)
+				ru.EXPECT().GetByEmail(user.Email).Return(user, nil)
+			},
+			returnError: ErrIdentityLinkRequired,
+		},
+		{
+			name:       "email in provider domains is linked",
+			attributes: attributes,
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession) {
+				ri.EXPECT().Get("okta", "00u1abcd").Return(Identity{}, modelErrors.ErrNotFound)
+				ru.EXPECT().GetByEmail(user.Email).Return(user, nil)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+				ri.EXPECT().Create(identity).Return(identity, nil)
+				noSecondFactor(r)
+				rs.EXPECT().Create(user, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: user}, nil)
+			},
+			returnUser: user,
+			returnKey:  "session",
+		},
+		{
+			name:       "email outside provider domains",
+			autoLink:   true,
+			attributes: map[string][]string{"email": {"ceo@othercompany.com"}, "role": {"admin"}},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession) {
+			},
+			returnError: ErrInvalidIdentity,
+		},
+		{
+			name:       "provider without domains doesn't verify emails",
+			autoLink:   true,
+			anyDomain:  true,
+			attributes: attributes,
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession) {
+				ri.EXPECT().Get("okta", "00u1abcd").Return(Identity{}, modelErrors.ErrNotFound)
+				ru.EXPECT().GetByEmail(user.Email).Return(user, nil)
+			},
+			returnError: ErrIdentityLinkRequired,
+		},
+		{
+			name:       "linking from signed in account",
+			token:      "current",
+			attributes: map[string][]string{"email": {"other@gmail.com"}, "role": {"admin"}, "department": {"engineering"}, "groups": {"admins", "developers"}},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession) {
+				rs.EXPECT().Get("current").Return(user, nil)
+				ri.EXPECT().Get("okta", "00u1abcd").Return(Identity{}, modelErrors.ErrNotFound)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+				ri.EXPECT().Create(Identity{UserID: user.ID, Provider: "okta", Subject: "00u1abcd", Email: "other@gmail.com"}).Return(identity, nil)
+				rs.EXPECT().Create(user, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: user}, nil)
+			},
+			returnUser: user,
+			returnKey:  "session",
+		},
+		{
+			name:       "tampered assertion",
+			attributes: attributes,
+			tamper: func(response string) string {
+				decoded, _ := base64.StdEncoding.DecodeString(response)
+				return base64.StdEncoding.EncodeToString(bytes.ReplaceAll(decoded, []byte("djvukovic@gmail.com"), []byte("attacker@gmail.com")))
+			},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession) {
+			},
+			returnError: ErrInvalidIdentity,
+		},
+		{
+			name:       "not base64 response",
+			attributes: attributes,
+			tamper: func(response string) string {
+				return "<Response/>"
+			},
+			setupModels: func(r *MockRepository, ru *MockRepositoryUser, ri *MockRepositoryIdentity, rs *MockRepositorySession) {
+			},
+			returnError: ErrInvalidIdentity,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			userRepository := NewMockRepositoryUser(t)
+			identityRepository := NewMockRepositoryIdentity(t)
+			sessionRepository := NewMockRepositorySession(t)
+			ceremonyRepository := NewMockRepositoryCeremony(t)
+
+			// Setup mocks
+			var stored []byte
+			ceremonyRepository.EXPECT().Create(mock.Anything).RunAndReturn(func(data []byte) (string, error) {
+				stored = data
+				return "state", nil
+			})
+			ceremonyRepository.EXPECT().Take("state").RunAndReturn(func(key string) ([]byte, error) {
+				return stored, nil
+			})
+
+			repository.EXPECT().Ceremony(context.TODO()).Return(ceremonyRepository)
+			repository.EXPECT().User(context.TODO()).Return(userRepository).Maybe()
+			repository.EXPECT().Identity(context.TODO()).Return(identityRepository).Maybe()
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository).Maybe()
+			tc.setupModels(repository, userRepository, identityRepository, sessionRepository)
+
+			config := samlConfig(provider)
+			config.SAMLProviders[0].AutoLink = tc.autoLink
+			if tc.anyDomain {
+				config.SAMLProviders[0].Domains = nil
+			}
+
+			// Run
+			domain := NewDomain(repository, config, NewMockNotifier(t))
+
+			metadata, err := domain.SAMLMetadata(setup, "okta")
+			require.NoError(t, err)
+
+			authURL, stateKey, err := domain.BeginSAMLLogIn(setup, "okta", tc.token)
+			require.NoError(t, err)
+
+			relayState, response := provider.respond(t, authURL, metadata, samlSession(tc.attributes))
+			require.Equal(t, "state", relayState)
+			require.Equal(t, stateKey, relayState)
+
+			if tc.tamper != nil {
+				response = tc.tamper(response)
+			}
+
+			existing, key, err := domain.FinishSAMLLogIn(setup, "okta", relayState, response)
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorIs(t, err, tc.returnError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.returnUser, existing)
+			require.Equal(t, tc.returnKey, key)
+		})
+	}
+}
+
+func TestSAMLResponseToOtherRequest(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	provider := newTestIdentityProvider(t)
+
+	// Create mocks
+	repository := NewMockRepository(t)
+	ceremonyRepository := NewMockRepositoryCeremony(t)
+
+	// Setup mocks
+	repository.EXPECT().Ceremony(context.TODO()).Return(ceremonyRepository)
+	ceremonyRepository.EXPECT().Create(mock.Anything).Return("state", nil)
+	ceremonyRepository.EXPECT().Take("state").Return([]byte(`{"provider": "okta", "request_id": "id-other"}`), nil)
+
+	// Run
+	domain := NewDomain(repository, samlConfig(provider), NewMockNotifier(t))
+
+	metadata, err := domain.SAMLMetadata(setup, "okta")
+	require.NoError(t, err)
+
+	authURL, _, err := domain.BeginSAMLLogIn(setup, "okta", "")
+	require.NoError(t, err)
+
+	relayState, response := provider.respond(t, authURL, metadata, samlSession(map[string][]string{"email": {"djvukovic@gmail.com"}}))
+	_, _, err = domain.FinishSAMLLogIn(setup, "okta", relayState, response)
+
+	// Assertions
+	require.ErrorIs(t, err, ErrInvalidIdentity)
+}
+
+func TestSAMLIdentityProviderMetadata(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	provider := newTestIdentityProvider(t)
+
+	expired := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		xml.NewEncoder(w).Encode(saml.EntityDescriptor{EntityID: "expired", ValidUntil: time.Now().Add(-time.Hour)})
+	}))
+	t.Cleanup(expired.Close)
+
+	config := samlConfig(provider)
+	config.SAMLProviders = append(config.SAMLProviders, utils.SAMLProvider{Name: "expired", MetadataURL: expired.URL})
+
+	d := NewDomain(NewMockRepository(t), config, NewMockNotifier(t)).(*domain)
+
+	// Run
+	for i := 0; i < 3; i++ {
+		metadata, err := d.samlIdentityProvider(setup, config.SAMLProviders[0])
+		require.NoError(t, err)
+		require.Equal(t, provider.idp.MetadataURL.String(), metadata.EntityID)
+	}
+
+	_, err := d.samlIdentityProvider(setup, config.SAMLProviders[1])
+
+	// Assertions
+	require.Equal(t, int32(1), provider.fetches.Load())
+	require.ErrorContains(t, err, "metadata of expired expired")
+}
+
+func TestSAMLPayload(t *testing.T) {
+	t.Parallel()
+
+	assertion := &saml.Assertion{AttributeStatements: []saml.AttributeStatement{{Attributes: []saml.Attribute{
+		{Name: "urn:oid:0.9.2342.19200300.100.1.3", FriendlyName: "mail", Values: []saml.AttributeValue{{Value: "djvukovic@gmail.com"}}},
+		{Name: "groups", Values: []saml.AttributeValue{{Value: "admins"}, {Value: "developers"}}},
+	}}}}
+
+	attributes := samlAttributes(assertion)
+
+	require.Equal(t, "djvukovic@gmail.com", samlAttribute(attributes, "mail"))
+	require.Equal(t, "djvukovic@gmail.com", samlAttribute(attributes, "urn:oid:0.9.2342.19200300.100.1.3"))
+	require.Equal(t, "", samlAttribute(attributes, "role"))
+	require.Equal(t,
+		map[string]any{"mail": "djvukovic@gmail.com", "groups": []any{"admins", "developers"}},
+		samlPayload(attributes, []string{"mail", "groups", "department"}),
+	)
+}
@@ -0,0 +1,154 @@
+package domain
+
+import "fmt"
+
+// sessionIDLength is length of public session id. Session keys are bearer
+// credentials so other sessions are referred to by their hash.
+const sessionIDLength = 32
+
+func sessionID(key string) string {
+	return hashToken(key)[:sessionIDLength]
+}
+
+// Sessions lists active sessions of the signed in user
+func (d *domain) Sessions(setup Setup, token string) (sessions []Session, err error) {
+	user, err := d.loginSession(setup, token)
+	if err != nil {
+		return
+	}
+
+	sessions, err = d.db.Session(setup.ctx).GetByUser(user.ID)
+	if err != nil {
+		err = fmt.Errorf("domain Sessions -> unable to get sessions of user %d %w", user.ID, err)
+		return
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == token
+		sessions[i].ID = sessionID(sessions[i].ID)
+	}
+
+	return
+}
+
+// RevokeSession ends one of the sessions of signed in user
+func (d *domain) RevokeSession(setup Setup, token string, id string) (err error) {
+	user, err := d.loginSession(setup, token)
+	if err != nil {
+		return
+	}
+
+	keys, err := d.userSessionKeys(setup, user)
+	if err != nil {
+		err = fmt.Errorf("domain RevokeSession -> %w", err)
+		return
+	}
+
+	for _, key := range keys {
+		if sessionID(key) != id {
+			continue
+		}
+
+		err = d.db.Session(setup.ctx).Delete(key)
+		if err != nil {
+			err = fmt.Errorf("domain RevokeSession -> unable to delete session %s %w", id, err)
+		}
+
+		return
+	}
+
+	err = ErrSessionNotFound
+
+	return
+}
+
+// RevokeOtherSessions ends all sessions of signed in user except the one making the request
+func (d *domain) RevokeOtherSessions(setup Setup, token string) (revoked int, err error) {
+	user, err := d.loginSession(setup, token)
+	if err != nil {
+		return
+	}
+
+	revoked, err = d.revokeSessions(setup, user.ID, token)
+	if err != nil {
+		err = fmt.Errorf("domain RevokeOtherSessions -> %w", err)
+	}
+
+	return
+}
+
+// revokeSessions ends all sessions of the user except the kept one. It has to be called whenever
+// credentials change so sessions started by someone who knew the old ones don't outlive them.
+func (d *domain) revokeSessions(setup Setup, userId uint64, keep string) (revoked int, err error) {
+	keys, err := d.userSessionKeys(setup, User{ID: userId})
+	if err != nil {
+		return
+	}
+
+	for _, key := range keys {
+		if key == keep {
+			continue
+		}
+
+		err = d.db.Session(setup.ctx).Delete(key)
+		if err != nil {
+			err = fmt.Errorf("unable to delete session of user %d %w", userId, err)
+			return
+		}
+
+		revoked++
+	}
+
+	return
+}
+
+// revokeCredentials revokes credentials user handed out to clients which outlive sessions,
+// refresh tokens and api keys
+func revokeCredentials(setup Setup, repo Repository, userId uint64) error {
+	err := repo.RefreshToken(setup.ctx).RevokeUser(userId)
+	if err != nil {
+		return fmt.Errorf("unable to revoke refresh tokens of user %d %w", userId, err)
+	}
+
+	err = repo.APIKey(setup.ctx).DeleteByUser(userId)
+	if err != nil {
+		return fmt.Errorf("unable to revoke api keys of user %d %w", userId, err)
+	}
+
+	return nil
+}
+
+// refreshSessions copies changed user into all of user's sessions so they don't serve stale data
+// until next login. Change is already stored so failing to refresh is only logged.
+func (d *domain) refreshSessions(setup Setup, user User) {
+	err := d.db.Session(setup.ctx).UpdateUser(user)
+	if err != nil {
+		setup.logger.Warn("unable to refresh sessions", "user", user.ID, "error", err.Error())
+	}
+}
+
+// refreshSessionsOf reloads the user before refreshing sessions when only its id is known
+func (d *domain) refreshSessionsOf(setup Setup, userId uint64) {
+	user, err := d.db.User(setup.ctx).GetByID(userId)
+	if err != nil {
+		setup.logger.Warn("unable to refresh sessions", "user", userId, "error", err.Error())
+		return
+	}
+
+	d.refreshSessions(setup, user)
+}
+
+func (d *domain) userSessionKeys(setup Setup, user User) (keys []string, err error) {
+	sessions, err := d.db.Session(setup.ctx).GetByUser(user.ID)
+	if err != nil {
+		err = fmt.Errorf("unable to get sessions of user %d %w", user.ID, err)
+		return
+	}
+
+	keys = make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		keys = append(keys, session.ID)
+	}
+
+	return
+}
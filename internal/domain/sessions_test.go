@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessions(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com"}
+	createdAt := time.Date(2023, 11, 22, 10, 0, 0, 0, time.UTC)
+
+	// Create mocks
+	repository := NewMockRepository(t)
+	sessionRepository := NewMockRepositorySession(t)
+
+	// Setup mocks
+	repository.EXPECT().Session(context.TODO()).Return(sessionRepository)
+	sessionRepository.EXPECT().Get("current").Return(user, nil)
+	sessionRepository.EXPECT().GetByUser(user.ID).Return([]Session{
+		{ID: "current", User: user, CreatedAt: createdAt, IP: "10.0.0.1", UserAgent: "curl/8.0"},
+		{ID: "other", User: user, CreatedAt: createdAt, IP: "10.0.0.2", UserAgent: "Firefox"},
+	}, nil)
+
+	// Run
+	domain := NewDomain(repository, utils.Config{}, NewMockNotifier(t))
+	sessions, err := domain.Sessions(setup, "current")
+
+	// Assertions
+	require.NoError(t, err)
+	require.Equal(t, []Session{
+		{ID: sessionID("current"), User: user, CreatedAt: createdAt, IP: "10.0.0.1", UserAgent: "curl/8.0", Current: true},
+		{ID: sessionID("other"), User: user, CreatedAt: createdAt, IP: "10.0.0.2", UserAgent: "Firefox"},
+	}, sessions)
+	require.Len(t, sessions[1].ID, sessionIDLength)
+	require.NotContains(t, sessions[1].ID, "other")
+}
+
+func TestSessionsWithAPIKey(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+
+	domain := NewDomain(NewMockRepository(t), utils.Config{}, NewMockNotifier(t))
+	_, err := domain.Sessions(setup, apiKeyPrefix+"secret")
+
+	require.Equal(t, ErrNoSession, err)
+}
+
+func TestRevokeSession(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com"}
+
+	tests := []struct {
+		name        string
+		id          string
+		deleteKey   string
+		deleteError error
+		returnError error
+	}{
+		{name: "other session", id: sessionID("other"), deleteKey: "other"},
+		{name: "current session", id: sessionID("current"), deleteKey: "current"},
+		{name: "session of another user", id: sessionID("foreign"), returnError: ErrSessionNotFound},
+		{name: "raw key is not an id", id: "other", returnError: ErrSessionNotFound},
+		{
+			name:        "delete fails",
+			id:          sessionID("other"),
+			deleteKey:   "other",
+			deleteError: errors.New("redis down"),
+			returnError: errors.New("domain RevokeSession -> unable to delete session"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			sessionRepository := NewMockRepositorySession(t)
+
+			// Setup mocks
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository)
+			sessionRepository.EXPECT().Get("current").Return(user, nil)
+			sessionRepository.EXPECT().GetByUser(user.ID).Return([]Session{{ID: "current", User: user}, {ID: "other", User: user}}, nil)
+
+			if tc.deleteKey != "" {
+				sessionRepository.EXPECT().Delete(tc.deleteKey).Return(tc.deleteError)
+			}
+
+			// Run
+			domain := NewDomain(repository, utils.Config{}, NewMockNotifier(t))
+			err := domain.RevokeSession(setup, "current", tc.id)
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorContains(t, err, tc.returnError.Error())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com"}
+
+	// Create mocks
+	repository := NewMockRepository(t)
+	sessionRepository := NewMockRepositorySession(t)
+
+	// Setup mocks
+	repository.EXPECT().Session(context.TODO()).Return(sessionRepository)
+	sessionRepository.EXPECT().Get("current").Return(user, nil)
+	sessionRepository.EXPECT().GetByUser(user.ID).Return([]Session{
+		{ID: "laptop", User: user},
+		{ID: "current", User: user},
+		{ID: "phone", User: user},
+	}, nil)
+	sessionRepository.EXPECT().Delete("laptop").Return(nil)
+	sessionRepository.EXPECT().Delete("phone").Return(nil)
+
+	// Run
+	domain := NewDomain(repository, utils.Config{}, NewMockNotifier(t))
+	revoked, err := domain.RevokeOtherSessions(setup, "current")
+
+	// Assertions
+	require.NoError(t, err)
+	require.Equal(t, 2, revoked)
+}
@@ -0,0 +1,626 @@
+package domain
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+const signingKeyBits = 2048
+
+// signingKeyRefresh is how often keys are reloaded so rotation done by another
+// instance or CLI is picked up. New key is used for signing only after this
+// long so every instance publishes it before tokens signed with it show up.
+const signingKeyRefresh = time.Minute
+
+var signingAlgorithms = []jose.SignatureAlgorithm{jose.RS256, jose.ES256, jose.EdDSA}
+
+type signingKey struct {
+	key       jose.JSONWebKey
+	createdAt time.Time
+}
+
+// signingKeyFile is PEM file in the key directory. Key is replaced once a newer file
+// is written and stays published for overlap window after the newer one is used.
+type signingKeyFile struct {
+	path       string
+	createdAt  time.Time
+	replacedAt time.Time
+}
+
+// signer caches keys used to sign issued tokens, newest first. Keys come from
+// configuration, key directory or database in that order. Without any of them
+// an ephemeral key is generated so tokens don't survive restarts.
+type signer struct {
+	mu       sync.Mutex
+	keys     []signingKey
+	loadedAt time.Time
+	static   bool
+}
+
+func (d *domain) signingKey(setup Setup) (key jose.JSONWebKey, err error) {
+	keys, err := d.loadSigningKeys(setup)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	for _, k := range keys {
+		if now.Sub(k.createdAt) >= signingKeyRefresh {
+			return k.key, nil
+		}
+	}
+
+	return keys[0].key, nil
+}
+
+// publicKeys returns all published keys including replaced ones still in overlap window
+func (d *domain) publicKeys(setup Setup) (set jose.JSONWebKeySet, err error) {
+	keys, err := d.loadSigningKeys(setup)
+	if err != nil {
+		return
+	}
+
+	for _, k := range keys {
+		set.Keys = append(set.Keys, k.key.Public())
+	}
+
+	return
+}
+
+func (d *domain) tokenSigner(setup Setup, typ string) (signer jose.Signer, err error) {
+	key, err := d.signingKey(setup)
+	if err != nil {
+		return
+	}
+
+	signer, err = jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.SignatureAlgorithm(key.Algorithm), Key: key},
+		(&jose.SignerOptions{}).WithType(jose.ContentType(typ)),
+	)
+	if err != nil {
+		err = fmt.Errorf("unable to create signer %w", err)
+	}
+
+	return
+}
+
+func (d *domain) loadSigningKeys(setup Setup) (keys []signingKey, err error) {
+	d.signer.mu.Lock()
+	defer d.signer.mu.Unlock()
+
+	if len(d.signer.keys) > 0 && (d.signer.static || time.Since(d.signer.loadedAt) < signingKeyRefresh) {
+		return d.signer.keys, nil
+	}
+
+	config := d.config.SigningKeys
+	static := false
+
+	switch {
+	case config.Key != "":
+		keys, err = configSigningKey(config.Key)
+		static = true
+	case config.Dir != "":
+		keys, err = d.signingKeyDir(setup)
+	case config.Secret != "":
+		keys, err = d.storedSigningKeys(setup)
+	default:
+		setup.logger.Warn("signing key is not configured, using ephemeral signing key")
+		keys, err = ephemeralSigningKey(config.Algorithm)
+		static = true
+	}
+
+	if err != nil {
+		err = fmt.Errorf("unable to load signing keys %w", err)
+		return
+	}
+
+	if len(keys) == 0 {
+		err = errors.New("no signing keys available")
+		return
+	}
+
+	d.signer.keys = keys
+	d.signer.loadedAt = time.Now()
+	d.signer.static = static
+
+	return
+}
+
+// storedSigningKeys loads keys from the database and rotates them when the
+// active one gets older than configured rotation period
+func (d *domain) storedSigningKeys(setup Setup) (keys []signingKey, err error) {
+	config := d.config.SigningKeys
+
+	stored, err := d.db.SigningKey(setup.ctx).GetPublished(time.Now().Add(-config.Overlap))
+	if err != nil {
+		return
+	}
+
+	if len(stored) == 0 || rotationDue(stored[0].CreatedAt, config.Rotation) {
+		if _, err = d.rotateStoredSigningKey(setup, true); err != nil {
+			return
+		}
+
+		stored, err = d.db.SigningKey(setup.ctx).GetPublished(time.Now().Add(-config.Overlap))
+		if err != nil {
+			return
+		}
+	}
+
+	for _, s := range stored {
+		der, e := decryptSigningKey(config.Secret, s.PrivateKey)
+		if e != nil {
+			err = fmt.Errorf("unable to decrypt key %s %w", s.ID, e)
+			return
+		}
+
+		private, e := parsePrivateKey(der)
+		if e != nil {
+			err = fmt.Errorf("invalid key %s %w", s.ID, e)
+			return
+		}
+
+		key, e := newSigningKey(private, s.CreatedAt)
+		if e != nil {
+			err = e
+			return
+		}
+
+		keys = append(keys, key)
+	}
+
+	return
+}
+
+// signingKeyDir reads keys from the key directory and rotates them when the
+// newest one gets older than configured rotation period
+func (d *domain) signingKeyDir(setup Setup) (keys []signingKey, err error) {
+	config := d.config.SigningKeys
+
+	keys, err = readSigningKeyDir(config.Dir, config.Overlap)
+	if err != nil || len(keys) == 0 || !rotationDue(keys[0].createdAt, config.Rotation) {
+		return
+	}
+
+	// every instance names the key after the moment rotation became due so only
+	// the first one of them writes it
+	kid, err := writeSigningKey(config.Dir, config.Algorithm, keys[0].createdAt.Add(config.Rotation))
+	if err == nil {
+		err = pruneSigningKeyDir(config.Dir, config.Overlap)
+	}
+
+	if err != nil {
+		// directory can be read only when keys are rotated outside of the app
+		setup.logger.Warn("unable to rotate signing key", "error", err)
+		return keys, nil
+	}
+
+	if kid != "" {
+		setup.logger.Info("signing key rotated", "kid", kid)
+	}
+
+	return readSigningKeyDir(config.Dir, config.Overlap)
+}
+
+func rotationDue(createdAt time.Time, rotation time.Duration) bool {
+	return rotation > 0 && time.Since(createdAt) >= rotation
+}
+
+// RotateSigningKey creates a new signing key. Replaced keys stay published until
+// overlap window passes so tokens they signed can still be verified.
+func (d *domain) RotateSigningKey(setup Setup) (kid string, err error) {
+	config := d.config.SigningKeys
+
+	switch {
+	case config.Key != "":
+		err = ErrKeyRotationUnavailable
+	case config.Dir != "":
+		kid, err = writeSigningKey(config.Dir, config.Algorithm, time.Now())
+		if err == nil {
+			err = pruneSigningKeyDir(config.Dir, config.Overlap)
+		}
+	case config.Secret != "":
+		kid, err = d.rotateStoredSigningKey(setup, false)
+	default:
+		err = ErrKeyRotationUnavailable
+	}
+
+	if err != nil {
+		if err != ErrKeyRotationUnavailable {
+			err = fmt.Errorf("domain RotateSigningKey -> %w", err)
+		}
+
+		return
+	}
+
+	d.signer.mu.Lock()
+	d.signer.loadedAt = time.Time{}
+	d.signer.mu.Unlock()
+
+	return
+}
+
+// rotateStoredSigningKey stores a new key while holding rotation lock. Scheduled
+// rotation is skipped when another instance has already rotated the key.
+func (d *domain) rotateStoredSigningKey(setup Setup, scheduled bool) (kid string, err error) {
+	config := d.config.SigningKeys
+
+	private, err := generatePrivateKey(config.Algorithm)
+	if err != nil {
+		return
+	}
+
+	key, err := newSigningKey(private, time.Now())
+	if err != nil {
+		return
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		err = fmt.Errorf("unable to serialize key %w", err)
+		return
+	}
+
+	encrypted, err := encryptSigningKey(config.Secret, der)
+	if err != nil {
+		return
+	}
+
+	err = d.db.Atomic(func(txRepo Repository) error {
+		repo := txRepo.SigningKey(setup.ctx)
+
+		if e := repo.Lock(); e != nil {
+			return e
+		}
+
+		now := time.Now()
+
+		if scheduled {
+			stored, e := repo.GetPublished(now.Add(-config.Overlap))
+			if e != nil {
+				return e
+			}
+
+			if len(stored) > 0 && !rotationDue(stored[0].CreatedAt, config.Rotation) {
+				return nil
+			}
+		}
+
+		if e := repo.Retire(now); e != nil {
+			return e
+		}
+
+		if e := repo.DeleteRetired(now.Add(-config.Overlap)); e != nil {
+			return e
+		}
+
+		if _, e := repo.Create(SigningKey{ID: key.key.KeyID, Algorithm: key.key.Algorithm, PrivateKey: encrypted}); e != nil {
+			return e
+		}
+
+		kid = key.key.KeyID
+		return nil
+	})
+
+	if err != nil {
+		kid = ""
+		err = fmt.Errorf("unable to store signing key %w", err)
+		return
+	}
+
+	if kid == "" {
+		return
+	}
+
+	setup.logger.Info("signing key rotated", "kid", kid)
+
+	return
+}
+
+func configSigningKey(data string) (keys []signingKey, err error) {
+	private, err := parsePEMKey([]byte(data))
+	if err != nil {
+		return
+	}
+
+	key, err := newSigningKey(private, time.Time{})
+	if err != nil {
+		return
+	}
+
+	return []signingKey{key}, nil
+}
+
+func ephemeralSigningKey(algorithm string) (keys []signingKey, err error) {
+	private, err := generatePrivateKey(algorithm)
+	if err != nil {
+		return
+	}
+
+	key, err := newSigningKey(private, time.Time{})
+	if err != nil {
+		return
+	}
+
+	return []signingKey{key}, nil
+}
+
+// listSigningKeyDir returns PEM files sorted by name from the newest one
+func listSigningKeyDir(dir string) (files []signingKeyFile, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	var replacedAt time.Time
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".pem") {
+			continue
+		}
+
+		info, e := entry.Info()
+		if e != nil {
+			err = e
+			return
+		}
+
+		files = append(files, signingKeyFile{
+			path:       filepath.Join(dir, entry.Name()),
+			createdAt:  info.ModTime(),
+			replacedAt: replacedAt,
+		})
+
+		// newer key is used for signing only after it's loaded by every instance
+		replacedAt = info.ModTime().Add(signingKeyRefresh)
+	}
+
+	return
+}
+
+// retired tells if key was replaced long enough ago that tokens it signed are no longer valid
+func (f signingKeyFile) retired(overlap time.Duration) bool {
+	return !f.replacedAt.IsZero() && time.Since(f.replacedAt) >= overlap
+}
+
+// readSigningKeyDir reads keys that are still published, the newest one first
+func readSigningKeyDir(dir string, overlap time.Duration) (keys []signingKey, err error) {
+	files, err := listSigningKeyDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, file := range files {
+		if file.retired(overlap) {
+			continue
+		}
+
+		data, e := os.ReadFile(file.path)
+		if e != nil {
+			err = e
+			return
+		}
+
+		private, e := parsePEMKey(data)
+		if e != nil {
+			err = fmt.Errorf("invalid key %s %w", filepath.Base(file.path), e)
+			return
+		}
+
+		key, e := newSigningKey(private, file.createdAt)
+		if e != nil {
+			err = e
+			return
+		}
+
+		keys = append(keys, key)
+	}
+
+	return
+}
+
+// pruneSigningKeyDir removes keys retired longer than overlap window ago
+func pruneSigningKeyDir(dir string, overlap time.Duration) error {
+	files, err := listSigningKeyDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if !file.retired(overlap) {
+			continue
+		}
+
+		// another instance sharing the directory may have removed it already
+		if err = os.Remove(file.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("unable to remove retired key %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeSigningKey writes a new key named after given time. Key is linked into
+// place so it's never read half written and nothing is written if the file
+// already exists, in which case kid is empty.
+func writeSigningKey(dir string, algorithm string, at time.Time) (kid string, err error) {
+	private, err := generatePrivateKey(algorithm)
+	if err != nil {
+		return
+	}
+
+	key, err := newSigningKey(private, time.Now())
+	if err != nil {
+		return
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		err = fmt.Errorf("unable to serialize key %w", err)
+		return
+	}
+
+	temp, err := os.CreateTemp(dir, ".key-*")
+	if err != nil {
+		err = fmt.Errorf("unable to write key %w", err)
+		return
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = temp.Write(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		err = fmt.Errorf("unable to write key %w", err)
+		return
+	}
+
+	name := filepath.Join(dir, at.UTC().Format("20060102150405.000000000")+".pem")
+	err = os.Link(temp.Name(), name)
+	if errors.Is(err, fs.ErrExist) {
+		return "", nil
+	}
+
+	if err != nil {
+		err = fmt.Errorf("unable to write key %w", err)
+		return
+	}
+
+	kid = key.key.KeyID
+	return
+}
+
+func newSigningKey(private crypto.PrivateKey, createdAt time.Time) (key signingKey, err error) {
+	algorithm, err := keyAlgorithm(private)
+	if err != nil {
+		return
+	}
+
+	key.createdAt = createdAt
+	key.key = jose.JSONWebKey{Key: private, Algorithm: algorithm, Use: "sig"}
+
+	public := key.key.Public()
+	thumbprint, err := public.Thumbprint(crypto.SHA256)
+	if err != nil {
+		err = fmt.Errorf("unable to compute key id %w", err)
+		return
+	}
+
+	key.key.KeyID = base64.RawURLEncoding.EncodeToString(thumbprint)
+
+	return
+}
+
+func generatePrivateKey(algorithm string) (crypto.PrivateKey, error) {
+	switch jose.SignatureAlgorithm(algorithm) {
+	case jose.RS256, "":
+		return rsa.GenerateKey(rand.Reader, signingKeyBits)
+	case jose.ES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case jose.EdDSA:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		return private, err
+	}
+
+	return nil, ErrUnsupportedAlgorithm
+}
+
+func keyAlgorithm(private crypto.PrivateKey) (string, error) {
+	switch key := private.(type) {
+	case *rsa.PrivateKey:
+		return string(jose.RS256), nil
+	case *ecdsa.PrivateKey:
+		if key.Curve == elliptic.P256() {
+			return string(jose.ES256), nil
+		}
+	case ed25519.PrivateKey:
+		return string(jose.EdDSA), nil
+	}
+
+	return "", ErrUnsupportedAlgorithm
+}
+
+func parsePEMKey(data []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("key is not PEM encoded")
+	}
+
+	return parsePrivateKey(block.Bytes)
+}
+
+func parsePrivateKey(der []byte) (crypto.PrivateKey, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	return x509.ParseECPrivateKey(der)
+}
+
+// encryptSigningKey seals the key with AES-GCM using key derived from the secret
+func encryptSigningKey(secret string, plain []byte) ([]byte, error) {
+	aead, err := signingKeyCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("unable to generate nonce %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+func decryptSigningKey(secret string, sealed []byte) ([]byte, error) {
+	aead, err := signingKeyCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("encrypted key is too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func signingKeyCipher(secret string) (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(secret))
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
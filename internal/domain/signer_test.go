@@ -0,0 +1,326 @@
+package domain
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func verifyWithJWKS(t *testing.T, d Domain, setup Setup, token string) jose.Header {
+	keys, err := d.JWKS(setup)
+	require.NoError(t, err)
+
+	var set jose.JSONWebKeySet
+	require.NoError(t, json.Unmarshal(keys, &set))
+
+	parsed, err := jwt.ParseSigned(token, signingAlgorithms)
+	require.NoError(t, err)
+
+	var claims jwt.Claims
+	require.NoError(t, parsed.Claims(set, &claims))
+
+	return parsed.Headers[0]
+}
+
+func TestSigningKeyAlgorithms(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com"}
+
+	for _, algorithm := range []string{"RS256", "ES256", "EdDSA"} {
+		t.Run(algorithm, func(t *testing.T) {
+			config := utils.Config{SigningKeys: utils.SigningKeys{Algorithm: algorithm}}
+			d := NewDomain(NewMockRepository(t), config, NewMockNotifier(t)).(*domain)
+
+			token, err := d.signAccessToken(setup, user)
+			require.NoError(t, err)
+
+			header := verifyWithJWKS(t, d, setup, token)
+			require.Equal(t, algorithm, header.Algorithm)
+		})
+	}
+
+	config := utils.Config{SigningKeys: utils.SigningKeys{Algorithm: "HS256"}}
+	d := NewDomain(NewMockRepository(t), config, NewMockNotifier(t)).(*domain)
+
+	_, err := d.signAccessToken(setup, user)
+	require.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+}
+
+func TestConfigSigningKey(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+
+	private, err := generatePrivateKey("ES256")
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+
+	config := utils.Config{SigningKeys: utils.SigningKeys{
+		Key: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	}}
+	d := NewDomain(NewMockRepository(t), config, NewMockNotifier(t)).(*domain)
+
+	token, err := d.signAccessToken(setup, User{ID: 452})
+	require.NoError(t, err)
+
+	header := verifyWithJWKS(t, d, setup, token)
+	require.Equal(t, "ES256", header.Algorithm)
+
+	_, err = d.RotateSigningKey(setup)
+	require.Equal(t, ErrKeyRotationUnavailable, err)
+}
+
+func TestSigningKeyDir(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	dir := t.TempDir()
+
+	config := utils.Config{SigningKeys: utils.SigningKeys{Dir: dir, Algorithm: "EdDSA"}}
+	d := NewDomain(NewMockRepository(t), config, NewMockNotifier(t)).(*domain)
+
+	first, err := d.RotateSigningKey(setup)
+	require.NoError(t, err)
+
+	// key written by another instance long time ago is the one used for signing
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.NoError(t, os.Chtimes(files[0], time.Now().Add(-time.Hour), time.Now().Add(-time.Hour)))
+
+	second, err := d.RotateSigningKey(setup)
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+
+	token, err := d.signAccessToken(setup, User{ID: 452})
+	require.NoError(t, err)
+
+	// new key is published right away but used only after other instances load it
+	header := verifyWithJWKS(t, d, setup, token)
+	require.Equal(t, first, header.KeyID)
+
+	set, err := d.publicKeys(setup)
+	require.NoError(t, err)
+	require.Len(t, set.Keys, 2)
+	require.Equal(t, second, set.Keys[0].KeyID)
+	require.Equal(t, first, set.Keys[1].KeyID)
+}
+
+func TestSigningKeyDirRetention(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	dir := t.TempDir()
+
+	config := utils.Config{SigningKeys: utils.SigningKeys{Dir: dir, Algorithm: "EdDSA", Overlap: time.Hour}}
+	d := NewDomain(NewMockRepository(t), config, NewMockNotifier(t)).(*domain)
+
+	// oldest key was replaced two hours ago, the one replacing it just now
+	oldest, err := writeSigningKey(dir, "EdDSA", time.Now())
+	require.NoError(t, err)
+	replaced, err := writeSigningKey(dir, "EdDSA", time.Now())
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.NoError(t, os.Chtimes(files[0], time.Now().Add(-3*time.Hour), time.Now().Add(-3*time.Hour)))
+	require.NoError(t, os.Chtimes(files[1], time.Now().Add(-2*time.Hour), time.Now().Add(-2*time.Hour)))
+
+	newest, err := d.RotateSigningKey(setup)
+	require.NoError(t, err)
+
+	set, err := d.publicKeys(setup)
+	require.NoError(t, err)
+	require.Len(t, set.Keys, 2)
+	require.Equal(t, newest, set.Keys[0].KeyID)
+	require.Equal(t, replaced, set.Keys[1].KeyID)
+
+	for _, key := range set.Keys {
+		require.NotEqual(t, oldest, key.KeyID)
+	}
+
+	// retired key is removed on rotation
+	files, err = filepath.Glob(filepath.Join(dir, "*.pem"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+}
+
+func TestSigningKeyDirRotation(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	dir := t.TempDir()
+
+	config := utils.Config{SigningKeys: utils.SigningKeys{Dir: dir, Algorithm: "EdDSA", Rotation: time.Hour, Overlap: time.Hour}}
+
+	expired, err := writeSigningKey(dir, "EdDSA", time.Now().Add(-2*time.Hour))
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	require.NoError(t, err)
+	require.NoError(t, os.Chtimes(files[0], time.Now().Add(-2*time.Hour), time.Now().Add(-2*time.Hour)))
+
+	// instances sharing the directory rotate expired key only once
+	first := NewDomain(NewMockRepository(t), config, NewMockNotifier(t)).(*domain)
+	second := NewDomain(NewMockRepository(t), config, NewMockNotifier(t)).(*domain)
+
+	firstSet, err := first.publicKeys(setup)
+	require.NoError(t, err)
+	secondSet, err := second.publicKeys(setup)
+	require.NoError(t, err)
+
+	files, err = filepath.Glob(filepath.Join(dir, "*.pem"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	require.Len(t, firstSet.Keys, 2)
+	require.Equal(t, expired, firstSet.Keys[1].KeyID)
+	require.Equal(t, firstSet.Keys[0].KeyID, secondSet.Keys[0].KeyID)
+	require.NotEqual(t, expired, firstSet.Keys[0].KeyID)
+}
+
+func TestStoredSigningKeys(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	config := utils.Config{SigningKeys: utils.SigningKeys{
+		Secret:    "secret",
+		Algorithm: "ES256",
+		Rotation:  time.Hour,
+		Overlap:   time.Hour,
+	}}
+
+	type testCase struct {
+		name        string
+		stored      func(*testing.T) []SigningKey
+		locked      func(*testing.T, []SigningKey) []SigningKey
+		rotates     bool
+		publishedNo int
+	}
+
+	storedKey := func(t *testing.T, createdAt time.Time) SigningKey {
+		private, err := generatePrivateKey("ES256")
+		require.NoError(t, err)
+
+		key, err := newSigningKey(private, createdAt)
+		require.NoError(t, err)
+
+		der, err := x509.MarshalPKCS8PrivateKey(private)
+		require.NoError(t, err)
+
+		encrypted, err := encryptSigningKey("secret", der)
+		require.NoError(t, err)
+
+		return SigningKey{ID: key.key.KeyID, Algorithm: "ES256", PrivateKey: encrypted, CreatedAt: createdAt}
+	}
+
+	tests := []testCase{
+		{
+			name:        "creates the first key",
+			stored:      func(t *testing.T) []SigningKey { return []SigningKey{} },
+			rotates:     true,
+			publishedNo: 1,
+		},
+		{
+			name: "uses active key",
+			stored: func(t *testing.T) []SigningKey {
+				return []SigningKey{storedKey(t, time.Now().Add(-time.Minute*10))}
+			},
+			publishedNo: 1,
+		},
+		{
+			name: "rotates expired key",
+			stored: func(t *testing.T) []SigningKey {
+				return []SigningKey{storedKey(t, time.Now().Add(-time.Hour*2))}
+			},
+			rotates:     true,
+			publishedNo: 2,
+		},
+		{
+			name: "uses key rotated by another instance",
+			stored: func(t *testing.T) []SigningKey {
+				return []SigningKey{storedKey(t, time.Now().Add(-time.Hour*2))}
+			},
+			locked: func(t *testing.T, stored []SigningKey) []SigningKey {
+				return append([]SigningKey{storedKey(t, time.Now())}, stored...)
+			},
+			publishedNo: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			keyRepository := NewMockRepositorySigningKey(t)
+
+			// Setup mocks
+			stored := tc.stored(t)
+			repository.EXPECT().SigningKey(context.TODO()).Return(keyRepository)
+			keyRepository.EXPECT().GetPublished(mock.Anything).Return(stored, nil).Once()
+
+			if tc.locked != nil {
+				stored = tc.locked(t, stored)
+			}
+
+			repository.EXPECT().Atomic(mock.Anything).RunAndReturn(func(f func(Repository) error) error {
+				return f(repository)
+			}).Maybe()
+			keyRepository.EXPECT().Lock().Return(nil).Maybe()
+			keyRepository.EXPECT().GetPublished(mock.Anything).RunAndReturn(func(time.Time) ([]SigningKey, error) {
+				return stored, nil
+			}).Maybe()
+
+			if tc.rotates {
+				keyRepository.EXPECT().Retire(mock.Anything).Return(nil)
+				keyRepository.EXPECT().DeleteRetired(mock.Anything).Return(nil)
+				keyRepository.EXPECT().Create(mock.Anything).RunAndReturn(func(key SigningKey) (SigningKey, error) {
+					key.CreatedAt = time.Now()
+					stored = append([]SigningKey{key}, stored...)
+					return key, nil
+				})
+			}
+
+			// Run
+			d := NewDomain(repository, config, NewMockNotifier(t)).(*domain)
+			set, err := d.publicKeys(setup)
+
+			// Assertions
+			require.NoError(t, err)
+			require.Len(t, set.Keys, tc.publishedNo)
+			require.True(t, set.Keys[0].IsPublic())
+			require.Equal(t, stored[0].ID, set.Keys[0].KeyID)
+		})
+	}
+}
+
+func TestEncryptSigningKey(t *testing.T) {
+	t.Parallel()
+
+	sealed, err := encryptSigningKey("secret", []byte("private key"))
+	require.NoError(t, err)
+	require.NotContains(t, string(sealed), "private key")
+
+	plain, err := decryptSigningKey("secret", sealed)
+	require.NoError(t, err)
+	require.Equal(t, []byte("private key"), plain)
+
+	_, err = decryptSigningKey("other secret", sealed)
+	require.Error(t, err)
+}
@@ -0,0 +1,208 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/google/uuid"
+)
+
+const refreshTokenBytes = 32
+
+// userTokenClaims are claims of access token issued on log in so downstream
+// services can identify the user without calling back
+type userTokenClaims struct {
+	jwt.Claims
+	Email    string `json:"email,omitempty"`
+	Username string `json:"username,omitempty"`
+	Role     string `json:"role,omitempty"`
+	Verified bool   `json:"verified"`
+}
+
+// ExchangeSession replaces freshly started session with signed access token
+// and refresh token which starts a new rotation family
+func (d *domain) ExchangeSession(setup Setup, sessionKey string) (tokens SessionTokens, err error) {
+	user, err := d.loginSession(setup, sessionKey)
+	if err != nil {
+		return
+	}
+
+	err = d.db.Session(setup.ctx).Delete(sessionKey)
+	if err != nil {
+		err = fmt.Errorf("domain ExchangeSession -> unable to delete session %w", err)
+		return
+	}
+
+	tokens, err = d.issueSessionTokens(setup, d.db, user, uuid.NewString())
+	if err != nil {
+		err = fmt.Errorf("domain ExchangeSession -> %w", err)
+	}
+
+	return
+}
+
+// RefreshTokens rotates refresh token. Presenting token that was already rotated
+// means it has leaked so the whole family is revoked and user has to log in again.
+func (d *domain) RefreshTokens(setup Setup, refreshToken string) (user User, tokens SessionTokens, err error) {
+	existing, err := d.db.RefreshToken(setup.ctx).GetByHash(hashToken(refreshToken))
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrInvalidRefreshToken
+		return
+	} else if err != nil {
+		err = fmt.Errorf("domain RefreshTokens -> %w", err)
+		return
+	}
+
+	if existing.Used {
+		err = d.revokeFamily(setup, existing.Family)
+		return
+	}
+
+	if time.Now().After(existing.ExpiresAt) {
+		err = ErrInvalidRefreshToken
+		return
+	}
+
+	err = d.db.Atomic(func(txRepo Repository) error {
+		e := txRepo.RefreshToken(setup.ctx).Use(existing.ID)
+		if errors.Is(e, modelErrors.ErrNotFound) {
+			return ErrRefreshTokenReused
+		} else if e != nil {
+			return e
+		}
+
+		found, e := txRepo.User(setup.ctx).GetByID(existing.UserID)
+		if errors.Is(e, modelErrors.ErrNotFound) {
+			return ErrInvalidRefreshToken
+		} else if e != nil {
+			return fmt.Errorf("unable to fetch user %d %w", existing.UserID, e)
+		}
+
+		issued, e := d.issueSessionTokens(setup, txRepo, found, existing.Family)
+		if e != nil {
+			return e
+		}
+
+		user = found
+		tokens = issued
+		return nil
+	})
+
+	if err == ErrRefreshTokenReused {
+		err = d.revokeFamily(setup, existing.Family)
+	} else if err != nil && err != ErrInvalidRefreshToken {
+		err = fmt.Errorf("domain RefreshTokens -> %w", err)
+	}
+
+	if err != nil {
+		user = User{}
+		tokens = SessionTokens{}
+	}
+
+	return
+}
+
+// RevokeRefreshToken logs out token based client by revoking the whole family
+func (d *domain) RevokeRefreshToken(setup Setup, refreshToken string) (err error) {
+	existing, err := d.db.RefreshToken(setup.ctx).GetByHash(hashToken(refreshToken))
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrInvalidRefreshToken
+		return
+	} else if err != nil {
+		err = fmt.Errorf("domain RevokeRefreshToken -> %w", err)
+		return
+	}
+
+	err = d.db.RefreshToken(setup.ctx).RevokeFamily(existing.Family)
+	if err != nil {
+		err = fmt.Errorf("domain RevokeRefreshToken -> %w", err)
+	}
+
+	return
+}
+
+func (d *domain) revokeFamily(setup Setup, family string) error {
+	setup.logger.Warn("refresh token reused, revoking family", "family", family)
+
+	err := d.db.RefreshToken(setup.ctx).RevokeFamily(family)
+	if err != nil {
+		return fmt.Errorf("domain RefreshTokens -> %w", err)
+	}
+
+	return ErrRefreshTokenReused
+}
+
+func (d *domain) issueSessionTokens(setup Setup, repo Repository, user User, family string) (tokens SessionTokens, err error) {
+	tokens.AccessToken, err = d.signAccessToken(setup, user)
+	if err != nil {
+		return
+	}
+
+	random := make([]byte, refreshTokenBytes)
+	if _, err = rand.Read(random); err != nil {
+		err = fmt.Errorf("unable to generate refresh token %w", err)
+		return
+	}
+
+	refreshToken := base64.RawURLEncoding.EncodeToString(random)
+
+	_, err = repo.RefreshToken(setup.ctx).Create(RefreshToken{
+		UserID:    user.ID,
+		Family:    family,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(utils.REFRESH_TOKEN_TTL),
+	})
+	if err != nil {
+		tokens = SessionTokens{}
+		return
+	}
+
+	tokens.RefreshToken = refreshToken
+	tokens.ExpiresIn = int64(utils.ACCESS_TOKEN_TTL.Seconds())
+
+	return
+}
+
+func (d *domain) signAccessToken(setup Setup, user User) (token string, err error) {
+	signer, err := d.tokenSigner(setup, accessTokenType)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	claims := userTokenClaims{
+		Claims: jwt.Claims{
+			Issuer:   d.config.OIDCIssuer,
+			Subject:  strconv.FormatUint(user.ID, 10),
+			ID:       uuid.NewString(),
+			IssuedAt: jwt.NewNumericDate(now),
+			Expiry:   jwt.NewNumericDate(now.Add(utils.ACCESS_TOKEN_TTL)),
+		},
+		Email:    user.Email,
+		Username: user.Username,
+		Role:     user.Role,
+		Verified: user.Verified,
+	}
+
+	token, err = jwt.Signed(signer).Claims(claims).Serialize()
+	if err != nil {
+		err = fmt.Errorf("unable to sign access token %w", err)
+	}
+
+	return
+}
+
+// hashToken is used for lookup so only hashes of refresh tokens and api keys are stored
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,214 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExchangeSession(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com", Username: "djvukovic", Role: "admin", Verified: true}
+
+	// Create mocks
+	repository := NewMockRepository(t)
+	sessionRepository := NewMockRepositorySession(t)
+	refreshRepository := NewMockRepositoryRefreshToken(t)
+
+	// Setup mocks
+	repository.EXPECT().Session(context.TODO()).Return(sessionRepository)
+	repository.EXPECT().RefreshToken(context.TODO()).Return(refreshRepository)
+	sessionRepository.EXPECT().Get("session").Return(user, nil)
+	sessionRepository.EXPECT().Delete("session").Return(nil)
+
+	var stored RefreshToken
+	refreshRepository.EXPECT().Create(mock.Anything).RunAndReturn(func(token RefreshToken) (RefreshToken, error) {
+		stored = token
+		return token, nil
+	})
+
+	// Run
+	domain := NewDomain(repository, utils.Config{}, NewMockNotifier(t))
+	tokens, err := domain.ExchangeSession(setup, "session")
+
+	// Assertions
+	require.NoError(t, err)
+	require.Equal(t, int64(utils.ACCESS_TOKEN_TTL.Seconds()), tokens.ExpiresIn)
+	require.Equal(t, hashToken(tokens.RefreshToken), stored.TokenHash)
+	require.Equal(t, user.ID, stored.UserID)
+	require.NotEmpty(t, stored.Family)
+	require.WithinDuration(t, time.Now().Add(utils.REFRESH_TOKEN_TTL), stored.ExpiresAt, time.Minute)
+
+	keys, err := domain.JWKS(setup)
+	require.NoError(t, err)
+
+	var set jose.JSONWebKeySet
+	require.NoError(t, json.Unmarshal(keys, &set))
+
+	parsed, err := jwt.ParseSigned(tokens.AccessToken, []jose.SignatureAlgorithm{jose.RS256})
+	require.NoError(t, err)
+
+	var claims userTokenClaims
+	require.NoError(t, parsed.Claims(set.Keys[0].Key, &claims))
+	require.NoError(t, claims.Validate(jwt.Expected{Subject: "452", Time: time.Now()}))
+	require.Equal(t, user.Email, claims.Email)
+	require.Equal(t, user.Username, claims.Username)
+	require.Equal(t, user.Role, claims.Role)
+	require.True(t, claims.Verified)
+}
+
+func TestRefreshTokens(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com"}
+	existing := RefreshToken{
+		ID:        9,
+		UserID:    user.ID,
+		Family:    "family",
+		TokenHash: hashToken("refresh"),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	type testCase struct {
+		name        string
+		setupModels func(*MockRepository, *MockRepositoryRefreshToken, *MockRepositoryUser, *testCase)
+		returnUser  User
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name: "rotates token",
+			setupModels: func(r *MockRepository, rr *MockRepositoryRefreshToken, ru *MockRepositoryUser, tc *testCase) {
+				rr.EXPECT().GetByHash(existing.TokenHash).Return(existing, nil)
+				r.EXPECT().Atomic(mock.Anything).RunAndReturn(func(f func(Repository) error) error {
+					return f(r)
+				})
+				rr.EXPECT().Use(existing.ID).Return(nil)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+				rr.EXPECT().Create(mock.MatchedBy(func(token RefreshToken) bool {
+					return token.Family == existing.Family && token.TokenHash != existing.TokenHash
+				})).RunAndReturn(func(token RefreshToken) (RefreshToken, error) {
+					return token, nil
+				})
+			},
+			returnUser: user,
+		},
+		{
+			name: "reused token revokes family",
+			setupModels: func(r *MockRepository, rr *MockRepositoryRefreshToken, ru *MockRepositoryUser, tc *testCase) {
+				used := existing
+				used.Used = true
+				rr.EXPECT().GetByHash(existing.TokenHash).Return(used, nil)
+				rr.EXPECT().RevokeFamily(existing.Family).Return(nil)
+			},
+			returnError: ErrRefreshTokenReused,
+		},
+		{
+			name: "concurrently used token revokes family",
+			setupModels: func(r *MockRepository, rr *MockRepositoryRefreshToken, ru *MockRepositoryUser, tc *testCase) {
+				rr.EXPECT().GetByHash(existing.TokenHash).Return(existing, nil)
+				r.EXPECT().Atomic(mock.Anything).RunAndReturn(func(f func(Repository) error) error {
+					return f(r)
+				})
+				rr.EXPECT().Use(existing.ID).Return(modelErrors.ErrNotFound)
+				rr.EXPECT().RevokeFamily(existing.Family).Return(nil)
+			},
+			returnError: ErrRefreshTokenReused,
+		},
+		{
+			name: "expired token",
+			setupModels: func(r *MockRepository, rr *MockRepositoryRefreshToken, ru *MockRepositoryUser, tc *testCase) {
+				expired := existing
+				expired.ExpiresAt = time.Now().Add(-time.Minute)
+				rr.EXPECT().GetByHash(existing.TokenHash).Return(expired, nil)
+			},
+			returnError: ErrInvalidRefreshToken,
+		},
+		{
+			name: "unknown token",
+			setupModels: func(r *MockRepository, rr *MockRepositoryRefreshToken, ru *MockRepositoryUser, tc *testCase) {
+				rr.EXPECT().GetByHash(existing.TokenHash).Return(RefreshToken{}, modelErrors.ErrNotFound)
+			},
+			returnError: ErrInvalidRefreshToken,
+		},
+		{
+			name: "storing new token fails",
+			setupModels: func(r *MockRepository, rr *MockRepositoryRefreshToken, ru *MockRepositoryUser, tc *testCase) {
+				rr.EXPECT().GetByHash(existing.TokenHash).Return(existing, nil)
+				r.EXPECT().Atomic(mock.Anything).RunAndReturn(func(f func(Repository) error) error {
+					return f(r)
+				})
+				rr.EXPECT().Use(existing.ID).Return(nil)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+				rr.EXPECT().Create(mock.Anything).Return(RefreshToken{}, errModel)
+			},
+			returnError: errors.New("domain RefreshTokens -> model error"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			refreshRepository := NewMockRepositoryRefreshToken(t)
+			userRepository := NewMockRepositoryUser(t)
+
+			// Setup mocks
+			repository.EXPECT().RefreshToken(context.TODO()).Return(refreshRepository)
+			repository.EXPECT().User(context.TODO()).Return(userRepository).Maybe()
+			tc.setupModels(repository, refreshRepository, userRepository, &tc)
+
+			// Run
+			domain := NewDomain(repository, utils.Config{}, NewMockNotifier(t))
+			refreshed, tokens, err := domain.RefreshTokens(setup, "refresh")
+
+			// Assertions
+			if tc.returnError != nil {
+				require.EqualError(t, err, tc.returnError.Error())
+				require.Empty(t, tokens)
+			} else {
+				require.NoError(t, err)
+				require.NotEmpty(t, tokens.AccessToken)
+				require.NotEqual(t, "refresh", tokens.RefreshToken)
+			}
+
+			require.Equal(t, tc.returnUser, refreshed)
+		})
+	}
+}
+
+func TestRevokeRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+
+	// Create mocks
+	repository := NewMockRepository(t)
+	refreshRepository := NewMockRepositoryRefreshToken(t)
+
+	// Setup mocks
+	repository.EXPECT().RefreshToken(context.TODO()).Return(refreshRepository)
+	refreshRepository.EXPECT().GetByHash(hashToken("refresh")).Return(RefreshToken{Family: "family"}, nil)
+	refreshRepository.EXPECT().GetByHash(hashToken("unknown")).Return(RefreshToken{}, modelErrors.ErrNotFound)
+	refreshRepository.EXPECT().RevokeFamily("family").Return(nil)
+
+	// Run
+	domain := NewDomain(repository, utils.Config{}, NewMockNotifier(t))
+
+	// Assertions
+	require.NoError(t, domain.RevokeRefreshToken(setup, "refresh"))
+	require.Equal(t, ErrInvalidRefreshToken, domain.RevokeRefreshToken(setup, "unknown"))
+}
@@ -0,0 +1,301 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+)
+
+const (
+	totpDigits       = 6
+	totpModulo       = 1000000
+	totpPeriod       = 30
+	totpSkew         = 1
+	totpSecretLength = 20
+
+	maxChallengeAttempts = 5
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func generateTOTPSecret() (string, error) {
+	secret := make([]byte, totpSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+func totpStep(t time.Time) int64 {
+	return t.Unix() / totpPeriod
+}
+
+// totpCode computes RFC 6238 code for a given time step (HMAC-SHA1, 6 digits)
+func totpCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("invalid totp secret %w", err)
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%totpModulo), nil
+}
+
+// validateTOTP checks code against current step allowing clock skew of one period.
+// Steps at or before lastStep are rejected so a code can not be replayed.
+func validateTOTP(secret string, code string, now time.Time, lastStep int64) (step int64, valid bool) {
+	current := totpStep(now)
+
+	for i := int64(-totpSkew); i <= totpSkew; i++ {
+		candidate := current + i
+		if candidate <= lastStep {
+			continue
+		}
+
+		expected, err := totpCode(secret, candidate)
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return candidate, true
+		}
+	}
+
+	return 0, false
+}
+
+func totpURI(issuer string, account string, secret string) string {
+	label := url.PathEscape(fmt.Sprintf("%s:%s", issuer, account))
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprintf("%d", totpDigits))
+	query.Set("period", fmt.Sprintf("%d", totpPeriod))
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode())
+}
+
+// hasSecondFactor reports whether user has confirmed TOTP or at least one registered passkey
+func (d *domain) hasSecondFactor(setup Setup, user User) (enrolled bool, err error) {
+	totp, err := d.db.TOTP(setup.ctx).Get(user.ID)
+	if err != nil && !errors.Is(err, modelErrors.ErrNotFound) {
+		err = fmt.Errorf("unable to check second factor for user id %d %w", user.ID, err)
+		return
+	}
+
+	if err == nil && totp.Confirmed {
+		return true, nil
+	}
+
+	passkeys, err := d.db.Passkey(setup.ctx).GetByUser(user.ID)
+	if err != nil {
+		err = fmt.Errorf("unable to check passkeys for user id %d %w", user.ID, err)
+		return
+	}
+
+	enrolled = len(passkeys) > 0
+	return
+}
+
+func (d *domain) EnrollTOTP(setup Setup, token string) (secret string, uri string, err error) {
+	user, err := d.loginSession(setup, token)
+	if err != nil {
+		return
+	}
+
+	totpModel := d.db.TOTP(setup.ctx)
+
+	existing, err := totpModel.Get(user.ID)
+	if err == nil && existing.Confirmed {
+		err = ErrAlreadyEnrolled
+		return
+	} else if err != nil && !errors.Is(err, modelErrors.ErrNotFound) {
+		err = fmt.Errorf("domain EnrollTOTP -> failed to fetch totp for user %d %w", user.ID, err)
+		return
+	}
+
+	secret, err = generateTOTPSecret()
+	if err != nil {
+		err = fmt.Errorf("domain EnrollTOTP -> failed to generate secret %w", err)
+		return
+	}
+
+	_, err = totpModel.Create(user.ID, secret)
+	if err != nil {
+		secret = ""
+		err = fmt.Errorf("domain EnrollTOTP -> failed to store secret %w", err)
+		return
+	}
+
+	uri = totpURI(d.config.MFAIssuer, user.Email, secret)
+
+	return
+}
+
+func (d *domain) ConfirmTOTP(setup Setup, token string, code string) (recoveryCodes []string, err error) {
+	user, err := d.loginSession(setup, token)
+	if err != nil {
+		return
+	}
+
+	totpModel := d.db.TOTP(setup.ctx)
+
+	totp, err := totpModel.Get(user.ID)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrInvalidCode
+		return
+	} else if err != nil {
+		err = fmt.Errorf("domain ConfirmTOTP -> failed to fetch totp for user %d %w", user.ID, err)
+		return
+	}
+
+	if totp.Confirmed {
+		err = ErrAlreadyEnrolled
+		return
+	}
+
+	step, valid := validateTOTP(totp.Secret, code, time.Now(), totp.LastStep)
+	if !valid {
+		err = ErrInvalidCode
+		return
+	}
+
+	enrolled, err := d.hasSecondFactor(setup, user)
+	if err != nil {
+		return
+	}
+
+	err = d.db.Atomic(func(txRepo Repository) error {
+		e := txRepo.TOTP(setup.ctx).Confirm(user.ID, step)
+		if e != nil {
+			return fmt.Errorf("domain ConfirmTOTP -> failed to confirm totp for user %d %w", user.ID, e)
+		}
+
+		// First second factor gets a fresh set of recovery codes
+		if enrolled {
+			return nil
+		}
+
+		codes, e := storeRecoveryCodes(txRepo.RecoveryCode(setup.ctx), user.ID)
+		if e != nil {
+			return fmt.Errorf("domain ConfirmTOTP -> %w", e)
+		}
+
+		recoveryCodes = codes
+		return nil
+	})
+
+	if err != nil {
+		recoveryCodes = nil
+	}
+
+	return
+}
+
+// attemptChallenge resolves pending login challenge and counts verification attempt
+// against it. Challenge is discarded once it runs out of attempts.
+func (d *domain) attemptChallenge(setup Setup, key string) (challenge Challenge, err error) {
+	challengeModel := d.db.Challenge(setup.ctx)
+
+	challenge, err = challengeModel.Get(key)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrInvalidChallenge
+		return
+	} else if err != nil {
+		err = fmt.Errorf("unable to get challenge %s %w", key, err)
+		return
+	}
+
+	attempts, err := challengeModel.Attempt(key)
+	if err != nil {
+		err = fmt.Errorf("unable to record attempt for challenge %s %w", key, err)
+		return
+	}
+
+	if attempts > maxChallengeAttempts {
+		challengeModel.Delete(key)
+		challenge = Challenge{}
+		err = ErrInvalidChallenge
+		return
+	}
+
+	challenge.Attempts = attempts
+	return
+}
+
+// completeChallenge discards solved challenge and starts regular session for its user
+func (d *domain) completeChallenge(setup Setup, challenge Challenge) (existing User, sessionKey string, err error) {
+	err = d.db.Challenge(setup.ctx).Delete(challenge.ID)
+	if err != nil {
+		err = fmt.Errorf("unable to delete challenge %s %w", challenge.ID, err)
+		return
+	}
+
+	existing, err = d.db.User(setup.ctx).GetByID(challenge.UserID)
+	if err != nil {
+		err = fmt.Errorf("unable to fetch user %d for challenge %w", challenge.UserID, err)
+		return
+	}
+
+	sessionKey, err = d.startSession(setup, existing)
+	if err != nil {
+		existing = User{}
+	}
+
+	return
+}
+
+func (d *domain) LogInTOTP(setup Setup, challengeKey string, code string) (existing User, sessionKey string, err error) {
+	challenge, err := d.attemptChallenge(setup, challengeKey)
+	if err != nil {
+		return
+	}
+
+	totpModel := d.db.TOTP(setup.ctx)
+
+	totp, err := totpModel.Get(challenge.UserID)
+	if errors.Is(err, modelErrors.ErrNotFound) {
+		err = ErrInvalidCode
+		return
+	} else if err != nil {
+		err = fmt.Errorf("domain LogInTOTP -> failed to fetch totp for user %d %w", challenge.UserID, err)
+		return
+	}
+
+	step, valid := validateTOTP(totp.Secret, code, time.Now(), totp.LastStep)
+	if !totp.Confirmed || !valid {
+		err = ErrInvalidCode
+		return
+	}
+
+	err = totpModel.SetLastStep(challenge.UserID, step)
+	if err != nil {
+		err = fmt.Errorf("domain LogInTOTP -> failed to store used step %w", err)
+		return
+	}
+
+	return d.completeChallenge(setup, challenge)
+}
@@ -0,0 +1,251 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// base32 encoded RFC 6238 SHA1 test secret "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		time   int64
+		result string
+	}{
+		{name: "time 59", time: 59, result: "287082"},
+		{name: "time 1111111109", time: 1111111109, result: "081804"},
+		{name: "time 1234567890", time: 1234567890, result: "005924"},
+		{name: "time 2000000000", time: 2000000000, result: "279037"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			code, err := totpCode(rfcSecret, totpStep(time.Unix(tc.time, 0)))
+
+			require.NoError(t, err)
+			require.Equal(t, tc.result, code)
+		})
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1111111109, 0)
+	current := totpStep(now)
+
+	codeAt := func(step int64) string {
+		code, _ := totpCode(rfcSecret, step)
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		lastStep int64
+		step     int64
+		valid    bool
+	}{
+		{name: "current step", code: codeAt(current), step: current, valid: true},
+		{name: "previous step", code: codeAt(current - 1), step: current - 1, valid: true},
+		{name: "next step", code: codeAt(current + 1), step: current + 1, valid: true},
+		{name: "outside of skew", code: codeAt(current - 2), valid: false},
+		{name: "replayed step", code: codeAt(current), lastStep: current, valid: false},
+		{name: "wrong code", code: "000000", valid: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step, valid := validateTOTP(rfcSecret, tc.code, now, tc.lastStep)
+
+			require.Equal(t, tc.valid, valid)
+			require.Equal(t, tc.step, step)
+		})
+	}
+}
+
+func TestEnrollTOTP(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com"}
+
+	type testCase struct {
+		name        string
+		setupModels func(*MockRepositorySession, *MockRepositoryTOTP, *testCase)
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name: "success",
+			setupModels: func(rs *MockRepositorySession, rt *MockRepositoryTOTP, tc *testCase) {
+				rs.EXPECT().Get("session").Return(user, nil)
+				rt.EXPECT().Get(user.ID).Return(TOTP{}, modelErrors.ErrNotFound)
+				rt.EXPECT().Create(user.ID, mock.Anything).Return(TOTP{}, nil)
+			},
+		},
+		{
+			name: "re-enroll unconfirmed",
+			setupModels: func(rs *MockRepositorySession, rt *MockRepositoryTOTP, tc *testCase) {
+				rs.EXPECT().Get("session").Return(user, nil)
+				rt.EXPECT().Get(user.ID).Return(TOTP{UserID: user.ID, Confirmed: false}, nil)
+				rt.EXPECT().Create(user.ID, mock.Anything).Return(TOTP{}, nil)
+			},
+		},
+		{
+			name: "already enrolled",
+			setupModels: func(rs *MockRepositorySession, rt *MockRepositoryTOTP, tc *testCase) {
+				rs.EXPECT().Get("session").Return(user, nil)
+				rt.EXPECT().Get(user.ID).Return(TOTP{UserID: user.ID, Confirmed: true}, nil)
+			},
+			returnError: ErrAlreadyEnrolled,
+		},
+		{
+			name: "no session",
+			setupModels: func(rs *MockRepositorySession, rt *MockRepositoryTOTP, tc *testCase) {
+				rs.EXPECT().Get("session").Return(User{}, modelErrors.ErrNotFound)
+			},
+			returnError: ErrNoSession,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			sessionRepository := NewMockRepositorySession(t)
+			totpRepository := NewMockRepositoryTOTP(t)
+
+			// Setup mocks
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository).Maybe()
+			repository.EXPECT().TOTP(context.TODO()).Return(totpRepository).Maybe()
+			tc.setupModels(sessionRepository, totpRepository, &tc)
+
+			// Run
+			domain := NewDomain(repository, utils.Config{MFAIssuer: "auth"}, NewMockNotifier(t))
+			secret, uri, err := domain.EnrollTOTP(setup, "session")
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorIs(t, err, tc.returnError)
+				require.Empty(t, secret)
+				require.Empty(t, uri)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotEmpty(t, secret)
+			require.True(t, strings.HasPrefix(uri, "otpauth://totp/auth:djvukovic@gmail.com?"))
+			require.Contains(t, uri, "secret="+secret)
+		})
+	}
+}
+
+func TestLogInTOTP(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	user := User{ID: 452, Email: "djvukovic@gmail.com", Verified: true}
+
+	validCode, _ := totpCode(rfcSecret, totpStep(time.Now()))
+	confirmed := TOTP{UserID: user.ID, Secret: rfcSecret, Confirmed: true}
+
+	type testCase struct {
+		name        string
+		code        string
+		setupModels func(*MockRepositoryChallenge, *MockRepositoryTOTP, *MockRepositoryUser, *MockRepositorySession, *testCase)
+		returnUser  User
+		returnKey   string
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name: "success",
+			code: validCode,
+			setupModels: func(rc *MockRepositoryChallenge, rt *MockRepositoryTOTP, ru *MockRepositoryUser, rs *MockRepositorySession, tc *testCase) {
+				rc.EXPECT().Get("challenge").Return(Challenge{ID: "challenge", UserID: user.ID}, nil)
+				rc.EXPECT().Attempt("challenge").Return(1, nil)
+				rt.EXPECT().Get(user.ID).Return(confirmed, nil)
+				rt.EXPECT().SetLastStep(user.ID, mock.Anything).Return(nil)
+				rc.EXPECT().Delete("challenge").Return(nil)
+				ru.EXPECT().GetByID(user.ID).Return(user, nil)
+				rs.EXPECT().Create(user, utils.Client{}, mock.Anything, mock.Anything).Return(Session{ID: "session", User: user}, nil)
+			},
+			returnUser: user,
+			returnKey:  "session",
+		},
+		{
+			name: "invalid code",
+			code: "000000",
+			setupModels: func(rc *MockRepositoryChallenge, rt *MockRepositoryTOTP, ru *MockRepositoryUser, rs *MockRepositorySession, tc *testCase) {
+				rc.EXPECT().Get("challenge").Return(Challenge{ID: "challenge", UserID: user.ID}, nil)
+				rc.EXPECT().Attempt("challenge").Return(1, nil)
+				rt.EXPECT().Get(user.ID).Return(confirmed, nil)
+			},
+			returnError: ErrInvalidCode,
+		},
+		{
+			name: "expired challenge",
+			code: validCode,
+			setupModels: func(rc *MockRepositoryChallenge, rt *MockRepositoryTOTP, ru *MockRepositoryUser, rs *MockRepositorySession, tc *testCase) {
+				rc.EXPECT().Get("challenge").Return(Challenge{}, modelErrors.ErrNotFound)
+			},
+			returnError: ErrInvalidChallenge,
+		},
+		{
+			name: "too many attempts",
+			code: validCode,
+			setupModels: func(rc *MockRepositoryChallenge, rt *MockRepositoryTOTP, ru *MockRepositoryUser, rs *MockRepositorySession, tc *testCase) {
+				rc.EXPECT().Get("challenge").Return(Challenge{ID: "challenge", UserID: user.ID}, nil)
+				rc.EXPECT().Attempt("challenge").Return(maxChallengeAttempts+1, nil)
+				rc.EXPECT().Delete("challenge").Return(nil)
+			},
+			returnError: ErrInvalidChallenge,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			challengeRepository := NewMockRepositoryChallenge(t)
+			totpRepository := NewMockRepositoryTOTP(t)
+			userRepository := NewMockRepositoryUser(t)
+			sessionRepository := NewMockRepositorySession(t)
+
+			// Setup mocks
+			repository.EXPECT().Challenge(context.TODO()).Return(challengeRepository).Maybe()
+			repository.EXPECT().TOTP(context.TODO()).Return(totpRepository).Maybe()
+			repository.EXPECT().User(context.TODO()).Return(userRepository).Maybe()
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository).Maybe()
+			tc.setupModels(challengeRepository, totpRepository, userRepository, sessionRepository, &tc)
+
+			// Run
+			domain := NewDomain(repository, utils.Config{}, NewMockNotifier(t))
+			existing, key, err := domain.LogInTOTP(setup, "challenge", tc.code)
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorIs(t, err, tc.returnError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.returnUser, existing)
+			require.Equal(t, tc.returnKey, key)
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 type User struct {
 	ID       uint64
 	Email    string
@@ -8,6 +10,8 @@ type User struct {
 	Role     string
 	Verified bool
 	Payload  map[string]any
+	// Scopes limit paths api key the user authenticated with can access
+	Scopes []string
 }
 
 type VerifyAccount struct {
@@ -22,7 +26,176 @@ type ForgetPassword struct {
 	UserID uint64
 }
 
+type MagicLink struct {
+	ID     uint64
+	Token  string
+	UserID uint64
+}
+
+type OneTimeCode struct {
+	Purpose  string
+	UserID   uint64
+	Code     string
+	Attempts int64
+}
+
 type Session struct {
-	ID   string
-	User User
+	ID            string
+	User          User
+	CreatedAt     time.Time
+	LastSeen      time.Time
+	ExpiresAt     time.Time
+	IdleExpiresAt time.Time
+	IP            string
+	UserAgent     string
+	Current       bool
+}
+
+type TOTP struct {
+	UserID    uint64
+	Secret    string
+	Confirmed bool
+	LastStep  int64
+}
+
+type Challenge struct {
+	ID       string
+	UserID   uint64
+	Attempts int64
+}
+
+type Passkey struct {
+	ID              uint64
+	UserID          uint64
+	CredentialID    []byte
+	PublicKey       []byte
+	AttestationType string
+	AAGUID          []byte
+	SignCount       uint32
+	Transports      []string
+	BackupEligible  bool
+	BackupState     bool
+}
+
+type Identity struct {
+	ID       uint64
+	UserID   uint64
+	Provider string
+	Subject  string
+	Email    string
+}
+
+type OAuthClient struct {
+	ID           string
+	Name         string
+	SecretHash   string
+	RedirectURIs []string
+	Trusted      bool
+	Scopes       []string
+}
+
+type Consent struct {
+	UserID   uint64
+	ClientID string
+	Scopes   []string
+}
+
+type AuthorizationRequest struct {
+	ResponseType        string `json:"response_type"`
+	ClientID            string `json:"client_id"`
+	RedirectURI         string `json:"redirect_uri"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	Nonce               string `json:"nonce"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+	Prompt              string `json:"prompt"`
+}
+
+type TokenRequest struct {
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	ClientID     string
+	ClientSecret string
+	CodeVerifier string
+	Scope        string
+	DeviceCode   string
+}
+
+type Tokens struct {
+	AccessToken string
+	IDToken     string
+	Scope       string
+	ExpiresIn   int64
+}
+
+// Introspection describes token presented to the introspection endpoint. Only
+// Active is set for tokens that are unknown, expired or revoked.
+type Introspection struct {
+	Active    bool
+	TokenType string
+	Subject   string
+	Username  string
+	Role      string
+	ClientID  string
+	Scopes    []string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+type RefreshToken struct {
+	ID        uint64
+	UserID    uint64
+	Family    string
+	TokenHash string
+	ExpiresAt time.Time
+	Used      bool
+}
+
+type SessionTokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+}
+
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey []byte
+	CreatedAt  time.Time
+	RetiredAt  time.Time
+}
+
+type APIKey struct {
+	ID        uint64
+	UserID    uint64
+	Name      string
+	Prefix    string
+	KeyHash   string
+	Scopes    []string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+type DeviceAuthorization struct {
+	DeviceCode string
+	UserCode   string
+	ClientID   string
+	Scope      string
+	Status     string
+	UserID     uint64
+	Interval   int64
+	DecidedAt  time.Time
+	ExpiresIn  int64
+}
+
+type LogInMethods struct {
+	Methods    []string
+	Connection Connection
+}
+
+type Connection struct {
+	Provider string
+	Protocol string
 }
@@ -8,6 +8,8 @@ import (
 )
 
 func (d *domain) VerifyAccount(setup Setup, token string) (verified bool, err error) {
+	var userId uint64
+
 	err = d.db.Atomic(func(txRepo Repository) error {
 		verifyAccount, err := txRepo.VerifyAccount(setup.ctx).Verify(token)
 		if err == modelErrors.ErrNotFound {
@@ -16,20 +18,27 @@ func (d *domain) VerifyAccount(setup Setup, token string) (verified bool, err er
 			return fmt.Errorf("failed to verify account %w", err)
 		}
 
-		user := User{ID: verifyAccount.ID}
+		user := User{ID: verifyAccount.UserID}
 		err = txRepo.User(setup.ctx).Verify(user)
 		if err != nil {
 			return fmt.Errorf("failed to verify account %w", err)
 		}
 
+		userId = user.ID
 		return nil
 	})
 
 	verified = err == nil
+	if verified {
+		d.refreshSessionsOf(setup, userId)
+	}
+
 	return
 }
 
-func (d *domain) VerifyPasswordReset(setup Setup, token string, password string) (updated User, err error) {
+// VerifyPasswordReset sets new password and signs the user out everywhere
+// except from the kept session. Refresh tokens and api keys are revoked as well.
+func (d *domain) VerifyPasswordReset(setup Setup, token string, password string, keepSession string) (updated User, err error) {
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), 14)
 	if err != nil {
 		err = fmt.Errorf("domain VerifyPasswordReset -> failed to generate password hash %w", err)
@@ -48,9 +57,22 @@ func (d *domain) VerifyPasswordReset(setup Setup, token string, password string)
 		newPassword := string(hash)
 		updateErr := txRepo.User(setup.ctx).SetPassword(user, newPassword)
 		if updateErr != nil {
-			return fmt.Errorf("failed to reset password %w", err)
+			return fmt.Errorf("failed to reset password %w", updateErr)
+		}
+
+		revokeErr := revokeCredentials(setup, txRepo, user.ID)
+		if revokeErr != nil {
+			return revokeErr
+		}
+
+		// password is changed only if every other session is gone, otherwise the reset
+		// fails and can be retried with the same token
+		_, revokeErr = d.revokeSessions(setup, user.ID, keepSession)
+		if revokeErr != nil {
+			return revokeErr
 		}
 
+		updated.ID = user.ID
 		updated.Password = newPassword
 		return nil
 	})
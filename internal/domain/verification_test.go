@@ -0,0 +1,201 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestVerifyAccount(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	verified := User{ID: 452, Email: "djvukovic@gmail.com", Role: "admin", Verified: true}
+
+	type testCase struct {
+		name        string
+		setupModels func(*MockRepositoryVerifyAccount, *MockRepositoryUser, *MockRepositorySession, *testCase)
+		verified    bool
+		returnError error
+	}
+
+	tests := []testCase{
+		{
+			name: "refreshes sessions",
+			setupModels: func(rv *MockRepositoryVerifyAccount, ru *MockRepositoryUser, rs *MockRepositorySession, tc *testCase) {
+				rv.EXPECT().Verify("token").Return(VerifyAccount{Token: "token", UserID: verified.ID}, nil)
+				ru.EXPECT().Verify(User{ID: verified.ID}).Return(nil)
+				ru.EXPECT().GetByID(verified.ID).Return(verified, nil)
+				rs.EXPECT().UpdateUser(verified).Return(nil)
+			},
+			verified: true,
+		},
+		{
+			name: "failing to refresh sessions keeps the account verified",
+			setupModels: func(rv *MockRepositoryVerifyAccount, ru *MockRepositoryUser, rs *MockRepositorySession, tc *testCase) {
+				rv.EXPECT().Verify("token").Return(VerifyAccount{Token: "token", UserID: verified.ID}, nil)
+				ru.EXPECT().Verify(User{ID: verified.ID}).Return(nil)
+				ru.EXPECT().GetByID(verified.ID).Return(verified, nil)
+				rs.EXPECT().UpdateUser(verified).Return(errors.New("redis down"))
+			},
+			verified: true,
+		},
+		{
+			name: "invalid token",
+			setupModels: func(rv *MockRepositoryVerifyAccount, ru *MockRepositoryUser, rs *MockRepositorySession, tc *testCase) {
+				rv.EXPECT().Verify("token").Return(VerifyAccount{}, modelErrors.ErrNotFound)
+			},
+			returnError: ErrInvalidToken,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			verifyRepository := NewMockRepositoryVerifyAccount(t)
+			userRepository := NewMockRepositoryUser(t)
+			sessionRepository := NewMockRepositorySession(t)
+
+			// Setup mocks
+			repository.EXPECT().Atomic(mock.Anything).RunAndReturn(func(f func(Repository) error) error {
+				return f(repository)
+			})
+			repository.EXPECT().VerifyAccount(context.TODO()).Return(verifyRepository)
+			repository.EXPECT().User(context.TODO()).Return(userRepository).Maybe()
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository).Maybe()
+			tc.setupModels(verifyRepository, userRepository, sessionRepository, &tc)
+
+			// Run
+			domain := NewDomain(repository, utils.Config{}, NewMockNotifier(t))
+			verified, err := domain.VerifyAccount(setup, "token")
+
+			// Assertions
+			require.ErrorIs(t, err, tc.returnError)
+			require.Equal(t, tc.verified, verified)
+		})
+	}
+}
+
+func TestVerifyPasswordReset(t *testing.T) {
+	t.Parallel()
+
+	setup := Setup{ctx: context.TODO(), logger: utils.NewSilentLogger()}
+	userId := uint64(452)
+
+	type testCase struct {
+		name        string
+		keepSession string
+		setupModels func(*MockRepositoryForgetPassword, *MockRepositoryUser, *MockRepositorySession, *testCase)
+		revokeError error
+		revoke      bool
+		returnError error
+	}
+
+	passwordMatcher := mock.MatchedBy(func(hash string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte("new-password")) == nil
+	})
+
+	tests := []testCase{
+		{
+			name: "revokes all sessions",
+			setupModels: func(rf *MockRepositoryForgetPassword, ru *MockRepositoryUser, rs *MockRepositorySession, tc *testCase) {
+				rf.EXPECT().Delete("token").Return(ForgetPassword{Token: "token", UserID: userId}, nil)
+				ru.EXPECT().SetPassword(User{ID: userId}, passwordMatcher).Return(nil)
+				rs.EXPECT().GetByUser(userId).Return([]Session{{ID: "attacker"}, {ID: "current"}}, nil)
+				rs.EXPECT().Delete("attacker").Return(nil)
+				rs.EXPECT().Delete("current").Return(nil)
+			},
+			revoke: true,
+		},
+		{
+			name:        "keeps current session",
+			keepSession: "current",
+			setupModels: func(rf *MockRepositoryForgetPassword, ru *MockRepositoryUser, rs *MockRepositorySession, tc *testCase) {
+				rf.EXPECT().Delete("token").Return(ForgetPassword{Token: "token", UserID: userId}, nil)
+				ru.EXPECT().SetPassword(User{ID: userId}, passwordMatcher).Return(nil)
+				rs.EXPECT().GetByUser(userId).Return([]Session{{ID: "attacker"}, {ID: "current"}}, nil)
+				rs.EXPECT().Delete("attacker").Return(nil)
+			},
+			revoke: true,
+		},
+		{
+			name: "invalid token",
+			setupModels: func(rf *MockRepositoryForgetPassword, ru *MockRepositoryUser, rs *MockRepositorySession, tc *testCase) {
+				rf.EXPECT().Delete("token").Return(ForgetPassword{}, modelErrors.ErrNotFound)
+			},
+			returnError: ErrInvalidToken,
+		},
+		{
+			name: "revoking fails",
+			setupModels: func(rf *MockRepositoryForgetPassword, ru *MockRepositoryUser, rs *MockRepositorySession, tc *testCase) {
+				rf.EXPECT().Delete("token").Return(ForgetPassword{Token: "token", UserID: userId}, nil)
+				ru.EXPECT().SetPassword(User{ID: userId}, passwordMatcher).Return(nil)
+				rs.EXPECT().GetByUser(userId).Return(nil, errors.New("redis down"))
+			},
+			revoke:      true,
+			returnError: errors.New("unable to get sessions of user 452 redis down"),
+		},
+		{
+			name: "revoking refresh tokens fails",
+			setupModels: func(rf *MockRepositoryForgetPassword, ru *MockRepositoryUser, rs *MockRepositorySession, tc *testCase) {
+				rf.EXPECT().Delete("token").Return(ForgetPassword{Token: "token", UserID: userId}, nil)
+				ru.EXPECT().SetPassword(User{ID: userId}, passwordMatcher).Return(nil)
+			},
+			revoke:      true,
+			revokeError: errors.New("db down"),
+			returnError: errors.New("unable to revoke refresh tokens of user 452 db down"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mocks
+			repository := NewMockRepository(t)
+			forgetPasswordRepository := NewMockRepositoryForgetPassword(t)
+			userRepository := NewMockRepositoryUser(t)
+			sessionRepository := NewMockRepositorySession(t)
+			refreshTokenRepository := NewMockRepositoryRefreshToken(t)
+			apiKeyRepository := NewMockRepositoryAPIKey(t)
+
+			// Setup mocks
+			if tc.revoke {
+				repository.EXPECT().RefreshToken(context.TODO()).Return(refreshTokenRepository)
+				refreshTokenRepository.EXPECT().RevokeUser(userId).Return(tc.revokeError)
+
+				if tc.revokeError == nil {
+					repository.EXPECT().APIKey(context.TODO()).Return(apiKeyRepository)
+					apiKeyRepository.EXPECT().DeleteByUser(userId).Return(nil)
+				}
+			}
+
+			repository.EXPECT().Atomic(mock.Anything).RunAndReturn(func(f func(Repository) error) error {
+				return f(repository)
+			})
+			repository.EXPECT().ForgetPassword(context.TODO()).Return(forgetPasswordRepository)
+			repository.EXPECT().User(context.TODO()).Return(userRepository).Maybe()
+			repository.EXPECT().Session(context.TODO()).Return(sessionRepository).Maybe()
+			tc.setupModels(forgetPasswordRepository, userRepository, sessionRepository, &tc)
+
+			// Run
+			domain := NewDomain(repository, utils.Config{}, NewMockNotifier(t))
+			updated, err := domain.VerifyPasswordReset(setup, "token", "new-password", tc.keepSession)
+
+			// Assertions
+			if tc.returnError != nil {
+				require.ErrorContains(t, err, tc.returnError.Error())
+				require.Empty(t, updated.Password)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, userId, updated.ID)
+				require.NotEmpty(t, updated.Password)
+			}
+		})
+	}
+}
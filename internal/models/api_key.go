@@ -0,0 +1,127 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type APIKey struct {
+	ID        pgtype.Int8        `db:"id"`
+	CreatedAt pgtype.Timestamptz `db:"created_at"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at"`
+	UserID    pgtype.Int8        `db:"user_id"`
+	Name      pgtype.Text        `db:"name"`
+	Prefix    pgtype.Text        `db:"prefix"`
+	KeyHash   pgtype.Text        `db:"key_hash"`
+	Scopes    []string           `db:"scopes"`
+}
+
+type repositoryAPIKey struct {
+	ctx context.Context
+	db  query
+}
+
+func (ak *repositoryAPIKey) Create(key domain.APIKey) (created domain.APIKey, err error) {
+	now := time.Now()
+	expiresAt := pgtype.Timestamptz{Time: key.ExpiresAt, Valid: !key.ExpiresAt.IsZero()}
+
+	scopes := key.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	row := ak.db.QueryRow(
+		ak.ctx,
+		"insert into api_keys (created_at, expires_at, user_id, name, prefix, key_hash, scopes) values ($1, $2, $3, $4, $5, $6, $7) returning id",
+		now, expiresAt, key.UserID, key.Name, key.Prefix, key.KeyHash, scopes,
+	)
+
+	var id pgtype.Int8
+	err = row.Scan(&id)
+	if err != nil {
+		err = fmt.Errorf("model APIKey -> unable to create api key for user %d %w", key.UserID, err)
+		return
+	}
+
+	created = key
+	created.ID = uint64(id.Int64)
+	created.Scopes = scopes
+	created.CreatedAt = now
+
+	return
+}
+
+func (ak *repositoryAPIKey) GetByHash(hash string) (key domain.APIKey, err error) {
+	rows, err := ak.db.Query(ak.ctx, "select * from api_keys where key_hash = $1", hash)
+	if err != nil {
+		err = fmt.Errorf("model APIKey -> can not execute query %w", err)
+		return
+	}
+
+	modelKey, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[APIKey])
+
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("model APIKey -> find api key %w", err)
+		return
+	}
+
+	key = modelAPIKeyToDomainAPIKey(modelKey)
+
+	return
+}
+
+func (ak *repositoryAPIKey) GetByUser(userId uint64) (keys []domain.APIKey, err error) {
+	rows, err := ak.db.Query(ak.ctx, "select * from api_keys where user_id = $1 order by id", userId)
+	if err != nil {
+		err = fmt.Errorf("model APIKey -> can not execute query %w", err)
+		return
+	}
+
+	modelKeys, err := pgx.CollectRows(rows, pgx.RowToStructByName[APIKey])
+	if err != nil {
+		err = fmt.Errorf("model APIKey -> unable to read api keys of user %d %w", userId, err)
+		return
+	}
+
+	keys = make([]domain.APIKey, 0, len(modelKeys))
+	for _, modelKey := range modelKeys {
+		keys = append(keys, modelAPIKeyToDomainAPIKey(modelKey))
+	}
+
+	return
+}
+
+func (ak *repositoryAPIKey) Delete(userId uint64, id uint64) error {
+	result, err := ak.db.Exec(ak.ctx, "delete from api_keys where user_id = $1 and id = $2", userId, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete api key %d of user %d %w", id, userId, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return modelErrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (ak *repositoryAPIKey) DeleteByUser(userId uint64) error {
+	_, err := ak.db.Exec(ak.ctx, "delete from api_keys where user_id = $1", userId)
+	if err != nil {
+		return fmt.Errorf("failed to delete api keys of user %d %w", userId, err)
+	}
+
+	return nil
+}
+
+func newRepositoryAPIKey(ctx context.Context, db query) *repositoryAPIKey {
+	return &repositoryAPIKey{ctx: ctx, db: db}
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func newRandomAPIKey(userId uint64) domain.APIKey {
+	return domain.APIKey{
+		UserID:  userId,
+		Name:    "ci",
+		Prefix:  "ak_abcdefg",
+		KeyHash: uuid.NewString(),
+		Scopes:  []string{"/reports", "/wiki"},
+	}
+}
+
+func TestAPIKeyCreate(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryAPIKey(context.TODO(), dbConnection)
+	key := newRandomAPIKey(existingUser.ID)
+	key.ExpiresAt = time.Now().Add(time.Hour).Truncate(time.Microsecond)
+
+	created, err := repo.Create(key)
+	require.Nil(t, err)
+	require.NotZero(t, created.ID)
+
+	found, err := repo.GetByHash(key.KeyHash)
+	require.Nil(t, err)
+	require.Equal(t, created.ID, found.ID)
+	require.Equal(t, existingUser.ID, found.UserID)
+	require.Equal(t, key.Name, found.Name)
+	require.Equal(t, key.Prefix, found.Prefix)
+	require.Equal(t, key.Scopes, found.Scopes)
+	require.True(t, key.ExpiresAt.Equal(found.ExpiresAt))
+
+	_, err = repo.GetByHash(uuid.NewString())
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+}
+
+func TestAPIKeyWithoutExpiration(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryAPIKey(context.TODO(), dbConnection)
+	key := newRandomAPIKey(existingUser.ID)
+	key.Scopes = nil
+
+	_, err = repo.Create(key)
+	require.Nil(t, err)
+
+	found, err := repo.GetByHash(key.KeyHash)
+	require.Nil(t, err)
+	require.True(t, found.ExpiresAt.IsZero())
+	require.Empty(t, found.Scopes)
+}
+
+func TestAPIKeyGetByUserAndDelete(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	otherUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryAPIKey(context.TODO(), dbConnection)
+
+	first, err := repo.Create(newRandomAPIKey(existingUser.ID))
+	require.Nil(t, err, "failed to initialize db state")
+
+	second, err := repo.Create(newRandomAPIKey(existingUser.ID))
+	require.Nil(t, err, "failed to initialize db state")
+
+	_, err = repo.Create(newRandomAPIKey(otherUser.ID))
+	require.Nil(t, err, "failed to initialize db state")
+
+	keys, err := repo.GetByUser(existingUser.ID)
+	require.Nil(t, err)
+	require.Len(t, keys, 2)
+	require.Equal(t, first.ID, keys[0].ID)
+	require.Equal(t, second.ID, keys[1].ID)
+
+	// keys of other users can't be deleted
+	err = repo.Delete(otherUser.ID, first.ID)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+
+	err = repo.Delete(existingUser.ID, first.ID)
+	require.Nil(t, err)
+
+	keys, err = repo.GetByUser(existingUser.ID)
+	require.Nil(t, err)
+	require.Len(t, keys, 1)
+	require.Equal(t, second.ID, keys[0].ID)
+}
+
+func TestAPIKeyDeleteByUser(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	otherUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryAPIKey(context.TODO(), dbConnection)
+
+	for i := 0; i < 2; i++ {
+		_, err = repo.Create(newRandomAPIKey(existingUser.ID))
+		require.Nil(t, err, "failed to initialize db state")
+	}
+
+	other, err := repo.Create(newRandomAPIKey(otherUser.ID))
+	require.Nil(t, err, "failed to initialize db state")
+
+	err = repo.DeleteByUser(existingUser.ID)
+	require.Nil(t, err)
+
+	keys, err := repo.GetByUser(existingUser.ID)
+	require.Nil(t, err)
+	require.Empty(t, keys)
+
+	_, err = repo.GetByHash(other.KeyHash)
+	require.Nil(t, err)
+}
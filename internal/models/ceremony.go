@@ -0,0 +1,52 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const ceremonyPrefix = "ceremony:"
+
+type repositoryCeremony struct {
+	ctx   context.Context
+	redis *redis.Client
+}
+
+func (c *repositoryCeremony) Create(data []byte) (key string, err error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		err = fmt.Errorf("unable to generate key for ceremony %w", err)
+		return
+	}
+
+	if cmd := c.redis.Set(c.ctx, ceremonyPrefix+id.String(), data, utils.WEBAUTHN_CEREMONY_TTL); cmd.Err() != nil {
+		err = fmt.Errorf("unable to store ceremony in redis %w", cmd.Err())
+		return
+	}
+
+	key = id.String()
+	return
+}
+
+// Take returns ceremony data and removes it so every ceremony can be finished only once
+func (c *repositoryCeremony) Take(key string) (data []byte, err error) {
+	data, err = c.redis.GetDel(c.ctx, ceremonyPrefix+key).Bytes()
+	if err == redis.Nil {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("unable to get ceremony %s %w", key, err)
+		return
+	}
+
+	return
+}
+
+func newRepositoryCeremony(ctx context.Context, redis *redis.Client) *repositoryCeremony {
+	return &repositoryCeremony{ctx: ctx, redis: redis}
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// repositoryCeremonyPostgres keeps passkey ceremonies in the database for deployments without Redis
+type repositoryCeremonyPostgres struct {
+	ctx context.Context
+	db  query
+}
+
+func (c *repositoryCeremonyPostgres) Create(data []byte) (key string, err error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		err = fmt.Errorf("unable to generate key for ceremony %w", err)
+		return
+	}
+
+	// ceremonies are not tied to users so abandoned ones are removed by whoever starts the next one
+	_, err = c.db.Exec(c.ctx, "delete from ceremonies where expires_at <= now()")
+	if err != nil {
+		err = fmt.Errorf("unable to delete expired ceremonies %w", err)
+		return
+	}
+
+	_, err = c.db.Exec(
+		c.ctx,
+		"insert into ceremonies (key, data, expires_at) values ($1, $2, $3)",
+		id.String(), data, time.Now().Add(utils.WEBAUTHN_CEREMONY_TTL),
+	)
+	if err != nil {
+		err = fmt.Errorf("unable to store ceremony %w", err)
+		return
+	}
+
+	key = id.String()
+	return
+}
+
+// Take returns ceremony data and removes it so every ceremony can be finished only once
+func (c *repositoryCeremonyPostgres) Take(key string) (data []byte, err error) {
+	row := c.db.QueryRow(c.ctx, "delete from ceremonies where key = $1 and expires_at > now() returning data", key)
+
+	err = row.Scan(&data)
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("unable to get ceremony %s %w", key, err)
+		return
+	}
+
+	return
+}
+
+func newRepositoryCeremonyPostgres(ctx context.Context, db query) *repositoryCeremonyPostgres {
+	return &repositoryCeremonyPostgres{ctx: ctx, db: db}
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCeremonyPostgres(t *testing.T) {
+	repo := newRepositoryCeremonyPostgres(context.TODO(), dbConnection)
+
+	key, err := repo.Create([]byte(`{"challenge":"abc"}`))
+	require.Nil(t, err)
+	require.NotEmpty(t, key)
+
+	data, err := repo.Take(key)
+	require.Nil(t, err)
+	require.Equal(t, []byte(`{"challenge":"abc"}`), data)
+
+	_, err = repo.Take(key)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+
+	key, err = repo.Create([]byte("expired"))
+	require.Nil(t, err)
+
+	_, err = dbConnection.Exec(context.TODO(), "update ceremonies set expires_at = now() - interval '1 second' where key = $1", key)
+	require.Nil(t, err)
+
+	_, err = repo.Take(key)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+}
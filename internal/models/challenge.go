@@ -0,0 +1,102 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const challengePrefix = "challenge:"
+
+type repositoryChallenge struct {
+	ctx   context.Context
+	redis *redis.Client
+}
+
+func (c *repositoryChallenge) Create(user domain.User) (challenge domain.Challenge, err error) {
+	key, err := uuid.NewRandom()
+	if err != nil {
+		err = fmt.Errorf("unable to generate key for challenge %w", err)
+		return
+	}
+
+	values := []string{
+		"user_id", fmt.Sprintf("%d", user.ID),
+		"attempts", "0",
+	}
+
+	redisKey := challengePrefix + key.String()
+
+	if cmd := c.redis.HSet(c.ctx, redisKey, values); cmd.Err() != nil {
+		err = fmt.Errorf("unable to store challenge for user %d in redis %w", user.ID, cmd.Err())
+		return
+	}
+
+	if res := c.redis.Expire(c.ctx, redisKey, utils.MFA_CHALLENGE_TTL); res.Err() != nil {
+		err = fmt.Errorf("unable to set expiration to challenge %s", key)
+		return
+	}
+
+	challenge.ID = key.String()
+	challenge.UserID = user.ID
+
+	return
+}
+
+func (c *repositoryChallenge) Get(key string) (challenge domain.Challenge, err error) {
+	result, err := c.redis.HGetAll(c.ctx, challengePrefix+key).Result()
+	if err != nil {
+		err = fmt.Errorf("unable to get challenge %s %w", key, err)
+		return
+	}
+
+	if len(result) == 0 {
+		err = modelErrors.ErrNotFound
+		return
+	}
+
+	userId, err := strconv.ParseUint(result["user_id"], 10, 64)
+	if err != nil {
+		err = fmt.Errorf("invalid value in challenge as user id %s %w", result["user_id"], err)
+		return
+	}
+
+	attempts, err := strconv.ParseInt(result["attempts"], 10, 64)
+	if err != nil {
+		err = fmt.Errorf("invalid value in challenge as attempts %s %w", result["attempts"], err)
+		return
+	}
+
+	challenge.ID = key
+	challenge.UserID = userId
+	challenge.Attempts = attempts
+
+	return
+}
+
+func (c *repositoryChallenge) Attempt(key string) (attempts int64, err error) {
+	attempts, err = c.redis.HIncrBy(c.ctx, challengePrefix+key, "attempts", 1).Result()
+	if err != nil {
+		err = fmt.Errorf("unable to increment attempts for challenge %s %w", key, err)
+	}
+
+	return
+}
+
+func (c *repositoryChallenge) Delete(key string) error {
+	if cmd := c.redis.Del(c.ctx, challengePrefix+key); cmd.Err() != nil {
+		return fmt.Errorf("unable to delete challenge %s %w", key, cmd.Err())
+	}
+
+	return nil
+}
+
+func newRepositoryChallenge(ctx context.Context, redis *redis.Client) *repositoryChallenge {
+	return &repositoryChallenge{ctx: ctx, redis: redis}
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// repositoryChallengePostgres keeps second factor challenges in the database for deployments without Redis
+type repositoryChallengePostgres struct {
+	ctx context.Context
+	db  query
+}
+
+func (c *repositoryChallengePostgres) Create(user domain.User) (challenge domain.Challenge, err error) {
+	key, err := uuid.NewRandom()
+	if err != nil {
+		err = fmt.Errorf("unable to generate key for challenge %w", err)
+		return
+	}
+
+	_, err = c.db.Exec(c.ctx, "delete from challenges where user_id = $1 and expires_at <= now()", user.ID)
+	if err != nil {
+		err = fmt.Errorf("unable to delete expired challenges of user %d %w", user.ID, err)
+		return
+	}
+
+	_, err = c.db.Exec(
+		c.ctx,
+		"insert into challenges (key, user_id, expires_at) values ($1, $2, $3)",
+		key.String(), user.ID, time.Now().Add(utils.MFA_CHALLENGE_TTL),
+	)
+	if err != nil {
+		err = fmt.Errorf("unable to store challenge for user %d %w", user.ID, err)
+		return
+	}
+
+	challenge.ID = key.String()
+	challenge.UserID = user.ID
+
+	return
+}
+
+func (c *repositoryChallengePostgres) Get(key string) (challenge domain.Challenge, err error) {
+	row := c.db.QueryRow(c.ctx, "select user_id, attempts from challenges where key = $1 and expires_at > now()", key)
+
+	var userId, attempts pgtype.Int8
+	err = row.Scan(&userId, &attempts)
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("unable to get challenge %s %w", key, err)
+		return
+	}
+
+	challenge.ID = key
+	challenge.UserID = uint64(userId.Int64)
+	challenge.Attempts = attempts.Int64
+
+	return
+}
+
+func (c *repositoryChallengePostgres) Attempt(key string) (attempts int64, err error) {
+	row := c.db.QueryRow(c.ctx, "update challenges set attempts = attempts + 1 where key = $1 and expires_at > now() returning attempts", key)
+
+	err = row.Scan(&attempts)
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+	} else if err != nil {
+		err = fmt.Errorf("unable to increment attempts for challenge %s %w", key, err)
+	}
+
+	return
+}
+
+func (c *repositoryChallengePostgres) Delete(key string) error {
+	_, err := c.db.Exec(c.ctx, "delete from challenges where key = $1", key)
+	if err != nil {
+		return fmt.Errorf("unable to delete challenge %s %w", key, err)
+	}
+
+	return nil
+}
+
+func newRepositoryChallengePostgres(ctx context.Context, db query) *repositoryChallengePostgres {
+	return &repositoryChallengePostgres{ctx: ctx, db: db}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallengePostgres(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryChallengePostgres(context.TODO(), dbConnection)
+
+	challenge, err := repo.Create(existingUser)
+	require.Nil(t, err)
+	require.NotEmpty(t, challenge.ID)
+
+	attempts, err := repo.Attempt(challenge.ID)
+	require.Nil(t, err)
+	require.Equal(t, int64(1), attempts)
+
+	found, err := repo.Get(challenge.ID)
+	require.Nil(t, err)
+	require.Equal(t, existingUser.ID, found.UserID)
+	require.Equal(t, int64(1), found.Attempts)
+
+	err = repo.Delete(challenge.ID)
+	require.Nil(t, err)
+
+	_, err = repo.Get(challenge.ID)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+
+	_, err = repo.Attempt(challenge.ID)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/jackc/pgx/v5"
+)
+
+type repositoryConsent struct {
+	ctx context.Context
+	db  query
+}
+
+func (c *repositoryConsent) Get(userId uint64, clientId string) (consent domain.Consent, err error) {
+	row := c.db.QueryRow(
+		c.ctx,
+		"select scopes from oauth_consents where user_id = $1 and client_id = $2",
+		userId, clientId,
+	)
+
+	var scopes []string
+	err = row.Scan(&scopes)
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("model Consent -> find consent of user %d for %s %w", userId, clientId, err)
+		return
+	}
+
+	consent.UserID = userId
+	consent.ClientID = clientId
+	consent.Scopes = scopes
+
+	return
+}
+
+// Save stores consent replacing previously granted scopes
+func (c *repositoryConsent) Save(consent domain.Consent) error {
+	now := time.Now()
+
+	_, err := c.db.Exec(
+		c.ctx,
+		`insert into oauth_consents (created_at, updated_at, user_id, client_id, scopes) values ($1, $1, $2, $3, $4)
+		on conflict (user_id, client_id) do update set scopes = excluded.scopes, updated_at = excluded.updated_at`,
+		now, consent.UserID, consent.ClientID, consent.Scopes,
+	)
+
+	if err != nil {
+		return fmt.Errorf("model Consent -> unable to store consent of user %d for %s %w", consent.UserID, consent.ClientID, err)
+	}
+
+	return nil
+}
+
+func newRepositoryConsent(ctx context.Context, db query) *repositoryConsent {
+	return &repositoryConsent{ctx: ctx, db: db}
+}
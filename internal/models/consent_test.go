@@ -0,0 +1,40 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsentSave(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	client, err := storeOAuthClient(newRandomOAuthClient())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryConsent(context.TODO(), dbConnection)
+
+	_, err = repo.Get(existingUser.ID, client.ID)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+
+	consent := domain.Consent{UserID: existingUser.ID, ClientID: client.ID, Scopes: []string{"openid"}}
+	err = repo.Save(consent)
+	require.Nil(t, err)
+
+	found, err := repo.Get(existingUser.ID, client.ID)
+	require.Nil(t, err)
+	require.Equal(t, consent, found)
+
+	// saving again replaces granted scopes
+	consent.Scopes = []string{"openid", "email"}
+	err = repo.Save(consent)
+	require.Nil(t, err)
+
+	found, err = repo.Get(existingUser.ID, client.ID)
+	require.Nil(t, err)
+	require.Equal(t, consent, found)
+}
@@ -0,0 +1,222 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const deviceCodePrefix = "device:"
+const userCodePrefix = "device_user:"
+
+// scripts update device authorization only while it exists so updates
+// racing with expiration don't leave keys without ttl behind
+var pollScript = redis.NewScript(`
+if redis.call('exists', KEYS[1]) == 0 then return false end
+local previous = redis.call('hget', KEYS[1], 'polled_at') or ''
+redis.call('hset', KEYS[1], 'polled_at', ARGV[1])
+return previous
+`)
+
+var slowDownScript = redis.NewScript(`
+if redis.call('exists', KEYS[1]) == 0 then return false end
+return redis.call('hincrby', KEYS[1], 'interval', ARGV[1])
+`)
+
+var decideScript = redis.NewScript(`
+if redis.call('exists', KEYS[1]) == 0 then return false end
+redis.call('hset', KEYS[1], 'status', ARGV[1], 'user_id', ARGV[2], 'decided_at', ARGV[3])
+return 1
+`)
+
+type repositoryDeviceAuthorization struct {
+	ctx   context.Context
+	redis *redis.Client
+}
+
+func (da *repositoryDeviceAuthorization) Create(device domain.DeviceAuthorization) (created domain.DeviceAuthorization, err error) {
+	key, err := uuid.NewRandom()
+	if err != nil {
+		err = fmt.Errorf("unable to generate device code %w", err)
+		return
+	}
+
+	deviceCode := key.String()
+
+	ok, err := da.redis.SetNX(da.ctx, userCodePrefix+device.UserCode, deviceCode, utils.DEVICE_CODE_TTL).Result()
+	if err != nil {
+		err = fmt.Errorf("unable to store user code in redis %w", err)
+		return
+	} else if !ok {
+		err = fmt.Errorf("user code %s is already in use", device.UserCode)
+		return
+	}
+
+	values := []string{
+		"client_id", device.ClientID,
+		"scope", device.Scope,
+		"user_code", device.UserCode,
+		"status", device.Status,
+		"user_id", "0",
+		"interval", strconv.FormatInt(device.Interval, 10),
+	}
+
+	redisKey := deviceCodePrefix + deviceCode
+
+	if cmd := da.redis.HSet(da.ctx, redisKey, values); cmd.Err() != nil {
+		err = fmt.Errorf("unable to store device authorization in redis %w", cmd.Err())
+		return
+	}
+
+	if res := da.redis.Expire(da.ctx, redisKey, utils.DEVICE_CODE_TTL); res.Err() != nil {
+		err = fmt.Errorf("unable to set expiration to device authorization %s", deviceCode)
+		return
+	}
+
+	created = device
+	created.DeviceCode = deviceCode
+
+	return
+}
+
+func (da *repositoryDeviceAuthorization) Get(deviceCode string) (device domain.DeviceAuthorization, err error) {
+	result, err := da.redis.HGetAll(da.ctx, deviceCodePrefix+deviceCode).Result()
+	if err != nil {
+		err = fmt.Errorf("unable to get device authorization %s %w", deviceCode, err)
+		return
+	}
+
+	if len(result) == 0 {
+		err = modelErrors.ErrNotFound
+		return
+	}
+
+	userId, err := strconv.ParseUint(result["user_id"], 10, 64)
+	if err != nil {
+		err = fmt.Errorf("invalid value in device authorization as user id %s %w", result["user_id"], err)
+		return
+	}
+
+	interval, err := strconv.ParseInt(result["interval"], 10, 64)
+	if err != nil {
+		err = fmt.Errorf("invalid value in device authorization as interval %s %w", result["interval"], err)
+		return
+	}
+
+	if decidedAt := result["decided_at"]; decidedAt != "" {
+		unix, e := strconv.ParseInt(decidedAt, 10, 64)
+		if e != nil {
+			err = fmt.Errorf("invalid value in device authorization as decided at %s %w", decidedAt, e)
+			return
+		}
+
+		device.DecidedAt = time.Unix(unix, 0)
+	}
+
+	device.DeviceCode = deviceCode
+	device.UserCode = result["user_code"]
+	device.ClientID = result["client_id"]
+	device.Scope = result["scope"]
+	device.Status = result["status"]
+	device.UserID = userId
+	device.Interval = interval
+
+	return
+}
+
+func (da *repositoryDeviceAuthorization) GetByUserCode(userCode string) (device domain.DeviceAuthorization, err error) {
+	deviceCode, err := da.redis.Get(da.ctx, userCodePrefix+userCode).Result()
+	if err == redis.Nil {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("unable to get user code %s %w", userCode, err)
+		return
+	}
+
+	return da.Get(deviceCode)
+}
+
+// Poll records time client polled for the token and returns time of the previous poll
+func (da *repositoryDeviceAuthorization) Poll(deviceCode string, at time.Time) (previous time.Time, err error) {
+	result, err := pollScript.Run(da.ctx, da.redis, []string{deviceCodePrefix + deviceCode}, at.UnixMilli()).Text()
+	if err == redis.Nil {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("unable to poll device authorization %s %w", deviceCode, err)
+		return
+	}
+
+	if result == "" {
+		return
+	}
+
+	milli, err := strconv.ParseInt(result, 10, 64)
+	if err != nil {
+		err = fmt.Errorf("invalid value in device authorization as polled at %s %w", result, err)
+		return
+	}
+
+	previous = time.UnixMilli(milli)
+
+	return
+}
+
+func (da *repositoryDeviceAuthorization) SlowDown(deviceCode string, by int64) (interval int64, err error) {
+	interval, err = slowDownScript.Run(da.ctx, da.redis, []string{deviceCodePrefix + deviceCode}, by).Int64()
+	if err == redis.Nil {
+		err = modelErrors.ErrNotFound
+	} else if err != nil {
+		err = fmt.Errorf("unable to increase interval of device authorization %s %w", deviceCode, err)
+	}
+
+	return
+}
+
+func (da *repositoryDeviceAuthorization) Decide(deviceCode string, status string, userId uint64) error {
+	keys := []string{deviceCodePrefix + deviceCode}
+
+	err := decideScript.Run(da.ctx, da.redis, keys, status, userId, time.Now().Unix()).Err()
+	if err == redis.Nil {
+		return modelErrors.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("unable to store decision for device authorization %s %w", deviceCode, err)
+	}
+
+	return nil
+}
+
+// Delete removes device authorization, only one of concurrent calls succeeds
+func (da *repositoryDeviceAuthorization) Delete(deviceCode string) error {
+	redisKey := deviceCodePrefix + deviceCode
+
+	userCode, err := da.redis.HGet(da.ctx, redisKey, "user_code").Result()
+	if err == redis.Nil {
+		return modelErrors.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("unable to get device authorization %s %w", deviceCode, err)
+	}
+
+	deleted, err := da.redis.Del(da.ctx, redisKey, userCodePrefix+userCode).Result()
+	if err != nil {
+		return fmt.Errorf("unable to delete device authorization %s %w", deviceCode, err)
+	}
+
+	if deleted == 0 {
+		return modelErrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func newRepositoryDeviceAuthorization(ctx context.Context, redis *redis.Client) *repositoryDeviceAuthorization {
+	return &repositoryDeviceAuthorization{ctx: ctx, redis: redis}
+}
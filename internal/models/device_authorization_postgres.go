@@ -0,0 +1,184 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type DeviceAuthorization struct {
+	DeviceCode   pgtype.Text        `db:"device_code"`
+	UserCode     pgtype.Text        `db:"user_code"`
+	ClientID     pgtype.Text        `db:"client_id"`
+	Scope        pgtype.Text        `db:"scope"`
+	Status       pgtype.Text        `db:"status"`
+	UserID       pgtype.Int8        `db:"user_id"`
+	PollInterval pgtype.Int8        `db:"poll_interval"`
+	PolledAt     pgtype.Timestamptz `db:"polled_at"`
+	DecidedAt    pgtype.Timestamptz `db:"decided_at"`
+	ExpiresAt    pgtype.Timestamptz `db:"expires_at"`
+}
+
+// repositoryDeviceAuthorizationPostgres keeps device authorizations in the database for deployments without Redis
+type repositoryDeviceAuthorizationPostgres struct {
+	ctx context.Context
+	db  query
+}
+
+func (da *repositoryDeviceAuthorizationPostgres) Create(device domain.DeviceAuthorization) (created domain.DeviceAuthorization, err error) {
+	key, err := uuid.NewRandom()
+	if err != nil {
+		err = fmt.Errorf("unable to generate device code %w", err)
+		return
+	}
+
+	deviceCode := key.String()
+
+	// expired authorizations would keep their user codes taken
+	_, err = da.db.Exec(da.ctx, "delete from device_authorizations where expires_at <= now()")
+	if err != nil {
+		err = fmt.Errorf("unable to delete expired device authorizations %w", err)
+		return
+	}
+
+	row := da.db.QueryRow(
+		da.ctx,
+		`insert into device_authorizations (device_code, user_code, client_id, scope, status, poll_interval, expires_at)
+		values ($1, $2, $3, $4, $5, $6, $7) on conflict (user_code) do nothing returning device_code`,
+		deviceCode, device.UserCode, device.ClientID, device.Scope, device.Status, device.Interval, time.Now().Add(utils.DEVICE_CODE_TTL),
+	)
+
+	var stored pgtype.Text
+	err = row.Scan(&stored)
+	if err == pgx.ErrNoRows {
+		err = fmt.Errorf("user code %s is already in use", device.UserCode)
+		return
+	} else if err != nil {
+		err = fmt.Errorf("unable to store device authorization %w", err)
+		return
+	}
+
+	created = device
+	created.DeviceCode = deviceCode
+
+	return
+}
+
+func (da *repositoryDeviceAuthorizationPostgres) Get(deviceCode string) (device domain.DeviceAuthorization, err error) {
+	return da.find("device_code", deviceCode)
+}
+
+func (da *repositoryDeviceAuthorizationPostgres) GetByUserCode(userCode string) (device domain.DeviceAuthorization, err error) {
+	return da.find("user_code", userCode)
+}
+
+// Poll records time client polled for the token and returns time of the previous poll
+func (da *repositoryDeviceAuthorizationPostgres) Poll(deviceCode string, at time.Time) (previous time.Time, err error) {
+	row := da.db.QueryRow(
+		da.ctx,
+		`update device_authorizations d set polled_at = $2 from device_authorizations old
+		where d.device_code = $1 and old.device_code = d.device_code and d.expires_at > now()
+		returning old.polled_at`,
+		deviceCode, at,
+	)
+
+	var polledAt pgtype.Timestamptz
+	err = row.Scan(&polledAt)
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("unable to poll device authorization %s %w", deviceCode, err)
+		return
+	}
+
+	if polledAt.Valid {
+		previous = polledAt.Time
+	}
+
+	return
+}
+
+func (da *repositoryDeviceAuthorizationPostgres) SlowDown(deviceCode string, by int64) (interval int64, err error) {
+	row := da.db.QueryRow(
+		da.ctx,
+		"update device_authorizations set poll_interval = poll_interval + $2 where device_code = $1 and expires_at > now() returning poll_interval",
+		deviceCode, by,
+	)
+
+	err = row.Scan(&interval)
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+	} else if err != nil {
+		err = fmt.Errorf("unable to increase interval of device authorization %s %w", deviceCode, err)
+	}
+
+	return
+}
+
+func (da *repositoryDeviceAuthorizationPostgres) Decide(deviceCode string, status string, userId uint64) error {
+	result, err := da.db.Exec(
+		da.ctx,
+		"update device_authorizations set status = $2, user_id = $3, decided_at = $4 where device_code = $1 and expires_at > now()",
+		deviceCode, status, userId, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("unable to store decision for device authorization %s %w", deviceCode, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return modelErrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete removes device authorization, only one of concurrent calls succeeds
+func (da *repositoryDeviceAuthorizationPostgres) Delete(deviceCode string) error {
+	result, err := da.db.Exec(da.ctx, "delete from device_authorizations where device_code = $1 and expires_at > now()", deviceCode)
+	if err != nil {
+		return fmt.Errorf("unable to delete device authorization %s %w", deviceCode, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return modelErrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (da *repositoryDeviceAuthorizationPostgres) find(column string, code string) (device domain.DeviceAuthorization, err error) {
+	rows, err := da.db.Query(
+		da.ctx,
+		fmt.Sprintf("select * from device_authorizations where %s = $1 and expires_at > now()", column),
+		code,
+	)
+	if err != nil {
+		err = fmt.Errorf("unable to get device authorization %s %w", code, err)
+		return
+	}
+
+	stored, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[DeviceAuthorization])
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("unable to get device authorization %s %w", code, err)
+		return
+	}
+
+	device = modelDeviceAuthorizationToDomainDeviceAuthorization(stored)
+
+	return
+}
+
+func newRepositoryDeviceAuthorizationPostgres(ctx context.Context, db query) *repositoryDeviceAuthorizationPostgres {
+	return &repositoryDeviceAuthorizationPostgres{ctx: ctx, db: db}
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceAuthorizationPostgres(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryDeviceAuthorizationPostgres(context.TODO(), dbConnection)
+	device := domain.DeviceAuthorization{
+		UserCode: uuid.NewString()[:8],
+		ClientID: "cli",
+		Scope:    "openid",
+		Status:   "pending",
+		Interval: 5,
+	}
+
+	created, err := repo.Create(device)
+	require.Nil(t, err)
+	require.NotEmpty(t, created.DeviceCode)
+
+	_, err = repo.Create(device)
+	require.NotNil(t, err, "user code is already in use")
+
+	found, err := repo.GetByUserCode(device.UserCode)
+	require.Nil(t, err)
+	require.Equal(t, created.DeviceCode, found.DeviceCode)
+	require.Equal(t, "cli", found.ClientID)
+	require.Equal(t, int64(5), found.Interval)
+	require.True(t, found.DecidedAt.IsZero())
+
+	polledAt := time.Now().Truncate(time.Microsecond)
+
+	previous, err := repo.Poll(created.DeviceCode, polledAt)
+	require.Nil(t, err)
+	require.True(t, previous.IsZero())
+
+	previous, err = repo.Poll(created.DeviceCode, polledAt.Add(time.Second))
+	require.Nil(t, err)
+	require.True(t, polledAt.Equal(previous))
+
+	interval, err := repo.SlowDown(created.DeviceCode, 5)
+	require.Nil(t, err)
+	require.Equal(t, int64(10), interval)
+
+	err = repo.Decide(created.DeviceCode, "approved", existingUser.ID)
+	require.Nil(t, err)
+
+	found, err = repo.Get(created.DeviceCode)
+	require.Nil(t, err)
+	require.Equal(t, "approved", found.Status)
+	require.Equal(t, existingUser.ID, found.UserID)
+	require.False(t, found.DecidedAt.IsZero())
+
+	err = repo.Delete(created.DeviceCode)
+	require.Nil(t, err)
+
+	err = repo.Delete(created.DeviceCode)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+
+	_, err = repo.Poll(created.DeviceCode, polledAt)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+}
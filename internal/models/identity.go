@@ -0,0 +1,115 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Identity struct {
+	ID        pgtype.Int8        `db:"id"`
+	CreatedAt pgtype.Timestamptz `db:"created_at"`
+	UserID    pgtype.Int8        `db:"user_id"`
+	Provider  pgtype.Text        `db:"provider"`
+	Subject   pgtype.Text        `db:"subject"`
+	Email     pgtype.Text        `db:"email"`
+}
+
+type repositoryIdentity struct {
+	ctx context.Context
+	db  query
+}
+
+func (i *repositoryIdentity) Create(identity domain.Identity) (created domain.Identity, err error) {
+	row := i.db.QueryRow(
+		i.ctx,
+		"insert into user_identities (created_at, user_id, provider, subject, email) values ($1, $2, $3, $4, $5) returning id",
+		time.Now(), identity.UserID, identity.Provider, identity.Subject, identity.Email,
+	)
+
+	var id pgtype.Int8
+	err = row.Scan(&id)
+	if err != nil {
+		err = fmt.Errorf("model Identity -> unable to link %s identity to user %d %w", identity.Provider, identity.UserID, err)
+		return
+	}
+
+	created = identity
+	created.ID = uint64(id.Int64)
+
+	return
+}
+
+func (i *repositoryIdentity) Get(provider string, subject string) (identity domain.Identity, err error) {
+	rows, err := i.db.Query(
+		i.ctx,
+		"select * from user_identities where provider = $1 and subject = $2",
+		provider, subject,
+	)
+	if err != nil {
+		err = fmt.Errorf("model Identity -> can not execute query %w", err)
+		return
+	}
+
+	modelIdentity, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[Identity])
+
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("model Identity -> find %s identity %s %w", provider, subject, err)
+		return
+	}
+
+	identity = modelIdentityToDomainIdentity(modelIdentity)
+
+	return
+}
+
+func (i *repositoryIdentity) GetByUser(userId uint64) (identities []domain.Identity, err error) {
+	rows, err := i.db.Query(i.ctx, "select * from user_identities where user_id = $1 order by id", userId)
+	if err != nil {
+		err = fmt.Errorf("model Identity -> can not execute query %w", err)
+		return
+	}
+
+	modelIdentities, err := pgx.CollectRows(rows, pgx.RowToStructByName[Identity])
+	if err != nil {
+		err = fmt.Errorf("model Identity -> unable to read identities of user %d %w", userId, err)
+		return
+	}
+
+	identities = make([]domain.Identity, 0, len(modelIdentities))
+	for _, modelIdentity := range modelIdentities {
+		identities = append(identities, modelIdentityToDomainIdentity(modelIdentity))
+	}
+
+	return
+}
+
+func (i *repositoryIdentity) Delete(userId uint64, provider string) error {
+	result, err := i.db.Exec(
+		i.ctx,
+		"delete from user_identities where user_id = $1 and provider = $2",
+		userId, provider,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to unlink %s identity of user %d %w", provider, userId, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return modelErrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func newRepositoryIdentity(ctx context.Context, db query) *repositoryIdentity {
+	return &repositoryIdentity{ctx: ctx, db: db}
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityCreate(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	otherUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryIdentity(context.TODO(), dbConnection)
+	identity := domain.Identity{UserID: existingUser.ID, Provider: "google", Subject: uuid.NewString(), Email: existingUser.Email}
+
+	created, err := repo.Create(identity)
+	require.Nil(t, err)
+	require.NotZero(t, created.ID)
+
+	// same subject can't be linked to another user
+	_, err = repo.Create(domain.Identity{UserID: otherUser.ID, Provider: "google", Subject: identity.Subject})
+	require.NotNil(t, err)
+
+	// user can have only one identity per provider
+	_, err = repo.Create(domain.Identity{UserID: existingUser.ID, Provider: "google", Subject: uuid.NewString()})
+	require.NotNil(t, err)
+
+	found, err := repo.Get("google", identity.Subject)
+	require.Nil(t, err)
+	require.Equal(t, found, created)
+
+	_, err = repo.Get("gitlab", identity.Subject)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+}
+
+func TestIdentityGetByUser(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryIdentity(context.TODO(), dbConnection)
+
+	for _, provider := range []string{"google", "gitlab"} {
+		_, err = repo.Create(domain.Identity{UserID: existingUser.ID, Provider: provider, Subject: uuid.NewString()})
+		require.Nil(t, err, "failed to initialize db state")
+	}
+
+	identities, err := repo.GetByUser(existingUser.ID)
+	require.Nil(t, err)
+	require.Len(t, identities, 2)
+	require.Equal(t, identities[0].Provider, "google")
+	require.Equal(t, identities[1].Provider, "gitlab")
+
+	identities, err = repo.GetByUser(nonExistingUserID)
+	require.Nil(t, err)
+	require.Empty(t, identities)
+}
+
+func TestIdentityDelete(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryIdentity(context.TODO(), dbConnection)
+
+	_, err = repo.Create(domain.Identity{UserID: existingUser.ID, Provider: "google", Subject: uuid.NewString()})
+	require.Nil(t, err, "failed to initialize db state")
+
+	err = repo.Delete(existingUser.ID, "google")
+	require.Nil(t, err)
+
+	err = repo.Delete(existingUser.ID, "google")
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+}
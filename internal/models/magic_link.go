@@ -0,0 +1,74 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type repositoryMagicLink struct {
+	ctx context.Context
+	db  query
+}
+
+func (ml *repositoryMagicLink) Create(token string, userId uint64, expiresAt time.Time) (link domain.MagicLink, err error) {
+	// only the latest requested link stays valid
+	_, err = ml.db.Exec(ml.ctx, "delete from magic_links where user_id = $1", userId)
+	if err != nil {
+		err = fmt.Errorf("failed to delete previous login links for user %d %w", userId, err)
+		return
+	}
+
+	row := ml.db.QueryRow(
+		ml.ctx,
+		"insert into magic_links (created_at, expires_at, token, user_id) values ($1, $2, $3, $4) returning id",
+		time.Now(), expiresAt, token, userId,
+	)
+
+	var id pgtype.Int8
+	err = row.Scan(&id)
+	if err != nil {
+		err = fmt.Errorf("failed to create login link for user %d %w", userId, err)
+		return
+	}
+
+	link.ID = uint64(id.Int64)
+	link.Token = token
+	link.UserID = userId
+
+	return
+}
+
+// Use deletes link in the same statement it's read with so it can't be used twice
+func (ml *repositoryMagicLink) Use(token string) (link domain.MagicLink, err error) {
+	row := ml.db.QueryRow(
+		ml.ctx,
+		"delete from magic_links where token = $1 and expires_at > $2 returning id, user_id",
+		token, time.Now(),
+	)
+
+	var id, userId pgtype.Int8
+	err = row.Scan(&id, &userId)
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("failed to use login link %w", err)
+		return
+	}
+
+	link.ID = uint64(id.Int64)
+	link.Token = token
+	link.UserID = uint64(userId.Int64)
+
+	return
+}
+
+func newRepositoryMagicLink(ctx context.Context, db query) *repositoryMagicLink {
+	return &repositoryMagicLink{ctx: ctx, db: db}
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMagicLinkCreate(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryMagicLink(context.TODO(), dbConnection)
+
+	first, err := repo.Create(uuid.NewString(), existingUser.ID, time.Now().Add(time.Hour))
+	require.Nil(t, err)
+	require.NotZero(t, first.ID)
+
+	second, err := repo.Create(uuid.NewString(), existingUser.ID, time.Now().Add(time.Hour))
+	require.Nil(t, err)
+
+	// requesting a new link invalidates the previous one
+	_, err = repo.Use(first.Token)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+
+	used, err := repo.Use(second.Token)
+	require.Nil(t, err)
+	require.Equal(t, used.UserID, existingUser.ID)
+}
+
+func TestMagicLinkUse(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	expiredUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryMagicLink(context.TODO(), dbConnection)
+
+	valid, err := repo.Create(uuid.NewString(), existingUser.ID, time.Now().Add(time.Hour))
+	require.Nil(t, err, "failed to initialize db state")
+
+	expired, err := repo.Create(uuid.NewString(), expiredUser.ID, time.Now().Add(-time.Minute))
+	require.Nil(t, err, "failed to initialize db state")
+
+	type testCase struct {
+		name        string
+		token       string
+		resultError error
+	}
+
+	tests := []testCase{
+		{
+			name:  "valid link",
+			token: valid.Token,
+		},
+		{
+			name:        "already used",
+			token:       valid.Token,
+			resultError: modelErrors.ErrNotFound,
+		},
+		{
+			name:        "expired",
+			token:       expired.Token,
+			resultError: modelErrors.ErrNotFound,
+		},
+		{
+			name:        "unknown token",
+			token:       uuid.NewString(),
+			resultError: modelErrors.ErrNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			result, err := repo.Use(tc.token)
+
+			if tc.resultError != nil {
+				require.ErrorIs(t, err, tc.resultError)
+			} else {
+				require.Nil(t, err)
+				require.Equal(t, result.UserID, existingUser.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type OAuthClient struct {
+	ID           pgtype.Text        `db:"id"`
+	CreatedAt    pgtype.Timestamptz `db:"created_at"`
+	Name         pgtype.Text        `db:"name"`
+	SecretHash   pgtype.Text        `db:"secret_hash"`
+	RedirectURIs []string           `db:"redirect_uris"`
+	Trusted      pgtype.Bool        `db:"trusted"`
+	Scopes       []string           `db:"scopes"`
+}
+
+type repositoryOAuthClient struct {
+	ctx context.Context
+	db  query
+}
+
+func (c *repositoryOAuthClient) Create(client domain.OAuthClient) (created domain.OAuthClient, err error) {
+	// clients using only client credentials have no redirect uris
+	redirectURIs := client.RedirectURIs
+	if redirectURIs == nil {
+		redirectURIs = []string{}
+	}
+
+	scopes := client.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	_, err = c.db.Exec(
+		c.ctx,
+		"insert into oauth_clients (id, created_at, name, secret_hash, redirect_uris, trusted, scopes) values ($1, $2, $3, $4, $5, $6, $7)",
+		client.ID, time.Now(), client.Name,
+		pgtype.Text{String: client.SecretHash, Valid: client.SecretHash != ""},
+		redirectURIs, client.Trusted, scopes,
+	)
+
+	if err != nil {
+		err = fmt.Errorf("model OAuthClient -> unable to create client %s %w", client.ID, err)
+		return
+	}
+
+	created = client
+	created.RedirectURIs = redirectURIs
+	created.Scopes = scopes
+	return
+}
+
+func (c *repositoryOAuthClient) Get(id string) (client domain.OAuthClient, err error) {
+	rows, err := c.db.Query(c.ctx, "select * from oauth_clients where id = $1", id)
+	if err != nil {
+		err = fmt.Errorf("model OAuthClient -> can not execute query %w", err)
+		return
+	}
+
+	modelClient, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[OAuthClient])
+
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("model OAuthClient -> find client %s %w", id, err)
+		return
+	}
+
+	client = modelOAuthClientToDomainOAuthClient(modelClient)
+
+	return
+}
+
+func newRepositoryOAuthClient(ctx context.Context, db query) *repositoryOAuthClient {
+	return &repositoryOAuthClient{ctx: ctx, db: db}
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func storeOAuthClient(client domain.OAuthClient) (domain.OAuthClient, error) {
+	return newRepositoryOAuthClient(context.TODO(), dbConnection).Create(client)
+}
+
+func newRandomOAuthClient() domain.OAuthClient {
+	return domain.OAuthClient{
+		ID:           uuid.NewString(),
+		Name:         "App",
+		RedirectURIs: []string{"https://app.example.com/callback"},
+	}
+}
+
+func TestOAuthClientCreate(t *testing.T) {
+	repo := newRepositoryOAuthClient(context.TODO(), dbConnection)
+
+	client := newRandomOAuthClient()
+	client.SecretHash = "hash"
+	client.Trusted = true
+	client.Scopes = []string{"reports:read", "reports:write"}
+
+	created, err := repo.Create(client)
+	require.Nil(t, err)
+	require.Equal(t, client, created)
+
+	// client ids are unique
+	_, err = repo.Create(client)
+	require.NotNil(t, err)
+
+	found, err := repo.Get(client.ID)
+	require.Nil(t, err)
+	require.Equal(t, client, found)
+
+	_, err = repo.Get(uuid.NewString())
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+}
+
+func TestOAuthClientPublic(t *testing.T) {
+	client, err := storeOAuthClient(newRandomOAuthClient())
+	require.Nil(t, err, "failed to initialize db state")
+
+	found, err := newRepositoryOAuthClient(context.TODO(), dbConnection).Get(client.ID)
+	require.Nil(t, err)
+	require.Empty(t, found.SecretHash)
+	require.False(t, found.Trusted)
+}
+
+func TestOAuthClientService(t *testing.T) {
+	client, err := storeOAuthClient(domain.OAuthClient{ID: uuid.NewString(), Name: "Billing", SecretHash: "hash"})
+	require.Nil(t, err, "failed to initialize db state")
+
+	found, err := newRepositoryOAuthClient(context.TODO(), dbConnection).Get(client.ID)
+	require.Nil(t, err)
+	require.Empty(t, found.RedirectURIs)
+	require.Empty(t, found.Scopes)
+}
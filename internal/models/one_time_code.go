@@ -0,0 +1,122 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	oneTimeCodePrefix         = "otc:"
+	oneTimeCodeAttemptsPrefix = "otc_attempts:"
+)
+
+type repositoryOneTimeCode struct {
+	ctx   context.Context
+	redis *redis.Client
+}
+
+func oneTimeCodeKey(purpose string, userId uint64) string {
+	return fmt.Sprintf("%s%s:%d", oneTimeCodePrefix, purpose, userId)
+}
+
+// oneTimeCodeAttemptsKey counts attempts separately from the code so requesting
+// new code doesn't give a new set of guesses
+func oneTimeCodeAttemptsKey(purpose string, userId uint64) string {
+	return fmt.Sprintf("%s%s:%d", oneTimeCodeAttemptsPrefix, purpose, userId)
+}
+
+// Create replaces any pending code for the same purpose. Attempts are kept, they
+// reset only once ONE_TIME_CODE_TTL passes from the first attempt.
+func (o *repositoryOneTimeCode) Create(purpose string, userId uint64, code string) (otc domain.OneTimeCode, err error) {
+	redisKey := oneTimeCodeKey(purpose, userId)
+
+	if cmd := o.redis.Set(o.ctx, redisKey, code, utils.ONE_TIME_CODE_TTL); cmd.Err() != nil {
+		err = fmt.Errorf("unable to store %s code for user %d in redis %w", purpose, userId, cmd.Err())
+		return
+	}
+
+	attempts, err := o.attempts(purpose, userId)
+	if err != nil {
+		return
+	}
+
+	otc.Purpose = purpose
+	otc.UserID = userId
+	otc.Code = code
+	otc.Attempts = attempts
+
+	return
+}
+
+func (o *repositoryOneTimeCode) Get(purpose string, userId uint64) (otc domain.OneTimeCode, err error) {
+	code, err := o.redis.Get(o.ctx, oneTimeCodeKey(purpose, userId)).Result()
+	if err == redis.Nil {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("unable to get %s code for user %d %w", purpose, userId, err)
+		return
+	}
+
+	attempts, err := o.attempts(purpose, userId)
+	if err != nil {
+		return
+	}
+
+	otc.Purpose = purpose
+	otc.UserID = userId
+	otc.Code = code
+	otc.Attempts = attempts
+
+	return
+}
+
+// Attempt counts the attempt. Window of the attempts starts with the first one.
+func (o *repositoryOneTimeCode) Attempt(purpose string, userId uint64) (attempts int64, err error) {
+	redisKey := oneTimeCodeAttemptsKey(purpose, userId)
+
+	attempts, err = o.redis.Incr(o.ctx, redisKey).Result()
+	if err != nil {
+		err = fmt.Errorf("unable to increment attempts for %s code of user %d %w", purpose, userId, err)
+		return
+	}
+
+	if attempts > 1 {
+		return
+	}
+
+	if res := o.redis.Expire(o.ctx, redisKey, utils.ONE_TIME_CODE_TTL); res.Err() != nil {
+		err = fmt.Errorf("unable to set expiration to attempts for %s code of user %d %w", purpose, userId, res.Err())
+	}
+
+	return
+}
+
+// Delete discards the code. Attempts stay until their window passes.
+func (o *repositoryOneTimeCode) Delete(purpose string, userId uint64) error {
+	if cmd := o.redis.Del(o.ctx, oneTimeCodeKey(purpose, userId)); cmd.Err() != nil {
+		return fmt.Errorf("unable to delete %s code for user %d %w", purpose, userId, cmd.Err())
+	}
+
+	return nil
+}
+
+func (o *repositoryOneTimeCode) attempts(purpose string, userId uint64) (attempts int64, err error) {
+	attempts, err = o.redis.Get(o.ctx, oneTimeCodeAttemptsKey(purpose, userId)).Int64()
+	if err == redis.Nil {
+		err = nil
+	} else if err != nil {
+		err = fmt.Errorf("unable to get attempts for %s code of user %d %w", purpose, userId, err)
+	}
+
+	return
+}
+
+func newRepositoryOneTimeCode(ctx context.Context, redis *redis.Client) *repositoryOneTimeCode {
+	return &repositoryOneTimeCode{ctx: ctx, redis: redis}
+}
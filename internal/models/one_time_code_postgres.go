@@ -0,0 +1,127 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// repositoryOneTimeCodePostgres keeps one-time codes in the database for deployments without Redis
+type repositoryOneTimeCodePostgres struct {
+	ctx context.Context
+	db  query
+}
+
+// Create replaces any pending code for the same purpose. Attempts are kept, they
+// reset only once ONE_TIME_CODE_TTL passes from the first attempt.
+func (o *repositoryOneTimeCodePostgres) Create(purpose string, userId uint64, code string) (otc domain.OneTimeCode, err error) {
+	_, err = o.db.Exec(
+		o.ctx,
+		`insert into one_time_codes (purpose, user_id, code, expires_at) values ($1, $2, $3, $4)
+		on conflict (purpose, user_id) do update set code = excluded.code, expires_at = excluded.expires_at`,
+		purpose, userId, code, time.Now().Add(utils.ONE_TIME_CODE_TTL),
+	)
+	if err != nil {
+		err = fmt.Errorf("unable to store %s code for user %d %w", purpose, userId, err)
+		return
+	}
+
+	attempts, err := o.attempts(purpose, userId)
+	if err != nil {
+		return
+	}
+
+	otc.Purpose = purpose
+	otc.UserID = userId
+	otc.Code = code
+	otc.Attempts = attempts
+
+	return
+}
+
+func (o *repositoryOneTimeCodePostgres) Get(purpose string, userId uint64) (otc domain.OneTimeCode, err error) {
+	row := o.db.QueryRow(
+		o.ctx,
+		"select code from one_time_codes where purpose = $1 and user_id = $2 and expires_at > now()",
+		purpose, userId,
+	)
+
+	var code pgtype.Text
+	err = row.Scan(&code)
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("unable to get %s code for user %d %w", purpose, userId, err)
+		return
+	}
+
+	attempts, err := o.attempts(purpose, userId)
+	if err != nil {
+		return
+	}
+
+	otc.Purpose = purpose
+	otc.UserID = userId
+	otc.Code = code.String
+	otc.Attempts = attempts
+
+	return
+}
+
+// Attempt counts the attempt. Window of the attempts starts with the first one.
+func (o *repositoryOneTimeCodePostgres) Attempt(purpose string, userId uint64) (attempts int64, err error) {
+	row := o.db.QueryRow(
+		o.ctx,
+		`insert into one_time_code_attempts as a (purpose, user_id, attempts, expires_at) values ($1, $2, 1, $3)
+		on conflict (purpose, user_id) do update set
+			attempts = case when a.expires_at > now() then a.attempts + 1 else 1 end,
+			expires_at = case when a.expires_at > now() then a.expires_at else excluded.expires_at end
+		returning attempts`,
+		purpose, userId, time.Now().Add(utils.ONE_TIME_CODE_TTL),
+	)
+
+	err = row.Scan(&attempts)
+	if err != nil {
+		err = fmt.Errorf("unable to increment attempts for %s code of user %d %w", purpose, userId, err)
+	}
+
+	return
+}
+
+// Delete discards the code. Attempts stay until their window passes.
+func (o *repositoryOneTimeCodePostgres) Delete(purpose string, userId uint64) error {
+	_, err := o.db.Exec(o.ctx, "delete from one_time_codes where purpose = $1 and user_id = $2", purpose, userId)
+	if err != nil {
+		return fmt.Errorf("unable to delete %s code for user %d %w", purpose, userId, err)
+	}
+
+	return nil
+}
+
+func (o *repositoryOneTimeCodePostgres) attempts(purpose string, userId uint64) (attempts int64, err error) {
+	row := o.db.QueryRow(
+		o.ctx,
+		"select attempts from one_time_code_attempts where purpose = $1 and user_id = $2 and expires_at > now()",
+		purpose, userId,
+	)
+
+	err = row.Scan(&attempts)
+	if err == pgx.ErrNoRows {
+		err = nil
+	} else if err != nil {
+		err = fmt.Errorf("unable to get attempts for %s code of user %d %w", purpose, userId, err)
+	}
+
+	return
+}
+
+func newRepositoryOneTimeCodePostgres(ctx context.Context, db query) *repositoryOneTimeCodePostgres {
+	return &repositoryOneTimeCodePostgres{ctx: ctx, db: db}
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOneTimeCodePostgres(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryOneTimeCodePostgres(context.TODO(), dbConnection)
+
+	_, err = repo.Get("login", existingUser.ID)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+
+	created, err := repo.Create("login", existingUser.ID, "123456")
+	require.Nil(t, err)
+	require.Equal(t, "123456", created.Code)
+	require.Zero(t, created.Attempts)
+
+	attempts, err := repo.Attempt("login", existingUser.ID)
+	require.Nil(t, err)
+	require.Equal(t, int64(1), attempts)
+
+	attempts, err = repo.Attempt("login", existingUser.ID)
+	require.Nil(t, err)
+	require.Equal(t, int64(2), attempts)
+
+	// new code doesn't give a new set of guesses
+	created, err = repo.Create("login", existingUser.ID, "654321")
+	require.Nil(t, err)
+	require.Equal(t, int64(2), created.Attempts)
+
+	found, err := repo.Get("login", existingUser.ID)
+	require.Nil(t, err)
+	require.Equal(t, "654321", found.Code)
+	require.Equal(t, int64(2), found.Attempts)
+
+	err = repo.Delete("login", existingUser.ID)
+	require.Nil(t, err)
+
+	_, err = repo.Get("login", existingUser.ID)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+
+	_, err = dbConnection.Exec(context.TODO(), "update one_time_code_attempts set expires_at = now() - interval '1 second' where user_id = $1", existingUser.ID)
+	require.Nil(t, err)
+
+	attempts, err = repo.Attempt("login", existingUser.ID)
+	require.Nil(t, err)
+	require.Equal(t, int64(1), attempts)
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Passkey struct {
+	ID              pgtype.Int8        `db:"id"`
+	CreatedAt       pgtype.Timestamptz `db:"created_at"`
+	LastUsedAt      pgtype.Timestamptz `db:"last_used_at"`
+	UserID          pgtype.Int8        `db:"user_id"`
+	CredentialID    []byte             `db:"credential_id"`
+	PublicKey       []byte             `db:"public_key"`
+	AttestationType pgtype.Text        `db:"attestation_type"`
+	AAGUID          []byte             `db:"aaguid"`
+	SignCount       pgtype.Int8        `db:"sign_count"`
+	Transports      []string           `db:"transports"`
+	BackupEligible  pgtype.Bool        `db:"backup_eligible"`
+	BackupState     pgtype.Bool        `db:"backup_state"`
+}
+
+type repositoryPasskey struct {
+	ctx context.Context
+	db  query
+}
+
+func (p *repositoryPasskey) Create(passkey domain.Passkey) (created domain.Passkey, err error) {
+	row := p.db.QueryRow(
+		p.ctx,
+		`insert into webauthn_credentials
+		(created_at, user_id, credential_id, public_key, attestation_type, aaguid, sign_count, transports, backup_eligible, backup_state)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) returning id`,
+		time.Now(), passkey.UserID, passkey.CredentialID, passkey.PublicKey, passkey.AttestationType,
+		passkey.AAGUID, int64(passkey.SignCount), passkey.Transports, passkey.BackupEligible, passkey.BackupState,
+	)
+
+	var id pgtype.Int8
+	err = row.Scan(&id)
+	if err != nil {
+		err = fmt.Errorf("model Passkey -> unable to store passkey for user %d %w", passkey.UserID, err)
+		return
+	}
+
+	created = passkey
+	created.ID = uint64(id.Int64)
+
+	return
+}
+
+func (p *repositoryPasskey) GetByUser(userId uint64) (passkeys []domain.Passkey, err error) {
+	rows, err := p.db.Query(p.ctx, "select * from webauthn_credentials where user_id = $1 order by id", userId)
+	if err != nil {
+		err = fmt.Errorf("model Passkey -> can not execute query %w", err)
+		return
+	}
+
+	modelPasskeys, err := pgx.CollectRows(rows, pgx.RowToStructByName[Passkey])
+	if err != nil {
+		err = fmt.Errorf("model Passkey -> find passkeys for user %d %w", userId, err)
+		return
+	}
+
+	passkeys = make([]domain.Passkey, 0, len(modelPasskeys))
+	for _, modelPasskey := range modelPasskeys {
+		passkeys = append(passkeys, modelPasskeyToDomainPasskey(modelPasskey))
+	}
+
+	return
+}
+
+func (p *repositoryPasskey) GetByCredentialID(credentialId []byte) (passkey domain.Passkey, err error) {
+	rows, err := p.db.Query(p.ctx, "select * from webauthn_credentials where credential_id = $1", credentialId)
+	if err != nil {
+		err = fmt.Errorf("model Passkey -> can not execute query %w", err)
+		return
+	}
+
+	modelPasskey, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[Passkey])
+
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("model Passkey -> find passkey by credential id %w", err)
+		return
+	}
+
+	passkey = modelPasskeyToDomainPasskey(modelPasskey)
+
+	return
+}
+
+func (p *repositoryPasskey) UpdateSignCount(credentialId []byte, signCount uint32, backupState bool) error {
+	result, err := p.db.Exec(
+		p.ctx,
+		"update webauthn_credentials set sign_count = $1, backup_state = $2, last_used_at = $3 where credential_id = $4",
+		int64(signCount), backupState, time.Now(), credentialId,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update passkey sign count %w", err)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("passkey does not exist")
+	}
+
+	return nil
+}
+
+func newRepositoryPasskey(ctx context.Context, db query) *repositoryPasskey {
+	return &repositoryPasskey{ctx: ctx, db: db}
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func newRandomPasskey(userId uint64) domain.Passkey {
+	return domain.Passkey{
+		UserID:          userId,
+		CredentialID:    []byte(uuid.NewString()),
+		PublicKey:       []byte("public key"),
+		AttestationType: "none",
+		AAGUID:          make([]byte, 16),
+		SignCount:       1,
+		Transports:      []string{"internal"},
+		BackupEligible:  true,
+	}
+}
+
+func TestPasskeyCreate(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryPasskey(context.TODO(), dbConnection)
+	passkey := newRandomPasskey(existingUser.ID)
+
+	created, err := repo.Create(passkey)
+	require.Nil(t, err)
+	require.NotZero(t, created.ID)
+
+	// same credential can not be registered twice
+	_, err = repo.Create(passkey)
+	require.NotNil(t, err)
+
+	passkeys, err := repo.GetByUser(existingUser.ID)
+	require.Nil(t, err)
+	require.Len(t, passkeys, 1)
+	require.Equal(t, passkeys[0].CredentialID, passkey.CredentialID)
+	require.Equal(t, passkeys[0].Transports, passkey.Transports)
+}
+
+func TestPasskeyGetByCredentialID(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryPasskey(context.TODO(), dbConnection)
+
+	existing, err := repo.Create(newRandomPasskey(existingUser.ID))
+	require.Nil(t, err, "failed to initialize db state")
+
+	type testCase struct {
+		name         string
+		credentialId []byte
+		resultError  error
+	}
+
+	tests := []testCase{
+		{
+			name:         "found",
+			credentialId: existing.CredentialID,
+		},
+		{
+			name:         "not registered",
+			credentialId: []byte("missing"),
+			resultError:  modelErrors.ErrNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			result, err := repo.GetByCredentialID(tc.credentialId)
+
+			if tc.resultError != nil {
+				require.ErrorIs(t, err, tc.resultError)
+			} else {
+				require.Nil(t, err)
+				require.Equal(t, result.UserID, existingUser.ID)
+			}
+		})
+	}
+}
+
+func TestPasskeyUpdateSignCount(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryPasskey(context.TODO(), dbConnection)
+
+	existing, err := repo.Create(newRandomPasskey(existingUser.ID))
+	require.Nil(t, err, "failed to initialize db state")
+
+	err = repo.UpdateSignCount(existing.CredentialID, 10, true)
+	require.Nil(t, err)
+
+	updated, err := repo.GetByCredentialID(existing.CredentialID)
+	require.Nil(t, err)
+	require.Equal(t, updated.SignCount, uint32(10))
+	require.True(t, updated.BackupState)
+
+	err = repo.UpdateSignCount([]byte("missing"), 10, true)
+	require.NotNil(t, err)
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type repositoryRecoveryCode struct {
+	ctx context.Context
+	db  query
+}
+
+func (rc *repositoryRecoveryCode) Replace(userId uint64, hashes []string) error {
+	_, err := rc.db.Exec(rc.ctx, "delete from recovery_codes where user_id = $1", userId)
+	if err != nil {
+		return fmt.Errorf("failed to delete previous recovery codes for user %d %w", userId, err)
+	}
+
+	now := time.Now()
+	for _, hash := range hashes {
+		_, err = rc.db.Exec(
+			rc.ctx,
+			"insert into recovery_codes (created_at, user_id, code_hash) values ($1, $2, $3)",
+			now, userId, hash,
+		)
+
+		if err != nil {
+			return fmt.Errorf("failed to store recovery code for user %d %w", userId, err)
+		}
+	}
+
+	return nil
+}
+
+func (rc *repositoryRecoveryCode) Count(userId uint64) (remaining int, err error) {
+	row := rc.db.QueryRow(
+		rc.ctx,
+		"select count(*) from recovery_codes where user_id = $1 and used_at is null",
+		userId,
+	)
+
+	var count pgtype.Int8
+	err = row.Scan(&count)
+	if err != nil {
+		err = fmt.Errorf("model RecoveryCode -> unable to count codes for user %d %w", userId, err)
+		return
+	}
+
+	remaining = int(count.Int64)
+	return
+}
+
+func (rc *repositoryRecoveryCode) Use(userId uint64, hash string) (used bool, err error) {
+	result, err := rc.db.Exec(
+		rc.ctx,
+		"update recovery_codes set used_at = $1 where user_id = $2 and code_hash = $3 and used_at is null",
+		time.Now(), userId, hash,
+	)
+
+	if err != nil {
+		err = fmt.Errorf("model RecoveryCode -> unable to use code for user %d %w", userId, err)
+		return
+	}
+
+	used = result.RowsAffected() == 1
+	return
+}
+
+func newRepositoryRecoveryCode(ctx context.Context, db query) *repositoryRecoveryCode {
+	return &repositoryRecoveryCode{ctx: ctx, db: db}
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoveryCodeReplace(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryRecoveryCode(context.TODO(), dbConnection)
+
+	err = repo.Replace(existingUser.ID, []string{"first", "second", "third"})
+	require.Nil(t, err)
+
+	err = repo.Replace(existingUser.ID, []string{"fourth", "fifth"})
+	require.Nil(t, err)
+
+	remaining, err := repo.Count(existingUser.ID)
+	require.Nil(t, err)
+	require.Equal(t, remaining, 2)
+
+	used, err := repo.Use(existingUser.ID, "first")
+	require.Nil(t, err)
+	require.False(t, used, "replaced code must not be usable")
+}
+
+func TestRecoveryCodeUse(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	otherUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryRecoveryCode(context.TODO(), dbConnection)
+
+	err = repo.Replace(existingUser.ID, []string{"hash_one", "hash_two"})
+	require.Nil(t, err, "failed to initialize db state")
+
+	type testCase struct {
+		name   string
+		userId uint64
+		hash   string
+		result bool
+	}
+
+	tests := []testCase{
+		{
+			name:   "uses code",
+			userId: existingUser.ID,
+			hash:   "hash_one",
+			result: true,
+		},
+		{
+			name:   "code already used",
+			userId: existingUser.ID,
+			hash:   "hash_one",
+			result: false,
+		},
+		{
+			name:   "code belongs to another user",
+			userId: otherUser.ID,
+			hash:   "hash_two",
+			result: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			used, err := repo.Use(tc.userId, tc.hash)
+
+			require.Nil(t, err)
+			require.Equal(t, used, tc.result)
+		})
+	}
+
+	remaining, err := repo.Count(existingUser.ID)
+	require.Nil(t, err)
+	require.Equal(t, remaining, 1)
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type RefreshToken struct {
+	ID        pgtype.Int8        `db:"id"`
+	CreatedAt pgtype.Timestamptz `db:"created_at"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at"`
+	UsedAt    pgtype.Timestamptz `db:"used_at"`
+	UserID    pgtype.Int8        `db:"user_id"`
+	Family    pgtype.Text        `db:"family"`
+	TokenHash pgtype.Text        `db:"token_hash"`
+}
+
+type repositoryRefreshToken struct {
+	ctx context.Context
+	db  query
+}
+
+func (rt *repositoryRefreshToken) Create(token domain.RefreshToken) (created domain.RefreshToken, err error) {
+	row := rt.db.QueryRow(
+		rt.ctx,
+		"insert into refresh_tokens (created_at, expires_at, user_id, family, token_hash) values ($1, $2, $3, $4, $5) returning id",
+		time.Now(), token.ExpiresAt, token.UserID, token.Family, token.TokenHash,
+	)
+
+	var id pgtype.Int8
+	err = row.Scan(&id)
+	if err != nil {
+		err = fmt.Errorf("failed to create refresh token for user %d %w", token.UserID, err)
+		return
+	}
+
+	created = token
+	created.ID = uint64(id.Int64)
+
+	return
+}
+
+func (rt *repositoryRefreshToken) GetByHash(hash string) (token domain.RefreshToken, err error) {
+	rows, err := rt.db.Query(rt.ctx, "select * from refresh_tokens where token_hash = $1", hash)
+	if err != nil {
+		err = fmt.Errorf("model RefreshToken -> can not execute query %w", err)
+		return
+	}
+
+	modelToken, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[RefreshToken])
+
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("model RefreshToken -> find refresh token %w", err)
+		return
+	}
+
+	token = modelRefreshTokenToDomainRefreshToken(modelToken)
+
+	return
+}
+
+// Use marks token as rotated. Token that was already used is not found
+// so only one of concurrent refresh requests can succeed.
+func (rt *repositoryRefreshToken) Use(id uint64) error {
+	result, err := rt.db.Exec(
+		rt.ctx,
+		"update refresh_tokens set used_at = $1 where id = $2 and used_at is null",
+		time.Now(), id,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to use refresh token %d %w", id, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return modelErrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (rt *repositoryRefreshToken) RevokeFamily(family string) error {
+	_, err := rt.db.Exec(rt.ctx, "delete from refresh_tokens where family = $1", family)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token family %s %w", family, err)
+	}
+
+	return nil
+}
+
+// RevokeUser revokes every family of the user
+func (rt *repositoryRefreshToken) RevokeUser(userId uint64) error {
+	_, err := rt.db.Exec(rt.ctx, "delete from refresh_tokens where user_id = $1", userId)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens of user %d %w", userId, err)
+	}
+
+	return nil
+}
+
+func newRepositoryRefreshToken(ctx context.Context, db query) *repositoryRefreshToken {
+	return &repositoryRefreshToken{ctx: ctx, db: db}
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func newRandomRefreshToken(userId uint64, family string) domain.RefreshToken {
+	return domain.RefreshToken{
+		UserID:    userId,
+		Family:    family,
+		TokenHash: uuid.NewString(),
+		ExpiresAt: time.Now().Add(time.Hour).Truncate(time.Microsecond),
+	}
+}
+
+func TestRefreshTokenCreate(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryRefreshToken(context.TODO(), dbConnection)
+	token := newRandomRefreshToken(existingUser.ID, uuid.NewString())
+
+	created, err := repo.Create(token)
+	require.Nil(t, err)
+	require.NotZero(t, created.ID)
+
+	found, err := repo.GetByHash(token.TokenHash)
+	require.Nil(t, err)
+	require.Equal(t, created.ID, found.ID)
+	require.Equal(t, token.Family, found.Family)
+	require.Equal(t, existingUser.ID, found.UserID)
+	require.True(t, token.ExpiresAt.Equal(found.ExpiresAt))
+	require.False(t, found.Used)
+
+	_, err = repo.GetByHash(uuid.NewString())
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+}
+
+func TestRefreshTokenUse(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryRefreshToken(context.TODO(), dbConnection)
+	created, err := repo.Create(newRandomRefreshToken(existingUser.ID, uuid.NewString()))
+	require.Nil(t, err, "failed to initialize db state")
+
+	err = repo.Use(created.ID)
+	require.Nil(t, err)
+
+	found, err := repo.GetByHash(created.TokenHash)
+	require.Nil(t, err)
+	require.True(t, found.Used)
+
+	// token can be rotated only once
+	err = repo.Use(created.ID)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+}
+
+func TestRefreshTokenRevokeFamily(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryRefreshToken(context.TODO(), dbConnection)
+	family := uuid.NewString()
+
+	first, err := repo.Create(newRandomRefreshToken(existingUser.ID, family))
+	require.Nil(t, err, "failed to initialize db state")
+
+	second, err := repo.Create(newRandomRefreshToken(existingUser.ID, family))
+	require.Nil(t, err, "failed to initialize db state")
+
+	other, err := repo.Create(newRandomRefreshToken(existingUser.ID, uuid.NewString()))
+	require.Nil(t, err, "failed to initialize db state")
+
+	err = repo.RevokeFamily(family)
+	require.Nil(t, err)
+
+	for _, token := range []domain.RefreshToken{first, second} {
+		_, err = repo.GetByHash(token.TokenHash)
+		require.ErrorIs(t, err, modelErrors.ErrNotFound)
+	}
+
+	_, err = repo.GetByHash(other.TokenHash)
+	require.Nil(t, err)
+}
+
+func TestRefreshTokenRevokeUser(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	otherUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryRefreshToken(context.TODO(), dbConnection)
+
+	first, err := repo.Create(newRandomRefreshToken(existingUser.ID, uuid.NewString()))
+	require.Nil(t, err, "failed to initialize db state")
+
+	second, err := repo.Create(newRandomRefreshToken(existingUser.ID, uuid.NewString()))
+	require.Nil(t, err, "failed to initialize db state")
+
+	other, err := repo.Create(newRandomRefreshToken(otherUser.ID, uuid.NewString()))
+	require.Nil(t, err, "failed to initialize db state")
+
+	err = repo.RevokeUser(existingUser.ID)
+	require.Nil(t, err)
+
+	for _, token := range []domain.RefreshToken{first, second} {
+		_, err = repo.GetByHash(token.TokenHash)
+		require.ErrorIs(t, err, modelErrors.ErrNotFound)
+	}
+
+	_, err = repo.GetByHash(other.TokenHash)
+	require.Nil(t, err)
+}
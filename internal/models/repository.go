@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -11,14 +12,18 @@ import (
 )
 
 type repository struct {
-	db    query
-	redis *redis.Client
+	db           query
+	redis        *redis.Client
+	sessionStore string
+	memory       *repositorySessionMemory
+	cookies      *cookieSessions
+	sessionUsers *sessionUsers
 }
 
 func (r *repository) Atomic(fn domain.AtomicFn) (err error) {
 	tx, err := r.db.Begin(context.Background())
 
-	newRepo := NewRepository(tx, r.redis)
+	newRepo := &repository{db: tx, redis: r.redis, sessionStore: r.sessionStore, memory: r.memory, cookies: r.cookies, sessionUsers: r.sessionUsers}
 
 	err = fn(newRepo)
 
@@ -45,12 +50,120 @@ func (r *repository) ForgetPassword(ctx context.Context) domain.RepositoryForget
 	return newRepositoryForgetPassword(ctx, r.db)
 }
 
+func (r *repository) MagicLink(ctx context.Context) domain.RepositoryMagicLink {
+	return newRepositoryMagicLink(ctx, r.db)
+}
+
+func (r *repository) OneTimeCode(ctx context.Context) domain.RepositoryOneTimeCode {
+	if !r.usesRedis() {
+		return newRepositoryOneTimeCodePostgres(ctx, r.db)
+	}
+
+	return newRepositoryOneTimeCode(ctx, r.redis)
+}
+
 func (r *repository) Session(ctx context.Context) domain.RepositorySession {
-	return newRepositorySession(ctx, r.redis)
+	var store domain.RepositorySession
+
+	switch r.sessionStore {
+	case utils.SESSION_STORE_MEMORY:
+		store = r.memory
+	case utils.SESSION_STORE_POSTGRES:
+		// reads user of the session on every request anyway
+		return newRepositorySessionPostgres(ctx, r.db)
+	case utils.SESSION_STORE_COOKIE:
+		// tracks changes of users itself so requests don't read the database
+		return newRepositorySessionCookie(ctx, r.db, r.cookies)
+	default:
+		store = newRepositorySession(ctx, r.redis)
+	}
+
+	return newRepositorySessionRefresh(ctx, r.db, store, r.sessionUsers)
+}
+
+func (r *repository) TOTP(ctx context.Context) domain.RepositoryTOTP {
+	return newRepositoryTOTP(ctx, r.db)
+}
+
+func (r *repository) Challenge(ctx context.Context) domain.RepositoryChallenge {
+	if !r.usesRedis() {
+		return newRepositoryChallengePostgres(ctx, r.db)
+	}
+
+	return newRepositoryChallenge(ctx, r.redis)
+}
+
+func (r *repository) RecoveryCode(ctx context.Context) domain.RepositoryRecoveryCode {
+	return newRepositoryRecoveryCode(ctx, r.db)
+}
+
+func (r *repository) Passkey(ctx context.Context) domain.RepositoryPasskey {
+	return newRepositoryPasskey(ctx, r.db)
 }
 
-func NewRepository(db query, redis *redis.Client) *repository {
-	return &repository{db: db, redis: redis}
+func (r *repository) Ceremony(ctx context.Context) domain.RepositoryCeremony {
+	if !r.usesRedis() {
+		return newRepositoryCeremonyPostgres(ctx, r.db)
+	}
+
+	return newRepositoryCeremony(ctx, r.redis)
+}
+
+func (r *repository) Identity(ctx context.Context) domain.RepositoryIdentity {
+	return newRepositoryIdentity(ctx, r.db)
+}
+
+func (r *repository) OAuthClient(ctx context.Context) domain.RepositoryOAuthClient {
+	return newRepositoryOAuthClient(ctx, r.db)
+}
+
+func (r *repository) Consent(ctx context.Context) domain.RepositoryConsent {
+	return newRepositoryConsent(ctx, r.db)
+}
+
+func (r *repository) RefreshToken(ctx context.Context) domain.RepositoryRefreshToken {
+	return newRepositoryRefreshToken(ctx, r.db)
+}
+
+func (r *repository) SigningKey(ctx context.Context) domain.RepositorySigningKey {
+	return newRepositorySigningKey(ctx, r.db)
+}
+
+func (r *repository) APIKey(ctx context.Context) domain.RepositoryAPIKey {
+	return newRepositoryAPIKey(ctx, r.db)
+}
+
+func (r *repository) DeviceAuthorization(ctx context.Context) domain.RepositoryDeviceAuthorization {
+	if !r.usesRedis() {
+		return newRepositoryDeviceAuthorizationPostgres(ctx, r.db)
+	}
+
+	return newRepositoryDeviceAuthorization(ctx, r.redis)
+}
+
+// usesRedis tells if short lived state (one-time codes, challenges, ceremonies, device
+// authorizations) is kept in Redis. It's kept in the database when sessions are not in Redis.
+func (r *repository) usesRedis() bool {
+	switch r.sessionStore {
+	case utils.SESSION_STORE_MEMORY, utils.SESSION_STORE_POSTGRES, utils.SESSION_STORE_COOKIE:
+		return false
+	default:
+		return true
+	}
+}
+
+func NewRepository(db query, redis *redis.Client, config utils.Config) (repo *repository, err error) {
+	repo = &repository{db: db, redis: redis, sessionStore: config.SessionStore, sessionUsers: newSessionUsers()}
+
+	// session state is shared by all repositories so it survives transactions
+	switch config.SessionStore {
+	case utils.SESSION_STORE_MEMORY:
+		repo.memory = newRepositorySessionMemory()
+	case utils.SESSION_STORE_COOKIE:
+		repo.cookies, err = newCookieSessions(config.SessionSecrets)
+	}
+
+	return
 }
 
 type query interface {
@@ -8,28 +8,82 @@ import (
 
 	"github.com/djordjev/auth/internal/domain"
 	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
+// touchSession records use of the session and extends it by idle timeout without going
+// past its absolute expiration. Sessions that reached it are deleted.
+var touchSession = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+
+local now = tonumber(ARGV[1])
+local fields = redis.call("HMGET", KEYS[1], "idle", "expires_at")
+local idle = tonumber(fields[1])
+local expiresAt = tonumber(fields[2])
+
+if idle and expiresAt then
+	local ttl = math.min(idle, expiresAt - now)
+	if ttl <= 0 then
+		redis.call("DEL", KEYS[1])
+		return 0
+	end
+
+	redis.call("EXPIRE", KEYS[1], ttl)
+end
+
+redis.call("HSET", KEYS[1], "last_seen", ARGV[1])
+return 1
+`)
+
+// updateSession replaces user data of the session unless it has expired in the meantime.
+// Payload is never kept in sessions, same as password hash.
+var updateSession = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+
+return redis.call("HSET", KEYS[1], "email", ARGV[1], "username", ARGV[2], "role", ARGV[3], "verified", ARGV[4])
+`)
+
 type repositorySession struct {
 	ctx   context.Context
 	redis *redis.Client
 }
 
-func (s *repositorySession) Create(user domain.User) (session domain.Session, err error) {
-	key, err := uuid.NewUUID()
+// userSessionsKey is sorted set of user's session keys scored by their expiration
+func userSessionsKey(userId uint64) string {
+	return fmt.Sprintf("user_sessions:%d", userId)
+}
+
+// Create stores session that expires after being idle for idle duration
+// and at the latest after its lifetime
+func (s *repositorySession) Create(user domain.User, client utils.Client, idle time.Duration, lifetime time.Duration) (session domain.Session, err error) {
+	key, err := uuid.NewRandom()
 	if err != nil {
 		err = fmt.Errorf("unable to generate key for session %w", err)
 		return
 	}
 
+	now := time.Now()
+	expiresAt := now.Add(lifetime)
+	idle = min(idle, lifetime)
+
 	values := []string{
 		"id", fmt.Sprintf("%d", user.ID),
 		"email", user.Email,
 		"username", user.Username,
 		"role", user.Role,
 		"verified", fmt.Sprintf("%t", user.Verified),
+		"created_at", strconv.FormatInt(now.Unix(), 10),
+		"last_seen", strconv.FormatInt(now.Unix(), 10),
+		"expires_at", strconv.FormatInt(expiresAt.Unix(), 10),
+		"idle", strconv.FormatInt(int64(idle.Seconds()), 10),
+		"ip", client.IP,
+		"user_agent", client.UserAgent,
 	}
 
 	cmd := s.redis.HSet(s.ctx, key.String(), values)
@@ -39,18 +93,93 @@ func (s *repositorySession) Create(user domain.User) (session domain.Session, er
 		return
 	}
 
-	if res := s.redis.Expire(s.ctx, key.String(), time.Duration(10*24*time.Hour)); res.Err() != nil {
+	if res := s.redis.Expire(s.ctx, key.String(), idle); res.Err() != nil {
 		err = fmt.Errorf("unable to set expiration to session %s", key)
 		return
 	}
 
+	index := userSessionsKey(user.ID)
+
+	_, err = s.redis.TxPipelined(s.ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(s.ctx, index, redis.Z{Score: float64(expiresAt.Unix()), Member: key.String()})
+		pipe.ZRemRangeByScore(s.ctx, index, "-inf", strconv.FormatInt(now.Unix(), 10))
+		// newest session expires last so index lives as long as it
+		pipe.Expire(s.ctx, index, lifetime)
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("unable to index session for user %d %w", user.ID, err)
+		return
+	}
+
 	session.User = user
 	session.ID = key.String()
+	session.CreatedAt = time.Unix(now.Unix(), 0)
+	session.LastSeen = session.CreatedAt
+	session.ExpiresAt = time.Unix(expiresAt.Unix(), 0)
+	session.IP = client.IP
+	session.UserAgent = client.UserAgent
 
 	return
 }
 
+// Get returns user of the session and extends the session
 func (s *repositorySession) Get(key string) (user domain.User, err error) {
+	active, err := touchSession.Run(s.ctx, s.redis, []string{key}, time.Now().Unix()).Int()
+	if err != nil {
+		err = fmt.Errorf("unable to extend session %s %w", key, err)
+		return
+	}
+
+	if active == 0 {
+		err = modelErrors.ErrNotFound
+		return
+	}
+
+	session, err := s.get(key)
+	if err != nil {
+		return
+	}
+
+	user = session.User
+
+	return
+}
+
+// Peek returns the session without extending it
+func (s *repositorySession) Peek(key string) (session domain.Session, err error) {
+	var fields *redis.MapStringStringCmd
+	var ttl *redis.DurationCmd
+
+	_, err = s.redis.TxPipelined(s.ctx, func(pipe redis.Pipeliner) error {
+		fields = pipe.HGetAll(s.ctx, key)
+		ttl = pipe.PTTL(s.ctx, key)
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("unable to get session %s %w", key, err)
+		return
+	}
+
+	session, err = sessionFromHash(key, fields.Val())
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	if !session.ExpiresAt.IsZero() && !now.Before(session.ExpiresAt) {
+		err = modelErrors.ErrNotFound
+		return
+	}
+
+	if ttl.Val() > 0 {
+		session.IdleExpiresAt = now.Add(ttl.Val())
+	}
+
+	return
+}
+
+func (s *repositorySession) get(key string) (session domain.Session, err error) {
 	cmd := s.redis.HGetAll(s.ctx, key)
 	if cmd.Err() != nil {
 		err = fmt.Errorf("failed to get key for session %s %w", key, cmd.Err())
@@ -63,6 +192,10 @@ func (s *repositorySession) Get(key string) (user domain.User, err error) {
 		return
 	}
 
+	return sessionFromHash(key, result)
+}
+
+func sessionFromHash(key string, result map[string]string) (session domain.Session, err error) {
 	if len(result) == 0 {
 		err = modelErrors.ErrNotFound
 		return
@@ -80,21 +213,117 @@ func (s *repositorySession) Get(key string) (user domain.User, err error) {
 		return
 	}
 
-	user.ID = uint64(id)
-	user.Email = result["email"]
-	user.Username = result["username"]
-	user.Password = result["password"]
-	user.Role = result["role"]
-	user.Verified = verified
+	session.ID = key
+	session.User.ID = uint64(id)
+	session.User.Email = result["email"]
+	session.User.Username = result["username"]
+	session.User.Password = result["password"]
+	session.User.Role = result["role"]
+	session.User.Verified = verified
+	session.IP = result["ip"]
+	session.UserAgent = result["user_agent"]
+
+	// sessions created before these were recorded don't have them
+	if createdAt, e := strconv.ParseInt(result["created_at"], 10, 64); e == nil {
+		session.CreatedAt = time.Unix(createdAt, 0)
+	}
+
+	if lastSeen, e := strconv.ParseInt(result["last_seen"], 10, 64); e == nil {
+		session.LastSeen = time.Unix(lastSeen, 0)
+	}
+
+	if expiresAt, e := strconv.ParseInt(result["expires_at"], 10, 64); e == nil {
+		session.ExpiresAt = time.Unix(expiresAt, 0)
+	}
 
 	return
 }
 
+// GetByUser returns active sessions of the user, expired ones are dropped from the index
+func (s *repositorySession) GetByUser(userId uint64) (sessions []domain.Session, err error) {
+	index := userSessionsKey(userId)
+
+	keys, err := s.redis.ZRange(s.ctx, index, 0, -1).Result()
+	if err != nil {
+		err = fmt.Errorf("unable to get sessions of user %d %w", userId, err)
+		return
+	}
+
+	cmds, err := s.redis.Pipelined(s.ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.HGetAll(s.ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("unable to get sessions of user %d %w", userId, err)
+		return
+	}
+
+	sessions = make([]domain.Session, 0, len(keys))
+	expired := make([]any, 0)
+
+	for i, cmd := range cmds {
+		session, e := sessionFromHash(keys[i], cmd.(*redis.MapStringStringCmd).Val())
+		if e == modelErrors.ErrNotFound {
+			expired = append(expired, keys[i])
+			continue
+		} else if e != nil {
+			err = e
+			return
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	if len(expired) > 0 {
+		if res := s.redis.ZRem(s.ctx, index, expired...); res.Err() != nil {
+			err = fmt.Errorf("unable to remove expired sessions of user %d %w", userId, res.Err())
+			return
+		}
+	}
+
+	return
+}
+
+// UpdateUser replaces user data in all active sessions of the user
+func (s *repositorySession) UpdateUser(user domain.User) error {
+	keys, err := s.redis.ZRange(s.ctx, userSessionsKey(user.ID), 0, -1).Result()
+	if err != nil {
+		return fmt.Errorf("unable to get sessions of user %d %w", user.ID, err)
+	}
+
+	for _, key := range keys {
+		err = updateSession.Run(s.ctx, s.redis, []string{key}, user.Email, user.Username, user.Role, fmt.Sprintf("%t", user.Verified)).Err()
+		if err != nil {
+			return fmt.Errorf("unable to update session %s of user %d %w", key, user.ID, err)
+		}
+	}
+
+	return nil
+}
+
 func (s *repositorySession) Delete(key string) error {
-	cmd := s.redis.HDel(s.ctx, key)
+	owner, err := s.redis.HGet(s.ctx, key, "id").Result()
+	if err == redis.Nil {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to get owner of session %s %w", key, err)
+	}
 
-	if cmd.Err() != nil {
-		return fmt.Errorf("unable to delete session %s %w", key, cmd.Err())
+	userId, err := strconv.ParseUint(owner, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid owner of session %s %w", key, err)
+	}
+
+	_, err = s.redis.TxPipelined(s.ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(s.ctx, key)
+		pipe.ZRem(s.ctx, userSessionsKey(userId), key)
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("unable to delete session %s %w", key, err)
 	}
 
 	return nil
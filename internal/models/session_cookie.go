@@ -0,0 +1,451 @@
+package models
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// sessionSealData binds sealed payloads to sessions so secrets can't be misused for other tokens
+var sessionSealData = []byte("session")
+
+// cookieSessionPrefix keeps sealed keys apart from other tokens, e.g. base64 of a sealed
+// session could otherwise start with the prefix of api keys
+const cookieSessionPrefix = "cs_"
+
+// cookiePayload is everything needed to authenticate request with cookie session
+type cookiePayload struct {
+	ID        string `json:"sid"`
+	UserID    uint64 `json:"uid"`
+	Email     string `json:"email"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	Verified  bool   `json:"verified"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+type userChange struct {
+	changedAt time.Time
+	expiresAt time.Time
+}
+
+// cookieSessions is state shared by all cookie session repositories. Revoked sessions and
+// users changed since their sessions were issued are cached and reloaded periodically.
+type cookieSessions struct {
+	ciphers []cipher.AEAD
+
+	mu       sync.Mutex
+	loadedAt time.Time
+	revoked  map[string]time.Time
+	changed  map[uint64]userChange
+}
+
+// repositorySessionCookie seals the session into its key. Sessions are still recorded in the
+// database when created so they can be listed and revoked, but using them doesn't read it.
+type repositorySessionCookie struct {
+	ctx      context.Context
+	db       query
+	sessions *cookieSessions
+}
+
+func (s *repositorySessionCookie) Create(user domain.User, client utils.Client, idle time.Duration, lifetime time.Duration) (session domain.Session, err error) {
+	now := time.Now()
+	expiresAt := now.Add(lifetime)
+
+	key, err := s.sessions.seal(cookiePayload{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		Email:     user.Email,
+		Username:  user.Username,
+		Role:      user.Role,
+		Verified:  user.Verified,
+		IssuedAt:  now.UnixMilli(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		err = fmt.Errorf("model Session -> unable to seal session for user %d %w", user.ID, err)
+		return
+	}
+
+	_, err = s.db.Exec(s.ctx, "delete from sessions where user_id = $1 and expires_at <= $2", user.ID, now)
+	if err != nil {
+		err = fmt.Errorf("model Session -> unable to delete expired sessions of user %d %w", user.ID, err)
+		return
+	}
+
+	// sealed session can't be extended so it lasts its whole lifetime
+	_, err = s.db.Exec(
+		s.ctx,
+		"insert into sessions (key, user_id, created_at, last_seen, idle_timeout, idle_expires_at, expires_at, ip, user_agent) values ($1, $2, $3, $3, $4, $5, $5, $6, $7)",
+		key, user.ID, now, int64(lifetime.Seconds()), expiresAt, client.IP, client.UserAgent,
+	)
+	if err != nil {
+		err = fmt.Errorf("model Session -> unable to store session for user %d %w", user.ID, err)
+		return
+	}
+
+	session = domain.Session{
+		ID:        key,
+		User:      user,
+		CreatedAt: now,
+		LastSeen:  now,
+		ExpiresAt: expiresAt,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+	}
+
+	return
+}
+
+// Get returns user sealed in the session. User is read from the database only if it
+// changed after the session was issued.
+func (s *repositorySessionCookie) Get(key string) (user domain.User, err error) {
+	payload, err := s.sessions.open(key)
+	if err != nil || time.Now().Unix() >= payload.ExpiresAt {
+		err = modelErrors.ErrNotFound
+		return
+	}
+
+	err = s.sessions.load(s.ctx, s.db)
+	if err != nil {
+		err = fmt.Errorf("model Session -> %w", err)
+		return
+	}
+
+	revoked, changed := s.sessions.status(payload)
+	if revoked {
+		err = modelErrors.ErrNotFound
+		return
+	}
+
+	if !changed {
+		user = domain.User{
+			ID:       payload.UserID,
+			Email:    payload.Email,
+			Username: payload.Username,
+			Role:     payload.Role,
+			Verified: payload.Verified,
+		}
+
+		return
+	}
+
+	rows, err := s.db.Query(s.ctx, "select * from users where id = $1", payload.UserID)
+	if err != nil {
+		err = fmt.Errorf("model Session -> can not execute query %w", err)
+		return
+	}
+
+	modelUser, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[User])
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("model Session -> find user %d %w", payload.UserID, err)
+		return
+	}
+
+	user = sessionUser(modelUser)
+
+	return
+}
+
+// Peek returns the session as Get does since using sealed session doesn't extend it
+func (s *repositorySessionCookie) Peek(key string) (session domain.Session, err error) {
+	user, err := s.Get(key)
+	if err != nil {
+		return
+	}
+
+	payload, err := s.sessions.open(key)
+	if err != nil {
+		err = modelErrors.ErrNotFound
+		return
+	}
+
+	session = domain.Session{
+		ID:            key,
+		User:          user,
+		CreatedAt:     time.UnixMilli(payload.IssuedAt),
+		ExpiresAt:     time.Unix(payload.ExpiresAt, 0),
+		IdleExpiresAt: time.Unix(payload.ExpiresAt, 0),
+	}
+
+	return
+}
+
+func (s *repositorySessionCookie) GetByUser(userId uint64) (sessions []domain.Session, err error) {
+	// sessions are recorded the same way as in the database store
+	return newRepositorySessionPostgres(s.ctx, s.db).GetByUser(userId)
+}
+
+// UpdateUser marks the user as changed so its sessions issued until now read it from the database
+func (s *repositorySessionCookie) UpdateUser(user domain.User) error {
+	now := time.Now()
+
+	rows, err := s.db.Query(
+		s.ctx,
+		`insert into session_user_changes (user_id, changed_at, expires_at)
+		select $1, $2, max(expires_at) from sessions where user_id = $1 having max(expires_at) > $2
+		on conflict (user_id) do update set changed_at = excluded.changed_at, expires_at = excluded.expires_at
+		returning expires_at`,
+		user.ID, now,
+	)
+	if err != nil {
+		return fmt.Errorf("model Session -> unable to mark user %d as changed %w", user.ID, err)
+	}
+
+	expiresAt, err := pgx.CollectOneRow(rows, pgx.RowTo[time.Time])
+	if err == pgx.ErrNoRows {
+		// user has no sessions
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("model Session -> unable to mark user %d as changed %w", user.ID, err)
+	}
+
+	s.sessions.change(user.ID, userChange{changedAt: now, expiresAt: expiresAt})
+
+	return nil
+}
+
+// Delete revokes the session. Keys that can't be opened were never valid so there's nothing to revoke.
+func (s *repositorySessionCookie) Delete(key string) error {
+	_, err := s.db.Exec(s.ctx, "delete from sessions where key = $1", key)
+	if err != nil {
+		return fmt.Errorf("model Session -> unable to delete session %w", err)
+	}
+
+	payload, err := s.sessions.open(key)
+	if err != nil {
+		return nil
+	}
+
+	expiresAt := time.Unix(payload.ExpiresAt, 0)
+
+	_, err = s.db.Exec(s.ctx, "delete from revoked_sessions where expires_at <= now()")
+	if err != nil {
+		return fmt.Errorf("model Session -> unable to delete expired revocations %w", err)
+	}
+
+	_, err = s.db.Exec(s.ctx, "insert into revoked_sessions (id, expires_at) values ($1, $2) on conflict do nothing", payload.ID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("model Session -> unable to revoke session %s %w", payload.ID, err)
+	}
+
+	s.sessions.revoke(payload.ID, expiresAt)
+
+	return nil
+}
+
+// seal encrypts payload with the first secret
+func (c *cookieSessions) seal(payload cookiePayload) (key string, err error) {
+	plain, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+
+	aead := c.ciphers[0]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		err = fmt.Errorf("unable to generate nonce %w", err)
+		return
+	}
+
+	key = cookieSessionPrefix + base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, plain, sessionSealData))
+
+	return
+}
+
+// open decrypts payload with any of the secrets so they can be rotated
+func (c *cookieSessions) open(key string) (payload cookiePayload, err error) {
+	encoded, ok := strings.CutPrefix(key, cookieSessionPrefix)
+	if !ok {
+		err = errors.New("session is not sealed")
+		return
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return
+	}
+
+	for _, aead := range c.ciphers {
+		if len(sealed) < aead.NonceSize() {
+			continue
+		}
+
+		plain, e := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], sessionSealData)
+		if e != nil {
+			continue
+		}
+
+		err = json.Unmarshal(plain, &payload)
+		return
+	}
+
+	err = errors.New("session is not sealed with any of the secrets")
+
+	return
+}
+
+// load reloads revocations made by other instances once they are older than refresh interval
+func (c *cookieSessions) load(ctx context.Context, db query) error {
+	c.mu.Lock()
+	if time.Since(c.loadedAt) < utils.SESSION_REVOCATIONS_REFRESH {
+		c.mu.Unlock()
+		return nil
+	}
+
+	// other requests keep using cached revocations while these are loaded
+	c.loadedAt = time.Now()
+	c.mu.Unlock()
+
+	revoked, changed, err := loadRevocations(ctx, db)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err != nil {
+		c.loadedAt = time.Time{}
+		return err
+	}
+
+	now := time.Now()
+
+	for id, expiresAt := range c.revoked {
+		if !now.Before(expiresAt) {
+			delete(c.revoked, id)
+		}
+	}
+
+	for id, expiresAt := range revoked {
+		c.revoked[id] = expiresAt
+	}
+
+	for userId, change := range c.changed {
+		if !now.Before(change.expiresAt) {
+			delete(c.changed, userId)
+		}
+	}
+
+	for userId, change := range changed {
+		if change.changedAt.After(c.changed[userId].changedAt) {
+			c.changed[userId] = change
+		}
+	}
+
+	return nil
+}
+
+// status reports whether session is revoked and whether its user changed after it was issued
+func (c *cookieSessions) status(payload cookiePayload) (revoked bool, changed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, revoked = c.revoked[payload.ID]
+
+	change, ok := c.changed[payload.UserID]
+	changed = ok && !time.UnixMilli(payload.IssuedAt).After(change.changedAt)
+
+	return
+}
+
+func (c *cookieSessions) revoke(id string, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.revoked[id] = expiresAt
+}
+
+func (c *cookieSessions) change(userId uint64, change userChange) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.changed[userId] = change
+}
+
+func loadRevocations(ctx context.Context, db query) (revoked map[string]time.Time, changed map[uint64]userChange, err error) {
+	rows, err := db.Query(ctx, "select id, expires_at from revoked_sessions where expires_at > now()")
+	if err != nil {
+		err = fmt.Errorf("can not execute query %w", err)
+		return
+	}
+
+	revoked = map[string]time.Time{}
+	var id string
+	var expiresAt time.Time
+	_, err = pgx.ForEachRow(rows, []any{&id, &expiresAt}, func() error {
+		revoked[id] = expiresAt
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("unable to load revoked sessions %w", err)
+		return
+	}
+
+	rows, err = db.Query(ctx, "select user_id, changed_at, expires_at from session_user_changes where expires_at > now()")
+	if err != nil {
+		err = fmt.Errorf("can not execute query %w", err)
+		return
+	}
+
+	changed = map[uint64]userChange{}
+	var userId int64
+	var change userChange
+	_, err = pgx.ForEachRow(rows, []any{&userId, &change.changedAt, &change.expiresAt}, func() error {
+		changed[uint64(userId)] = change
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("unable to load changed users %w", err)
+	}
+
+	return
+}
+
+func newCookieSessions(secrets []string) (*cookieSessions, error) {
+	sessions := &cookieSessions{revoked: map[string]time.Time{}, changed: map[uint64]userChange{}}
+
+	for _, secret := range secrets {
+		key := sha256.Sum256([]byte(secret))
+
+		block, err := aes.NewCipher(key[:])
+		if err != nil {
+			return nil, err
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions.ciphers = append(sessions.ciphers, aead)
+	}
+
+	if len(sessions.ciphers) == 0 {
+		return nil, errors.New("cookie sessions require at least one secret")
+	}
+
+	return sessions, nil
+}
+
+func newRepositorySessionCookie(ctx context.Context, db query, sessions *cookieSessions) *repositorySessionCookie {
+	return &repositorySessionCookie{ctx: ctx, db: db, sessions: sessions}
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCookieSessionsSeal(t *testing.T) {
+	old, err := newCookieSessions([]string{"old"})
+	require.Nil(t, err)
+
+	rotated, err := newCookieSessions([]string{"new", "old"})
+	require.Nil(t, err)
+
+	other, err := newCookieSessions([]string{"other"})
+	require.Nil(t, err)
+
+	payload := cookiePayload{ID: "id", UserID: 1, Email: "user@gmail.com", Role: "admin", Verified: true, ExpiresAt: 100}
+
+	key, err := old.seal(payload)
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(key, cookieSessionPrefix))
+
+	opened, err := rotated.open(key)
+	require.Nil(t, err)
+	require.Equal(t, payload, opened)
+
+	_, err = other.open(key)
+	require.NotNil(t, err)
+
+	_, err = old.open(key[:len(key)-2] + "AA")
+	require.NotNil(t, err)
+
+	_, err = old.open(strings.TrimPrefix(key, cookieSessionPrefix))
+	require.NotNil(t, err)
+}
+
+func TestSessionCookie(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	sessions, err := newCookieSessions([]string{"secret"})
+	require.Nil(t, err)
+
+	repo := newRepositorySessionCookie(context.TODO(), dbConnection, sessions)
+
+	session, err := repo.Create(existingUser, utils.Client{IP: "10.0.0.1"}, time.Hour, 24*time.Hour)
+	require.Nil(t, err)
+
+	user, err := repo.Get(session.ID)
+	require.Nil(t, err)
+	require.Equal(t, existingUser.ID, user.ID)
+	require.Equal(t, existingUser.Role, user.Role)
+
+	listed, err := repo.GetByUser(existingUser.ID)
+	require.Nil(t, err)
+	require.Len(t, listed, 1)
+	require.Equal(t, session.ID, listed[0].ID)
+
+	// instance that didn't revoke the session learns about it from the database
+	other := newRepositorySessionCookie(context.TODO(), dbConnection, sessions)
+	err = other.Delete(session.ID)
+	require.Nil(t, err)
+
+	fresh, err := newCookieSessions([]string{"secret"})
+	require.Nil(t, err)
+
+	_, err = newRepositorySessionCookie(context.TODO(), dbConnection, fresh).Get(session.ID)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+
+	_, err = repo.Get(session.ID)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+}
+
+func TestSessionCookieUpdateUser(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	sessions, err := newCookieSessions([]string{"secret"})
+	require.Nil(t, err)
+
+	repo := newRepositorySessionCookie(context.TODO(), dbConnection, sessions)
+
+	session, err := repo.Create(existingUser, utils.Client{}, time.Hour, 24*time.Hour)
+	require.Nil(t, err)
+
+	err = newRepositoryUser(context.TODO(), dbConnection).Verify(existingUser)
+	require.Nil(t, err)
+
+	err = repo.UpdateUser(domain.User{ID: existingUser.ID, Verified: true})
+	require.Nil(t, err)
+
+	user, err := repo.Get(session.ID)
+	require.Nil(t, err)
+	require.True(t, user.Verified)
+}
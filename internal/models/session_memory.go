@@ -0,0 +1,154 @@
+package models
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/google/uuid"
+)
+
+type memorySession struct {
+	session       domain.Session
+	idle          time.Duration
+	idleExpiresAt time.Time
+}
+
+// repositorySessionMemory keeps sessions in memory of the process. It is meant for tests
+// and single instance setups, sessions are not shared between instances and are lost on restart.
+type repositorySessionMemory struct {
+	mu       sync.Mutex
+	sessions map[string]*memorySession
+}
+
+func (s *repositorySessionMemory) Create(user domain.User, client utils.Client, idle time.Duration, lifetime time.Duration) (session domain.Session, err error) {
+	key, err := uuid.NewRandom()
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	idle = min(idle, lifetime)
+
+	session = domain.Session{
+		ID:        key.String(),
+		User:      user,
+		CreatedAt: now,
+		LastSeen:  now,
+		ExpiresAt: now.Add(lifetime),
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteExpired(now)
+	s.sessions[session.ID] = &memorySession{session: session, idle: idle, idleExpiresAt: now.Add(idle)}
+
+	return
+}
+
+// Get returns user of the session and extends the session by idle timeout without going past its expiration
+func (s *repositorySessionMemory) Get(key string) (user domain.User, err error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.sessions[key]
+	if !ok || !now.Before(stored.idleExpiresAt) {
+		err = modelErrors.ErrNotFound
+		return
+	}
+
+	stored.session.LastSeen = now
+	stored.idleExpiresAt = now.Add(stored.idle)
+	if stored.idleExpiresAt.After(stored.session.ExpiresAt) {
+		stored.idleExpiresAt = stored.session.ExpiresAt
+	}
+
+	user = stored.session.User
+
+	return
+}
+
+// Peek returns the session without extending it
+func (s *repositorySessionMemory) Peek(key string) (session domain.Session, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.sessions[key]
+	if !ok || !time.Now().Before(stored.idleExpiresAt) {
+		err = modelErrors.ErrNotFound
+		return
+	}
+
+	session = stored.session
+	session.IdleExpiresAt = stored.idleExpiresAt
+
+	return
+}
+
+func (s *repositorySessionMemory) GetByUser(userId uint64) (sessions []domain.Session, err error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions = []domain.Session{}
+	for _, stored := range s.sessions {
+		if stored.session.User.ID == userId && now.Before(stored.idleExpiresAt) {
+			sessions = append(sessions, stored.session)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+
+	return
+}
+
+func (s *repositorySessionMemory) UpdateUser(user domain.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.sessions {
+		if stored.session.User.ID == user.ID {
+			stored.session.User = domain.User{
+				ID:       user.ID,
+				Email:    user.Email,
+				Username: user.Username,
+				Role:     user.Role,
+				Verified: user.Verified,
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *repositorySessionMemory) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, key)
+
+	return nil
+}
+
+func (s *repositorySessionMemory) deleteExpired(now time.Time) {
+	for key, stored := range s.sessions {
+		if !now.Before(stored.idleExpiresAt) {
+			delete(s.sessions, key)
+		}
+	}
+}
+
+func newRepositorySessionMemory() *repositorySessionMemory {
+	return &repositorySessionMemory{sessions: map[string]*memorySession{}}
+}
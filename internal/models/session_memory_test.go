@@ -0,0 +1,82 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionMemory(t *testing.T) {
+	repo := newRepositorySessionMemory()
+	existingUser := domain.User{ID: 1, Email: "user@gmail.com", Role: "user"}
+
+	session, err := repo.Create(existingUser, utils.Client{IP: "10.0.0.1"}, time.Hour, 24*time.Hour)
+	require.Nil(t, err)
+
+	user, err := repo.Get(session.ID)
+	require.Nil(t, err)
+	require.Equal(t, existingUser.Email, user.Email)
+
+	err = repo.UpdateUser(domain.User{ID: 1, Email: "user@gmail.com", Role: "admin", Verified: true})
+	require.Nil(t, err)
+
+	user, err = repo.Get(session.ID)
+	require.Nil(t, err)
+	require.Equal(t, "admin", user.Role)
+	require.True(t, user.Verified)
+
+	sessions, err := repo.GetByUser(existingUser.ID)
+	require.Nil(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, "10.0.0.1", sessions[0].IP)
+
+	err = repo.Delete(session.ID)
+	require.Nil(t, err)
+
+	_, err = repo.Get(session.ID)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+}
+
+func TestSessionMemoryExpired(t *testing.T) {
+	repo := newRepositorySessionMemory()
+	existingUser := domain.User{ID: 1}
+
+	session, err := repo.Create(existingUser, utils.Client{}, time.Hour, time.Hour)
+	require.Nil(t, err)
+
+	repo.sessions[session.ID].idleExpiresAt = time.Now().Add(-time.Second)
+
+	_, err = repo.Get(session.ID)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+
+	sessions, err := repo.GetByUser(existingUser.ID)
+	require.Nil(t, err)
+	require.Empty(t, sessions)
+}
+
+func TestSessionMemoryPeek(t *testing.T) {
+	repo := newRepositorySessionMemory()
+	existingUser := domain.User{ID: 1}
+
+	session, err := repo.Create(existingUser, utils.Client{}, time.Hour, 24*time.Hour)
+	require.Nil(t, err)
+
+	idleExpiresAt := time.Now().Add(time.Minute)
+	repo.sessions[session.ID].idleExpiresAt = idleExpiresAt
+
+	peeked, err := repo.Peek(session.ID)
+	require.Nil(t, err)
+	require.Equal(t, existingUser.ID, peeked.User.ID)
+	require.Equal(t, idleExpiresAt, peeked.IdleExpiresAt)
+	require.Equal(t, session.ExpiresAt, peeked.ExpiresAt)
+	require.Equal(t, idleExpiresAt, repo.sessions[session.ID].idleExpiresAt, "peek doesn't extend the session")
+
+	repo.sessions[session.ID].idleExpiresAt = time.Now().Add(-time.Second)
+
+	_, err = repo.Peek(session.ID)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+}
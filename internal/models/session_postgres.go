@@ -0,0 +1,207 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Session struct {
+	Key           pgtype.Text        `db:"key"`
+	UserID        pgtype.Int8        `db:"user_id"`
+	CreatedAt     pgtype.Timestamptz `db:"created_at"`
+	LastSeen      pgtype.Timestamptz `db:"last_seen"`
+	IdleTimeout   pgtype.Int8        `db:"idle_timeout"`
+	IdleExpiresAt pgtype.Timestamptz `db:"idle_expires_at"`
+	ExpiresAt     pgtype.Timestamptz `db:"expires_at"`
+	IP            pgtype.Text        `db:"ip"`
+	UserAgent     pgtype.Text        `db:"user_agent"`
+}
+
+// repositorySessionPostgres keeps sessions in the database for deployments without Redis.
+// User is read together with the session so sessions never hold stale user data.
+type repositorySessionPostgres struct {
+	ctx context.Context
+	db  query
+}
+
+func (s *repositorySessionPostgres) Create(user domain.User, client utils.Client, idle time.Duration, lifetime time.Duration) (session domain.Session, err error) {
+	key, err := uuid.NewRandom()
+	if err != nil {
+		err = fmt.Errorf("unable to generate key for session %w", err)
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(lifetime)
+	idle = min(idle, lifetime)
+
+	_, err = s.db.Exec(s.ctx, "delete from sessions where user_id = $1 and idle_expires_at <= $2", user.ID, now)
+	if err != nil {
+		err = fmt.Errorf("model Session -> unable to delete expired sessions of user %d %w", user.ID, err)
+		return
+	}
+
+	_, err = s.db.Exec(
+		s.ctx,
+		"insert into sessions (key, user_id, created_at, last_seen, idle_timeout, idle_expires_at, expires_at, ip, user_agent) values ($1, $2, $3, $3, $4, $5, $6, $7, $8)",
+		key.String(), user.ID, now, int64(idle.Seconds()), now.Add(idle), expiresAt, client.IP, client.UserAgent,
+	)
+	if err != nil {
+		err = fmt.Errorf("model Session -> unable to store session for user %d %w", user.ID, err)
+		return
+	}
+
+	session = domain.Session{
+		ID:        key.String(),
+		User:      user,
+		CreatedAt: now,
+		LastSeen:  now,
+		ExpiresAt: expiresAt,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+	}
+
+	return
+}
+
+// Get returns user of the session and extends the session by idle timeout without going past its expiration
+func (s *repositorySessionPostgres) Get(key string) (user domain.User, err error) {
+	rows, err := s.db.Query(
+		s.ctx,
+		`with touched as (
+			update sessions set last_seen = now(), idle_expires_at = least(now() + make_interval(secs => idle_timeout), expires_at)
+			where key = $1 and idle_expires_at > now() returning user_id
+		)
+		select users.* from users join touched on users.id = touched.user_id`,
+		key,
+	)
+	if err != nil {
+		err = fmt.Errorf("model Session -> can not execute query %w", err)
+		return
+	}
+
+	modelUser, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[User])
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("model Session -> find session %w", err)
+		return
+	}
+
+	user = sessionUser(modelUser)
+
+	return
+}
+
+// Peek returns the session without extending it
+func (s *repositorySessionPostgres) Peek(key string) (session domain.Session, err error) {
+	rows, err := s.db.Query(s.ctx, "select * from sessions where key = $1 and idle_expires_at > now()", key)
+	if err != nil {
+		err = fmt.Errorf("model Session -> can not execute query %w", err)
+		return
+	}
+
+	modelSession, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[Session])
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("model Session -> find session %w", err)
+		return
+	}
+
+	rows, err = s.db.Query(s.ctx, "select * from users where id = $1", modelSession.UserID.Int64)
+	if err != nil {
+		err = fmt.Errorf("model Session -> can not execute query %w", err)
+		return
+	}
+
+	modelUser, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[User])
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("model Session -> find user %d %w", modelSession.UserID.Int64, err)
+		return
+	}
+
+	session = modelSessionToDomainSession(modelSession)
+	session.User = sessionUser(modelUser)
+
+	return
+}
+
+func (s *repositorySessionPostgres) GetByUser(userId uint64) (sessions []domain.Session, err error) {
+	rows, err := s.db.Query(s.ctx, "select * from users where id = $1", userId)
+	if err != nil {
+		err = fmt.Errorf("model Session -> can not execute query %w", err)
+		return
+	}
+
+	modelUser, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[User])
+	if err == pgx.ErrNoRows {
+		return []domain.Session{}, nil
+	} else if err != nil {
+		err = fmt.Errorf("model Session -> find user %d %w", userId, err)
+		return
+	}
+
+	rows, err = s.db.Query(s.ctx, "select * from sessions where user_id = $1 and idle_expires_at > now() order by created_at", userId)
+	if err != nil {
+		err = fmt.Errorf("model Session -> can not execute query %w", err)
+		return
+	}
+
+	modelSessions, err := pgx.CollectRows(rows, pgx.RowToStructByName[Session])
+	if err != nil {
+		err = fmt.Errorf("model Session -> find sessions of user %d %w", userId, err)
+		return
+	}
+
+	user := sessionUser(modelUser)
+
+	sessions = make([]domain.Session, 0, len(modelSessions))
+	for _, modelSession := range modelSessions {
+		session := modelSessionToDomainSession(modelSession)
+		session.User = user
+		sessions = append(sessions, session)
+	}
+
+	return
+}
+
+// UpdateUser has nothing to do as user is read from the users table with every session
+func (s *repositorySessionPostgres) UpdateUser(user domain.User) error {
+	return nil
+}
+
+func (s *repositorySessionPostgres) Delete(key string) error {
+	_, err := s.db.Exec(s.ctx, "delete from sessions where key = $1", key)
+	if err != nil {
+		return fmt.Errorf("model Session -> unable to delete session %s %w", key, err)
+	}
+
+	return nil
+}
+
+// sessionUser drops fields that sessions in other stores don't hold either
+func sessionUser(model User) domain.User {
+	user := modelUserToDomainUser(model)
+	user.Password = ""
+	user.Payload = nil
+
+	return user
+}
+
+func newRepositorySessionPostgres(ctx context.Context, db query) *repositorySessionPostgres {
+	return &repositorySessionPostgres{ctx: ctx, db: db}
+}
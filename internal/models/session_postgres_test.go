@@ -0,0 +1,87 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionPostgres(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositorySessionPostgres(context.TODO(), dbConnection)
+	client := utils.Client{IP: "10.0.0.1", UserAgent: "test"}
+
+	session, err := repo.Create(existingUser, client, time.Hour, 24*time.Hour)
+	require.Nil(t, err)
+	require.NotEmpty(t, session.ID)
+
+	user, err := repo.Get(session.ID)
+	require.Nil(t, err)
+	require.Equal(t, existingUser.ID, user.ID)
+	require.Equal(t, existingUser.Email, user.Email)
+	require.Empty(t, user.Password)
+
+	sessions, err := repo.GetByUser(existingUser.ID)
+	require.Nil(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, session.ID, sessions[0].ID)
+	require.Equal(t, client.IP, sessions[0].IP)
+	require.Equal(t, client.UserAgent, sessions[0].UserAgent)
+
+	err = repo.Delete(session.ID)
+	require.Nil(t, err)
+
+	_, err = repo.Get(session.ID)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+}
+
+func TestSessionPostgresExpired(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositorySessionPostgres(context.TODO(), dbConnection)
+
+	session, err := repo.Create(existingUser, utils.Client{}, time.Hour, 24*time.Hour)
+	require.Nil(t, err)
+
+	_, err = dbConnection.Exec(context.TODO(), "update sessions set idle_expires_at = now() - interval '1 second' where key = $1", session.ID)
+	require.Nil(t, err)
+
+	_, err = repo.Get(session.ID)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+
+	sessions, err := repo.GetByUser(existingUser.ID)
+	require.Nil(t, err)
+	require.Empty(t, sessions)
+}
+
+func TestSessionPostgresPeek(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositorySessionPostgres(context.TODO(), dbConnection)
+
+	session, err := repo.Create(existingUser, utils.Client{}, time.Hour, 24*time.Hour)
+	require.Nil(t, err)
+
+	_, err = dbConnection.Exec(context.TODO(), "update sessions set idle_expires_at = now() + interval '1 minute' where key = $1", session.ID)
+	require.Nil(t, err)
+
+	peeked, err := repo.Peek(session.ID)
+	require.Nil(t, err)
+	require.Equal(t, existingUser.ID, peeked.User.ID)
+	require.Empty(t, peeked.User.Password)
+	require.WithinDuration(t, time.Now().Add(time.Minute), peeked.IdleExpiresAt, 10*time.Second, "peek doesn't extend the session")
+
+	_, err = dbConnection.Exec(context.TODO(), "update sessions set idle_expires_at = now() - interval '1 second' where key = $1", session.ID)
+	require.Nil(t, err)
+
+	_, err = repo.Peek(session.ID)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+type cachedUser struct {
+	user     domain.User
+	loadedAt time.Time
+}
+
+// sessionUsers caches users of sessions read from the database. It is shared by all
+// repositories of the process and entries are reloaded after SESSION_USER_REFRESH.
+type sessionUsers struct {
+	mu      sync.Mutex
+	users   map[uint64]cachedUser
+	sweptAt time.Time
+}
+
+// repositorySessionRefresh reads user of the session from the database instead of the copy
+// kept by the store. Changes made by the app are written to sessions right away, changes made
+// directly in the database (e.g. by an admin) reach them once the cached user is reloaded.
+type repositorySessionRefresh struct {
+	domain.RepositorySession
+	ctx   context.Context
+	db    query
+	users *sessionUsers
+}
+
+func (s *repositorySessionRefresh) Get(key string) (user domain.User, err error) {
+	user, err = s.RepositorySession.Get(key)
+	if err != nil {
+		return
+	}
+
+	return s.refresh(user.ID)
+}
+
+func (s *repositorySessionRefresh) Peek(key string) (session domain.Session, err error) {
+	session, err = s.RepositorySession.Peek(key)
+	if err != nil {
+		return
+	}
+
+	user, err := s.refresh(session.User.ID)
+	if err != nil {
+		return domain.Session{}, err
+	}
+
+	session.User = user
+
+	return
+}
+
+func (s *repositorySessionRefresh) UpdateUser(user domain.User) error {
+	s.users.forget(user.ID)
+
+	return s.RepositorySession.UpdateUser(user)
+}
+
+// refresh returns cached user of the session or reads it from the database once it's outdated
+func (s *repositorySessionRefresh) refresh(userId uint64) (user domain.User, err error) {
+	if cached, ok := s.users.get(userId); ok {
+		return cached, nil
+	}
+
+	rows, err := s.db.Query(s.ctx, "select * from users where id = $1", userId)
+	if err != nil {
+		err = fmt.Errorf("model Session -> can not execute query %w", err)
+		return
+	}
+
+	modelUser, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[User])
+	if err == pgx.ErrNoRows {
+		// user was deleted while the session was still around
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("model Session -> find user %d %w", userId, err)
+		return
+	}
+
+	user = sessionUser(modelUser)
+	s.users.set(user)
+
+	return
+}
+
+func (c *sessionUsers) get(userId uint64) (user domain.User, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.users[userId]
+	if !ok || time.Since(cached.loadedAt) >= utils.SESSION_USER_REFRESH {
+		return domain.User{}, false
+	}
+
+	return cached.user, true
+}
+
+func (c *sessionUsers) set(user domain.User) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	// users who stopped making requests are dropped once in a while
+	if now.Sub(c.sweptAt) >= utils.SESSION_USER_REFRESH {
+		for userId, cached := range c.users {
+			if now.Sub(cached.loadedAt) >= utils.SESSION_USER_REFRESH {
+				delete(c.users, userId)
+			}
+		}
+
+		c.sweptAt = now
+	}
+
+	c.users[user.ID] = cachedUser{user: user, loadedAt: now}
+}
+
+func (c *sessionUsers) forget(userId uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.users, userId)
+}
+
+func newSessionUsers() *sessionUsers {
+	return &sessionUsers{users: map[uint64]cachedUser{}}
+}
+
+func newRepositorySessionRefresh(ctx context.Context, db query, store domain.RepositorySession, users *sessionUsers) *repositorySessionRefresh {
+	return &repositorySessionRefresh{RepositorySession: store, ctx: ctx, db: db, users: users}
+}
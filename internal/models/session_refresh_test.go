@@ -0,0 +1,48 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRefresh(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	users := newSessionUsers()
+	repo := newRepositorySessionRefresh(context.TODO(), dbConnection, newRepositorySessionMemory(), users)
+
+	session, err := repo.Create(existingUser, utils.Client{}, time.Hour, 24*time.Hour)
+	require.Nil(t, err)
+
+	user, err := repo.Get(session.ID)
+	require.Nil(t, err)
+	require.Equal(t, existingUser.ID, user.ID)
+	require.Empty(t, user.Password)
+
+	// role changed directly in the database
+	_, err = dbConnection.Exec(context.TODO(), "update users set role = 'admin' where id = $1", existingUser.ID)
+	require.Nil(t, err)
+
+	user, err = repo.Get(session.ID)
+	require.Nil(t, err)
+	require.Equal(t, existingUser.Role, user.Role, "cached user is used until it's reloaded")
+
+	users.forget(existingUser.ID)
+
+	user, err = repo.Get(session.ID)
+	require.Nil(t, err)
+	require.Equal(t, "admin", user.Role)
+
+	_, err = dbConnection.Exec(context.TODO(), "delete from users where id = $1", existingUser.ID)
+	require.Nil(t, err)
+	users.forget(existingUser.ID)
+
+	_, err = repo.Get(session.ID)
+	require.ErrorIs(t, err, modelErrors.ErrNotFound)
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// signingKeyLock is advisory lock id held while keys are rotated
+const signingKeyLock = 7_300_233_847
+
+type SigningKey struct {
+	ID         pgtype.Text        `db:"id"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at"`
+	RetiredAt  pgtype.Timestamptz `db:"retired_at"`
+	Algorithm  pgtype.Text        `db:"algorithm"`
+	PrivateKey []byte             `db:"private_key"`
+}
+
+type repositorySigningKey struct {
+	ctx context.Context
+	db  query
+}
+
+func (sk *repositorySigningKey) Create(key domain.SigningKey) (created domain.SigningKey, err error) {
+	now := time.Now()
+
+	_, err = sk.db.Exec(
+		sk.ctx,
+		"insert into signing_keys (id, created_at, algorithm, private_key) values ($1, $2, $3, $4)",
+		key.ID, now, key.Algorithm, key.PrivateKey,
+	)
+
+	if err != nil {
+		err = fmt.Errorf("model SigningKey -> unable to create key %s %w", key.ID, err)
+		return
+	}
+
+	created = key
+	created.CreatedAt = now
+
+	return
+}
+
+// GetPublished returns active keys and keys retired after given time, newest first
+func (sk *repositorySigningKey) GetPublished(retiredAfter time.Time) (keys []domain.SigningKey, err error) {
+	rows, err := sk.db.Query(
+		sk.ctx,
+		"select * from signing_keys where retired_at is null or retired_at > $1 order by created_at desc",
+		retiredAfter,
+	)
+	if err != nil {
+		err = fmt.Errorf("model SigningKey -> can not execute query %w", err)
+		return
+	}
+
+	modelKeys, err := pgx.CollectRows(rows, pgx.RowToStructByName[SigningKey])
+	if err != nil {
+		err = fmt.Errorf("model SigningKey -> find published keys %w", err)
+		return
+	}
+
+	keys = make([]domain.SigningKey, 0, len(modelKeys))
+	for _, key := range modelKeys {
+		keys = append(keys, modelSigningKeyToDomainSigningKey(key))
+	}
+
+	return
+}
+
+func (sk *repositorySigningKey) Retire(at time.Time) error {
+	_, err := sk.db.Exec(sk.ctx, "update signing_keys set retired_at = $1 where retired_at is null", at)
+	if err != nil {
+		return fmt.Errorf("model SigningKey -> unable to retire keys %w", err)
+	}
+
+	return nil
+}
+
+func (sk *repositorySigningKey) DeleteRetired(before time.Time) error {
+	_, err := sk.db.Exec(sk.ctx, "delete from signing_keys where retired_at < $1", before)
+	if err != nil {
+		return fmt.Errorf("model SigningKey -> unable to delete retired keys %w", err)
+	}
+
+	return nil
+}
+
+// Lock holds rotation lock until the transaction ends so instances don't rotate keys at the same time
+func (sk *repositorySigningKey) Lock() error {
+	_, err := sk.db.Exec(sk.ctx, "select pg_advisory_xact_lock($1)", signingKeyLock)
+	if err != nil {
+		return fmt.Errorf("model SigningKey -> unable to lock keys %w", err)
+	}
+
+	return nil
+}
+
+func newRepositorySigningKey(ctx context.Context, db query) *repositorySigningKey {
+	return &repositorySigningKey{ctx: ctx, db: db}
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func newRandomSigningKey() domain.SigningKey {
+	return domain.SigningKey{
+		ID:         uuid.NewString(),
+		Algorithm:  "ES256",
+		PrivateKey: []byte(uuid.NewString()),
+	}
+}
+
+func findSigningKey(keys []domain.SigningKey, id string) (domain.SigningKey, bool) {
+	for _, key := range keys {
+		if key.ID == id {
+			return key, true
+		}
+	}
+
+	return domain.SigningKey{}, false
+}
+
+func TestSigningKeyCreate(t *testing.T) {
+	repo := newRepositorySigningKey(context.TODO(), dbConnection)
+	key := newRandomSigningKey()
+
+	created, err := repo.Create(key)
+	require.Nil(t, err)
+	require.Equal(t, key.ID, created.ID)
+	require.False(t, created.CreatedAt.IsZero())
+
+	published, err := repo.GetPublished(time.Now())
+	require.Nil(t, err)
+
+	found, ok := findSigningKey(published, key.ID)
+	require.True(t, ok)
+	require.Equal(t, key.Algorithm, found.Algorithm)
+	require.Equal(t, key.PrivateKey, found.PrivateKey)
+	require.True(t, found.RetiredAt.IsZero())
+}
+
+func TestSigningKeyRetire(t *testing.T) {
+	repo := newRepositorySigningKey(context.TODO(), dbConnection)
+
+	old, err := repo.Create(newRandomSigningKey())
+	require.Nil(t, err, "failed to initialize db state")
+
+	retiredAt := time.Now()
+	err = repo.Retire(retiredAt)
+	require.Nil(t, err)
+
+	active, err := repo.Create(newRandomSigningKey())
+	require.Nil(t, err, "failed to initialize db state")
+
+	// retired key is still published during overlap
+	published, err := repo.GetPublished(retiredAt.Add(-time.Hour))
+	require.Nil(t, err)
+
+	found, ok := findSigningKey(published, old.ID)
+	require.True(t, ok)
+	require.False(t, found.RetiredAt.IsZero())
+
+	_, ok = findSigningKey(published, active.ID)
+	require.True(t, ok)
+
+	// and removed after it
+	published, err = repo.GetPublished(retiredAt.Add(time.Hour))
+	require.Nil(t, err)
+
+	_, ok = findSigningKey(published, old.ID)
+	require.False(t, ok)
+
+	_, ok = findSigningKey(published, active.ID)
+	require.True(t, ok)
+}
+
+func TestSigningKeyDeleteRetired(t *testing.T) {
+	repo := newRepositorySigningKey(context.TODO(), dbConnection)
+
+	old, err := repo.Create(newRandomSigningKey())
+	require.Nil(t, err, "failed to initialize db state")
+
+	err = repo.Retire(time.Now().Add(-time.Hour))
+	require.Nil(t, err, "failed to initialize db state")
+
+	active, err := repo.Create(newRandomSigningKey())
+	require.Nil(t, err, "failed to initialize db state")
+
+	err = repo.DeleteRetired(time.Now())
+	require.Nil(t, err)
+
+	published, err := repo.GetPublished(time.Time{})
+	require.Nil(t, err)
+
+	_, ok := findSigningKey(published, old.ID)
+	require.False(t, ok)
+
+	_, ok = findSigningKey(published, active.ID)
+	require.True(t, ok)
+}
+
+func TestSigningKeyLock(t *testing.T) {
+	tx, err := dbConnection.Begin(context.TODO())
+	require.Nil(t, err, "failed to initialize db state")
+	defer tx.Rollback(context.TODO())
+
+	repo := newRepositorySigningKey(context.TODO(), tx)
+
+	err = repo.Lock()
+	require.Nil(t, err)
+
+	// other transactions can't take the lock until this one ends
+	var acquired bool
+	err = dbConnection.QueryRow(context.TODO(), "select pg_try_advisory_xact_lock($1)", signingKeyLock).Scan(&acquired)
+	require.Nil(t, err)
+	require.False(t, acquired)
+}
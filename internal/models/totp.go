@@ -0,0 +1,110 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/djordjev/auth/internal/domain"
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type TOTP struct {
+	ID        pgtype.Int8        `db:"id"`
+	CreatedAt pgtype.Timestamptz `db:"created_at"`
+	UserID    pgtype.Int8        `db:"user_id"`
+	Secret    pgtype.Text        `db:"secret"`
+	Confirmed pgtype.Bool        `db:"confirmed"`
+	LastStep  pgtype.Int8        `db:"last_step"`
+}
+
+type repositoryTOTP struct {
+	ctx context.Context
+	db  query
+}
+
+func (t *repositoryTOTP) Create(userId uint64, secret string) (totp domain.TOTP, err error) {
+	_, err = t.db.Exec(
+		t.ctx,
+		`insert into user_totps (created_at, user_id, secret, confirmed, last_step) values ($1, $2, $3, false, 0)
+		on conflict (user_id) do update set created_at = excluded.created_at, secret = excluded.secret, confirmed = false, last_step = 0`,
+		time.Now(), userId, secret,
+	)
+
+	if err != nil {
+		err = fmt.Errorf("model TOTP -> unable to store secret for user %d %w", userId, err)
+		return
+	}
+
+	totp.UserID = userId
+	totp.Secret = secret
+
+	return
+}
+
+func (t *repositoryTOTP) Get(userId uint64) (totp domain.TOTP, err error) {
+	rows, err := t.db.Query(t.ctx, "select * from user_totps where user_id = $1", userId)
+	if err != nil {
+		err = fmt.Errorf("model TOTP -> can not execute query %w", err)
+		return
+	}
+
+	modelTOTP, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[TOTP])
+
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("model TOTP -> find totp for user %d %w", userId, err)
+		return
+	}
+
+	totp.UserID = uint64(modelTOTP.UserID.Int64)
+	totp.Secret = modelTOTP.Secret.String
+	totp.Confirmed = modelTOTP.Confirmed.Bool
+	totp.LastStep = modelTOTP.LastStep.Int64
+
+	return
+}
+
+func (t *repositoryTOTP) Confirm(userId uint64, step int64) error {
+	result, err := t.db.Exec(
+		t.ctx,
+		"update user_totps set confirmed = true, last_step = $1 where user_id = $2",
+		step, userId,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to confirm totp for user %d %w", userId, err)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("totp for user %d does not exist", userId)
+	}
+
+	return nil
+}
+
+func (t *repositoryTOTP) SetLastStep(userId uint64, step int64) error {
+	result, err := t.db.Exec(
+		t.ctx,
+		"update user_totps set last_step = $1 where user_id = $2 and last_step < $1",
+		step, userId,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to set last step for user %d %w", userId, err)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("totp step %d for user %d was already used", step, userId)
+	}
+
+	return nil
+}
+
+func newRepositoryTOTP(ctx context.Context, db query) *repositoryTOTP {
+	return &repositoryTOTP{ctx: ctx, db: db}
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	modelErrors "github.com/djordjev/auth/internal/models/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTPCreate(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryTOTP(context.TODO(), dbConnection)
+
+	_, err = repo.Create(existingUser.ID, "FIRSTSECRET")
+	require.Nil(t, err)
+
+	err = repo.Confirm(existingUser.ID, 10)
+	require.Nil(t, err)
+
+	// enrolling again replaces secret and resets confirmation
+	result, err := repo.Create(existingUser.ID, "SECONDSECRET")
+	require.Nil(t, err)
+	require.Equal(t, result.Secret, "SECONDSECRET")
+
+	stored, err := repo.Get(existingUser.ID)
+	require.Nil(t, err)
+	require.Equal(t, stored.Secret, "SECONDSECRET")
+	require.False(t, stored.Confirmed)
+	require.Zero(t, stored.LastStep)
+}
+
+func TestTOTPGet(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	_, err = dbConnection.Exec(
+		context.Background(),
+		"insert into user_totps (user_id, secret, confirmed, last_step) values ($1, $2, true, 5)",
+		existingUser.ID, "SECRET",
+	)
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryTOTP(context.TODO(), dbConnection)
+
+	type testCase struct {
+		name        string
+		userId      uint64
+		resultError error
+	}
+
+	tests := []testCase{
+		{
+			name:   "found",
+			userId: existingUser.ID,
+		},
+		{
+			name:        "not enrolled",
+			userId:      nonExistingUserID,
+			resultError: modelErrors.ErrNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			result, err := repo.Get(tc.userId)
+
+			if tc.resultError != nil {
+				require.ErrorIs(t, err, tc.resultError)
+			} else {
+				require.Nil(t, err)
+				require.Equal(t, result.Secret, "SECRET")
+				require.True(t, result.Confirmed)
+				require.Equal(t, result.LastStep, int64(5))
+			}
+		})
+	}
+}
+
+func TestTOTPSetLastStep(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryTOTP(context.TODO(), dbConnection)
+
+	_, err = repo.Create(existingUser.ID, "SECRET")
+	require.Nil(t, err, "failed to initialize db state")
+
+	type testCase struct {
+		name        string
+		step        int64
+		resultError string
+	}
+
+	tests := []testCase{
+		{
+			name: "stores newer step",
+			step: 20,
+		},
+		{
+			name:        "rejects used step",
+			step:        20,
+			resultError: "was already used",
+		},
+		{
+			name:        "rejects older step",
+			step:        19,
+			resultError: "was already used",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			err := repo.SetLastStep(existingUser.ID, tc.step)
+
+			if tc.resultError != "" {
+				require.ErrorContains(t, err, tc.resultError)
+			} else {
+				require.Nil(t, err)
+			}
+		})
+	}
+}
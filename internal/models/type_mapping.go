@@ -52,3 +52,98 @@ func domainUserToModelUser(user domain.User) User {
 
 	return usr
 }
+
+func modelPasskeyToDomainPasskey(model Passkey) domain.Passkey {
+	return domain.Passkey{
+		ID:              uint64(model.ID.Int64),
+		UserID:          uint64(model.UserID.Int64),
+		CredentialID:    model.CredentialID,
+		PublicKey:       model.PublicKey,
+		AttestationType: model.AttestationType.String,
+		AAGUID:          model.AAGUID,
+		SignCount:       uint32(model.SignCount.Int64),
+		Transports:      model.Transports,
+		BackupEligible:  model.BackupEligible.Bool,
+		BackupState:     model.BackupState.Bool,
+	}
+}
+
+func modelIdentityToDomainIdentity(model Identity) domain.Identity {
+	return domain.Identity{
+		ID:       uint64(model.ID.Int64),
+		UserID:   uint64(model.UserID.Int64),
+		Provider: model.Provider.String,
+		Subject:  model.Subject.String,
+		Email:    model.Email.String,
+	}
+}
+
+func modelOAuthClientToDomainOAuthClient(model OAuthClient) domain.OAuthClient {
+	return domain.OAuthClient{
+		ID:           model.ID.String,
+		Name:         model.Name.String,
+		SecretHash:   model.SecretHash.String,
+		RedirectURIs: model.RedirectURIs,
+		Trusted:      model.Trusted.Bool,
+		Scopes:       model.Scopes,
+	}
+}
+
+func modelRefreshTokenToDomainRefreshToken(model RefreshToken) domain.RefreshToken {
+	return domain.RefreshToken{
+		ID:        uint64(model.ID.Int64),
+		UserID:    uint64(model.UserID.Int64),
+		Family:    model.Family.String,
+		TokenHash: model.TokenHash.String,
+		ExpiresAt: model.ExpiresAt.Time,
+		Used:      model.UsedAt.Valid,
+	}
+}
+
+func modelSigningKeyToDomainSigningKey(model SigningKey) domain.SigningKey {
+	return domain.SigningKey{
+		ID:         model.ID.String,
+		Algorithm:  model.Algorithm.String,
+		PrivateKey: model.PrivateKey,
+		CreatedAt:  model.CreatedAt.Time,
+		RetiredAt:  model.RetiredAt.Time,
+	}
+}
+
+func modelAPIKeyToDomainAPIKey(model APIKey) domain.APIKey {
+	return domain.APIKey{
+		ID:        uint64(model.ID.Int64),
+		UserID:    uint64(model.UserID.Int64),
+		Name:      model.Name.String,
+		Prefix:    model.Prefix.String,
+		KeyHash:   model.KeyHash.String,
+		Scopes:    model.Scopes,
+		ExpiresAt: model.ExpiresAt.Time,
+		CreatedAt: model.CreatedAt.Time,
+	}
+}
+
+func modelSessionToDomainSession(model Session) domain.Session {
+	return domain.Session{
+		ID:            model.Key.String,
+		CreatedAt:     model.CreatedAt.Time,
+		LastSeen:      model.LastSeen.Time,
+		ExpiresAt:     model.ExpiresAt.Time,
+		IdleExpiresAt: model.IdleExpiresAt.Time,
+		IP:            model.IP.String,
+		UserAgent:     model.UserAgent.String,
+	}
+}
+
+func modelDeviceAuthorizationToDomainDeviceAuthorization(model DeviceAuthorization) domain.DeviceAuthorization {
+	return domain.DeviceAuthorization{
+		DeviceCode: model.DeviceCode.String,
+		UserCode:   model.UserCode.String,
+		ClientID:   model.ClientID.String,
+		Scope:      model.Scope.String,
+		Status:     model.Status.String,
+		UserID:     uint64(model.UserID.Int64),
+		Interval:   model.PollInterval.Int64,
+		DecidedAt:  model.DecidedAt.Time,
+	}
+}
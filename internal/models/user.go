@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/djordjev/auth/internal/domain"
@@ -71,6 +72,28 @@ func (r *repositoryUser) GetByUsername(username string) (user domain.User, err e
 	return
 }
 
+func (r *repositoryUser) GetByID(id uint64) (user domain.User, err error) {
+	rows, err := r.db.Query(r.ctx, "select * from users where id = $1", id)
+	if err != nil {
+		err = fmt.Errorf("model GetByID -> can not execute query %w", err)
+		return
+	}
+
+	modelUser, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[User])
+
+	if err == pgx.ErrNoRows {
+		err = modelErrors.ErrNotFound
+		return
+	} else if err != nil {
+		err = fmt.Errorf("model GetByID -> find user by id %d, %w", id, err)
+		return
+	}
+
+	user = modelUserToDomainUser(modelUser)
+
+	return
+}
+
 func (r *repositoryUser) Create(user domain.User) (newUser domain.User, err error) {
 	modelUser := domainUserToModelUser(user)
 
@@ -147,6 +170,47 @@ func (r *repositoryUser) SetPassword(user domain.User, password string) error {
 	return nil
 }
 
+func (r *repositoryUser) SetRole(user domain.User, role string) error {
+	if user.ID == 0 {
+		return fmt.Errorf("missing user ID in update function")
+	}
+
+	result, err := r.db.Exec(r.ctx, "update users set role = $1 where id = $2", role, user.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to set role for user with ID %d %w", user.ID, err)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("user with id %d does not exist", user.ID)
+	}
+
+	return nil
+}
+
+func (r *repositoryUser) SetPayload(user domain.User, payload map[string]any) error {
+	if user.ID == 0 {
+		return fmt.Errorf("missing user ID in update function")
+	}
+
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("unable to encode payload for user with ID %d %w", user.ID, err)
+	}
+
+	result, err := r.db.Exec(r.ctx, "update users set payload = $1 where id = $2", bytes, user.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to set payload for user with ID %d %w", user.ID, err)
+	}
+
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("user with id %d does not exist", user.ID)
+	}
+
+	return nil
+}
+
 func newRepositoryUser(ctx context.Context, db query) *repositoryUser {
 	return &repositoryUser{ctx: ctx, db: db}
 }
@@ -111,6 +111,47 @@ func TestGetByUsername(t *testing.T) {
 	}
 }
 
+func TestGetByID(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryUser(context.TODO(), dbConnection)
+
+	type testCase struct {
+		name        string
+		id          uint64
+		result      domain.User
+		resultError error
+	}
+
+	tests := []testCase{
+		{
+			name:   "user found",
+			id:     existingUser.ID,
+			result: existingUser,
+		},
+		{
+			name:        "user not found",
+			id:          nonExistingUserID,
+			resultError: modelErrors.ErrNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			result, err := repo.GetByID(tc.id)
+
+			require.Equal(t, result, tc.result)
+			if tc.resultError != nil {
+				require.ErrorIs(t, tc.resultError, err)
+			} else {
+				require.Nil(t, err)
+			}
+		})
+	}
+}
+
 func TestCreate(t *testing.T) {
 	newUser := newRandomUser()
 
@@ -289,3 +330,101 @@ func TestSetPassword(t *testing.T) {
 		})
 	}
 }
+
+func TestSetRole(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryUser(context.TODO(), dbConnection)
+
+	type testCase struct {
+		name   string
+		user   domain.User
+		result string
+	}
+
+	tests := []testCase{
+		{
+			name:   "updates role",
+			user:   existingUser,
+			result: "",
+		},
+		{
+			name:   "user doesn't have ID",
+			user:   domain.User{},
+			result: "missing user ID in update function",
+		},
+		{
+			name:   "fails to update",
+			user:   domain.User{ID: nonExistingUserID},
+			result: "does not exist",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			result := repo.SetRole(tc.user, "developer")
+
+			if tc.result != "" {
+				require.ErrorContains(t, result, tc.result)
+				return
+			}
+
+			require.Nil(t, result)
+
+			updated, err := repo.GetByID(tc.user.ID)
+			require.Nil(t, err)
+			require.Equal(t, "developer", updated.Role)
+		})
+	}
+}
+
+func TestSetPayload(t *testing.T) {
+	existingUser, err := storeUser(newRandomUser())
+	require.Nil(t, err, "failed to initialize db state")
+
+	repo := newRepositoryUser(context.TODO(), dbConnection)
+
+	type testCase struct {
+		name   string
+		user   domain.User
+		result string
+	}
+
+	tests := []testCase{
+		{
+			name:   "updates payload",
+			user:   existingUser,
+			result: "",
+		},
+		{
+			name:   "user doesn't have ID",
+			user:   domain.User{},
+			result: "missing user ID in update function",
+		},
+		{
+			name:   "fails to update",
+			user:   domain.User{ID: nonExistingUserID},
+			result: "does not exist",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			result := repo.SetPayload(tc.user, map[string]any{"department": "engineering"})
+
+			if tc.result != "" {
+				require.ErrorContains(t, result, tc.result)
+				return
+			}
+
+			require.Nil(t, result)
+
+			updated, err := repo.GetByID(tc.user.ID)
+			require.Nil(t, err)
+			require.Equal(t, map[string]any{"department": "engineering"}, updated.Payload)
+		})
+	}
+}
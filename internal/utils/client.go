@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+const clientKey = "__app_client_key"
+
+// Client describes device request came from
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+func InjectClientIntoContext(r *http.Request) *http.Request {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	client := Client{IP: ip, UserAgent: r.UserAgent()}
+
+	return r.WithContext(context.WithValue(r.Context(), clientKey, client))
+}
+
+// GetClient returns client of the request or empty client outside of requests
+func GetClient(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey).(Client)
+	return client
+}
@@ -2,8 +2,11 @@ package utils
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Mailjet struct {
@@ -11,6 +14,82 @@ type Mailjet struct {
 	SecretKey string
 }
 
+type WebAuthn struct {
+	RPID          string
+	RPDisplayName string
+	RPOrigins     []string
+}
+
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	Domains      []string
+	AutoLink     bool
+}
+
+type SAMLProvider struct {
+	Name         string
+	MetadataURL  string
+	EmailAttr    string
+	RoleAttr     string
+	PayloadAttrs []string
+	Domains      []string
+	AutoLink     bool
+}
+
+type SigningKeys struct {
+	Key       string
+	Dir       string
+	Secret    string
+	Algorithm string
+	Rotation  time.Duration
+	Overlap   time.Duration
+}
+
+type LDAP struct {
+	URL          string
+	StartTLS     bool
+	UserDN       string
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	Filter       string
+	EmailAttr    string
+	GroupAttr    string
+	Roles        []LDAPRole
+	AutoLink     bool
+}
+
+type LDAPRole struct {
+	Group string
+	Role  string
+}
+
+// ForwardAuthRule restricts paths starting with Path to users with one of the roles
+type ForwardAuthRule struct {
+	Path  string
+	Roles []string
+}
+
+type ForwardAuth struct {
+	LoginURL string
+	Rules    []ForwardAuthRule
+}
+
+// ProxyRoute forwards requests under Path to Upstream with Path stripped
+type ProxyRoute struct {
+	Path     string
+	Upstream *url.URL
+}
+
+type Proxy struct {
+	Routes []ProxyRoute
+	Secret string
+}
+
 type Config struct {
 	DBHost              string
 	DBName              string
@@ -24,12 +103,33 @@ type Config struct {
 	Mailjet             Mailjet
 	VerificationLink    string
 	ForgetPasswordLink  string
+	MagicLink           string
 	Sender              string
 	RedisPort           uint
 	RedisHost           string
 	RedisPassword       string
 	RedisDatabase       int
 	SessionCookie       string
+	SessionStore        string
+	SessionSecrets      []string
+	SessionIdleTimeout  time.Duration
+	SessionLifetime     time.Duration
+	MFAIssuer           string
+	WebAuthn            WebAuthn
+	OIDCRedirectURL     string
+	OIDCProviders       []OIDCProvider
+	OIDCIssuer          string
+	OIDCLoginURL        string
+	OIDCConsentURL      string
+	OIDCDeviceURL       string
+	SAMLURL             string
+	SAMLKey             string
+	SAMLCertificate     string
+	SAMLProviders       []SAMLProvider
+	SigningKeys         SigningKeys
+	LDAP                LDAP
+	ForwardAuth         ForwardAuth
+	Proxy               Proxy
 }
 
 func BuildConfigFromEnv() (Config, error) {
@@ -70,6 +170,7 @@ func BuildConfigFromEnv() (Config, error) {
 
 	config.VerificationLink = os.Getenv("VERIFICATION_LINK")
 	config.ForgetPasswordLink = os.Getenv("FORGET_PASSWORD_LINK")
+	config.MagicLink = os.Getenv("MAGIC_LINK")
 	config.Sender = os.Getenv("SENDER")
 
 	if os.Getenv("REDIS_PORT") == "" {
@@ -102,9 +203,266 @@ func BuildConfigFromEnv() (Config, error) {
 		config.SessionCookie = "_tkn"
 	}
 
+	config.SessionStore = os.Getenv("SESSION_STORE")
+	switch config.SessionStore {
+	case "":
+		config.SessionStore = SESSION_STORE_REDIS
+	case SESSION_STORE_REDIS, SESSION_STORE_MEMORY, SESSION_STORE_POSTGRES, SESSION_STORE_COOKIE:
+	default:
+		return Config{}, fmt.Errorf("unknown SESSION_STORE %s", config.SessionStore)
+	}
+
+	if secrets := os.Getenv("SESSION_SECRETS"); secrets != "" {
+		config.SessionSecrets = strings.Split(secrets, ",")
+	}
+
+	if config.SessionStore == SESSION_STORE_COOKIE && len(config.SessionSecrets) == 0 {
+		return Config{}, fmt.Errorf("SESSION_SECRETS is required with %s session store", SESSION_STORE_COOKIE)
+	}
+
+	config.SessionIdleTimeout = 5 * 24 * time.Hour
+	if idle := os.Getenv("SESSION_IDLE_TIMEOUT"); idle != "" {
+		duration, err := time.ParseDuration(idle)
+		if err != nil {
+			return Config{}, err
+		}
+
+		config.SessionIdleTimeout = duration
+	}
+
+	config.SessionLifetime = 30 * 24 * time.Hour
+	if lifetime := os.Getenv("SESSION_LIFETIME"); lifetime != "" {
+		duration, err := time.ParseDuration(lifetime)
+		if err != nil {
+			return Config{}, err
+		}
+
+		config.SessionLifetime = duration
+	}
+
+	if config.SessionIdleTimeout > config.SessionLifetime {
+		config.SessionIdleTimeout = config.SessionLifetime
+	}
+
+	config.MFAIssuer = os.Getenv("MFA_ISSUER")
+	if config.MFAIssuer == "" {
+		config.MFAIssuer = "auth"
+	}
+
+	config.WebAuthn.RPID = os.Getenv("WEBAUTHN_RP_ID")
+	if config.WebAuthn.RPID == "" {
+		config.WebAuthn.RPID = config.Domain
+	}
+
+	config.WebAuthn.RPDisplayName = os.Getenv("WEBAUTHN_RP_NAME")
+	if config.WebAuthn.RPDisplayName == "" {
+		config.WebAuthn.RPDisplayName = config.MFAIssuer
+	}
+
+	if origins := os.Getenv("WEBAUTHN_RP_ORIGINS"); origins != "" {
+		config.WebAuthn.RPOrigins = strings.Split(origins, ",")
+	}
+
+	config.OIDCRedirectURL = strings.TrimSuffix(os.Getenv("OIDC_REDIRECT_URL"), "/")
+
+	if providers := os.Getenv("OIDC_PROVIDERS"); providers != "" {
+		for _, name := range strings.Split(providers, ",") {
+			config.OIDCProviders = append(config.OIDCProviders, oidcProviderFromEnv(strings.TrimSpace(name)))
+		}
+	}
+
+	config.OIDCIssuer = strings.TrimSuffix(os.Getenv("OIDC_ISSUER"), "/")
+	config.OIDCLoginURL = os.Getenv("OIDC_LOGIN_URL")
+	config.OIDCConsentURL = os.Getenv("OIDC_CONSENT_URL")
+	config.OIDCDeviceURL = os.Getenv("OIDC_DEVICE_URL")
+
+	config.SAMLURL = strings.TrimSuffix(os.Getenv("SAML_URL"), "/")
+	config.SAMLKey = os.Getenv("SAML_KEY")
+	config.SAMLCertificate = os.Getenv("SAML_CERTIFICATE")
+
+	if providers := os.Getenv("SAML_PROVIDERS"); providers != "" {
+		for _, name := range strings.Split(providers, ",") {
+			config.SAMLProviders = append(config.SAMLProviders, samlProviderFromEnv(strings.TrimSpace(name)))
+		}
+	}
+
+	config.SigningKeys.Key = os.Getenv("SIGNING_KEY")
+	config.SigningKeys.Dir = os.Getenv("SIGNING_KEY_DIR")
+	config.SigningKeys.Secret = os.Getenv("SIGNING_KEY_SECRET")
+
+	config.SigningKeys.Algorithm = os.Getenv("SIGNING_KEY_ALGORITHM")
+	if config.SigningKeys.Algorithm == "" {
+		config.SigningKeys.Algorithm = "RS256"
+	}
+
+	config.SigningKeys.Rotation = 30 * 24 * time.Hour
+	if rotation := os.Getenv("SIGNING_KEY_ROTATION"); rotation != "" {
+		duration, err := time.ParseDuration(rotation)
+		if err != nil {
+			return Config{}, err
+		}
+
+		config.SigningKeys.Rotation = duration
+	}
+
+	config.SigningKeys.Overlap = 24 * time.Hour
+	if overlap := os.Getenv("SIGNING_KEY_OVERLAP"); overlap != "" {
+		duration, err := time.ParseDuration(overlap)
+		if err != nil {
+			return Config{}, err
+		}
+
+		config.SigningKeys.Overlap = duration
+	}
+
+	config.LDAP.URL = os.Getenv("LDAP_URL")
+	config.LDAP.StartTLS = os.Getenv("LDAP_START_TLS") == "true"
+	config.LDAP.UserDN = os.Getenv("LDAP_USER_DN")
+	config.LDAP.BindDN = os.Getenv("LDAP_BIND_DN")
+	config.LDAP.BindPassword = os.Getenv("LDAP_BIND_PASSWORD")
+	config.LDAP.BaseDN = os.Getenv("LDAP_BASE_DN")
+	config.LDAP.AutoLink = os.Getenv("LDAP_AUTO_LINK") == "true"
+
+	config.LDAP.Filter = os.Getenv("LDAP_FILTER")
+	if config.LDAP.Filter == "" {
+		config.LDAP.Filter = "(uid=%s)"
+	}
+
+	config.LDAP.EmailAttr = os.Getenv("LDAP_EMAIL_ATTRIBUTE")
+	if config.LDAP.EmailAttr == "" {
+		config.LDAP.EmailAttr = "mail"
+	}
+
+	config.LDAP.GroupAttr = os.Getenv("LDAP_GROUP_ATTRIBUTE")
+	if config.LDAP.GroupAttr == "" {
+		config.LDAP.GroupAttr = "memberOf"
+	}
+
+	if roles := os.Getenv("LDAP_ROLES"); roles != "" {
+		for _, mapping := range strings.Split(roles, ";") {
+			// group DNs contain commas and equal signs so role is after the last colon
+			separator := strings.LastIndex(mapping, ":")
+			if separator == -1 {
+				return Config{}, fmt.Errorf("invalid LDAP_ROLES mapping %s", mapping)
+			}
+
+			config.LDAP.Roles = append(config.LDAP.Roles, LDAPRole{
+				Group: strings.TrimSpace(mapping[:separator]),
+				Role:  strings.TrimSpace(mapping[separator+1:]),
+			})
+		}
+	}
+
+	config.ForwardAuth.LoginURL = os.Getenv("FORWARD_AUTH_LOGIN_URL")
+
+	if rules := os.Getenv("FORWARD_AUTH_RULES"); rules != "" {
+		for _, rule := range strings.Split(rules, ";") {
+			path, roles, found := strings.Cut(rule, ":")
+			path = strings.TrimSpace(path)
+			if !found || !strings.HasPrefix(path, "/") {
+				return Config{}, fmt.Errorf("invalid FORWARD_AUTH_RULES rule %s", rule)
+			}
+
+			parsed := ForwardAuthRule{Path: path}
+			for _, role := range strings.Split(roles, ",") {
+				if role = strings.TrimSpace(role); role != "" {
+					parsed.Roles = append(parsed.Roles, role)
+				}
+			}
+
+			config.ForwardAuth.Rules = append(config.ForwardAuth.Rules, parsed)
+		}
+	}
+
+	config.Proxy.Secret = os.Getenv("PROXY_SECRET")
+
+	if routes := os.Getenv("PROXY_ROUTES"); routes != "" {
+		for _, route := range strings.Split(routes, ";") {
+			path, upstream, found := strings.Cut(route, "=")
+			path = strings.TrimSpace(path)
+
+			// root is where the app itself is mounted
+			if !found || !strings.HasPrefix(path, "/") || path == "/" {
+				return Config{}, fmt.Errorf("invalid PROXY_ROUTES route %s", route)
+			}
+
+			parsed, err := url.Parse(strings.TrimSpace(upstream))
+			if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+				return Config{}, fmt.Errorf("invalid upstream of PROXY_ROUTES route %s", route)
+			}
+
+			config.Proxy.Routes = append(config.Proxy.Routes, ProxyRoute{
+				Path:     strings.TrimSuffix(path, "/") + "/",
+				Upstream: parsed,
+			})
+		}
+
+		if config.Proxy.Secret == "" {
+			return Config{}, fmt.Errorf("PROXY_SECRET is required with PROXY_ROUTES")
+		}
+	}
+
 	return config, nil
 }
 
+func oidcProviderFromEnv(name string) OIDCProvider {
+	prefix := fmt.Sprintf("OIDC_%s_", strings.ToUpper(name))
+
+	provider := OIDCProvider{
+		Name:         name,
+		Issuer:       os.Getenv(prefix + "ISSUER"),
+		ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+		ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+		Scopes:       []string{"openid", "email", "profile"},
+		AutoLink:     os.Getenv(prefix+"AUTO_LINK") == "true",
+	}
+
+	if scopes := os.Getenv(prefix + "SCOPES"); scopes != "" {
+		provider.Scopes = strings.Split(scopes, ",")
+	}
+
+	provider.Domains = domainsFromEnv(prefix + "DOMAINS")
+
+	return provider
+}
+
+func samlProviderFromEnv(name string) SAMLProvider {
+	prefix := fmt.Sprintf("SAML_%s_", strings.ToUpper(name))
+
+	provider := SAMLProvider{
+		Name:        name,
+		MetadataURL: os.Getenv(prefix + "METADATA_URL"),
+		EmailAttr:   os.Getenv(prefix + "EMAIL_ATTRIBUTE"),
+		RoleAttr:    os.Getenv(prefix + "ROLE_ATTRIBUTE"),
+		AutoLink:    os.Getenv(prefix+"AUTO_LINK") == "true",
+	}
+
+	if provider.EmailAttr == "" {
+		provider.EmailAttr = "email"
+	}
+
+	if attributes := os.Getenv(prefix + "PAYLOAD_ATTRIBUTES"); attributes != "" {
+		provider.PayloadAttrs = strings.Split(attributes, ",")
+	}
+
+	provider.Domains = domainsFromEnv(prefix + "DOMAINS")
+
+	return provider
+}
+
+func domainsFromEnv(key string) (domains []string) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	for _, domain := range strings.Split(value, ",") {
+		domains = append(domains, strings.ToLower(strings.TrimSpace(domain)))
+	}
+
+	return
+}
+
 func (config Config) GetConnectionString() string {
 	if config.DBName == "" || config.DBUser == "" || config.DBPass == "" || config.DBHost == "" {
 		panic(fmt.Errorf("missing database fields in configuration"))
@@ -124,6 +482,46 @@ func (config Config) IsDev() bool {
 	return config.GoEnv == "development"
 }
 
+func (config Config) HasWebAuthnSetup() bool {
+	return config.WebAuthn.RPID != "" && len(config.WebAuthn.RPOrigins) > 0
+}
+
+func (config Config) GetOIDCProvider(name string) (provider OIDCProvider, ok bool) {
+	for _, p := range config.OIDCProviders {
+		if p.Name == name {
+			return p, true
+		}
+	}
+
+	return
+}
+
+func (config Config) GetSAMLProvider(name string) (provider SAMLProvider, ok bool) {
+	for _, p := range config.SAMLProviders {
+		if p.Name == name {
+			return p, true
+		}
+	}
+
+	return
+}
+
+func (config Config) IsOIDCProvider() bool {
+	return config.OIDCIssuer != ""
+}
+
+func (config Config) HasDeviceFlow() bool {
+	return config.IsOIDCProvider() && config.OIDCDeviceURL != ""
+}
+
+func (config Config) HasLDAPSetup() bool {
+	return config.LDAP.URL != "" && (config.LDAP.UserDN != "" || config.LDAP.BaseDN != "")
+}
+
+func (config Config) HasProxy() bool {
+	return len(config.Proxy.Routes) > 0
+}
+
 func (config Config) HasEmailSetup() bool {
 	return config.Mailjet.ApiKey != "" && config.Mailjet.SecretKey != ""
 }
@@ -2,4 +2,27 @@ package utils
 
 import "time"
 
-var SESSION_TTL = 5 * 24 * time.Hour
+var MFA_CHALLENGE_TTL = 5 * time.Minute
+var WEBAUTHN_CEREMONY_TTL = 5 * time.Minute
+var MAGIC_LINK_TTL = 15 * time.Minute
+var ONE_TIME_CODE_TTL = 10 * time.Minute
+var OIDC_TOKEN_TTL = time.Hour
+var ACCESS_TOKEN_TTL = 15 * time.Minute
+var REFRESH_TOKEN_TTL = 30 * 24 * time.Hour
+var DEVICE_CODE_TTL = 10 * time.Minute
+var DEVICE_POLL_INTERVAL = 5 * time.Second
+var LDAP_TIMEOUT = 10 * time.Second
+var OIDC_DISCOVERY_REFRESH = time.Hour
+var SAML_METADATA_TIMEOUT = 10 * time.Second
+var SAML_METADATA_REFRESH = time.Hour
+
+const SESSION_STORE_REDIS = "redis"
+const SESSION_STORE_MEMORY = "memory"
+const SESSION_STORE_POSTGRES = "postgres"
+const SESSION_STORE_COOKIE = "cookie"
+
+// SESSION_USER_REFRESH is how long user of the session is cached before it's read from the database again
+var SESSION_USER_REFRESH = 30 * time.Second
+
+// SESSION_REVOCATIONS_REFRESH is how often revoked cookie sessions are reloaded from the database
+var SESSION_REVOCATIONS_REFRESH = 10 * time.Second
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"net/http"
+	"strings"
+)
+
+// SessionToken reads session key from the session cookie falling back to bearer
+// authorization used by api keys and then to token query param
+func SessionToken(r *http.Request, cookieName string) string {
+	tokenCookie, err := r.Cookie(cookieName)
+	if err == nil {
+		return tokenCookie.Value
+	}
+
+	bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if ok && bearer != "" {
+		return bearer
+	}
+
+	return r.URL.Query().Get("token")
+}
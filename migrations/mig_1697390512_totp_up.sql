@@ -0,0 +1,8 @@
+create table user_totps (
+  id bigserial primary key,
+  created_at timestamptz default now(),
+  user_id bigint not null unique references users(id) on delete cascade on update cascade,
+  secret varchar not null,
+  confirmed boolean default false,
+  last_step bigint default 0
+);
@@ -0,0 +1,3 @@
+drop index idx_recovery_code_user;
+
+drop table recovery_codes;
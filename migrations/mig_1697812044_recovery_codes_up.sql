@@ -0,0 +1,11 @@
+create table recovery_codes (
+  id bigserial primary key,
+  created_at timestamptz default now(),
+  user_id bigint not null references users(id) on delete cascade on update cascade,
+  code_hash varchar not null,
+  used_at timestamptz
+);
+
+create index idx_recovery_code_user on recovery_codes (
+  user_id, code_hash
+);
@@ -0,0 +1,3 @@
+drop index idx_webauthn_credential_user;
+
+drop table webauthn_credentials;
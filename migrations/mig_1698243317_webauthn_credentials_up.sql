@@ -0,0 +1,18 @@
+create table webauthn_credentials (
+  id bigserial primary key,
+  created_at timestamptz default now(),
+  last_used_at timestamptz,
+  user_id bigint not null references users(id) on delete cascade on update cascade,
+  credential_id bytea not null unique,
+  public_key bytea not null,
+  attestation_type varchar,
+  aaguid bytea,
+  sign_count bigint default 0,
+  transports varchar[],
+  backup_eligible boolean default false,
+  backup_state boolean default false
+);
+
+create index idx_webauthn_credential_user on webauthn_credentials (
+  user_id asc
+);
@@ -0,0 +1,3 @@
+drop index idx_magic_link_user;
+
+drop table magic_links;
@@ -0,0 +1,11 @@
+create table magic_links (
+  id bigserial primary key,
+  created_at timestamptz default now(),
+  expires_at timestamptz not null,
+  token varchar not null unique,
+  user_id bigint not null references users(id) on delete cascade on update cascade
+);
+
+create index idx_magic_link_user on magic_links (
+  user_id
+);
@@ -0,0 +1,10 @@
+create table user_identities (
+  id bigserial primary key,
+  created_at timestamptz default now(),
+  user_id bigint not null references users(id) on delete cascade on update cascade,
+  provider varchar not null,
+  subject varchar not null,
+  email varchar,
+  unique (provider, subject),
+  unique (user_id, provider)
+);
@@ -0,0 +1,3 @@
+drop table oauth_consents;
+
+drop table oauth_clients;
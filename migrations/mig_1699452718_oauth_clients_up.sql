@@ -0,0 +1,18 @@
+create table oauth_clients (
+  id varchar primary key,
+  created_at timestamptz default now(),
+  name varchar not null,
+  secret_hash varchar,
+  redirect_uris varchar[] not null,
+  trusted boolean default false
+);
+
+create table oauth_consents (
+  id bigserial primary key,
+  created_at timestamptz default now(),
+  updated_at timestamptz default now(),
+  user_id bigint not null references users(id) on delete cascade on update cascade,
+  client_id varchar not null references oauth_clients(id) on delete cascade on update cascade,
+  scopes varchar[] not null,
+  unique (user_id, client_id)
+);
@@ -0,0 +1,13 @@
+create table refresh_tokens (
+  id bigserial primary key,
+  created_at timestamptz default now(),
+  expires_at timestamptz not null,
+  used_at timestamptz,
+  user_id bigint not null references users(id) on delete cascade on update cascade,
+  family varchar not null,
+  token_hash varchar not null unique
+);
+
+create index idx_refresh_token_family on refresh_tokens (
+  family
+);
@@ -0,0 +1,7 @@
+create table signing_keys (
+  id varchar primary key,
+  created_at timestamptz default now(),
+  retired_at timestamptz,
+  algorithm varchar not null,
+  private_key bytea not null
+);
@@ -0,0 +1,14 @@
+create table api_keys (
+  id bigserial primary key,
+  created_at timestamptz default now(),
+  expires_at timestamptz,
+  user_id bigint not null references users(id) on delete cascade on update cascade,
+  name varchar not null,
+  prefix varchar not null,
+  key_hash varchar not null unique,
+  scopes varchar[] not null
+);
+
+create index idx_api_key_user on api_keys (
+  user_id
+);
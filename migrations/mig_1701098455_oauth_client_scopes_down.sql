@@ -0,0 +1 @@
+alter table oauth_clients drop column scopes;
@@ -0,0 +1 @@
+alter table oauth_clients add column scopes varchar[] not null default '{}';
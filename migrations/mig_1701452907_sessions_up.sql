@@ -0,0 +1,15 @@
+create table sessions (
+  key varchar primary key,
+  user_id bigint not null references users(id) on delete cascade on update cascade,
+  created_at timestamptz not null default now(),
+  last_seen timestamptz not null default now(),
+  idle_timeout bigint not null,
+  idle_expires_at timestamptz not null,
+  expires_at timestamptz not null,
+  ip varchar not null default '',
+  user_agent varchar not null default ''
+);
+
+create index idx_session_user on sessions (
+  user_id
+);
@@ -0,0 +1,2 @@
+drop table session_user_changes;
+drop table revoked_sessions;
@@ -0,0 +1,10 @@
+create table revoked_sessions (
+  id varchar primary key,
+  expires_at timestamptz not null
+);
+
+create table session_user_changes (
+  user_id bigint primary key references users(id) on delete cascade on update cascade,
+  changed_at timestamptz not null,
+  expires_at timestamptz not null
+);
@@ -0,0 +1,5 @@
+drop table device_authorizations;
+drop table ceremonies;
+drop table challenges;
+drop table one_time_code_attempts;
+drop table one_time_codes;
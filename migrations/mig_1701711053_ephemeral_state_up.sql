@@ -0,0 +1,41 @@
+create table one_time_codes (
+  purpose varchar not null,
+  user_id bigint not null references users(id) on delete cascade on update cascade,
+  code varchar not null,
+  expires_at timestamptz not null,
+  primary key (purpose, user_id)
+);
+
+create table one_time_code_attempts (
+  purpose varchar not null,
+  user_id bigint not null references users(id) on delete cascade on update cascade,
+  attempts bigint not null,
+  expires_at timestamptz not null,
+  primary key (purpose, user_id)
+);
+
+create table challenges (
+  key varchar primary key,
+  user_id bigint not null references users(id) on delete cascade on update cascade,
+  attempts bigint not null default 0,
+  expires_at timestamptz not null
+);
+
+create table ceremonies (
+  key varchar primary key,
+  data bytea not null,
+  expires_at timestamptz not null
+);
+
+create table device_authorizations (
+  device_code varchar primary key,
+  user_code varchar not null unique,
+  client_id varchar not null,
+  scope varchar not null default '',
+  status varchar not null,
+  user_id bigint not null default 0,
+  poll_interval bigint not null,
+  polled_at timestamptz,
+  decided_at timestamptz,
+  expires_at timestamptz not null
+);
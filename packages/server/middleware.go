@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+)
+
+const userKey = "__app_user_key"
+
+// User is the signed in user host app handlers get from UserFromContext
+type User = domain.User
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// RequireSession lets through only requests with valid session key or api key, the same ones
+// accepted by the app's own endpoints. User is put into request context.
+func (s *server) RequireSession(next http.Handler) http.Handler {
+	return s.requireUser(next, func(User) bool { return true })
+}
+
+// RequireVerified lets through only users who verified their account
+func (s *server) RequireVerified(next http.Handler) http.Handler {
+	return s.requireUser(next, func(user User) bool { return user.Verified })
+}
+
+// RequireRole lets through only users with one of the roles
+func (s *server) RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return s.requireUser(next, func(user User) bool { return slices.Contains(roles, user.Role) })
+	}
+}
+
+// UserFromContext returns user of the request passed through one of the middlewares
+func UserFromContext(ctx context.Context) (user User, ok bool) {
+	user, ok = ctx.Value(userKey).(User)
+	return
+}
+
+// requireUser resolves user once so middlewares can be chained. Requests without session
+// get 401 and those whose user isn't allowed or whose api key doesn't cover the path get 403.
+func (s *server) requireUser(next http.Handler, allowed func(User) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := UserFromContext(r.Context())
+		if !ok {
+			token := utils.SessionToken(r, s.config.SessionCookie)
+			if token == "" {
+				respondWithError(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			var err error
+			user, err = s.domain.Session(domain.NewSetup(r.Context(), s.logger), token)
+			if err == domain.ErrNoSession {
+				respondWithError(w, "unauthorized", http.StatusUnauthorized)
+				return
+			} else if err != nil {
+				utils.LogError(s.logger, err)
+				respondWithError(w, "internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+		}
+
+		if !allowed(user) || !user.InScope(r.URL.Path) {
+			respondWithError(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func respondWithError(w http.ResponseWriter, message string, status int) {
+	responseData, _ := json.Marshal(errorResponse{Error: message})
+
+	http.Error(w, string(responseData), status)
+}
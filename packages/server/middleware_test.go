@@ -0,0 +1,150 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/djordjev/auth/internal/domain"
+	"github.com/djordjev/auth/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	verifiedAdmin := User{ID: 452, Email: "djvukovic@gmail.com", Role: "admin", Verified: true}
+
+	tests := []struct {
+		name       string
+		middleware func(*server) func(http.Handler) http.Handler
+		token      string
+		returnUser User
+		returnErr  error
+		statusCode int
+		response   string
+	}{
+		{
+			name:       "session",
+			middleware: func(s *server) func(http.Handler) http.Handler { return s.RequireSession },
+			token:      "session",
+			returnUser: verifiedAdmin,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "no token",
+			middleware: func(s *server) func(http.Handler) http.Handler { return s.RequireSession },
+			statusCode: http.StatusUnauthorized,
+			response:   utils.ErrorJSON("unauthorized"),
+		},
+		{
+			name:       "expired session",
+			middleware: func(s *server) func(http.Handler) http.Handler { return s.RequireSession },
+			token:      "session",
+			returnErr:  domain.ErrNoSession,
+			statusCode: http.StatusUnauthorized,
+			response:   utils.ErrorJSON("unauthorized"),
+		},
+		{
+			name:       "verified",
+			middleware: func(s *server) func(http.Handler) http.Handler { return s.RequireVerified },
+			token:      "session",
+			returnUser: verifiedAdmin,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "not verified",
+			middleware: func(s *server) func(http.Handler) http.Handler { return s.RequireVerified },
+			token:      "session",
+			returnUser: User{ID: 452, Role: "admin"},
+			statusCode: http.StatusForbidden,
+			response:   utils.ErrorJSON("forbidden"),
+		},
+		{
+			name:       "allowed role",
+			middleware: func(s *server) func(http.Handler) http.Handler { return s.RequireRole("editor", "admin") },
+			token:      "session",
+			returnUser: verifiedAdmin,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "role not allowed",
+			middleware: func(s *server) func(http.Handler) http.Handler { return s.RequireRole("editor") },
+			token:      "session",
+			returnUser: verifiedAdmin,
+			statusCode: http.StatusForbidden,
+			response:   utils.ErrorJSON("forbidden"),
+		},
+		{
+			name:       "api key scoped to the path",
+			middleware: func(s *server) func(http.Handler) http.Handler { return s.RequireSession },
+			token:      "ak_secret",
+			returnUser: User{ID: 452, Scopes: []string{"/reports"}},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "api key scoped to other path",
+			middleware: func(s *server) func(http.Handler) http.Handler { return s.RequireSession },
+			token:      "ak_secret",
+			returnUser: User{ID: 452, Scopes: []string{"/wiki"}},
+			statusCode: http.StatusForbidden,
+			response:   utils.ErrorJSON("forbidden"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			baseMock := domain.NewMockDomain(t)
+
+			if tc.token != "" {
+				baseMock.EXPECT().Session(mock.Anything, tc.token).Return(tc.returnUser, tc.returnErr)
+			}
+
+			srv := &server{domain: baseMock, config: utils.Config{SessionCookie: "_tkn"}, logger: utils.NewSilentLogger()}
+
+			var handled User
+			handler := tc.middleware(srv)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handled, _ = UserFromContext(r.Context())
+			}))
+
+			req := utils.RequestBuilder("GET", "/reports")("")
+			if tc.token != "" {
+				req.AddCookie(&http.Cookie{Name: "_tkn", Value: tc.token})
+			}
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.statusCode, rr.Code)
+			if tc.response != "" {
+				require.JSONEq(t, tc.response, rr.Body.String())
+				return
+			}
+
+			require.Equal(t, tc.returnUser.ID, handled.ID)
+		})
+	}
+}
+
+func TestMiddlewareChain(t *testing.T) {
+	t.Parallel()
+
+	baseMock := domain.NewMockDomain(t)
+	baseMock.EXPECT().Session(mock.Anything, "ak_key").Return(User{ID: 452, Role: "admin", Verified: true}, nil).Once()
+
+	srv := &server{domain: baseMock, config: utils.Config{SessionCookie: "_tkn"}, logger: utils.NewSilentLogger()}
+
+	handler := srv.RequireVerified(srv.RequireRole("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	req := utils.RequestBuilder("GET", "/reports")("")
+	req.Header.Set("Authorization", "Bearer ak_key")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	// session is resolved only once
+	require.Equal(t, http.StatusNoContent, rr.Code)
+}
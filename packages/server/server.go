@@ -12,11 +12,14 @@ import (
 	"github.com/djordjev/auth/internal/utils"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
+	"golang.org/x/exp/slog"
 )
 
 type server struct {
 	mux    *http.ServeMux
 	api    api.Api
+	domain domain.Domain
+	logger *slog.Logger
 	config utils.Config
 	pool   *pgxpool.Pool
 }
@@ -25,10 +28,25 @@ func (s *server) Mount(url string) {
 	s.api.Mount(url)
 }
 
+// MountProxy puts configured upstream routes behind authentication. Has to be called after Mount.
+func (s *server) MountProxy() error {
+	return s.api.MountProxy()
+}
+
 func (s *server) Close() {
 	s.pool.Close()
 }
 
+// RotateSigningKey creates a new token signing key, other running instances pick it up on their own
+func (s *server) RotateSigningKey() (kid string, err error) {
+	return s.domain.RotateSigningKey(domain.NewSetup(context.Background(), s.logger))
+}
+
+// CreateOAuthClient registers client of the OpenID provider and returns its secret
+func (s *server) CreateOAuthClient(client domain.OAuthClient, public bool) (created domain.OAuthClient, secret string, err error) {
+	return s.domain.CreateOAuthClient(domain.NewSetup(context.Background(), s.logger), client, public)
+}
+
 func NewServer(mux *http.ServeMux, config utils.Config) *server {
 	srv := &server{mux: mux, config: config}
 
@@ -45,7 +63,7 @@ func (s *server) setup() {
 		panic(err)
 	}
 
-	// Init redis
+	// Init redis, client connects on the first use so it's not required when nothing is kept in redis
 	client := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", s.config.RedisHost, s.config.RedisPort),
 		Password: s.config.RedisPassword,
@@ -53,7 +71,10 @@ func (s *server) setup() {
 	})
 
 	// Setup repos
-	repo := models.NewRepository(pool, client)
+	repo, err := models.NewRepository(pool, client, s.config)
+	if err != nil {
+		panic(err)
+	}
 
 	// Init api
 	logger := utils.MustBuildLogger(s.config)
@@ -66,6 +87,8 @@ func (s *server) setup() {
 
 	// Init app domain
 	appDomain := domain.NewDomain(repo, s.config, notifier)
+	s.domain = appDomain
+	s.logger = logger
 
 	// Init API
 	appApi := api.NewApi(s.config, s.mux, appDomain, logger)